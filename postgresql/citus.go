@@ -0,0 +1,121 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// citusDatabasePropagationVersion is the first Citus version propagating CREATE and DROP DATABASE
+// to the worker nodes, when citus.enable_create_database_propagation is enabled.
+var citusDatabasePropagationVersion = semver.MustParse("12.1.0")
+
+// citusRolePropagationVersion is the first Citus version propagating CREATE and DROP ROLE to the worker nodes,
+// when citus.enable_create_role_propagation is enabled (ALTER ROLE is propagated by the previous versions too).
+var citusRolePropagationVersion = semver.MustParse("11.0.0")
+
+// citusVersion returns the version of the citus extension installed in the database of db (e.g. `12.1-1`),
+// or false if it is not installed.
+func citusVersion(db QueryAble) (string, bool, error) {
+	var version string
+	err := db.QueryRow("SELECT extversion FROM pg_catalog.pg_extension WHERE extname = 'citus'").Scan(&version)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", false, nil
+	case err != nil:
+		return "", false, fmt.Errorf("could not read the version of the citus extension: %w", err)
+	}
+	return version, true, nil
+}
+
+// parseCitusVersion parses the version of the citus extension, without the revision of its scripts
+// (the `-1` of `12.1-1`).
+func parseCitusVersion(version string) (semver.Version, error) {
+	parsed, err := semver.ParseTolerant(strings.SplitN(version, "-", 2)[0])
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("could not parse the version of the citus extension %q: %w", version, err)
+	}
+	return parsed, nil
+}
+
+// installedCitusVersion returns the version of the citus extension, which must be installed in database.
+func installedCitusVersion(db QueryAble, database string) (semver.Version, error) {
+	extVersion, installed, err := citusVersion(db)
+	if err != nil {
+		return semver.Version{}, err
+	}
+	if !installed {
+		return semver.Version{}, fmt.Errorf("citus is enabled but the citus extension is not installed in database %s", database)
+	}
+	return parseCitusVersion(extVersion)
+}
+
+// setCitusRolePropagation enables, with the citus setting of the provider, the propagation of the role DDL
+// of txn to the worker nodes by Citus 11.0+. Older Citus versions only create the roles on the coordinator,
+// so ALTER ROLE is not propagated either: it would fail on the worker nodes, where the role doesn't exist.
+func setCitusRolePropagation(db *DBConnection, txn *sql.Tx) error {
+	if !db.client.config.Citus {
+		return nil
+	}
+
+	version, err := installedCitusVersion(txn, db.client.databaseName)
+	if err != nil {
+		return err
+	}
+
+	value := "on"
+	settings := []string{"citus.enable_create_role_propagation", "citus.enable_alter_role_propagation", "citus.enable_alter_role_set_propagation"}
+	if version.LT(citusRolePropagationVersion) {
+		log.Printf("[WARN] Citus %s doesn't propagate roles to the worker nodes (it requires Citus %s)", version, citusRolePropagationVersion)
+		value = "off"
+		settings = settings[1:]
+	}
+	for _, setting := range settings {
+		if _, err := txn.Exec(fmt.Sprintf("SET LOCAL %s = %s", setting, value)); err != nil {
+			return fmt.Errorf("could not set %s: %w", setting, err)
+		}
+	}
+	return nil
+}
+
+// execDatabaseDDL executes a CREATE or DROP DATABASE statement. With the citus setting of the provider,
+// it is propagated to the worker nodes by Citus 12.1+, on the coordinator the provider is connected to.
+// Older Citus versions only run it on the coordinator, the database has to be managed on each node.
+func execDatabaseDDL(db *DBConnection, query string) error {
+	if !db.client.config.Citus {
+		_, err := db.Exec(query)
+		return err
+	}
+
+	version, err := installedCitusVersion(db, db.client.databaseName)
+	if err != nil {
+		return err
+	}
+	if version.LT(citusDatabasePropagationVersion) {
+		log.Printf("[WARN] Citus %s doesn't propagate databases to the worker nodes (it requires Citus %s)", version, citusDatabasePropagationVersion)
+		_, err := db.Exec(query)
+		return err
+	}
+
+	// The setting is enabled for the session of a dedicated connection, CREATE DATABASE cannot run in a transaction.
+	conn, err := db.Conn(db.context())
+	if err != nil {
+		return fmt.Errorf("could not get a connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(db.context(), "SET citus.enable_create_database_propagation = on"); err != nil {
+		return fmt.Errorf("could not enable the propagation of databases: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(db.context(), "RESET citus.enable_create_database_propagation"); err != nil {
+			log.Printf("[WARN] could not reset citus.enable_create_database_propagation: %v", err)
+		}
+	}()
+
+	_, err = conn.ExecContext(db.context(), query)
+	return err
+}
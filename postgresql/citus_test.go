@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCitusVersion(t *testing.T) {
+	for version, expected := range map[string]semver.Version{
+		"12.1-1": semver.MustParse("12.1.0"),
+		"11.3-2": semver.MustParse("11.3.0"),
+		"13.0":   semver.MustParse("13.0.0"),
+	} {
+		parsed, err := parseCitusVersion(version)
+		assert.NoError(t, err, version)
+		assert.Equal(t, expected, parsed, version)
+	}
+
+	_, err := parseCitusVersion("dev")
+	assert.ErrorContains(t, err, "could not parse the version of the citus extension")
+}
+
+// Test that the role DDL is propagated to the worker nodes by Citus 11.0+, and only run on the coordinator before.
+func TestRoleDeleteCitusPropagation(t *testing.T) {
+	for _, test := range []struct {
+		citusVersion string
+		expected     []string
+	}{
+		{
+			citusVersion: "12.1-1",
+			expected: []string{
+				"SET LOCAL citus.enable_create_role_propagation = on",
+				"SET LOCAL citus.enable_alter_role_propagation = on",
+				"SET LOCAL citus.enable_alter_role_set_propagation = on",
+				`DROP ROLE "my_role"`,
+			},
+		},
+		{
+			citusVersion: "10.2-4",
+			expected: []string{
+				"SET LOCAL citus.enable_alter_role_propagation = off",
+				"SET LOCAL citus.enable_alter_role_set_propagation = off",
+				`DROP ROLE "my_role"`,
+			},
+		},
+	} {
+		fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+			if strings.HasPrefix(query, "SELECT extversion FROM pg_catalog.pg_extension WHERE extname = 'citus'") {
+				return &fakeRows{values: [][]driver.Value{{test.citusVersion}}}, nil
+			}
+			return nil, nil
+		}}
+		db, err := newFakeClient(t, fake, "16.0.0", func(config *Config) {
+			config.Citus = true
+		}).Connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+			roleNameAttr:              "my_role",
+			roleSkipReassignOwnedAttr: true,
+		})
+		if err := resourcePostgreSQLRoleDelete(db, d); err != nil {
+			t.Fatal(err)
+		}
+
+		var statements []string
+		for _, statement := range fake.Statements() {
+			if strings.HasPrefix(statement, "SET LOCAL citus.") || strings.HasPrefix(statement, "DROP ROLE ") {
+				statements = append(statements, statement)
+			}
+		}
+		if !reflect.DeepEqual(statements, test.expected) {
+			t.Errorf("Citus %s: the role deletion sent %#v, expected %#v", test.citusVersion, statements, test.expected)
+		}
+	}
+}
@@ -3,15 +3,19 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/blang/semver"
-	_ "github.com/lib/pq" // PostgreSQL db
+	"github.com/lib/pq" // PostgreSQL db
 	"gocloud.dev/postgres"
 	_ "gocloud.dev/postgres/awspostgres"
 	_ "gocloud.dev/postgres/gcppostgres"
@@ -41,6 +45,17 @@ const (
 	featurePubWithoutTruncate
 	featureFunction
 	featureServer
+	featureMembershipSetOption
+	featurePartitionedTables
+	featureSequencesView
+	featureMaintainPrivilege
+	featureAlterSystem
+	featureDatabaseCollationVersion
+	featureDatabaseLocaleProvider
+	featureDatabaseBuiltinLocale
+	featureBlockingPids
+	featureMaxActiveReplicationOrigins
+	featureSubscriptionParallelApply
 )
 
 var (
@@ -112,6 +127,51 @@ var (
 		featureServer: semver.MustParseRange(">=10.0.0"),
 
 		featureDatabaseOwnerRole: semver.MustParseRange(">=15.0.0"),
+
+		// Role memberships have SET/INHERIT options and granting them requires
+		// ADMIN OPTION on the granted role (even with CREATEROLE)
+		featureMembershipSetOption: semver.MustParseRange(">=16.0.0"),
+
+		// Declarative partitioning (relkind 'p' and pg_class.relispartition)
+		featurePartitionedTables: semver.MustParseRange(">=10.0.0"),
+
+		// pg_sequences view
+		featureSequencesView: semver.MustParseRange(">=10.0.0"),
+
+		// MAINTAIN privilege on tables (VACUUM, ANALYZE, REINDEX, REFRESH MATERIALIZED VIEW...)
+		featureMaintainPrivilege: semver.MustParseRange(">=17.0.0"),
+
+		// ALTER SYSTEM with pg_file_settings and pg_settings.pending_restart
+		featureAlterSystem: semver.MustParseRange(">=9.5.0"),
+
+		// pg_database.datcollversion and pg_database_collation_actual_version
+		featureDatabaseCollationVersion: semver.MustParseRange(">=15.0.0"),
+
+		// CREATE DATABASE ... LOCALE_PROVIDER/ICU_LOCALE and pg_database.datlocprovider
+		featureDatabaseLocaleProvider: semver.MustParseRange(">=15.0.0"),
+
+		// builtin locale provider, CREATE DATABASE ... BUILTIN_LOCALE and pg_database.datlocale
+		featureDatabaseBuiltinLocale: semver.MustParseRange(">=17.0.0"),
+
+		// pg_blocking_pids and pg_stat_activity.wait_event
+		featureBlockingPids: semver.MustParseRange(">=9.6.0"),
+
+		// Replication origins limited by max_active_replication_origins instead of max_replication_slots
+		featureMaxActiveReplicationOrigins: semver.MustParseRange(">=18.0.0"),
+
+		// Parallel apply workers of the subscriptions and pg_stat_subscription.leader_pid
+		featureSubscriptionParallelApply: semver.MustParseRange(">=16.0.0"),
+	}
+
+	// disableableFeatures are the features which can be disabled in the provider
+	// configuration (disabled_features) when they are blocked by a managed service
+	// (e.g. AWS RDS) regardless of the server version.
+	disableableFeatures = map[string]featureName{
+		"database_allow_connections": featureDBAllowConnections,
+		"database_is_template":       featureDBIsTemplate,
+		"force_drop_database":        featureForceDropDatabase,
+		"role_bypassrls":             featureRLS,
+		"role_replication":           featureReplication,
 	}
 )
 
@@ -123,6 +183,186 @@ type DBConnection struct {
 	// version is the version number of the database as determined by parsing the
 	// output of `SELECT VERSION()`.x
 	version semver.Version
+
+	// ctx is the context of the Terraform operation this connection is used for.
+	// Queries sent through Exec/Query/QueryRow/Begin are cancelled with it.
+	ctx context.Context
+
+	// capabilities caches the result of the capabilities probe of the connection user
+	// (e.g. isSuperuser is checked on every role refresh).
+	// It is shared by all the connections bound to the same pool.
+	capabilities *capabilitiesCache
+
+	// stmts caches the prepared statements of the pool, nil if statement_cache_size is 0.
+	// It is shared by all the connections bound to the same pool.
+	stmts *stmtCache
+}
+
+// roleCapabilities are the attributes of the connection user which decide what
+// it can do regardless of the server version, see DBConnection.roleCapabilities.
+type roleCapabilities struct {
+	superuser    bool
+	createDB     bool
+	rdsSuperuser bool
+}
+
+type capabilitiesCache struct {
+	mu sync.Mutex
+	// value is nil until the capabilities have been fetched.
+	value *roleCapabilities
+}
+
+const (
+	// stmtCacheConnMaxIdleTime is how long a connection is kept idle when the statement cache is enabled.
+	stmtCacheConnMaxIdleTime = 30 * time.Second
+)
+
+// stmtCacheMaxIdleConns returns how many connections are kept idle when the statement cache is enabled.
+func stmtCacheMaxIdleConns(maxConns int) int {
+	if maxConns <= 0 {
+		return defaultProviderMaxOpenConnections
+	}
+	return maxConns
+}
+
+// stmtCache caches prepared statements by query text, so the queries repeated by
+// each Read (e.g. one per role) are parsed and planned once per server connection.
+// Only parameterized queries are cached: the others embed identifiers or literals
+// and are rarely repeated. Once the cache is full, queries are run without it.
+type stmtCache struct {
+	mu    sync.Mutex
+	size  int
+	stmts map[string]*sql.Stmt
+}
+
+func newStmtCache(size int) *stmtCache {
+	if size <= 0 {
+		return nil
+	}
+	return &stmtCache{size: size, stmts: make(map[string]*sql.Stmt, size)}
+}
+
+// get returns the prepared statement of query, preparing it if needed.
+// It returns nil if the cache is disabled or full.
+func (c *stmtCache) get(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stmt, found := c.stmts[query]; found {
+		return stmt, nil
+	}
+	if len(c.stmts) >= c.size {
+		return nil, nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+
+	return stmt, nil
+}
+
+// close closes all the cached statements and empties the cache.
+func (c *stmtCache) close() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []string
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+		delete(c.stmts, query)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("could not close prepared statements: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// context returns the context queries should be bound to.
+func (db *DBConnection) context() context.Context {
+	if db.ctx == nil {
+		return context.Background()
+	}
+	return db.ctx
+}
+
+// Exec executes a query bound to the context of the connection.
+func (db *DBConnection) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.ExecContext(db.context(), query, args...)
+}
+
+// Query executes a query bound to the context of the connection.
+// Parameterized queries go through the prepared statement cache if it is enabled.
+func (db *DBConnection) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if stmt := db.cachedStmt(query, args); stmt != nil {
+		return stmt.QueryContext(db.context(), args...)
+	}
+	return db.DB.QueryContext(db.context(), query, args...)
+}
+
+// QueryRow executes a query bound to the context of the connection.
+// Parameterized queries go through the prepared statement cache if it is enabled.
+func (db *DBConnection) QueryRow(query string, args ...interface{}) *sql.Row {
+	if stmt := db.cachedStmt(query, args); stmt != nil {
+		return stmt.QueryRowContext(db.context(), args...)
+	}
+	return db.DB.QueryRowContext(db.context(), query, args...)
+}
+
+// cachedStmt returns the cached prepared statement of query, or nil if it must be run directly.
+// If the query cannot be prepared, it is run directly so it reports the error itself.
+func (db *DBConnection) cachedStmt(query string, args []interface{}) *sql.Stmt {
+	if db.stmts == nil || len(args) == 0 {
+		return nil
+	}
+	stmt, err := db.stmts.get(db.context(), db.DB, query)
+	if err != nil {
+		return nil
+	}
+	return stmt
+}
+
+// cachedTx is a transaction whose parameterized queries go through the prepared statement
+// cache of the pool it was started on, as the ones of DBConnection (see startCachedTransaction).
+type cachedTx struct {
+	*sql.Tx
+	db *DBConnection
+}
+
+// Query executes a query in the transaction, with the cached prepared statement of query if any.
+func (tx cachedTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if stmt := tx.db.cachedStmt(query, args); stmt != nil {
+		return tx.StmtContext(tx.db.context(), stmt).QueryContext(tx.db.context(), args...)
+	}
+	return tx.Tx.Query(query, args...)
+}
+
+// QueryRow executes a query in the transaction, with the cached prepared statement of query if any.
+func (tx cachedTx) QueryRow(query string, args ...interface{}) *sql.Row {
+	if stmt := tx.db.cachedStmt(query, args); stmt != nil {
+		return tx.StmtContext(tx.db.context(), stmt).QueryRowContext(tx.db.context(), args...)
+	}
+	return tx.Tx.QueryRow(query, args...)
+}
+
+// Begin starts a transaction bound to the context of the connection.
+// If the context is cancelled, the transaction is rolled back once the
+// statement currently running (if any) returns.
+func (db *DBConnection) Begin() (*sql.Tx, error) {
+	return db.DB.BeginTx(db.context(), nil)
 }
 
 // featureSupported returns true if a given feature is supported or not. This is
@@ -135,18 +375,68 @@ func (db *DBConnection) featureSupported(name featureName) bool {
 		panic(fmt.Sprintf("unknown feature flag %v", name))
 	}
 
+	if db.featureDisabled(name) {
+		return false
+	}
+
 	return fn(db.version)
 }
 
-// isSuperuser returns true if connected user is a Postgres SUPERUSER
-func (db *DBConnection) isSuperuser() (bool, error) {
-	var superuser bool
+// disableableFeatureNames returns the sorted names of the features which can be disabled.
+func disableableFeatureNames() []string {
+	names := make([]string, 0, len(disableableFeatures))
+	for name := range disableableFeatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-	if err := db.QueryRow("SELECT rolsuper FROM pg_roles WHERE rolname = CURRENT_USER").Scan(&superuser); err != nil {
-		return false, fmt.Errorf("could not check if current user is superuser: %w", err)
+// featureDisabled returns true if the feature has been disabled in the provider configuration.
+func (db *DBConnection) featureDisabled(name featureName) bool {
+	return db.client != nil && db.client.config.featureDisabled(name)
+}
+
+// unsupportedFeatureError returns the error to return when the configuration requires
+// a feature which is not supported, either because of the server version or because
+// it has been disabled in the provider configuration.
+func (db *DBConnection) unsupportedFeatureError(name featureName, description string) error {
+	if db.featureDisabled(name) {
+		return fmt.Errorf("%s is disabled in the provider configuration (disabled_features)", description)
 	}
+	return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support %s", db.version.String(), description)
+}
+
+// roleCapabilities returns the capabilities of the connection user, so the resources
+// can choose their code path (e.g. whether the owner has to be granted to it first).
+// They are only fetched once per connection pool, a failed probe is retried by the next call.
+func (db *DBConnection) roleCapabilities() (roleCapabilities, error) {
+	db.capabilities.mu.Lock()
+	defer db.capabilities.mu.Unlock()
+
+	if db.capabilities.value != nil {
+		return *db.capabilities.value, nil
+	}
+
+	var capabilities roleCapabilities
+	if err := db.QueryRow(
+		"SELECT rolsuper, rolcreatedb, "+
+			"EXISTS (SELECT 1 FROM pg_catalog.pg_roles AS r WHERE r.rolname = $1 AND pg_has_role(r.oid, 'MEMBER')) "+
+			"FROM pg_catalog.pg_roles WHERE rolname = CURRENT_USER",
+		rdsSuperuserRole,
+	).Scan(&capabilities.superuser, &capabilities.createDB, &capabilities.rdsSuperuser); err != nil {
+		// Not cached, so a transient error (e.g. a connection reset) fails only this call.
+		return roleCapabilities{}, fmt.Errorf("could not check the capabilities of the current user: %w", err)
+	}
+	db.capabilities.value = &capabilities
+
+	return capabilities, nil
+}
 
-	return superuser, nil
+// isSuperuser returns true if connected user is a Postgres SUPERUSER.
+func (db *DBConnection) isSuperuser() (bool, error) {
+	capabilities, err := db.roleCapabilities()
+	return capabilities.superuser, err
 }
 
 type ClientCertificateConfig struct {
@@ -169,9 +459,33 @@ type Config struct {
 	Timeout           int
 	ConnectTimeoutSec int
 	MaxConns          int
+	StmtCacheSize     int
 	ExpectedVersion   semver.Version
 	SSLClientCert     *ClientCertificateConfig
 	SSLRootCertPath   string
+	SSLNegotiation    string
+	ChannelBinding    string
+
+	// SSLHostOverride is the name the certificate of the server is verified against instead of the host.
+	SSLHostOverride string
+
+	// TLSMinVersion (e.g. 1.2) and TLSCipherSuites restrict the TLS connections to the server.
+	TLSMinVersion   string
+	TLSCipherSuites []string
+
+	// TargetSessionAttrs is the kind of server to look for if multiple hosts are specified.
+	TargetSessionAttrs string
+
+	// ConnectionParams are the additional parameters of the connection string
+	// (e.g. application_name or options) which have no dedicated setting.
+	ConnectionParams map[string]string
+
+	// Citus is whether the server is a Citus coordinator, whose database and role DDL is propagated to the workers.
+	Citus bool
+
+	// DisabledFeatures are the features the provider must not use even if the
+	// server version supports them.
+	DisabledFeatures map[featureName]bool
 }
 
 // Client struct holding connection string
@@ -180,6 +494,9 @@ type Client struct {
 	config Config
 
 	databaseName string
+
+	// ctx is the context of the Terraform operation using this client, see withContext.
+	ctx context.Context
 }
 
 // NewClient returns client config for the specified database.
@@ -190,6 +507,24 @@ func (c *Config) NewClient(database string) *Client {
 	}
 }
 
+// withContext returns a copy of the client whose connections are bound to ctx,
+// so that in-flight queries are cancelled if Terraform cancels the operation
+// (e.g.: Ctrl-C or timeout).
+func (c *Client) withContext(ctx context.Context) *Client {
+	client := *c
+	client.ctx = ctx
+	return &client
+}
+
+// withHost returns a copy of the client connecting to host instead of the configured one.
+// As the host is chosen explicitly, target_session_attrs is not checked.
+func (c *Client) withHost(host string) *Client {
+	client := *c
+	client.config.Host = host
+	client.config.TargetSessionAttrs = "any"
+	return &client
+}
+
 // featureSupported returns true if a given feature is supported or not.  This
 // is slightly different from Client's featureSupported in that here we're
 // evaluating against the expected version, not the fingerprinted version.
@@ -200,11 +535,23 @@ func (c *Config) featureSupported(name featureName) bool {
 		panic(fmt.Sprintf("unknown feature flag %v", name))
 	}
 
+	if c.featureDisabled(name) {
+		return false
+	}
+
 	return fn(c.ExpectedVersion)
 }
 
+// featureDisabled returns true if the feature has been disabled in the provider configuration.
+func (c *Config) featureDisabled(name featureName) bool {
+	return c.DisabledFeatures[name]
+}
+
 func (c *Config) connParams() []string {
 	params := map[string]string{}
+	for key, value := range c.ConnectionParams {
+		params[key] = value
+	}
 
 	// sslmode and connect_timeout are not allowed with gocloud
 	// (TLS is provided by gocloud directly)
@@ -232,12 +579,67 @@ func (c *Config) connParams() []string {
 	for key, value := range params {
 		paramsArray = append(paramsArray, fmt.Sprintf("%s=%s", key, url.QueryEscape(value)))
 	}
+	// The connection string identifies the pool (see dbRegistry) and the grant batches,
+	// so it must not depend on the iteration order of the map.
+	sort.Strings(paramsArray)
 
 	return paramsArray
 }
 
+// sslModeVerifiesCA returns whether sslMode verifies the certificate of the server.
+func sslModeVerifiesCA(sslMode string) bool {
+	return sslMode == "verify-ca" || sslMode == "verify-full"
+}
+
+// checkSSLHostOverride returns an error if ssl_host_override cannot be used: the name is verified by lib/pq,
+// so it is not supported by the gocloud schemes (whose TLS is provided by gocloud).
+func (c *Config) checkSSLHostOverride() error {
+	if c.SSLHostOverride == "" {
+		return nil
+	}
+	if c.Scheme != "postgres" {
+		return fmt.Errorf("postgresql: ssl_host_override is only supported with the postgres scheme")
+	}
+	if !sslModeVerifiesCA(c.SSLMode) {
+		return fmt.Errorf("postgresql: ssl_host_override requires sslmode verify-ca or verify-full, got %q", c.SSLMode)
+	}
+	return nil
+}
+
+// checkDriverSupport returns an error if a connection setting cannot be honored by the driver.
+// lib/pq neither implements SCRAM channel binding (SCRAM-SHA-256-PLUS) nor direct SSL negotiation,
+// so the settings which require them cannot be silently ignored.
+// `channel_binding=prefer` is accepted as libpq falls back to no channel binding if it is not available,
+// so it behaves as `disable` and the provider never uses channel binding.
+func (c *Config) checkDriverSupport() error {
+	if c.ChannelBinding == "require" {
+		return errors.New("postgresql: channel_binding = require is not supported by the PostgreSQL driver of the provider, which cannot authenticate with SCRAM-SHA-256-PLUS")
+	}
+	if c.SSLNegotiation == "direct" {
+		return errors.New("postgresql: sslnegotiation = direct is not supported by the PostgreSQL driver of the provider, use the default `postgres` negotiation")
+	}
+	return nil
+}
+
+// hosts returns the comma-separated list of hosts to try, in order.
+func (c *Config) hosts() []string {
+	hosts := []string{}
+	for _, host := range strings.Split(c.Host, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return []string{c.Host}
+	}
+	return hosts
+}
+
 func (c *Config) connStr(database string) string {
-	host := c.Host
+	return c.connStrForHost(c.Host, database)
+}
+
+func (c *Config) connStrForHost(host, database string) string {
 	// For GCP, support both project/region/instance and project:region:instance
 	// (The second one allows to use the output of google_sql_database_instance as host
 	if c.Scheme == "gcppostgres" {
@@ -258,6 +660,69 @@ func (c *Config) connStr(database string) string {
 	return connStr
 }
 
+// parseConnectionString parses a libpq connection string, either as `key=value` pairs
+// or as a `postgres://` URL, and returns its parameters.
+func parseConnectionString(connStr string) (map[string]string, error) {
+	if strings.HasPrefix(connStr, "postgres://") || strings.HasPrefix(connStr, "postgresql://") {
+		var err error
+		if connStr, err = pq.ParseURL(connStr); err != nil {
+			return nil, err
+		}
+	}
+
+	params := map[string]string{}
+	s := []rune(connStr)
+	i := 0
+	skipSpaces := func() {
+		for i < len(s) && unicode.IsSpace(s[i]) {
+			i++
+		}
+	}
+
+	for {
+		skipSpaces()
+		if i >= len(s) {
+			return params, nil
+		}
+
+		keyStart := i
+		for i < len(s) && s[i] != '=' && !unicode.IsSpace(s[i]) {
+			i++
+		}
+		key := string(s[keyStart:i])
+
+		skipSpaces()
+		if i >= len(s) || s[i] != '=' || key == "" {
+			return nil, fmt.Errorf("missing \"=\" after %q in connection string", key)
+		}
+		i++
+		skipSpaces()
+
+		var value strings.Builder
+		if i < len(s) && s[i] == '\'' {
+			i++
+			for ; i < len(s) && s[i] != '\''; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteRune(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated quoted value of %q in connection string", key)
+			}
+			i++
+		} else {
+			for ; i < len(s) && !unicode.IsSpace(s[i]); i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteRune(s[i])
+			}
+		}
+		params[key] = value.String()
+	}
+}
+
 func (c *Config) getDatabaseUsername() string {
 	if c.DatabaseUsername != "" {
 		return c.DatabaseUsername
@@ -272,54 +737,225 @@ func (c *Client) Connect() (*DBConnection, error) {
 	dbRegistryLock.Lock()
 	defer dbRegistryLock.Unlock()
 
-	dsn := c.config.connStr(c.databaseName)
-	conn, found := dbRegistry[dsn]
-	if !found {
+	// Try each host in order until one matches target_session_attrs,
+	// so a failover is transparently handled on the next connection.
+	var errs []string
+	for _, host := range c.config.hosts() {
+		conn, err := c.connectHost(host)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
 
-		var db *sql.DB
-		var err error
-		if c.config.Scheme == "postgres" {
-			db, err = sql.Open(proxyDriverName, dsn)
-		} else {
-			db, err = postgres.Open(context.Background(), dsn)
+		// The pool is shared, but the connection returned is bound to this client
+		// (and so to its context).
+		bound := *conn
+		bound.client = c
+		bound.ctx = c.ctx
+
+		return &bound, nil
+	}
+
+	return nil, errors.New(strings.Join(errs, "; "))
+}
+
+// closeDatabaseStmtCaches closes the prepared statements cached for the pools connected to database.
+func closeDatabaseStmtCaches(database string) error {
+	dbRegistryLock.Lock()
+	defer dbRegistryLock.Unlock()
+
+	for _, conn := range dbRegistry {
+		if conn.client.databaseName != database {
+			continue
 		}
+		if err := conn.stmts.close(); err != nil {
+			return err
+		}
+	}
 
+	return nil
+}
+
+// connectHost returns the connection pool to the specified host, opening it if needed.
+// dbRegistryLock must be held.
+func (c *Client) connectHost(host string) (*DBConnection, error) {
+	dsn := c.config.connStrForHost(host, c.databaseName)
+	conn, found := dbRegistry[dsn]
+	if found {
+		// The server may not match target_session_attrs anymore (e.g. the primary
+		// has been demoted by a failover), its pool is then replaced.
+		err := checkTargetSessionAttrs(conn.DB, c.config.TargetSessionAttrs)
 		if err == nil {
-			err = db.Ping()
+			return conn, nil
 		}
+		log.Printf("[WARN] closing the connections to PostgreSQL server %s: %v", host, err)
+		delete(dbRegistry, dsn)
+		_ = conn.stmts.close()
+		_ = conn.DB.Close()
+	}
+
+	var db *sql.DB
+	var err error
+	if c.config.Scheme == "postgres" && c.config.usesCustomTLS() {
+		db, err = openWithTLSConfig(c.config, host, c.databaseName)
+	} else if c.config.Scheme == "postgres" && c.config.SSLHostOverride != "" {
+		db, err = openWithSSLHostOverride(c.config, host, c.databaseName)
+	} else if c.config.Scheme == "postgres" {
+		db, err = sql.Open(proxyDriverName, dsn)
+	} else {
+		db, err = postgres.Open(context.Background(), dsn)
+	}
+
+	if err == nil {
+		// connect_timeout is not a connection parameter of the gocloud schemes, the first
+		// connection is bounded here for all of them.
+		ctx, cancel := connectTimeoutContext(c.config.ConnectTimeoutSec)
+		err = db.PingContext(ctx)
+		cancel()
+	}
+	if err == nil {
+		err = checkTargetSessionAttrs(db, c.config.TargetSessionAttrs)
 		if err != nil {
-			errString := strings.Replace(err.Error(), c.config.Password, "XXXX", 2)
-			return nil, fmt.Errorf("Error connecting to PostgreSQL server %s (scheme: %s): %s", c.config.Host, c.config.Scheme, errString)
+			_ = db.Close()
 		}
+	}
+	if err != nil {
+		errString := strings.Replace(err.Error(), c.config.Password, "XXXX", 2)
+		return nil, fmt.Errorf("Error connecting to PostgreSQL server %s (scheme: %s): %s", host, c.config.Scheme, errString)
+	}
 
-		// We don't want to retain connection
-		// So when we connect on a specific database which might be managed by terraform,
-		// we don't keep opened connection in case of the db has to be dopped in the plan.
-		db.SetMaxIdleConns(0)
-		db.SetMaxOpenConns(c.config.MaxConns)
-
-		defaultVersion, _ := semver.Parse(defaultExpectedPostgreSQLVersion)
-		version := &c.config.ExpectedVersion
-		if defaultVersion.Equals(c.config.ExpectedVersion) {
-			// Version hint not set by user, need to fingerprint
-			version, err = fingerprintCapabilities(db)
-			if err != nil {
-				_ = db.Close()
-				return nil, fmt.Errorf("error detecting capabilities: %w", err)
-			}
+	// We don't want to retain connection
+	// So when we connect on a specific database which might be managed by terraform,
+	// we don't keep opened connection in case of the db has to be dopped in the plan.
+	db.SetMaxIdleConns(0)
+	db.SetMaxOpenConns(c.config.MaxConns)
+
+	stmtCacheSize := c.config.StmtCacheSize
+	if stmtCacheSize > 0 {
+		ctx, cancel := connectTimeoutContext(c.config.ConnectTimeoutSec)
+		pooled, err := usesTransactionPooling(ctx, db, c.config.MaxConns)
+		cancel()
+		switch {
+		case err != nil:
+			log.Printf("[WARN] statement_cache_size is ignored for PostgreSQL server %s, could not check if it is behind a connection pooler in transaction pooling mode: %v", host, err)
+			stmtCacheSize = 0
+		case pooled:
+			log.Printf("[WARN] statement_cache_size is ignored for PostgreSQL server %s, which is behind a connection pooler in transaction pooling mode", host)
+			stmtCacheSize = 0
 		}
+	}
 
-		conn = &DBConnection{
-			db,
-			c,
-			*version,
+	// Prepared statements are bound to server connections, so they are only worth caching
+	// if the connections are kept open between the Reads.
+	// Idle connections are closed quickly to not block a DROP DATABASE for long.
+	if stmtCacheSize > 0 {
+		db.SetMaxIdleConns(stmtCacheMaxIdleConns(c.config.MaxConns))
+		db.SetConnMaxIdleTime(stmtCacheConnMaxIdleTime)
+	}
+
+	defaultVersion, _ := semver.Parse(defaultExpectedPostgreSQLVersion)
+	version := &c.config.ExpectedVersion
+	if defaultVersion.Equals(c.config.ExpectedVersion) {
+		// Version hint not set by user, need to fingerprint
+		version, err = fingerprintCapabilities(db)
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("error detecting capabilities: %w", err)
 		}
-		dbRegistry[dsn] = conn
 	}
 
+	conn = &DBConnection{
+		DB:           db,
+		client:       c,
+		version:      *version,
+		capabilities: &capabilitiesCache{},
+		stmts:        newStmtCache(stmtCacheSize),
+	}
+	dbRegistry[dsn] = conn
+
 	return conn, nil
 }
 
+// usesTransactionPooling returns whether db is behind a connection pooler in transaction (or statement)
+// pooling mode, e.g. PgBouncer with pool_mode = transaction, whose server connections can't keep prepared statements.
+// Such a pooler hands the server connection of a finished transaction to the next client, so two
+// client connections running one query after the other see the same pg_backend_pid(), which never
+// happens when connected to PostgreSQL. The process of a client connection changing between two
+// queries is also a sign of it. A single connection is used if maxConns is 1.
+func usesTransactionPooling(ctx context.Context, db *sql.DB, maxConns int) (bool, error) {
+	first, err := db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer first.Close()
+
+	var firstPID, pid int
+	if err := first.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&firstPID); err != nil {
+		return false, err
+	}
+
+	if maxConns != 1 {
+		second, err := db.Conn(ctx)
+		if err != nil {
+			return false, err
+		}
+		defer second.Close()
+
+		if err := second.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+			return false, err
+		}
+		if pid == firstPID {
+			return true, nil
+		}
+	}
+
+	if err := first.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		return false, err
+	}
+	return pid != firstPID, nil
+}
+
+// connectTimeoutContext returns the context bounding the first connection to a host
+// by connect_timeout (in seconds), which is not bounded if it is zero or negative.
+func connectTimeoutContext(timeoutSec int) (context.Context, context.CancelFunc) {
+	if timeoutSec <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
+}
+
+// checkTargetSessionAttrs checks that the server db is connected to matches
+// target_session_attrs, following libpq semantics.
+func checkTargetSessionAttrs(db *sql.DB, targetSessionAttrs string) error {
+	switch targetSessionAttrs {
+	case "", "any":
+		return nil
+
+	case "read-write", "read-only":
+		var readOnly string
+		if err := db.QueryRow("SHOW transaction_read_only").Scan(&readOnly); err != nil {
+			return fmt.Errorf("could not check if session is read-only: %w", err)
+		}
+		if (readOnly == "on") != (targetSessionAttrs == "read-only") {
+			return fmt.Errorf("session is not %s (target_session_attrs)", targetSessionAttrs)
+		}
+
+	case "primary", "standby":
+		var inRecovery bool
+		if err := db.QueryRow("SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+			return fmt.Errorf("could not check if server is in recovery: %w", err)
+		}
+		if inRecovery != (targetSessionAttrs == "standby") {
+			return fmt.Errorf("server is not a %s (target_session_attrs)", targetSessionAttrs)
+		}
+
+	default:
+		return fmt.Errorf("unknown target_session_attrs %q", targetSessionAttrs)
+	}
+
+	return nil
+}
+
 // fingerprintCapabilities queries PostgreSQL to populate a local catalog of
 // capabilities.  This is only run once per Client.
 func fingerprintCapabilities(db *sql.DB) (*semver.Version, error) {
@@ -1,12 +1,44 @@
 package postgresql
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// dataSourceHostAttr overrides the host of the provider for a data source, e.g. to read from a replica.
+const dataSourceHostAttr = "host"
+
+func dataSourceHostSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Description: "The host (or comma-separated list of hosts) to read from instead of the `host` of the provider, e.g. a read replica. " +
+			"The other connection settings of the provider are used, except `target_session_attrs` which is not applied",
+	}
+}
+
+// PGDataSourceFunc is PGResourceFunc for the data sources, which are read from the host
+// set in their host attribute if any.
+func PGDataSourceFunc(fn func(*DBConnection, *schema.ResourceData) error) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		client := meta.(*Client).withContext(ctx)
+		if host := d.Get(dataSourceHostAttr).(string); host != "" {
+			client = client.withHost(host)
+		}
+
+		db, err := client.Connect()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		return diag.FromErr(fn(db, d))
+	}
+}
+
 const (
 	queryConcatKeywordWhere = "WHERE"
 	queryConcatKeywordAnd   = "AND"
@@ -0,0 +1,134 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const citusNodesQuery = `
+SELECT nodeid, groupid, nodename, nodeport, noderole::text, isactive, shouldhaveshards
+FROM pg_catalog.pg_dist_node
+ORDER BY nodeid
+`
+
+func dataSourcePostgreSQLCitus() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLCitusRead),
+		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
+			"database": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database the citus extension is installed in. Defaults to the provider database",
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the citus extension (e.g. `12.1-1`)",
+			},
+			"full_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of Citus with its build details, as returned by `citus_version()`",
+			},
+			"nodes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"group_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"port": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"role": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"is_active": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"should_have_shards": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+				Description: "The nodes of the cluster (`pg_dist_node`), the coordinator is in the group 0",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLCitusRead(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	version, installed, err := citusVersion(txn)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		return fmt.Errorf("the citus extension is not installed in database %s", database)
+	}
+
+	var fullVersion string
+	if err := txn.QueryRow("SELECT citus_version()").Scan(&fullVersion); err != nil {
+		return fmt.Errorf("could not read the version of Citus: %w", err)
+	}
+
+	rows, err := txn.Query(citusNodesQuery)
+	if err != nil {
+		return fmt.Errorf("could not read the Citus nodes: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := make([]interface{}, 0)
+	for rows.Next() {
+		var id, groupID, port int
+		var name, role string
+		var isActive, shouldHaveShards bool
+		if err := rows.Scan(&id, &groupID, &name, &port, &role, &isActive, &shouldHaveShards); err != nil {
+			return fmt.Errorf("could not scan Citus node: %w", err)
+		}
+		nodes = append(nodes, map[string]interface{}{
+			"id":                 id,
+			"group_id":           groupID,
+			"name":               name,
+			"port":               port,
+			"role":               role,
+			"is_active":          isActive,
+			"should_have_shards": shouldHaveShards,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read the Citus nodes: %w", err)
+	}
+
+	d.Set("database", database)
+	d.Set("version", version)
+	d.Set("full_version", fullVersion)
+	d.Set("nodes", nodes)
+	d.SetId(database)
+
+	return nil
+}
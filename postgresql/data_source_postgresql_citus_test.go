@@ -0,0 +1,33 @@
+package postgresql
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// The test requires the test server to be a Citus coordinator (with citus in shared_preload_libraries
+// and the extension installed in the provider database).
+func TestAccPostgresqlDataSourceCitus(t *testing.T) {
+	skipIfNotAcc(t)
+	if os.Getenv("PGCITUS") == "" {
+		t.Skip("Citus acceptance tests skipped unless env 'PGCITUS' set")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_citus" "test" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.postgresql_citus.test", "version"),
+					resource.TestCheckResourceAttrSet("data.postgresql_citus.test", "full_version"),
+				),
+			},
+		},
+	})
+}
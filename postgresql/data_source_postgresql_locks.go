@@ -0,0 +1,240 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	// blockedSessionsQuery returns the sessions waiting for a lock held by other sessions ($1 filters their database),
+	// with the first lock they wait for. The relations can only be named in the current database.
+	blockedSessionsQuery = `
+SELECT a.pid, COALESCE(a.usename, ''), COALESCE(a.datname, ''), COALESCE(a.query, ''), COALESCE(a.state, ''),
+	COALESCE(a.wait_event_type, ''), COALESCE(a.wait_event, ''),
+	COALESCE(floor(EXTRACT(EPOCH FROM pg_catalog.now() - a.query_start)), 0)::bigint,
+	pg_catalog.pg_blocking_pids(a.pid)::bigint[],
+	COALESCE(l.locktype, ''),
+	COALESCE(CASE WHEN l.database = d.oid THEN l.relation::regclass::text ELSE l.relation::text END, ''),
+	COALESCE(l.mode, '')
+FROM pg_catalog.pg_stat_activity AS a
+LEFT JOIN LATERAL (
+	SELECT locktype, database, relation, mode FROM pg_catalog.pg_locks WHERE pid = a.pid AND NOT granted LIMIT 1
+) AS l ON true
+LEFT JOIN pg_catalog.pg_database AS d ON d.datname = pg_catalog.current_database()
+WHERE a.pid <> pg_catalog.pg_backend_pid()
+AND pg_catalog.cardinality(pg_catalog.pg_blocking_pids(a.pid)) > 0
+AND ($1 = '' OR a.datname = $1)
+ORDER BY a.pid
+`
+	// blockingSessionsQuery returns the sessions holding the locks waited for by the sessions of blockedSessionsQuery.
+	blockingSessionsQuery = `
+SELECT a.pid, COALESCE(a.usename, ''), COALESCE(a.datname, ''), COALESCE(a.query, ''), COALESCE(a.state, ''),
+	COALESCE(floor(EXTRACT(EPOCH FROM pg_catalog.now() - a.xact_start)), 0)::bigint
+FROM pg_catalog.pg_stat_activity AS a
+WHERE a.pid IN (
+	SELECT pg_catalog.unnest(pg_catalog.pg_blocking_pids(b.pid)) FROM pg_catalog.pg_stat_activity AS b
+	WHERE b.pid <> pg_catalog.pg_backend_pid() AND ($1 = '' OR b.datname = $1)
+)
+ORDER BY a.pid
+`
+)
+
+func dataSourcePostgreSQLLocks() *schema.Resource {
+	sessionSchema := map[string]*schema.Schema{
+		"pid": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"user": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"database": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"query": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"state": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+
+	blockedSchema := map[string]*schema.Schema{
+		"wait_event_type": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"wait_event": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"query_seconds": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"blocking_pids": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeInt},
+		},
+		"lock_type": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"lock_relation": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"lock_mode": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+	blockingSchema := map[string]*schema.Schema{
+		"transaction_seconds": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+	}
+	for name, s := range sessionSchema {
+		blockedSchema[name] = s
+		blockingSchema[name] = s
+	}
+
+	return &schema.Resource{
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLLocksRead),
+		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
+			"database": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only read the sessions of this database waiting for a lock (all the databases if not set)",
+			},
+			"blocked": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Resource{Schema: blockedSchema},
+				Description: "The sessions waiting for a lock held by other sessions (`blocking_pids`), with the lock they wait for " +
+					"(`lock_relation` is only resolved in the database of the provider, it is an OID in the other ones) and how long their query has been running",
+			},
+			"blocking": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: blockingSchema},
+				Description: "The sessions holding the locks the `blocked` sessions wait for, with how long their transaction has been running",
+			},
+		},
+	}
+}
+
+// dataSourcePostgreSQLLocksRead reads pg_stat_activity, which only shows the queries of the sessions of the roles
+// the provider user is a member of unless it has pg_read_all_stats (the other ones are `<insufficient privilege>`).
+func dataSourcePostgreSQLLocksRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureBlockingPids) {
+		return fmt.Errorf(
+			"postgresql_locks data source is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	database := d.Get("database").(string)
+
+	txn, err := startTransaction(db.client, "")
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	blocked, err := readBlockedSessions(txn, database)
+	if err != nil {
+		return err
+	}
+	blocking, err := readBlockingSessions(txn, database)
+	if err != nil {
+		return err
+	}
+
+	d.Set("blocked", blocked)
+	d.Set("blocking", blocking)
+	if database == "" {
+		d.SetId("locks")
+	} else {
+		d.SetId(database)
+	}
+
+	return nil
+}
+
+func readBlockedSessions(txn *sql.Tx, database string) ([]interface{}, error) {
+	rows, err := txn.Query(blockedSessionsQuery, database)
+	if err != nil {
+		return nil, fmt.Errorf("could not read blocked sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]interface{}, 0)
+	for rows.Next() {
+		var pid, querySeconds int64
+		var user, dbName, query, state, waitEventType, waitEvent, lockType, lockRelation, lockMode string
+		var blockingPIDs pq.Int64Array
+		if err := rows.Scan(
+			&pid, &user, &dbName, &query, &state, &waitEventType, &waitEvent, &querySeconds, &blockingPIDs,
+			&lockType, &lockRelation, &lockMode,
+		); err != nil {
+			return nil, fmt.Errorf("could not scan blocked session: %w", err)
+		}
+
+		pids := make([]interface{}, len(blockingPIDs))
+		for i, blockingPID := range blockingPIDs {
+			pids[i] = int(blockingPID)
+		}
+		sessions = append(sessions, map[string]interface{}{
+			"pid":             int(pid),
+			"user":            user,
+			"database":        dbName,
+			"query":           query,
+			"state":           state,
+			"wait_event_type": waitEventType,
+			"wait_event":      waitEvent,
+			"query_seconds":   int(querySeconds),
+			"blocking_pids":   pids,
+			"lock_type":       lockType,
+			"lock_relation":   lockRelation,
+			"lock_mode":       lockMode,
+		})
+	}
+	return sessions, rows.Err()
+}
+
+func readBlockingSessions(txn *sql.Tx, database string) ([]interface{}, error) {
+	rows, err := txn.Query(blockingSessionsQuery, database)
+	if err != nil {
+		return nil, fmt.Errorf("could not read blocking sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]interface{}, 0)
+	for rows.Next() {
+		var pid, transactionSeconds int64
+		var user, dbName, query, state string
+		if err := rows.Scan(&pid, &user, &dbName, &query, &state, &transactionSeconds); err != nil {
+			return nil, fmt.Errorf("could not scan blocking session: %w", err)
+		}
+		sessions = append(sessions, map[string]interface{}{
+			"pid":                 int(pid),
+			"user":                user,
+			"database":            dbName,
+			"query":               query,
+			"state":               state,
+			"transaction_seconds": int(transactionSeconds),
+		})
+	}
+	return sessions, rows.Err()
+}
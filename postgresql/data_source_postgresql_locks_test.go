@@ -0,0 +1,85 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceLocks(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+	createTestTables(t, dbSuffix, []string{"test_schema.test_table"}, "")
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+
+	db, err := sql.Open("postgres", config.connStr(dbName))
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer db.Close()
+
+	// The lock is held by a transaction until the end of the test, the SELECT of the other connection waits for it.
+	txn, err := db.Begin()
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer txn.Rollback()
+	if _, err := txn.Exec("LOCK TABLE test_schema.test_table IN ACCESS EXCLUSIVE MODE"); err != nil {
+		t.Fatalf("could not lock table: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go db.ExecContext(ctx, "SELECT * FROM test_schema.test_table")
+
+	for i := 0; ; i++ {
+		var waiting bool
+		if err := db.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM pg_stat_activity WHERE datname = $1 AND wait_event_type = 'Lock')", dbName,
+		).Scan(&waiting); err != nil {
+			t.Fatalf("could not read pg_stat_activity: %v", err)
+		}
+		if waiting {
+			break
+		}
+		if i == 50 {
+			t.Fatal("the SELECT is not waiting for the lock")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureBlockingPids)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "postgresql_locks" "test" {
+	database = "%s"
+}
+`, dbName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_locks.test", "blocked.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_locks.test", "blocked.0.query", "SELECT * FROM test_schema.test_table"),
+					resource.TestCheckResourceAttr("data.postgresql_locks.test", "blocked.0.wait_event_type", "Lock"),
+					resource.TestCheckResourceAttr("data.postgresql_locks.test", "blocked.0.lock_type", "relation"),
+					resource.TestCheckResourceAttr("data.postgresql_locks.test", "blocked.0.lock_mode", "AccessShareLock"),
+					resource.TestCheckResourceAttr("data.postgresql_locks.test", "blocked.0.blocking_pids.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_locks.test", "blocking.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_locks.test", "blocking.0.state", "idle in transaction"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,135 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	pgBouncerHostAttr          = "host"
+	pgBouncerPortAttr          = "port"
+	pgBouncerAdminDatabaseAttr = "admin_database"
+	pgBouncerDatabasesAttr     = "databases"
+	pgBouncerPoolsAttr         = "pools"
+)
+
+// dataSourcePostgreSQLPgBouncer reads the PgBouncer admin console. It only understands
+// the SHOW commands (simple query protocol, no transaction) so it has its own connection,
+// which doesn't go through Client.Connect (the server fingerprinting would fail).
+func dataSourcePostgreSQLPgBouncer() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLPgBouncerRead,
+		Schema: map[string]*schema.Schema{
+			pgBouncerHostAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The host of PgBouncer. Defaults to the (first) `host` of the provider",
+			},
+			pgBouncerPortAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      6432,
+				ValidateFunc: validation.IsPortNumber,
+				Description:  "The port of PgBouncer",
+			},
+			pgBouncerAdminDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "pgbouncer",
+				Description: "The admin console database of PgBouncer. The provider username must be in its `admin_users` or `stats_users`",
+			},
+			pgBouncerDatabasesAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeMap, Elem: &schema.Schema{Type: schema.TypeString}},
+				Description: "The result of `SHOW DATABASES`, one map per database with the columns returned by this PgBouncer version (e.g. `name`, `host`, `port`, `database`, `pool_size`, `paused`)",
+			},
+			pgBouncerPoolsAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeMap, Elem: &schema.Schema{Type: schema.TypeString}},
+				Description: "The result of `SHOW POOLS`, one map per pool with the columns returned by this PgBouncer version (e.g. `database`, `user`, `cl_active`, `cl_waiting`, `sv_active`, `sv_idle`, `pool_mode`)",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLPgBouncerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Client).config
+	if config.Scheme != "postgres" {
+		return diag.Errorf("postgresql_pgbouncer data source is only supported with the postgres scheme, not %s", config.Scheme)
+	}
+
+	host := d.Get(pgBouncerHostAttr).(string)
+	if host == "" {
+		host = config.hosts()[0]
+	}
+	config.Port = d.Get(pgBouncerPortAttr).(int)
+	adminDatabase := d.Get(pgBouncerAdminDatabaseAttr).(string)
+
+	db, err := sql.Open(proxyDriverName, config.connStrForHost(host, adminDatabase))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer db.Close()
+
+	databases, err := readPgBouncerShow(ctx, db, "DATABASES")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	pools, err := readPgBouncerShow(ctx, db, "POOLS")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set(pgBouncerDatabasesAttr, databases)
+	d.Set(pgBouncerPoolsAttr, pools)
+	d.SetId(strings.Join([]string{host, strconv.Itoa(config.Port), adminDatabase}, "."))
+
+	return nil
+}
+
+// readPgBouncerShow runs a SHOW command of the admin console. The query has no arguments,
+// so it is sent with the simple query protocol, the only one the admin console supports.
+func readPgBouncerShow(ctx context.Context, db *sql.DB, command string) ([]interface{}, error) {
+	rows, err := db.QueryContext(ctx, "SHOW "+command)
+	if err != nil {
+		return nil, fmt.Errorf("could not run SHOW %s on PgBouncer: %w", command, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0)
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("could not scan SHOW %s: %w", command, err)
+		}
+		result = append(result, pgBouncerRow(columns, values))
+	}
+	return result, rows.Err()
+}
+
+// pgBouncerRow returns a row of a SHOW command as a map of its columns, NULL values are empty.
+func pgBouncerRow(columns []string, values []sql.NullString) map[string]interface{} {
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		row[column] = values[i].String
+	}
+	return row
+}
@@ -0,0 +1,50 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPgBouncerRow(t *testing.T) {
+	row := pgBouncerRow(
+		[]string{"name", "host", "pool_size"},
+		[]sql.NullString{{String: "app", Valid: true}, {}, {String: "20", Valid: true}},
+	)
+	assert.Equal(t, map[string]interface{}{"name": "app", "host": "", "pool_size": "20"}, row)
+}
+
+// The test requires a PgBouncer in front of the test server, listening on PGBOUNCER_PORT,
+// with the test user in its admin_users.
+func TestAccPostgresqlDataSourcePgBouncer(t *testing.T) {
+	skipIfNotAcc(t)
+	port := os.Getenv("PGBOUNCER_PORT")
+	if port == "" {
+		t.Skip("PgBouncer acceptance tests skipped unless env 'PGBOUNCER_PORT' set")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "postgresql_pgbouncer" "test" {
+	port = %s
+}
+`, port),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.postgresql_pgbouncer.test", "databases.#"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.postgresql_pgbouncer.test", "databases.*", map[string]string{
+						"name": "pgbouncer",
+					}),
+					resource.TestCheckResourceAttrSet("data.postgresql_pgbouncer.test", "pools.#"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,139 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourcePostgreSQLPublication() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLPublicationRead),
+		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
+			pubNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The name of the publication",
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			pubDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database the publication belongs to",
+			},
+			pubOwnerAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The owner of the publication",
+			},
+			pubAllTablesAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the publication publishes all the tables of the database",
+			},
+			pubTablesAttr: {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The list of tables (schema.table) published",
+			},
+			"publish_insert": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether INSERT operations are published",
+			},
+			"publish_update": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether UPDATE operations are published",
+			},
+			"publish_delete": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether DELETE operations are published",
+			},
+			"publish_truncate": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether TRUNCATE operations are published (always false before PostgreSQL 11)",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLPublicationRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePublication) {
+		return fmt.Errorf(
+			"postgresql_publication data source is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	database := getDatabaseForPublication(d, db.client.databaseName)
+	pubName := d.Get(pubNameAttr).(string)
+
+	catalogLock.RLock()
+	defer catalogLock.RUnlock()
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var owner string
+	var allTables, pubInsert, pubUpdate, pubDelete, pubTruncate bool
+	columns := []string{"pg_catalog.pg_get_userbyid(pubowner)", "puballtables", "pubinsert", "pubupdate", "pubdelete"}
+	values := []interface{}{&owner, &allTables, &pubInsert, &pubUpdate, &pubDelete}
+	if db.featureSupported(featurePubTruncate) {
+		columns = append(columns, "pubtruncate")
+		values = append(values, &pubTruncate)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM pg_catalog.pg_publication WHERE pubname = $1", strings.Join(columns, ", "))
+	switch err := txn.QueryRow(query, pubName).Scan(values...); {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("publication %s not found in database %s", pubName, database)
+	case err != nil:
+		return fmt.Errorf("Error reading publication info: %w", err)
+	}
+
+	rows, err := txn.Query(
+		"SELECT schemaname || '.' || tablename FROM pg_catalog.pg_publication_tables WHERE pubname = $1",
+		pubName,
+	)
+	if err != nil {
+		return fmt.Errorf("could not get publication tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []string{}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return fmt.Errorf("could not scan publication table: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not get publication tables: %w", err)
+	}
+
+	d.SetId(strings.Join([]string{database, pubName}, "."))
+	d.Set(pubDatabaseAttr, database)
+	d.Set(pubOwnerAttr, owner)
+	d.Set(pubAllTablesAttr, allTables)
+	d.Set(pubTablesAttr, stringSliceToSet(tables))
+	d.Set("publish_insert", pubInsert)
+	d.Set("publish_update", pubUpdate)
+	d.Set("publish_delete", pubDelete)
+	d.Set("publish_truncate", pubTruncate)
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourcePublication(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+	testTables := []string{"test_schema.test_table_1", "test_schema.test_table_2"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := fmt.Sprintf(`
+resource "postgresql_publication" "test" {
+	name          = "publication"
+	database      = "%s"
+	owner         = "%s"
+	tables        = ["test_schema.test_table_1", "test_schema.test_table_2"]
+	publish_param = ["insert", "update"]
+}
+
+data "postgresql_publication" "test" {
+	name     = postgresql_publication.test.name
+	database = postgresql_publication.test.database
+}
+`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePublication)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_publication.test", "owner", roleName),
+					resource.TestCheckResourceAttr("data.postgresql_publication.test", "all_tables", "false"),
+					resource.TestCheckResourceAttr("data.postgresql_publication.test", "tables.#", "2"),
+					resource.TestCheckTypeSetElemAttr("data.postgresql_publication.test", "tables.*", "test_schema.test_table_1"),
+					resource.TestCheckTypeSetElemAttr("data.postgresql_publication.test", "tables.*", "test_schema.test_table_2"),
+					resource.TestCheckResourceAttr("data.postgresql_publication.test", "publish_insert", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_publication.test", "publish_update", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_publication.test", "publish_delete", "false"),
+					resource.TestCheckResourceAttr("data.postgresql_publication.test", "publish_truncate", "false"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,156 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// roleMembersQuery returns the roles which are (directly or not) members of
+	// the role $1, $2 tells if transitive memberships are included.
+	roleMembersQuery = `
+WITH RECURSIVE memberships AS (
+	SELECT m.member AS role_oid, m.admin_option, 1 AS depth
+	FROM pg_catalog.pg_auth_members m
+	WHERE m.roleid = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1)
+	UNION
+	SELECT m.member, m.admin_option, t.depth + 1
+	FROM pg_catalog.pg_auth_members m
+	JOIN memberships t ON m.roleid = t.role_oid
+	WHERE $2
+)
+SELECT pg_catalog.pg_get_userbyid(role_oid), bool_or(admin_option AND depth = 1), min(depth) = 1
+FROM memberships
+GROUP BY role_oid
+ORDER BY 1
+`
+	// roleMemberOfQuery returns the roles which the role $1 is (directly or not)
+	// a member of, $2 tells if transitive memberships are included.
+	roleMemberOfQuery = `
+WITH RECURSIVE memberships AS (
+	SELECT m.roleid AS role_oid, m.admin_option, 1 AS depth
+	FROM pg_catalog.pg_auth_members m
+	WHERE m.member = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1)
+	UNION
+	SELECT m.roleid, m.admin_option, t.depth + 1
+	FROM pg_catalog.pg_auth_members m
+	JOIN memberships t ON m.member = t.role_oid
+	WHERE $2
+)
+SELECT pg_catalog.pg_get_userbyid(role_oid), bool_or(admin_option AND depth = 1), min(depth) = 1
+FROM memberships
+GROUP BY role_oid
+ORDER BY 1
+`
+)
+
+func dataSourcePostgreSQLRoleMemberships() *schema.Resource {
+	membershipSchema := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"role": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"admin_option": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"direct": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+
+	return &schema.Resource{
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLRoleMembershipsRead),
+		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
+			"role": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The name of the role to read the memberships of",
+			},
+			"recursive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to include transitive memberships (e.g. the roles the granted roles are members of)",
+			},
+			"members": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        membershipSchema,
+				Description: "The roles which are members of the role. `admin_option` is only true for direct memberships granted WITH ADMIN OPTION, `direct` is false for transitive memberships",
+			},
+			"member_of": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        membershipSchema,
+				Description: "The roles the role is a member of. `admin_option` is only true for direct memberships granted WITH ADMIN OPTION, `direct` is false for transitive memberships",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLRoleMembershipsRead(db *DBConnection, d *schema.ResourceData) error {
+	roleName := d.Get("role").(string)
+	recursive := d.Get("recursive").(bool)
+
+	txn, err := startTransaction(db.client, "")
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	exists, err := roleExists(txn, roleName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("role %s does not exist", roleName)
+	}
+
+	members, err := readRoleMemberships(txn, roleMembersQuery, roleName, recursive)
+	if err != nil {
+		return err
+	}
+
+	memberOf, err := readRoleMemberships(txn, roleMemberOfQuery, roleName, recursive)
+	if err != nil {
+		return err
+	}
+
+	d.Set("members", members)
+	d.Set("member_of", memberOf)
+	d.SetId(roleName)
+
+	return nil
+}
+
+func readRoleMemberships(txn *sql.Tx, query, roleName string, recursive bool) ([]interface{}, error) {
+	rows, err := txn.Query(query, roleName, recursive)
+	if err != nil {
+		return nil, fmt.Errorf("could not read memberships of role %s: %w", roleName, err)
+	}
+	defer rows.Close()
+
+	memberships := make([]interface{}, 0)
+	for rows.Next() {
+		var role string
+		var adminOption, direct bool
+		if err := rows.Scan(&role, &adminOption, &direct); err != nil {
+			return nil, fmt.Errorf("could not scan membership of role %s: %w", roleName, err)
+		}
+		memberships = append(memberships, map[string]interface{}{
+			"role":         role,
+			"admin_option": adminOption,
+			"direct":       direct,
+		})
+	}
+	return memberships, rows.Err()
+}
@@ -0,0 +1,62 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceRoleMemberships(t *testing.T) {
+	config := `
+resource "postgresql_role" "top" {
+	name = "test_memberships_top"
+}
+
+resource "postgresql_role" "middle" {
+	name  = "test_memberships_middle"
+	roles = [postgresql_role.top.name]
+}
+
+resource "postgresql_role" "bottom" {
+	name  = "test_memberships_bottom"
+	roles = [postgresql_role.middle.name]
+}
+
+data "postgresql_role_memberships" "middle" {
+	role = postgresql_role.middle.name
+
+	depends_on = [postgresql_role.bottom]
+}
+
+data "postgresql_role_memberships" "bottom_recursive" {
+	role      = postgresql_role.bottom.name
+	recursive = true
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.middle", "members.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.middle", "members.0.role", "test_memberships_bottom"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.middle", "members.0.admin_option", "false"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.middle", "member_of.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.middle", "member_of.0.role", "test_memberships_top"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.middle", "member_of.0.direct", "true"),
+
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.bottom_recursive", "members.#", "0"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.bottom_recursive", "member_of.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.bottom_recursive", "member_of.0.role", "test_memberships_middle"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.bottom_recursive", "member_of.0.direct", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.bottom_recursive", "member_of.1.role", "test_memberships_top"),
+					resource.TestCheckResourceAttr("data.postgresql_role_memberships.bottom_recursive", "member_of.1.direct", "false"),
+				),
+			},
+		},
+	})
+}
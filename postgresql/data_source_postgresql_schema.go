@@ -0,0 +1,144 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	schemaMetadataQuery = `
+SELECT oid, pg_catalog.pg_get_userbyid(nspowner)
+FROM pg_catalog.pg_namespace
+WHERE nspname = $1
+`
+	// schemaPrivilegesQuery expands the ACL of the schema (the default one if it has never been changed),
+	// grantee 0 is PUBLIC.
+	schemaPrivilegesQuery = `
+SELECT
+	CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(a.grantee) END,
+	pg_catalog.array_agg(a.privilege_type ORDER BY a.privilege_type),
+	COALESCE(pg_catalog.array_agg(a.privilege_type ORDER BY a.privilege_type) FILTER (WHERE a.is_grantable), '{}')
+FROM pg_catalog.pg_namespace AS n,
+	pg_catalog.aclexplode(COALESCE(n.nspacl, pg_catalog.acldefault('n', n.nspowner))) AS a
+WHERE n.nspname = $1
+GROUP BY 1
+ORDER BY 1
+`
+)
+
+func dataSourcePostgreSQLSchema() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLSchemaRead),
+		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
+			"database": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database of the schema. Defaults to the provider database",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The name of the schema",
+			},
+			"owner": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The owner of the schema",
+			},
+			"oid": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The OID of the schema",
+			},
+			"privileges": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"grantee": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"privileges": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"grantable_privileges": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+				Description: "The privileges on the schema per grantee (`PUBLIC` for the privileges granted to everyone), including the implicit ones of the owner. " +
+					"`grantable_privileges` are the ones granted WITH GRANT OPTION",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLSchemaRead(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	schemaName := d.Get("name").(string)
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var oid uint32
+	var owner string
+	err = txn.QueryRow(schemaMetadataQuery, schemaName).Scan(&oid, &owner)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("schema %s does not exist in database %s", schemaName, database)
+	case err != nil:
+		return fmt.Errorf("could not read schema %s: %w", schemaName, err)
+	}
+
+	privileges, err := readSchemaPrivileges(txn, schemaName)
+	if err != nil {
+		return err
+	}
+
+	d.Set("database", database)
+	d.Set("owner", owner)
+	d.Set("oid", int(oid))
+	d.Set("privileges", privileges)
+	d.SetId(strings.Join([]string{database, schemaName}, "."))
+
+	return nil
+}
+
+func readSchemaPrivileges(txn *sql.Tx, schemaName string) ([]interface{}, error) {
+	rows, err := txn.Query(schemaPrivilegesQuery, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read privileges of schema %s: %w", schemaName, err)
+	}
+	defer rows.Close()
+
+	privileges := make([]interface{}, 0)
+	for rows.Next() {
+		var grantee string
+		var granted, grantable pq.ByteaArray
+		if err := rows.Scan(&grantee, &granted, &grantable); err != nil {
+			return nil, fmt.Errorf("could not scan privileges of schema %s: %w", schemaName, err)
+		}
+		privileges = append(privileges, map[string]interface{}{
+			"grantee":              grantee,
+			"privileges":           pgArrayToSet(granted),
+			"grantable_privileges": pgArrayToSet(grantable),
+		})
+	}
+	return privileges, rows.Err()
+}
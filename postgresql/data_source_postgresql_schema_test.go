@@ -0,0 +1,56 @@
+package postgresql
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceSchema(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+	adminUser := config.getDatabaseUsername()
+
+	tfConfig := `
+data "postgresql_schema" "test" {
+	database = "%s"
+	name     = "%s"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, "test_schema"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_schema.test", "owner", adminUser),
+					resource.TestCheckResourceAttrSet("data.postgresql_schema.test", "oid"),
+					resource.TestCheckResourceAttr("data.postgresql_schema.test", "privileges.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.postgresql_schema.test", "privileges.*", map[string]string{
+						"grantee":                adminUser,
+						"privileges.#":           "2",
+						"grantable_privileges.#": "0",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("data.postgresql_schema.test", "privileges.*", map[string]string{
+						"grantee":                roleName,
+						"privileges.#":           "1",
+						"grantable_privileges.#": "0",
+					}),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(tfConfig, dbName, "missing_schema"),
+				ExpectError: regexp.MustCompile("schema missing_schema does not exist"),
+			},
+		},
+	})
+}
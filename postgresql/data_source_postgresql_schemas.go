@@ -25,8 +25,9 @@ const schemaPatternMatchingTarget = "schema_name"
 
 func dataSourcePostgreSQLDatabaseSchemas() *schema.Resource {
 	return &schema.Resource{
-		Read: PGResourceFunc(dataSourcePostgreSQLSchemasRead),
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLSchemasRead),
 		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
 			"database": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -0,0 +1,151 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	sequenceMetadataQuery = `
+SELECT data_type::text, start_value, min_value, max_value, increment_by, cache_size, cycle,
+	has_sequence_privilege(quote_ident(schemaname) || '.' || quote_ident(sequencename), 'SELECT')
+FROM pg_catalog.pg_sequences
+WHERE schemaname = $1 AND sequencename = $2
+`
+)
+
+func dataSourcePostgreSQLSequence() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLSequenceRead),
+		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
+			"database": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database of the sequence. Defaults to the provider database",
+			},
+			"schema": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema of the sequence",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The name of the sequence",
+			},
+			"data_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The data type of the sequence",
+			},
+			"start_value": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The start value of the sequence",
+			},
+			"min_value": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The minimum value of the sequence",
+			},
+			"max_value": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The maximum value of the sequence",
+			},
+			"increment": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The increment of the sequence",
+			},
+			"cache": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of sequence values preallocated in memory",
+			},
+			"cycle": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the sequence cycles once a limit is reached",
+			},
+			"last_value": {
+				Type:     schema.TypeInt,
+				Computed: true,
+				Description: "The last value returned by the sequence (reading it does not advance the sequence). " +
+					"It requires the SELECT privilege on the sequence, and is not set without it",
+			},
+			"is_called": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether `last_value` has already been returned by nextval. It requires the SELECT privilege on the sequence, and is not set without it",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLSequenceRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureSequencesView) {
+		return fmt.Errorf(
+			"postgresql_sequence data source is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	database := getDatabase(d, db.client.databaseName)
+	schemaName := d.Get("schema").(string)
+	sequenceName := d.Get("name").(string)
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var dataType string
+	var startValue, minValue, maxValue, increment, cache int64
+	var cycle, canSelect bool
+	err = txn.QueryRow(sequenceMetadataQuery, schemaName, sequenceName).Scan(
+		&dataType, &startValue, &minValue, &maxValue, &increment, &cache, &cycle, &canSelect,
+	)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("sequence %s.%s does not exist in database %s", schemaName, sequenceName, database)
+	case err != nil:
+		return fmt.Errorf("could not read sequence %s.%s: %w", schemaName, sequenceName, err)
+	}
+
+	d.Set("database", database)
+	d.Set("data_type", dataType)
+	d.Set("start_value", startValue)
+	d.Set("min_value", minValue)
+	d.Set("max_value", maxValue)
+	d.Set("increment", increment)
+	d.Set("cache", cache)
+	d.Set("cycle", cycle)
+
+	if canSelect {
+		var lastValue int64
+		var isCalled bool
+		query := fmt.Sprintf("SELECT last_value, is_called FROM %s", quoteQualifiedIdentifier(schemaName, sequenceName))
+		if err := txn.QueryRow(query).Scan(&lastValue, &isCalled); err != nil {
+			return fmt.Errorf("could not read last value of sequence %s.%s: %w", schemaName, sequenceName, err)
+		}
+		d.Set("last_value", lastValue)
+		d.Set("is_called", isCalled)
+	} else {
+		log.Printf("[WARN] missing SELECT privilege on sequence %s.%s, last_value and is_called are not read", schemaName, sequenceName)
+	}
+
+	d.SetId(strings.Join([]string{database, schemaName, sequenceName}, "."))
+
+	return nil
+}
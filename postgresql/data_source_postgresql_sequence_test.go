@@ -0,0 +1,48 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceSequence(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr(dbName), "CREATE SEQUENCE test_schema.test_sequence INCREMENT BY 5 START WITH 10 CACHE 3")
+	dbExecute(t, config.connStr(dbName), "SELECT nextval('test_schema.test_sequence')")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureSequencesView)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "postgresql_sequence" "test" {
+	database = "%s"
+	schema   = "test_schema"
+	name     = "test_sequence"
+}
+`, dbName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_sequence.test", "data_type", "bigint"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.test", "start_value", "10"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.test", "increment", "5"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.test", "cache", "3"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.test", "cycle", "false"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.test", "last_value", "10"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.test", "is_called", "true"),
+				),
+			},
+		},
+	})
+}
@@ -18,8 +18,9 @@ const (
 
 func dataSourcePostgreSQLDatabaseSequences() *schema.Resource {
 	return &schema.Resource{
-		Read: PGResourceFunc(dataSourcePostgreSQLSequencesRead),
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLSequencesRead),
 		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
 			"database": {
 				Type:        schema.TypeString,
 				Required:    true,
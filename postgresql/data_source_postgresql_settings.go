@@ -0,0 +1,124 @@
+package postgresql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/lib/pq"
+)
+
+const settingsQuery = `
+SELECT name, setting, COALESCE(unit, ''), context, source
+FROM pg_catalog.pg_settings
+WHERE cardinality($1::text[]) = 0 OR name = ANY($1)
+ORDER BY name
+`
+
+func dataSourcePostgreSQLSettings() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLSettingsRead),
+		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
+			"names": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringIsNotEmpty},
+				Description: "The names of the settings to read (e.g. `wal_level`). All the settings are read if not specified",
+			},
+			"settings": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"setting": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"unit": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"context": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+				Description: "The settings read from pg_settings, ordered by name. `setting` is expressed in `unit` (e.g. `8kB` for shared_buffers), `context` tells how the setting can be changed (e.g. `postmaster` requires a restart)",
+			},
+			"values": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The values of the settings by name, e.g. `values[\"wal_level\"]`",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLSettingsRead(db *DBConnection, d *schema.ResourceData) error {
+	names := []string{}
+	for _, name := range d.Get("names").([]interface{}) {
+		names = append(names, name.(string))
+	}
+
+	rows, err := db.Query(settingsQuery, pq.Array(names))
+	if err != nil {
+		return fmt.Errorf("could not read settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make([]interface{}, 0)
+	values := map[string]interface{}{}
+	for rows.Next() {
+		var name, setting, unit, context, source string
+		if err := rows.Scan(&name, &setting, &unit, &context, &source); err != nil {
+			return fmt.Errorf("could not scan setting: %w", err)
+		}
+		settings = append(settings, map[string]interface{}{
+			"name":    name,
+			"setting": setting,
+			"unit":    unit,
+			"context": context,
+			"source":  source,
+		})
+		values[name] = setting
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Report typos instead of silently returning nothing.
+	var missing []string
+	for _, name := range names {
+		if _, found := values[name]; !found {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("unknown settings: %s", strings.Join(missing, ", "))
+	}
+
+	d.Set("settings", settings)
+	d.Set("values", values)
+	d.SetId(generateDataSourceSettingsID(names))
+
+	return nil
+}
+
+func generateDataSourceSettingsID(names []string) string {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	return "settings_" + strings.Join(sorted, ",")
+}
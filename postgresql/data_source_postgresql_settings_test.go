@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_settings" "test" {
+	names = ["wal_level", "shared_buffers"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_settings.test", "settings.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_settings.test", "settings.0.name", "shared_buffers"),
+					resource.TestCheckResourceAttr("data.postgresql_settings.test", "settings.0.unit", "8kB"),
+					resource.TestCheckResourceAttr("data.postgresql_settings.test", "settings.0.context", "postmaster"),
+					resource.TestCheckResourceAttr("data.postgresql_settings.test", "settings.1.name", "wal_level"),
+					resource.TestCheckResourceAttr("data.postgresql_settings.test", "values.%", "2"),
+					resource.TestCheckResourceAttrSet("data.postgresql_settings.test", "values.wal_level"),
+				),
+			},
+			{
+				Config: `
+data "postgresql_settings" "test" {
+	names = ["wal_levle"]
+}
+`,
+				ExpectError: regexp.MustCompile("unknown settings: wal_levle"),
+			},
+		},
+	})
+}
+
+// Test that a data source can be read from another host than the one of the provider.
+func TestAccPostgresqlDataSourceSettings_Host(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "postgresql_settings" "test" {
+	host  = "%s"
+	names = ["transaction_read_only"]
+}
+`, getTestConfig(t).Host),
+				Check: resource.TestCheckResourceAttr("data.postgresql_settings.test", "settings.#", "1"),
+			},
+			{
+				Config: `
+data "postgresql_settings" "test" {
+	host  = "tf-test-unknown-host.invalid"
+	names = ["transaction_read_only"]
+}
+`,
+				ExpectError: regexp.MustCompile("tf-test-unknown-host.invalid"),
+			},
+		},
+	})
+}
+
+func TestGenerateDataSourceSettingsID(t *testing.T) {
+	if id := generateDataSourceSettingsID([]string{"wal_level", "max_connections"}); id != "settings_max_connections,wal_level" {
+		t.Errorf("unexpected ID: %s", id)
+	}
+	if id := generateDataSourceSettingsID(nil); id != "settings_" {
+		t.Errorf("unexpected ID: %s", id)
+	}
+}
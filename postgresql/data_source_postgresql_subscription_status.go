@@ -0,0 +1,132 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourcePostgreSQLSubscriptionStatus() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLSubscriptionStatusRead),
+		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The name of the subscription",
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"database": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database the subscription belongs to",
+			},
+			"pid": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Process ID of the subscription main worker process (0 if the worker is not running)",
+			},
+			"received_lsn": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Last write-ahead log location received",
+			},
+			"latest_end_lsn": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Last write-ahead log location reported to origin WAL sender",
+			},
+			"last_msg_send_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Send time of last message received from origin WAL sender (RFC 3339)",
+			},
+			"last_msg_receipt_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Receipt time of last message received from origin WAL sender (RFC 3339)",
+			},
+			"latest_end_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time of last write-ahead log location reported to origin WAL sender (RFC 3339)",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLSubscriptionStatusRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePublication) {
+		return fmt.Errorf(
+			"postgresql_subscription_status data source is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	database := getDatabaseForSubscription(d, db.client.databaseName)
+	subName := d.Get("name").(string)
+
+	catalogLock.RLock()
+	defer catalogLock.RUnlock()
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var pid sql.NullInt64
+	var receivedLSN, latestEndLSN sql.NullString
+	var lastMsgSendTime, lastMsgReceiptTime, latestEndTime sql.NullTime
+
+	query := subscriptionStatusQuery(db)
+	switch err := txn.QueryRow(query, subName).Scan(
+		&pid, &receivedLSN, &latestEndLSN, &lastMsgSendTime, &lastMsgReceiptTime, &latestEndTime,
+	); {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("subscription %s not found in database %s", subName, database)
+	case err != nil:
+		return fmt.Errorf("Error reading subscription status: %w", err)
+	}
+
+	d.SetId(strings.Join([]string{database, subName}, "."))
+	d.Set("database", database)
+	d.Set("pid", int(pid.Int64))
+	d.Set("received_lsn", receivedLSN.String)
+	d.Set("latest_end_lsn", latestEndLSN.String)
+	d.Set("last_msg_send_time", formatNullTime(lastMsgSendTime))
+	d.Set("last_msg_receipt_time", formatNullTime(lastMsgReceiptTime))
+	d.Set("latest_end_time", formatNullTime(latestEndTime))
+
+	return nil
+}
+
+// subscriptionStatusQuery returns the query of the status of the main apply worker of the subscription $1
+// of the current database: pg_stat_subscription lists the subscriptions of all the databases, relid is only
+// set for the table synchronization workers and leader_pid for the parallel apply workers (PostgreSQL 16+).
+func subscriptionStatusQuery(db *DBConnection) string {
+	query := `SELECT st.pid, st.received_lsn::text, st.latest_end_lsn::text, st.last_msg_send_time, st.last_msg_receipt_time, st.latest_end_time ` +
+		`FROM pg_catalog.pg_stat_subscription st ` +
+		`JOIN pg_catalog.pg_subscription s ON s.oid = st.subid ` +
+		`WHERE s.subname = $1 ` +
+		`AND s.subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = pg_catalog.current_database()) ` +
+		`AND st.relid IS NULL`
+	if db.featureSupported(featureSubscriptionParallelApply) {
+		query += ` AND st.leader_pid IS NULL`
+	}
+	return query
+}
+
+// formatNullTime returns t in RFC 3339 format, or an empty string if it is NULL.
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
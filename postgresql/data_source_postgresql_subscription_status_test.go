@@ -0,0 +1,82 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestSubscriptionStatusQuery(t *testing.T) {
+	query := subscriptionStatusQuery(&DBConnection{version: semver.MustParse("15.0.0")})
+	if !strings.Contains(query, "s.subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = pg_catalog.current_database())") {
+		t.Errorf("the query should only read the subscriptions of the current database: %s", query)
+	}
+	if strings.Contains(query, "leader_pid") {
+		t.Errorf("leader_pid doesn't exist before PostgreSQL 16: %s", query)
+	}
+
+	// The parallel apply workers have no relid either.
+	query = subscriptionStatusQuery(&DBConnection{version: semver.MustParse("16.0.0")})
+	if !strings.HasSuffix(query, "AND st.relid IS NULL AND st.leader_pid IS NULL") {
+		t.Errorf("the query should exclude the parallel apply workers: %s", query)
+	}
+}
+
+func TestAccPostgresqlDataSourceSubscriptionStatus(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffixPub, teardownPub := setupTestDatabase(t, true, true)
+	dbSuffixSub, teardownSub := setupTestDatabase(t, true, true)
+
+	defer teardownPub()
+	defer teardownSub()
+	testTables := []string{"test_schema.test_table_1"}
+	createTestTables(t, dbSuffixPub, testTables, "")
+	createTestTables(t, dbSuffixSub, testTables, "")
+
+	dbNamePub, _ := getTestDBNames(dbSuffixPub)
+	dbNameSub, _ := getTestDBNames(dbSuffixSub)
+
+	conninfo := getConnInfo(t, dbNamePub)
+
+	config := fmt.Sprintf(`
+	resource "postgresql_publication" "test_pub" {
+		name     = "test_publication"
+		database = "%s"
+		tables   = ["test_schema.test_table_1"]
+	}
+	resource "postgresql_subscription" "test_sub" {
+		name         = "subscription"
+		database     = "%s"
+		conninfo     = "%s"
+		publications = [postgresql_publication.test_pub.name]
+	}
+	data "postgresql_subscription_status" "test_sub" {
+		name     = postgresql_subscription.test_sub.name
+		database = postgresql_subscription.test_sub.database
+	}
+	`, dbNamePub, dbNameSub, conninfo)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSubscriptionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_subscription_status.test_sub", "name", "subscription"),
+					resource.TestCheckResourceAttr("data.postgresql_subscription_status.test_sub", "database", dbNameSub),
+					resource.TestCheckResourceAttrSet("data.postgresql_subscription_status.test_sub", "pid"),
+				),
+			},
+		},
+	})
+	coolDown()
+}
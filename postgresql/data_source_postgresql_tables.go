@@ -9,8 +9,10 @@ import (
 
 const (
 	tableQuery = `
-	SELECT table_name, table_schema, table_type
+	SELECT table_name, table_schema, table_type, c.relkind, c.reltuples::bigint, %s
 	FROM information_schema.tables
+	JOIN pg_catalog.pg_namespace n ON n.nspname = table_schema
+	JOIN pg_catalog.pg_class c ON c.relnamespace = n.oid AND c.relname = table_name
 	`
 	tablePatternMatchingTarget = "table_name"
 	tableSchemaKeyword         = "table_schema"
@@ -19,8 +21,9 @@ const (
 
 func dataSourcePostgreSQLDatabaseTables() *schema.Resource {
 	return &schema.Resource{
-		Read: PGResourceFunc(dataSourcePostgreSQLTablesRead),
+		ReadContext: PGDataSourceFunc(dataSourcePostgreSQLTablesRead),
 		Schema: map[string]*schema.Schema{
+			dataSourceHostAttr: dataSourceHostSchema(),
 			"database": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -67,6 +70,12 @@ func dataSourcePostgreSQLDatabaseTables() *schema.Resource {
 				Optional:    true,
 				Description: "Expression which will be pattern matched against table names in the query using the PostgreSQL ~ (regular expression match) operator",
 			},
+			"include_size": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to compute `total_bytes` for each table. It calls pg_total_relation_size on each table, which can be slow on databases with many tables",
+			},
 			"tables": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -84,9 +93,21 @@ func dataSourcePostgreSQLDatabaseTables() *schema.Resource {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
+						"relation_kind": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"estimated_row_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"total_bytes": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
 					},
 				},
-				Description: "The list of PostgreSQL tables retrieved by this data source. Note that this returns a set, so duplicate table names across different schemas will be consolidated.",
+				Description: "The list of PostgreSQL tables retrieved by this data source. Note that this returns a set, so duplicate table names across different schemas will be consolidated. `estimated_row_count` comes from the planner statistics (pg_class.reltuples) and is -1 or 0 for tables never analyzed, `total_bytes` is only computed with `include_size`.",
 			},
 		},
 	}
@@ -101,7 +122,12 @@ func dataSourcePostgreSQLTablesRead(db *DBConnection, d *schema.ResourceData) er
 	}
 	defer deferredRollback(txn)
 
-	query := tableQuery
+	sizeColumn := "0"
+	if d.Get("include_size").(bool) {
+		sizeColumn = "pg_catalog.pg_total_relation_size(c.oid)"
+	}
+
+	query := fmt.Sprintf(tableQuery, sizeColumn)
 	queryConcatKeyword := queryConcatKeywordWhere
 
 	query = applyTableDataSourceQueryFilters(query, queryConcatKeyword, d)
@@ -117,8 +143,11 @@ func dataSourcePostgreSQLTablesRead(db *DBConnection, d *schema.ResourceData) er
 		var object_name string
 		var schema_name string
 		var table_type string
+		var relkind string
+		var estimated_row_count int64
+		var total_bytes int64
 
-		if err = rows.Scan(&object_name, &schema_name, &table_type); err != nil {
+		if err = rows.Scan(&object_name, &schema_name, &table_type, &relkind, &estimated_row_count, &total_bytes); err != nil {
 			return fmt.Errorf("could not scan table output for database: %w", err)
 		}
 
@@ -126,6 +155,9 @@ func dataSourcePostgreSQLTablesRead(db *DBConnection, d *schema.ResourceData) er
 		result["object_name"] = object_name
 		result["schema_name"] = schema_name
 		result["table_type"] = table_type
+		result["relation_kind"] = tableRelationKind(relkind)
+		result["estimated_row_count"] = int(estimated_row_count)
+		result["total_bytes"] = int(total_bytes)
 		tables = append(tables, result)
 	}
 
@@ -147,6 +179,24 @@ func generateDataSourceTablesID(d *schema.ResourceData, databaseName string) str
 	}, "_")
 }
 
+// tableRelationKind returns a readable name for a pg_class.relkind value.
+func tableRelationKind(relkind string) string {
+	switch relkind {
+	case "r":
+		return "ordinary"
+	case "p":
+		return "partitioned"
+	case "f":
+		return "foreign"
+	case "v":
+		return "view"
+	case "m":
+		return "materialized_view"
+	default:
+		return relkind
+	}
+}
+
 func applyTableDataSourceQueryFilters(query string, queryConcatKeyword string, d *schema.ResourceData) string {
 	filters := []string{}
 	schemasTypeFilter := applyTypeMatchingToQuery(tableSchemaKeyword, d.Get("schemas").([]interface{}))
@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -36,6 +37,11 @@ func TestAccPostgresqlDataSourceTables(t *testing.T) {
 					resource.TestCheckResourceAttr("data.postgresql_tables.test_schema", "tables.0.object_name", "test_table"),
 					resource.TestCheckResourceAttr("data.postgresql_tables.test_schema", "tables.0.schema_name", "test_schema"),
 					resource.TestCheckResourceAttr("data.postgresql_tables.test_schema", "tables.0.table_type", "BASE TABLE"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.test_schema", "tables.0.relation_kind", "ordinary"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.test_schema", "tables.0.total_bytes", "0"),
+					resource.TestCheckResourceAttrSet("data.postgresql_tables.test_schema", "tables.0.estimated_row_count"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.test_schema_size", "tables.#", "1"),
+					resource.TestMatchResourceAttr("data.postgresql_tables.test_schema_size", "tables.0.total_bytes", regexp.MustCompile(`^[1-9][0-9]*$`)),
 					resource.TestCheckResourceAttr("data.postgresql_tables.test_schemas1and2", "tables.#", "3"),
 					resource.TestCheckResourceAttr("data.postgresql_tables.test_schemas1and2_type_base", "tables.#", "3"),
 					resource.TestCheckResourceAttr("data.postgresql_tables.test_schemas1and2_type_other", "tables.#", "0"),
@@ -67,6 +73,12 @@ func generateDataSourceTablesConfig(dbName string) string {
 		schemas = ["test_schema"]
 	}
 
+	data "postgresql_tables" "test_schema_size" {
+		database = "%[1]s"
+		schemas = ["test_schema"]
+		include_size = true
+	}
+
 	data "postgresql_tables" "test_schemas1and2_type_base" {
 		database = "%[1]s"
 		schemas = ["test_schema1","test_schema2"]
@@ -0,0 +1,373 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// fakeDB is a database/sql driver answering the statements sent by the provider with answer,
+// so the statements (and so the round trips) of an operation can be checked without a PostgreSQL server.
+type fakeDB struct {
+	// answer returns the rows of query, a nil result is an empty result.
+	answer func(query string, args []driver.NamedValue) (*fakeRows, error)
+	// latency is added to each round trip, to compare operations sending a different number of statements.
+	latency time.Duration
+	// parseRoundTrips makes the parameterized queries which are not prepared parse in their own round trip
+	// (as lib/pq does), as well as the prepared statements, they are counted in parses.
+	parseRoundTrips bool
+	// transactionPooling answers the same pg_backend_pid() on all the connections, as a pooler in
+	// transaction pooling mode handing them the same server connection.
+	transactionPooling bool
+	// commitLatency is added to the latency of COMMIT, as the flush of the WAL.
+	commitLatency time.Duration
+	// locks returns the name of the catalog rows updated by query (e.g. the ACL of the tables of a schema
+	// by a GRANT), an empty name if none. They are locked until the end of the transaction: as PostgreSQL,
+	// the other transactions updating them wait for it, then fail if it has been committed.
+	locks func(query string) string
+
+	mu          sync.Mutex
+	statements  []string
+	parses      int
+	connections int
+	rowLocks    map[string]*fakeRowLock
+}
+
+// fakeRowLock is the lock of catalog rows, updated tells whether its last holder committed.
+type fakeRowLock struct {
+	sync.Mutex
+	updated bool
+}
+
+// fakeRows are the rows answered by fakeDB.
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+// newFakeClient returns a client whose connection pools are fake, registered for the lifetime of tb.
+// configure can change the configuration of the client, fake then answers on all its hosts.
+func newFakeClient(tb testing.TB, fake *fakeDB, version string, configure ...func(*Config)) *Client {
+	config := Config{
+		Scheme:          "postgres",
+		Host:            "fake",
+		Port:            5432,
+		Username:        "postgres",
+		SSLMode:         "disable",
+		ExpectedVersion: semver.MustParse(version),
+	}
+	for _, fn := range configure {
+		fn(&config)
+	}
+	client := config.NewClient("postgres").withContext(context.Background())
+
+	for _, host := range config.hosts() {
+		registerFakeHost(tb, client, host, fake)
+	}
+	return client
+}
+
+// registerFakeHost registers the fake connection pool of client to host for the lifetime of tb.
+func registerFakeHost(tb testing.TB, client *Client, host string, fake *fakeDB) {
+	dsn := client.config.connStrForHost(host, client.databaseName)
+	db := sql.OpenDB(fakeConnector{fake})
+	dbRegistryLock.Lock()
+	dbRegistry[dsn] = &DBConnection{
+		DB:           db,
+		client:       client,
+		version:      client.config.ExpectedVersion,
+		capabilities: &capabilitiesCache{},
+		stmts:        newStmtCache(client.config.StmtCacheSize),
+	}
+	dbRegistryLock.Unlock()
+
+	tb.Cleanup(func() {
+		dbRegistryLock.Lock()
+		delete(dbRegistry, dsn)
+		dbRegistryLock.Unlock()
+		_ = db.Close()
+	})
+}
+
+// Statements returns the statements received so far and forgets them.
+func (f *fakeDB) Statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	statements := f.statements
+	f.statements = nil
+	return statements
+}
+
+// Parses returns how many queries have been parsed so far (see parseRoundTrips).
+func (f *fakeDB) Parses() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.parses
+}
+
+func (f *fakeDB) parse() {
+	if !f.parseRoundTrips {
+		return
+	}
+	f.mu.Lock()
+	f.parses++
+	f.mu.Unlock()
+
+	if f.latency > 0 {
+		time.Sleep(f.latency)
+	}
+}
+
+func (f *fakeDB) connect() *fakeConn {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.connections++
+	return &fakeConn{db: f, pid: f.connections}
+}
+
+// rowLock returns the lock of the catalog rows named name.
+func (f *fakeDB) rowLock(name string) *fakeRowLock {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.rowLocks == nil {
+		f.rowLocks = map[string]*fakeRowLock{}
+	}
+	lock, ok := f.rowLocks[name]
+	if !ok {
+		lock = &fakeRowLock{}
+		f.rowLocks[name] = lock
+	}
+	return lock
+}
+
+func (f *fakeDB) roundTrip(query string, args []driver.NamedValue) (*fakeRows, error) {
+	f.mu.Lock()
+	f.statements = append(f.statements, query)
+	f.mu.Unlock()
+
+	if f.latency > 0 {
+		time.Sleep(f.latency)
+	}
+	if f.answer == nil {
+		return nil, nil
+	}
+	return f.answer(query, args)
+}
+
+type fakeConnector struct {
+	db *fakeDB
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.db.connect(), nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{c.db}
+}
+
+type fakeDriver struct {
+	db *fakeDB
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return d.db.connect(), nil
+}
+
+type fakeConn struct {
+	db  *fakeDB
+	pid int
+	// held are the row locks taken by the transaction of the connection (see fakeDB.locks).
+	held map[string]*fakeRowLock
+}
+
+// lock takes the row locks of query for the rest of the transaction. If another transaction holds them,
+// it waits for its end and fails if it committed.
+func (c *fakeConn) lock(query string) error {
+	if c.db.locks == nil {
+		return nil
+	}
+	name := c.db.locks(query)
+	if name == "" || c.held[name] != nil {
+		return nil
+	}
+	lock := c.db.rowLock(name)
+	if !lock.TryLock() {
+		lock.Lock()
+		if lock.updated {
+			lock.Unlock()
+			return errors.New("tuple concurrently updated")
+		}
+	}
+	if c.held == nil {
+		c.held = map[string]*fakeRowLock{}
+	}
+	c.held[name] = lock
+	return nil
+}
+
+// unlock releases the row locks of the transaction once it has ended.
+func (c *fakeConn) unlock(committed bool) {
+	for name, lock := range c.held {
+		lock.updated = committed
+		lock.Unlock()
+		delete(c.held, name)
+	}
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.parse()
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	if _, err := c.db.roundTrip("BEGIN", nil); err != nil {
+		return nil, err
+	}
+	return fakeTx{c}, nil
+}
+
+// CheckNamedValue accepts all the arguments as they are, the fake doesn't encode them.
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) > 0 {
+		c.db.parse()
+	}
+	return c.exec(query, args)
+}
+
+func (c *fakeConn) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.lock(query); err != nil {
+		return nil, err
+	}
+	if _, err := c.db.roundTrip(query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) > 0 {
+		c.db.parse()
+	}
+	return c.query(query, args)
+}
+
+func (c *fakeConn) query(query string, args []driver.NamedValue) (driver.Rows, error) {
+	if query == "SELECT pg_backend_pid()" {
+		pid := c.pid
+		if c.db.transactionPooling {
+			pid = 1
+		}
+		return &fakeRowsCursor{rows: &fakeRows{values: [][]driver.Value{{int64(pid)}}}}, nil
+	}
+
+	if err := c.lock(query); err != nil {
+		return nil, err
+	}
+	rows, err := c.db.roundTrip(query, args)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = &fakeRows{}
+	}
+	return &fakeRowsCursor{rows: rows}, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (tx fakeTx) Commit() error {
+	defer tx.conn.unlock(true)
+	if tx.conn.db.commitLatency > 0 {
+		time.Sleep(tx.conn.db.commitLatency)
+	}
+	_, err := tx.conn.db.roundTrip("COMMIT", nil)
+	return err
+}
+
+func (tx fakeTx) Rollback() error {
+	defer tx.conn.unlock(false)
+	_, err := tx.conn.db.roundTrip("ROLLBACK", nil)
+	return err
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.exec(s.query, namedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.query(s.query, namedValues(args))
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+type fakeRowsCursor struct {
+	rows *fakeRows
+	next int
+}
+
+func (r *fakeRowsCursor) Columns() []string {
+	columns := r.rows.columns
+	if columns == nil && len(r.rows.values) > 0 {
+		// The names of the columns are not used by Scan.
+		columns = make([]string, len(r.rows.values[0]))
+	}
+	return columns
+}
+
+func (r *fakeRowsCursor) Close() error {
+	return nil
+}
+
+func (r *fakeRowsCursor) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows.values) {
+		return io.EOF
+	}
+	copy(dest, r.rows.values[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,234 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// grantBatches coalesces the changes of the postgresql_grant resources targeting the same schema.
+var grantBatches = &grantBatcher{
+	pending: map[grantBatchKey]*grantBatch{},
+	running: map[grantBatchKey]*sync.Mutex{},
+}
+
+// defaultPrivilegesBatches coalesces the changes of the postgresql_default_privileges resources
+// targeting the same schema, they all update the rows of pg_default_acl of the schema.
+var defaultPrivilegesBatches = &grantBatcher{
+	pending: map[grantBatchKey]*grantBatch{},
+	running: map[grantBatchKey]*sync.Mutex{},
+}
+
+// defaultACLScans shares the scans of pg_default_acl between the reads of the
+// postgresql_default_privileges resources of the same schema.
+var defaultACLScans = &defaultACLScanner{
+	pending: map[grantBatchKey]*defaultACLScan{},
+	running: map[grantBatchKey]*sync.Mutex{},
+}
+
+// grantBatcher applies the grants targeting the same schema in a single transaction:
+// the ones requested while a batch of the schema is being applied are applied together
+// in the next one. Instead of one transaction per role, concurrently updating the same
+// catalog rows (the ACL of the schema and of its objects) and waiting for each other's locks,
+// each batch takes them once.
+type grantBatcher struct {
+	mu      sync.Mutex
+	pending map[grantBatchKey]*grantBatch
+	// running serializes the batches of the same key.
+	running map[grantBatchKey]*sync.Mutex
+}
+
+type grantBatchKey struct {
+	connStr string
+	schema  string
+}
+
+type grantBatch struct {
+	requests []*grantBatchRequest
+	done     chan struct{}
+}
+
+type grantBatchRequest struct {
+	role string
+	fn   func(*sql.Tx) error
+	err  error
+}
+
+// apply runs fn in the next batch of key and returns its error. The first request of a
+// batch starts its transaction with begin once the previous batch of key is committed.
+func (b *grantBatcher) apply(key grantBatchKey, begin func() (*sql.Tx, error), role string, fn func(*sql.Tx) error) error {
+	request := &grantBatchRequest{role: role, fn: fn}
+
+	b.mu.Lock()
+	batch, joined := b.pending[key]
+	if !joined {
+		batch = &grantBatch{done: make(chan struct{})}
+		b.pending[key] = batch
+	}
+	batch.requests = append(batch.requests, request)
+	running, ok := b.running[key]
+	if !ok {
+		running = &sync.Mutex{}
+		b.running[key] = running
+	}
+	b.mu.Unlock()
+
+	if joined {
+		<-batch.done
+		return request.err
+	}
+
+	// The batch collects the other requests until the previous one is committed.
+	running.Lock()
+	defer running.Unlock()
+
+	b.mu.Lock()
+	delete(b.pending, key)
+	b.mu.Unlock()
+
+	batch.run(begin)
+	close(batch.done)
+
+	return request.err
+}
+
+// run applies the requests of the batch, each one in its own savepoint so the failure of
+// one of them doesn't fail the others. They are sorted by role so the concurrent batches
+// take the advisory locks of the roles (see pgLockRole) in the same order.
+func (batch *grantBatch) run(begin func() (*sql.Tx, error)) {
+	fail := func(err error) {
+		for _, request := range batch.requests {
+			if request.err == nil {
+				request.err = err
+			}
+		}
+	}
+
+	txn, err := begin()
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer deferredRollback(txn)
+
+	sort.SliceStable(batch.requests, func(i, j int) bool {
+		return batch.requests[i].role < batch.requests[j].role
+	})
+
+	for _, request := range batch.requests {
+		if _, err := txn.Exec("SAVEPOINT grant_batch"); err != nil {
+			fail(fmt.Errorf("could not create savepoint: %w", err))
+			return
+		}
+		if request.err = request.fn(txn); request.err != nil {
+			if _, err := txn.Exec("ROLLBACK TO SAVEPOINT grant_batch"); err != nil {
+				fail(fmt.Errorf("could not roll back to savepoint: %w", err))
+				return
+			}
+		}
+		if _, err := txn.Exec("RELEASE SAVEPOINT grant_batch"); err != nil {
+			fail(fmt.Errorf("could not release savepoint: %w", err))
+			return
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		fail(fmt.Errorf("could not commit transaction: %w", err))
+	}
+}
+
+// withGrantTx runs fn in a transaction on the database of the grant, shared with the other
+// grants of the same schema (see grantBatcher). The grants which are not in a schema
+// (database, foreign data wrapper and foreign server) use their own transaction.
+func withGrantTx(db *DBConnection, d *schema.ResourceData, fn func(*sql.Tx) error) error {
+	database := d.Get("database").(string)
+	schemaName := d.Get("schema").(string)
+	if schemaName == "" || d.Get("object_type").(string) == "database" {
+		return db.client.withTx(database, fn)
+	}
+
+	key := grantBatchKey{connStr: db.client.config.connStr(database), schema: schemaName}
+	begin := func() (*sql.Tx, error) {
+		return startTransaction(db.client, database)
+	}
+	return grantBatches.apply(key, begin, d.Get("role").(string), fn)
+}
+
+// withDefaultPrivilegesTx runs fn in a transaction on the database of the default privileges, shared with
+// the other default privileges of the same schema (see grantBatcher). The global default privileges
+// (without schema) of the database are batched together. The requests are sorted by owner, the role
+// locked by pgLockRole.
+func withDefaultPrivilegesTx(db *DBConnection, d *schema.ResourceData, fn func(*sql.Tx) error) error {
+	database := d.Get("database").(string)
+	begin := func() (*sql.Tx, error) {
+		return startTransaction(db.client, database)
+	}
+	return defaultPrivilegesBatches.apply(defaultPrivilegesBatchKey(db, d), begin, d.Get("owner").(string), fn)
+}
+
+// defaultPrivilegesBatchKey returns the key of the batches and of the scans of the default privileges of d.
+func defaultPrivilegesBatchKey(db *DBConnection, d *schema.ResourceData) grantBatchKey {
+	return grantBatchKey{connStr: db.client.config.connStr(d.Get("database").(string)), schema: d.Get("schema").(string)}
+}
+
+// defaultACLScanner scans pg_default_acl once for the reads of the default privileges of the same
+// schema: the ones requested while a scan of the schema is running share the next one. Unlike
+// grantBatcher, each read keeps its own transaction, the scan is sent on the one of the first
+// read of the batch, so it doesn't need another connection of the pool.
+type defaultACLScanner struct {
+	mu      sync.Mutex
+	pending map[grantBatchKey]*defaultACLScan
+	// running serializes the scans of the same key.
+	running map[grantBatchKey]*sync.Mutex
+}
+
+type defaultACLScan struct {
+	// reads is the number of reads sharing the scan.
+	reads   int
+	entries []defaultACLEntry
+	err     error
+	done    chan struct{}
+}
+
+// scan returns the entries of pg_default_acl of the schema of key and the global ones, scanned with
+// txn or by another read of the same batch. As the scan starts once all the reads of its batch requested
+// it, it sees the default privileges committed before each of them (e.g. after its pgLockRole).
+func (s *defaultACLScanner) scan(key grantBatchKey, txn *sql.Tx) ([]defaultACLEntry, error) {
+	s.mu.Lock()
+	scan, joined := s.pending[key]
+	if !joined {
+		scan = &defaultACLScan{done: make(chan struct{})}
+		s.pending[key] = scan
+	}
+	scan.reads++
+	running, ok := s.running[key]
+	if !ok {
+		running = &sync.Mutex{}
+		s.running[key] = running
+	}
+	s.mu.Unlock()
+
+	if joined {
+		<-scan.done
+		return scan.entries, scan.err
+	}
+
+	// The scan collects the other reads until the previous one is done.
+	running.Lock()
+	defer running.Unlock()
+
+	s.mu.Lock()
+	delete(s.pending, key)
+	reads := scan.reads
+	s.mu.Unlock()
+
+	log.Printf("[DEBUG] scanning the default privileges of schema %q for %d reads", key.schema, reads)
+	scan.entries, scan.err = scanDefaultACL(txn, key.schema)
+	close(scan.done)
+
+	return scan.entries, scan.err
+}
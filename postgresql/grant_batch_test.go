@@ -0,0 +1,514 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the requests made while a batch is running are applied in the next one.
+func TestGrantBatcherCoalesce(t *testing.T) {
+	batcher := &grantBatcher{
+		pending: map[grantBatchKey]*grantBatch{},
+		running: map[grantBatchKey]*sync.Mutex{},
+	}
+	key := grantBatchKey{connStr: "test", schema: "public"}
+	noop := func(*sql.Tx) error { return nil }
+
+	var begins int32
+	firstStarted := make(chan struct{})
+	releaseFirst := make(chan struct{})
+	begin := func() (*sql.Tx, error) {
+		if atomic.AddInt32(&begins, 1) == 1 {
+			close(firstStarted)
+			<-releaseFirst
+		}
+		return nil, errors.New("could not start transaction")
+	}
+
+	firstErr := make(chan error)
+	go func() { firstErr <- batcher.apply(key, begin, "role_0", noop) }()
+	<-firstStarted
+
+	const count = 10
+	var wg sync.WaitGroup
+	errs := make([]error, count)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = batcher.apply(key, begin, fmt.Sprintf("role_%d", i+1), noop)
+		}(i)
+	}
+
+	// Wait for all the requests to join the next batch before releasing the first one.
+	for {
+		batcher.mu.Lock()
+		joined := batcher.pending[key] != nil && len(batcher.pending[key].requests) == count
+		batcher.mu.Unlock()
+		if joined {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(releaseFirst)
+
+	assert.EqualError(t, <-firstErr, "could not start transaction")
+	wg.Wait()
+	for _, err := range errs {
+		assert.EqualError(t, err, "could not start transaction")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&begins))
+}
+
+// Test that a failed request of a batch only rolls back its own savepoint, the other ones are committed.
+func TestGrantBatchRollsBackFailedRequest(t *testing.T) {
+	fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+		if query == "GRANT SELECT ON ALL TABLES IN SCHEMA public TO role_b" {
+			return nil, errors.New(`role "role_b" does not exist`)
+		}
+		return nil, nil
+	}}
+	client := newFakeClient(t, fake, "16.0.0")
+
+	batch := &grantBatch{}
+	for _, role := range []string{"role_c", "role_b", "role_a"} {
+		query := "GRANT SELECT ON ALL TABLES IN SCHEMA public TO " + role
+		batch.requests = append(batch.requests, &grantBatchRequest{role: role, fn: func(txn *sql.Tx) error {
+			_, err := txn.Exec(query)
+			return err
+		}})
+	}
+	batch.run(func() (*sql.Tx, error) {
+		return startTransaction(client, "")
+	})
+
+	errs := map[string]string{}
+	for _, request := range batch.requests {
+		if request.err != nil {
+			errs[request.role] = request.err.Error()
+		}
+	}
+	assert.Equal(t, map[string]string{"role_b": `role "role_b" does not exist`}, errs)
+
+	expected := []string{
+		"BEGIN",
+		"SAVEPOINT grant_batch",
+		"GRANT SELECT ON ALL TABLES IN SCHEMA public TO role_a",
+		"RELEASE SAVEPOINT grant_batch",
+		"SAVEPOINT grant_batch",
+		"GRANT SELECT ON ALL TABLES IN SCHEMA public TO role_b",
+		"ROLLBACK TO SAVEPOINT grant_batch",
+		"RELEASE SAVEPOINT grant_batch",
+		"SAVEPOINT grant_batch",
+		"GRANT SELECT ON ALL TABLES IN SCHEMA public TO role_c",
+		"RELEASE SAVEPOINT grant_batch",
+		"COMMIT",
+	}
+	if statements := fake.Statements(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("the batch sent %#v, expected %#v", statements, expected)
+	}
+}
+
+// Test that the reads of the default privileges requested while a scan of pg_default_acl is running share the next one.
+func TestDefaultACLScannerCoalesce(t *testing.T) {
+	var scans int32
+	firstStarted := make(chan struct{})
+	releaseFirst := make(chan struct{})
+	fake := &fakeDB{answer: func(query string, args []driver.NamedValue) (*fakeRows, error) {
+		if query != scanDefaultACLQuery {
+			return nil, nil
+		}
+		if atomic.AddInt32(&scans, 1) == 1 {
+			close(firstStarted)
+			<-releaseFirst
+		}
+		assert.Equal(t, "public", args[0].Value)
+		return &fakeRows{values: [][]driver.Value{{"owner", false, "r", "owner", "reader", "SELECT", false}}}, nil
+	}}
+	client := newFakeClient(t, fake, "16.0.0")
+
+	scanner := &defaultACLScanner{
+		pending: map[grantBatchKey]*defaultACLScan{},
+		running: map[grantBatchKey]*sync.Mutex{},
+	}
+	key := grantBatchKey{connStr: "test", schema: "public"}
+	read := func() ([]defaultACLEntry, error) {
+		txn, err := startTransaction(client, "")
+		if err != nil {
+			return nil, err
+		}
+		defer deferredRollback(txn)
+		return scanner.scan(key, txn)
+	}
+
+	firstErr := make(chan error)
+	go func() {
+		_, err := read()
+		firstErr <- err
+	}()
+	<-firstStarted
+
+	const count = 10
+	var wg sync.WaitGroup
+	entries := make([][]defaultACLEntry, count)
+	errs := make([]error, count)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entries[i], errs[i] = read()
+		}(i)
+	}
+
+	// Wait for all the reads to join the next scan before releasing the first one.
+	for {
+		scanner.mu.Lock()
+		joined := scanner.pending[key] != nil && scanner.pending[key].reads == count
+		scanner.mu.Unlock()
+		if joined {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(releaseFirst)
+
+	assert.NoError(t, <-firstErr)
+	wg.Wait()
+	expected := []defaultACLEntry{{owner: "owner", objectType: "r", grantor: "owner", grantee: "reader", privilege: "SELECT"}}
+	for i := range entries {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, expected, entries[i])
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&scans))
+}
+
+// BenchmarkGrantBatch measures the time needed to concurrently grant 50 roles on the tables of the same schema,
+// each one in its own transaction or in the batches of withGrantTx, against a fake server whose GRANT and REVOKE
+// update the ACL of the tables of the schema: as PostgreSQL, the transactions waiting for another one updating
+// them fail with "tuple concurrently updated" once it is committed.
+func BenchmarkGrantBatch(b *testing.B) {
+	const roleCount = 50
+	const parallelism = 10
+
+	res := resourcePostgreSQLGrant()
+	grants := make([]*schema.ResourceData, roleCount)
+	for i := range grants {
+		d := res.TestResourceData()
+		d.Set("database", "postgres")
+		d.Set("role", fmt.Sprintf("tf_bench_grant_role_%d", i))
+		d.Set("schema", "public")
+		d.Set("object_type", "table")
+		d.Set("privileges", []interface{}{"SELECT", "INSERT"})
+		grants[i] = d
+	}
+
+	for _, test := range []struct {
+		name   string
+		withTx func(*DBConnection, *schema.ResourceData, func(*sql.Tx) error) error
+	}{
+		{name: "transaction per grant", withTx: func(db *DBConnection, d *schema.ResourceData, fn func(*sql.Tx) error) error {
+			return db.client.withTx(d.Get("database").(string), fn)
+		}},
+		{name: "batched", withTx: withGrantTx},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			fake := &fakeDB{
+				latency:       time.Millisecond,
+				commitLatency: 2 * time.Millisecond,
+				locks: func(query string) string {
+					if strings.Contains(query, " ON ALL TABLES IN SCHEMA public ") {
+						return "public tables"
+					}
+					return ""
+				},
+			}
+			db, err := newFakeClient(b, fake, "16.0.0").Connect()
+			if err != nil {
+				b.Fatal(err)
+			}
+			grant := func(db *DBConnection, d *schema.ResourceData) error {
+				return test.withTx(db, d, func(txn *sql.Tx) error {
+					role := d.Get("role").(string)
+					if err := pgLockRole(txn, role); err != nil {
+						return err
+					}
+					if _, err := txn.Exec("REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA public FROM " + role); err != nil {
+						return err
+					}
+					_, err := txn.Exec("GRANT SELECT,INSERT ON ALL TABLES IN SCHEMA public TO " + role)
+					return err
+				})
+			}
+
+			var failed int32
+			fake.Statements()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				sem := make(chan struct{}, parallelism)
+				var wg sync.WaitGroup
+				for _, d := range grants {
+					wg.Add(1)
+					sem <- struct{}{}
+					go func(d *schema.ResourceData) {
+						defer wg.Done()
+						defer func() { <-sem }()
+						if err := grant(db, d); err != nil {
+							atomic.AddInt32(&failed, 1)
+						}
+					}(d)
+				}
+				wg.Wait()
+			}
+			b.StopTimer()
+
+			var commits int
+			for _, statement := range fake.Statements() {
+				if statement == "COMMIT" {
+					commits++
+				}
+			}
+			b.ReportMetric(float64(commits)/float64(b.N), "commits/op")
+			b.ReportMetric(float64(failed)/float64(b.N), "failed/op")
+		})
+	}
+}
+
+// BenchmarkDefaultPrivilegesRead measures the time needed to concurrently read 100 default privileges
+// of the same owner in the same schema, each one scanning pg_default_acl or sharing the scans of
+// defaultACLScans, against a fake server answering the entries of the 100 roles.
+func BenchmarkDefaultPrivilegesRead(b *testing.B) {
+	const roleCount = 100
+	const parallelism = 10
+
+	res := resourcePostgreSQLDefaultPrivileges()
+	defaultPrivileges := make([]*schema.ResourceData, roleCount)
+	var entries [][]driver.Value
+	for i := range defaultPrivileges {
+		role := fmt.Sprintf("tf_bench_default_privileges_role_%d", i)
+		d := res.TestResourceData()
+		d.Set("database", "postgres")
+		d.Set("owner", "tf_bench_default_privileges_owner")
+		d.Set("role", role)
+		d.Set("schema", "public")
+		d.Set("object_type", "table")
+		d.Set("privileges", []interface{}{"SELECT", "INSERT"})
+		defaultPrivileges[i] = d
+		for _, privilege := range []string{"SELECT", "INSERT"} {
+			entries = append(entries, []driver.Value{"tf_bench_default_privileges_owner", false, "r", "tf_bench_default_privileges_owner", role, privilege, false})
+		}
+	}
+
+	for _, test := range []struct {
+		name string
+		read func(*DBConnection, *sql.Tx, *schema.ResourceData) error
+	}{
+		{name: "scan per read", read: func(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+			if err := pgLockRole(txn, d.Get("owner").(string)); err != nil {
+				return err
+			}
+			entries, err := scanDefaultACL(txn, d.Get("schema").(string))
+			if err != nil {
+				return err
+			}
+			privileges, _, _ := defaultPrivilegesOf(entries, d.Get("owner").(string), d.Get("role").(string), "r", false)
+			if len(privileges) != 2 {
+				return fmt.Errorf("expected 2 default privileges, got %d", len(privileges))
+			}
+			return nil
+		}},
+		{name: "shared scan", read: func(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+			if err := readRoleDefaultPrivileges(db, txn, d); err != nil {
+				return err
+			}
+			if d.Get("privileges").(*schema.Set).Len() != 2 {
+				return fmt.Errorf("expected 2 default privileges, got %d", d.Get("privileges").(*schema.Set).Len())
+			}
+			return nil
+		}},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			var scans int32
+			fake := &fakeDB{
+				latency: time.Millisecond,
+				answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+					if query == scanDefaultACLQuery {
+						atomic.AddInt32(&scans, 1)
+						return &fakeRows{values: entries}, nil
+					}
+					return nil, nil
+				},
+			}
+			db, err := newFakeClient(b, fake, "16.0.0").Connect()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				benchmarkForEach(b, db, defaultPrivileges, parallelism, func(db *DBConnection, d *schema.ResourceData) error {
+					return db.client.withTx(d.Get("database").(string), func(txn *sql.Tx) error {
+						return test.read(db, txn, d)
+					})
+				})
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(atomic.LoadInt32(&scans))/float64(b.N), "scans/op")
+		})
+	}
+}
+
+// BenchmarkAccPostgresqlGrant_Schema measures the time needed to concurrently grant
+// 50 roles on the tables of the same schema, as Terraform does with its default parallelism.
+// To compare two revisions, run it on each of them and compare the results with benchstat:
+//
+//	TF_ACC=1 go test ./postgresql -run '^$' -bench PostgresqlGrant_Schema -count 5
+func BenchmarkAccPostgresqlGrant_Schema(b *testing.B) {
+	if os.Getenv(resource.EnvTfAcc) == "" {
+		b.Skipf("Acceptance tests skipped unless env '%s' set", resource.EnvTfAcc)
+	}
+
+	client, db := benchmarkConnect(b)
+
+	const roleCount = 50
+	const parallelism = 10
+
+	roles, deleteRoles := benchmarkCreateRoles(b, db, "tf_bench_grant_role", roleCount)
+	defer deleteRoles()
+
+	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS public.tf_bench_grant_table (id int)"); err != nil {
+		b.Fatalf("could not create table: %v", err)
+	}
+	defer func() {
+		if _, err := db.Exec("DROP TABLE public.tf_bench_grant_table"); err != nil {
+			b.Errorf("could not drop table: %v", err)
+		}
+	}()
+
+	res := resourcePostgreSQLGrant()
+	for n := 0; n < b.N; n++ {
+		grants := make([]*schema.ResourceData, roleCount)
+		for i := range grants {
+			d := res.TestResourceData()
+			d.Set("database", client.databaseName)
+			d.Set("role", roles[i].Get(roleNameAttr))
+			d.Set("schema", "public")
+			d.Set("object_type", "table")
+			d.Set("privileges", []interface{}{"SELECT", "INSERT"})
+			grants[i] = d
+		}
+
+		benchmarkForEach(b, db, grants, parallelism, resourcePostgreSQLGrantCreate)
+
+		b.StopTimer()
+		benchmarkForEach(b, db, grants, parallelism, resourcePostgreSQLGrantDelete)
+		b.StartTimer()
+	}
+}
+
+// BenchmarkAccPostgresqlDefaultPrivileges_Schema measures the time needed to concurrently create
+// and read 100 default privileges of the same owner in the same schema.
+//
+//	TF_ACC=1 go test ./postgresql -run '^$' -bench PostgresqlDefaultPrivileges_Schema -count 5
+func BenchmarkAccPostgresqlDefaultPrivileges_Schema(b *testing.B) {
+	if os.Getenv(resource.EnvTfAcc) == "" {
+		b.Skipf("Acceptance tests skipped unless env '%s' set", resource.EnvTfAcc)
+	}
+
+	client, db := benchmarkConnect(b)
+
+	const roleCount = 100
+	const parallelism = 10
+
+	owners, deleteOwners := benchmarkCreateRoles(b, db, "tf_bench_default_privileges_owner", 1)
+	defer deleteOwners()
+	roles, deleteRoles := benchmarkCreateRoles(b, db, "tf_bench_default_privileges_role", roleCount)
+	defer deleteRoles()
+
+	res := resourcePostgreSQLDefaultPrivileges()
+	for n := 0; n < b.N; n++ {
+		defaultPrivileges := make([]*schema.ResourceData, roleCount)
+		for i := range defaultPrivileges {
+			d := res.TestResourceData()
+			d.Set("database", client.databaseName)
+			d.Set("owner", owners[0].Get(roleNameAttr))
+			d.Set("role", roles[i].Get(roleNameAttr))
+			d.Set("schema", "public")
+			d.Set("object_type", "table")
+			d.Set("privileges", []interface{}{"SELECT", "INSERT"})
+			defaultPrivileges[i] = d
+		}
+
+		benchmarkForEach(b, db, defaultPrivileges, parallelism, resourcePostgreSQLDefaultPrivilegesCreate)
+		benchmarkForEach(b, db, defaultPrivileges, parallelism, resourcePostgreSQLDefaultPrivilegesRead)
+
+		b.StopTimer()
+		benchmarkForEach(b, db, defaultPrivileges, parallelism, resourcePostgreSQLDefaultPrivilegesDelete)
+		b.StartTimer()
+	}
+}
+
+func benchmarkConnect(b *testing.B) (*Client, *DBConnection) {
+	if err := testAccProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(nil)); err != nil {
+		b.Fatal(err)
+	}
+	client := testAccProvider.Meta().(*Client)
+	db, err := client.Connect()
+	if err != nil {
+		b.Fatalf("could not connect to database: %v", err)
+	}
+	return client, db
+}
+
+// benchmarkCreateRoles creates count roles named prefix_<i> and returns them with a function deleting them.
+func benchmarkCreateRoles(b *testing.B, db *DBConnection, prefix string, count int) ([]*schema.ResourceData, func()) {
+	roles := make([]*schema.ResourceData, count)
+	for i := range roles {
+		d := resourcePostgreSQLRole().TestResourceData()
+		d.Set(roleNameAttr, fmt.Sprintf("%s_%d", prefix, i))
+		if err := resourcePostgreSQLRoleCreate(db, d); err != nil {
+			b.Fatalf("could not create role: %v", err)
+		}
+		roles[i] = d
+	}
+	return roles, func() {
+		for _, d := range roles {
+			if err := resourcePostgreSQLRoleDelete(db, d); err != nil {
+				b.Errorf("could not delete role: %v", err)
+			}
+		}
+	}
+}
+
+// benchmarkForEach runs fn for each resource with at most parallelism goroutines.
+func benchmarkForEach(b *testing.B, db *DBConnection, resources []*schema.ResourceData, parallelism int, fn func(*DBConnection, *schema.ResourceData) error) {
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, d := range resources {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d *schema.ResourceData) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(db, d); err != nil {
+				b.Errorf("could not apply %s: %v", d.Get("role"), err)
+			}
+		}(d)
+	}
+	wg.Wait()
+}
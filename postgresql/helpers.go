@@ -1,26 +1,33 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 )
 
-func PGResourceFunc(fn func(*DBConnection, *schema.ResourceData) error) func(*schema.ResourceData, interface{}) error {
-	return func(d *schema.ResourceData, meta interface{}) error {
-		client := meta.(*Client)
+// PGResourceFunc wraps a resource function so it receives a connection
+// bound to the context of the Terraform operation, which means queries are
+// cancelled if the operation is cancelled or times out.
+func PGResourceFunc(fn func(*DBConnection, *schema.ResourceData) error) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		client := meta.(*Client).withContext(ctx)
 
 		db, err := client.Connect()
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 
-		return fn(db, d)
+		return diag.FromErr(fn(db, d))
 	}
 }
 
@@ -71,10 +78,376 @@ func isMemberOfRole(db QueryAble, role, member string) (bool, error) {
 	return true, nil
 }
 
+// ownerOIDPrefix allows to reference an owner role by its OID (e.g. `oid:16384`)
+// instead of its name, so renaming the role doesn't affect the objects it owns.
+const ownerOIDPrefix = "oid:"
+
+// parseOwnerOID returns the OID referenced by owner and true if owner uses the
+// `oid:NNN` form.
+func parseOwnerOID(owner string) (uint32, bool, error) {
+	if !strings.HasPrefix(owner, ownerOIDPrefix) {
+		return 0, false, nil
+	}
+	oid, err := strconv.ParseUint(strings.TrimPrefix(owner, ownerOIDPrefix), 10, 32)
+	if err != nil {
+		return 0, true, fmt.Errorf("invalid owner %q, expected %sNNN: %w", owner, ownerOIDPrefix, err)
+	}
+	return uint32(oid), true, nil
+}
+
+// validateOwner checks that an owner attribute is either a role name or a valid
+// `oid:NNN` reference.
+func validateOwner(v interface{}, key string) (warnings []string, errors []error) {
+	if _, _, err := parseOwnerOID(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%s: %w", key, err))
+	}
+	return
+}
+
+// resolveOwner returns the name of the role referenced by owner, which can be
+// either a role name or an `oid:NNN` reference.
+func resolveOwner(db QueryAble, owner string) (string, error) {
+	oid, isOID, err := parseOwnerOID(owner)
+	if err != nil || !isOID {
+		return owner, err
+	}
+
+	var name string
+	err = db.QueryRow("SELECT rolname FROM pg_catalog.pg_roles WHERE oid = $1", oid).Scan(&name)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", fmt.Errorf("could not find role with OID %d referenced by owner %q", oid, owner)
+	case err != nil:
+		return "", fmt.Errorf("could not resolve owner %q: %w", owner, err)
+	}
+	return name, nil
+}
+
+// checkRoleExistence returns a clear error if role doesn't exist, instead of the
+// error of the statement using it, which is not always explicit.
+func checkRoleExistence(db QueryAble, role string) error {
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1)", role).Scan(&exists); err != nil {
+		return fmt.Errorf("could not check if role %s exists: %w", role, err)
+	}
+	if !exists {
+		return fmt.Errorf("role %s does not exist", role)
+	}
+	return nil
+}
+
+// checkTablespaceExistence returns a clear error if tablespace doesn't exist, see checkRoleExistence.
+func checkTablespaceExistence(db QueryAble, tablespace string) error {
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_tablespace WHERE spcname = $1)", tablespace).Scan(&exists); err != nil {
+		return fmt.Errorf("could not check if tablespace %s exists: %w", tablespace, err)
+	}
+	if !exists {
+		return fmt.Errorf("tablespace %s does not exist", tablespace)
+	}
+	return nil
+}
+
+// checkExtensionInstalled returns a clear error if extension is not installed in the database
+// db is connected to, for the resources wrapping the functions of an extension.
+func checkExtensionInstalled(db QueryAble, extension, database string) error {
+	var installed bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_extension WHERE extname = $1)", extension).Scan(&installed); err != nil {
+		return fmt.Errorf("could not check if extension %s is installed: %w", extension, err)
+	}
+	if !installed {
+		return fmt.Errorf("extension %s is not installed in database %s (CREATE EXTENSION %s)", extension, database, extension)
+	}
+	return nil
+}
+
+// listSettings are the settings whose elements are quoted separately by ALTER SYSTEM/ROLE/DATABASE SET
+// (GUC_LIST_QUOTE), their value is split on commas to set each element as its own literal.
+var listSettings = map[string]bool{
+	"local_preload_libraries":   true,
+	"search_path":               true,
+	"session_preload_libraries": true,
+	"shared_preload_libraries":  true,
+	"temp_tablespaces":          true,
+	"unix_socket_directories":   true,
+}
+
+// serverSettingSources are the sources of pg_settings of the values set for the whole server,
+// the other ones (database, user, session...) only apply to the current session.
+const serverSettingSources = "'default', 'configuration file', 'command line', 'environment variable', 'override'"
+
+// readServerSetting returns the value of the setting for the whole server, when the one of the current session
+// comes from its role or its database: the last one of the configuration files if they can be read
+// (pg_file_settings, superuser or pg_read_all_settings), the default value otherwise.
+func readServerSetting(db QueryAble, name string) (string, error) {
+	var canReadFiles bool
+	if err := db.QueryRow("SELECT pg_catalog.has_table_privilege('pg_catalog.pg_file_settings', 'SELECT')").Scan(&canReadFiles); err != nil {
+		return "", fmt.Errorf("could not check if the configuration files can be read: %w", err)
+	}
+
+	query := "SELECT boot_val FROM pg_catalog.pg_settings WHERE name = $1"
+	if canReadFiles {
+		query = `
+SELECT COALESCE(
+	(SELECT setting FROM pg_catalog.pg_file_settings WHERE name = $1 AND applied ORDER BY seqno DESC LIMIT 1),
+	(SELECT boot_val FROM pg_catalog.pg_settings WHERE name = $1)
+)`
+	}
+	var value sql.NullString
+	if err := db.QueryRow(query, name).Scan(&value); err != nil {
+		return "", fmt.Errorf("could not read the server value of %s: %w", name, err)
+	}
+	return value.String, nil
+}
+
+// quoteSettingName quotes each part of the setting name, custom settings are prefixed
+// by the name of their extension (e.g. pgaudit.log).
+func quoteSettingName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = pq.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// settingValueSQL returns the value to use in a SET statement. It is a single literal
+// (e.g. pgaudit.log = 'write, ddl' is kept as is) except for the elements of list settings.
+func settingValueSQL(name, value string) string {
+	if !listSettings[name] {
+		return pq.QuoteLiteral(value)
+	}
+
+	var values []string
+	for _, element := range splitSettingList(value) {
+		values = append(values, pq.QuoteLiteral(element))
+	}
+	if len(values) == 0 {
+		return "''"
+	}
+	return strings.Join(values, ", ")
+}
+
+func splitSettingList(value string) []string {
+	var elements []string
+	for _, element := range strings.Split(value, ",") {
+		if element = strings.Trim(strings.TrimSpace(element), `"`); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
+
+// settingValuesEqual ignores the spaces and quotes PostgreSQL adds between
+// the elements of list settings (e.g. "a,b" is stored as "a, b").
+func settingValuesEqual(name, old, new string) bool {
+	if !listSettings[name] {
+		return old == new
+	}
+	return strings.Join(splitSettingList(old), ",") == strings.Join(splitSettingList(new), ",")
+}
+
+// parseConfig parses the settings stored in pg_db_role_setting (rolconfig, setconfig), as name=value.
+func parseConfig(config pq.ByteaArray) map[string]string {
+	settings := make(map[string]string, len(config))
+	for _, v := range config {
+		if name, value, ok := strings.Cut(string(v), "="); ok {
+			settings[name] = value
+		}
+	}
+	return settings
+}
+
+// managedConfig returns the settings read for the keys of the config attribute,
+// the settings which are not listed are not managed (e.g. set by another tool).
+func managedConfig(d *schema.ResourceData, attr string, settings map[string]string) map[string]interface{} {
+	managed := map[string]interface{}{}
+	for name := range d.Get(attr).(map[string]interface{}) {
+		if value, ok := settings[name]; ok {
+			managed[name] = value
+		}
+	}
+	return managed
+}
+
+// configQueries returns the ALTER statements setting the new values of the config attribute
+// and resetting the removed ones, sorted by name. alterPrefix is e.g. `ALTER ROLE "foo"`.
+func configQueries(d *schema.ResourceData, attr, alterPrefix string) []string {
+	if !d.HasChange(attr) {
+		return nil
+	}
+
+	oldRaw, newRaw := d.GetChange(attr)
+	return configMapQueries(oldRaw.(map[string]interface{}), newRaw.(map[string]interface{}), alterPrefix)
+}
+
+// configMapQueries returns the ALTER statements going from the old settings to the new ones, see configQueries.
+func configMapQueries(old, new map[string]interface{}, alterPrefix string) []string {
+	names := make([]string, 0, len(old)+len(new))
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for name := range new {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	queries := make([]string, 0, len(names))
+	for _, name := range names {
+		value, ok := new[name]
+		switch {
+		case !ok:
+			queries = append(queries, fmt.Sprintf("%s RESET %s", alterPrefix, quoteSettingName(name)))
+		case old[name] != value:
+			queries = append(queries, fmt.Sprintf("%s SET %s TO %s", alterPrefix, quoteSettingName(name), settingValueSQL(name, value.(string))))
+		}
+	}
+	return queries
+}
+
+// resetAllConfigQueries returns the ALTER statements resetting all the settings then setting the ones of config,
+// sorted by name.
+func resetAllConfigQueries(config map[string]interface{}, alterPrefix string) []string {
+	return append([]string{alterPrefix + " RESET ALL"}, configMapQueries(map[string]interface{}{}, config, alterPrefix)...)
+}
+
+// suppressConfigDiff is the DiffSuppressFunc of the config attributes, see settingValuesEqual.
+func suppressConfigDiff(k, old, new string, _ *schema.ResourceData) bool {
+	name := k[strings.Index(k, ".")+1:]
+	return name != "%" && settingValuesEqual(name, old, new)
+}
+
+// skipReplicationChecksAttr is the attribute of the logical replication resources
+// disabling checkReplicationPrerequisites.
+const skipReplicationChecksAttr = "skip_replication_checks"
+
+// replicationPrerequisite is a server setting required by logical replication.
+type replicationPrerequisite struct {
+	name string
+	// expected describes the expected value in the errors, e.g. "logical" or "> 0".
+	expected string
+	ok       func(value string) bool
+}
+
+func settingIsPositive(value string) bool {
+	n, err := strconv.Atoi(value)
+	return err == nil && n > 0
+}
+
+// checkReplicationPrerequisites returns an actionable error listing the settings of the server
+// which don't match the prerequisites, instead of a failure once the replication is used.
+func checkReplicationPrerequisites(db QueryAble, object string, prerequisites []replicationPrerequisite) error {
+	names := make([]string, len(prerequisites))
+	for i, prerequisite := range prerequisites {
+		names[i] = prerequisite.name
+	}
+
+	rows, err := db.Query("SELECT name, setting FROM pg_catalog.pg_settings WHERE name = ANY($1)", pq.Array(names))
+	if err != nil {
+		return fmt.Errorf("could not read replication settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := map[string]string{}
+	for rows.Next() {
+		var name, setting string
+		if err := rows.Scan(&name, &setting); err != nil {
+			return fmt.Errorf("could not scan replication setting: %w", err)
+		}
+		settings[name] = setting
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var mismatches []string
+	for _, prerequisite := range prerequisites {
+		if value, found := settings[prerequisite.name]; found && !prerequisite.ok(value) {
+			mismatches = append(mismatches, fmt.Sprintf("%s %s (current: %s)", prerequisite.name, prerequisite.expected, value))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf(
+			"%s requires %s: update the server configuration (these settings require a restart) "+
+				"or set %s = true to skip this check",
+			object, strings.Join(mismatches, ", "), skipReplicationChecksAttr,
+		)
+	}
+
+	return nil
+}
+
+// ownerStateValue returns the value to store in the state for an owner
+// attribute: an `oid:NNN` reference is kept as long as it matches the current
+// owner OID, so a renamed owner doesn't show any diff.
+func ownerStateValue(current, ownerName string, ownerOID uint32) string {
+	if oid, isOID, err := parseOwnerOID(current); err == nil && isOID && oid == ownerOID {
+		return current
+	}
+	return ownerName
+}
+
+// commentAttr is the inline comment attribute of the resources supporting
+// COMMENT ON. It is applied when creating the object, so it is not lost if the
+// object is replaced.
+const commentAttr = "comment"
+
+// setObjectComment runs COMMENT ON for the object, an empty comment removes it.
+// objectType is the SQL object type (e.g. SCHEMA) and quotedName its already quoted name.
+func setObjectComment(db QueryAble, objectType, quotedName, comment string) error {
+	quotedComment := "NULL"
+	if comment != "" {
+		quotedComment = pq.QuoteLiteral(comment)
+	}
+
+	query := fmt.Sprintf("COMMENT ON %s %s IS %s", objectType, quotedName, quotedComment)
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("could not set comment on %s %s: %w", strings.ToLower(objectType), quotedName, err)
+	}
+	return nil
+}
+
+// canSetRole returns true if the current user can SET ROLE to *role*.
+// It requires PostgreSQL 16+ (SET option on memberships).
+func canSetRole(db QueryAble, role string) (bool, error) {
+	var canSet bool
+	if err := db.QueryRow("SELECT pg_has_role($1, 'SET')", role).Scan(&canSet); err != nil {
+		return false, fmt.Errorf("could not check if current user can set role %s: %w", role, err)
+	}
+	return canSet, nil
+}
+
+// hasRolePrivileges returns true if the current user has the privileges of *role*,
+// i.e. if it is a member of it with INHERIT option (PostgreSQL 16+).
+func hasRolePrivileges(db QueryAble, role string) (bool, error) {
+	var hasPrivileges bool
+	if err := db.QueryRow("SELECT pg_has_role($1, 'USAGE')", role).Scan(&hasPrivileges); err != nil {
+		return false, fmt.Errorf("could not check if current user has the privileges of role %s: %w", role, err)
+	}
+	return hasPrivileges, nil
+}
+
+// hasRoleAdminOption returns true if the current user has ADMIN OPTION on *role*,
+// i.e. if it can grant it to other roles.
+func hasRoleAdminOption(db QueryAble, role string) (bool, error) {
+	var hasAdmin bool
+	if err := db.QueryRow("SELECT pg_has_role($1, 'USAGE WITH ADMIN OPTION')", role).Scan(&hasAdmin); err != nil {
+		return false, fmt.Errorf("could not check admin option of current user on role %s: %w", role, err)
+	}
+	return hasAdmin, nil
+}
+
 // grantRoleMembership grants the role *role* to the user *member*.
 // It returns false if the grant is not needed because the user is already
 // a member of this role.
 func grantRoleMembership(db QueryAble, role, member string) (bool, error) {
+	return grantRoleMembershipWithOptions(db, role, member, "")
+}
+
+// grantRoleMembershipWithOptions is grantRoleMembership with the membership
+// options of PostgreSQL 16+ (e.g. "SET TRUE, INHERIT FALSE"), if not empty.
+func grantRoleMembershipWithOptions(db QueryAble, role, member, options string) (bool, error) {
 	if member == role {
 		return false, nil
 	}
@@ -91,13 +464,21 @@ func grantRoleMembership(db QueryAble, role, member string) (bool, error) {
 
 	log.Printf("grantRoleMembership: granting %s to %s", role, member)
 
-	sql := fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
+	sql := grantRoleMembershipQuery(role, member, options)
 	if _, err := db.Exec(sql); err != nil {
 		return false, fmt.Errorf("Error granting role %s to %s: %w", role, member, err)
 	}
 	return true, nil
 }
 
+func grantRoleMembershipQuery(role, member, options string) string {
+	sql := fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
+	if options != "" {
+		sql += " WITH " + options
+	}
+	return sql
+}
+
 // revokeRoleMembership revokes the role *role* from the user *member*.
 // It returns false if the revoke is not needed because the user is not a member of this role.
 func revokeRoleMembership(db QueryAble, role, member string) (bool, error) {
@@ -125,7 +506,8 @@ func revokeRoleMembership(db QueryAble, role, member string) (bool, error) {
 // withRolesGranted temporarily grants, if needed, the roles specified to connected user
 // (i.e.: the admin configure in the provider) and revoke them as soon as the
 // callback func has finished.
-func withRolesGranted(txn *sql.Tx, roles []string, fn func() error) error {
+// db is only used for the capabilities of the connected user, txn may be on another database of the server.
+func withRolesGranted(db *DBConnection, txn *sql.Tx, roles []string, fn func() error) error {
 	// No roles asked, execute the function directly
 	if len(roles) == 0 {
 		return fn()
@@ -136,7 +518,7 @@ func withRolesGranted(txn *sql.Tx, roles []string, fn func() error) error {
 		return err
 	}
 
-	superuser, err := isSuperuser(txn, currentUser)
+	superuser, err := db.isSuperuser()
 	if err != nil {
 		return err
 	}
@@ -237,7 +619,7 @@ func sliceContainsStr(haystack []string, needle string) bool {
 // see: https://www.postgresql.org/docs/current/sql-grant.html
 var allowedPrivileges = map[string][]string{
 	"database":             {"ALL", "CREATE", "CONNECT", "TEMPORARY"},
-	"table":                {"ALL", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+	"table":                {"ALL", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER", "MAINTAIN"},
 	"sequence":             {"ALL", "USAGE", "SELECT", "UPDATE"},
 	"schema":               {"ALL", "CREATE", "USAGE"},
 	"function":             {"ALL", "EXECUTE"},
@@ -250,7 +632,18 @@ var allowedPrivileges = map[string][]string{
 }
 
 // validatePrivileges checks that privileges to apply are allowed for this object type.
-func validatePrivileges(d *schema.ResourceData) error {
+// versionedPrivileges are the privileges which only exist from a given PostgreSQL version.
+var versionedPrivileges = map[string]featureName{
+	"MAINTAIN": featureMaintainPrivilege,
+}
+
+// privilegeSupported returns false if the privilege doesn't exist in the server version.
+func privilegeSupported(db *DBConnection, privilege string) bool {
+	feature, ok := versionedPrivileges[privilege]
+	return !ok || db.featureSupported(feature)
+}
+
+func validatePrivileges(db *DBConnection, d *schema.ResourceData) error {
 	objectType := d.Get("object_type").(string)
 	privileges := d.Get("privileges").(*schema.Set).List()
 
@@ -263,10 +656,33 @@ func validatePrivileges(d *schema.ResourceData) error {
 		if !sliceContainsStr(allowed, priv.(string)) {
 			return fmt.Errorf("%s is not an allowed privilege for object type %s", priv, objectType)
 		}
+		if !privilegeSupported(db, priv.(string)) {
+			return db.unsupportedFeatureError(versionedPrivileges[priv.(string)], fmt.Sprintf("the %s privilege", priv))
+		}
 	}
 	return nil
 }
 
+// readPrivilegesSet returns the privileges read from the catalog to store in the state of a
+// grant resource. If the privileges in the state are ["ALL"], they are kept as is as long as
+// the privileges read include all the privileges of the object type supported by the server,
+// which is what ALL expands to.
+func readPrivilegesSet(db *DBConnection, d *schema.ResourceData, privileges pq.ByteaArray) *schema.Set {
+	read := pgArrayToSet(privileges)
+	current := d.Get("privileges").(*schema.Set)
+	if !current.Contains("ALL") {
+		return read
+	}
+
+	for _, privilege := range allowedPrivileges[d.Get("object_type").(string)] {
+		if privilege != "ALL" && privilegeSupported(db, privilege) && !read.Contains(privilege) {
+			return read
+		}
+	}
+
+	return schema.NewSet(schema.HashString, []interface{}{"ALL"})
+}
+
 func pgArrayToSet(arr pq.ByteaArray) *schema.Set {
 	s := make([]interface{}, len(arr))
 	for i, v := range arr {
@@ -292,7 +708,7 @@ func quoteIdentifyIdent(ident string) string {
 	// https://en.wikipedia.org/wiki/Function_overloading
 	// https://stackoverflow.com/a/48640797
 
-	s := strings.Split(ident, "(")
+	s := strings.SplitN(ident, "(", 2)
 
 	functionArgTypes := ""
 
@@ -303,6 +719,61 @@ func quoteIdentifyIdent(ident string) string {
 	return fmt.Sprintf("%s%s", pq.QuoteIdentifier(s[0]), functionArgTypes)
 }
 
+// quoteQualifiedIdentifier quotes each part of a qualified identifier
+// (e.g. schema and table names) and joins them with a dot.
+func quoteQualifiedIdentifier(parts ...string) string {
+	quotedParts := make([]string, len(parts))
+	for i, part := range parts {
+		quotedParts[i] = pq.QuoteIdentifier(part)
+	}
+	return strings.Join(quotedParts, ".")
+}
+
+// splitQualifiedIdentifier splits a qualified identifier like `schema.table` on the dots
+// which are not enclosed in double quotes, the double quoted parts are unquoted.
+func splitQualifiedIdentifier(ident string) ([]string, error) {
+	var parts []string
+	var part strings.Builder
+	quoted := false
+
+	for i := 0; i < len(ident); i++ {
+		c := ident[i]
+		switch {
+		case c == '"' && quoted && i+1 < len(ident) && ident[i+1] == '"':
+			// Escaped double quote inside of a quoted part
+			part.WriteByte('"')
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == '.' && !quoted:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quoted identifier in %q", ident)
+	}
+	parts = append(parts, part.String())
+
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf("empty identifier in %q", ident)
+		}
+	}
+	return parts, nil
+}
+
+// quoteIdentifierIfNeeded quotes the identifier only if it could not be split back
+// by splitQualifiedIdentifier, this keeps the IDs of the usual names readable.
+func quoteIdentifierIfNeeded(ident string) string {
+	if strings.ContainsAny(ident, `."(`) {
+		return pq.QuoteIdentifier(ident)
+	}
+	return ident
+}
+
 func setToPgIdentList(schema string, idents *schema.Set) string {
 	quotedIdents := make([]string, idents.Len())
 	for i, ident := range idents.List() {
@@ -334,20 +805,51 @@ func setToPgIdentSimpleList(idents *schema.Set) string {
 // If the database is specified and different from the one configured in the provider,
 // it will create a new connection pool if needed.
 func startTransaction(client *Client, database string) (*sql.Tx, error) {
+	txn, err := startCachedTransaction(client, database)
+	return txn.Tx, err
+}
+
+// startCachedTransaction starts a transaction as startTransaction, whose parameterized queries go
+// through the prepared statement cache of the pool (see cachedTx), e.g. for the queries repeated by Read.
+func startCachedTransaction(client *Client, database string) (cachedTx, error) {
 	if database != "" && database != client.databaseName {
-		client = client.config.NewClient(database)
+		client = client.config.NewClient(database).withContext(client.ctx)
 	}
 	db, err := client.Connect()
 	if err != nil {
-		return nil, err
+		return cachedTx{}, err
 	}
 
 	txn, err := db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("could not start transaction: %w", err)
+		return cachedTx{}, fmt.Errorf("could not start transaction: %w", err)
 	}
 
-	return txn, nil
+	return cachedTx{Tx: txn, db: db}, nil
+}
+
+// withTx runs fn inside a single transaction on the specified database, committing
+// if fn succeeds and rolling back otherwise, so a failure in the middle of a
+// multi-statement operation does not leave partial changes behind.
+// Statements which cannot run inside a transaction block (e.g.: CREATE/DROP DATABASE,
+// ALTER DATABASE SET TABLESPACE, CREATE INDEX CONCURRENTLY or CREATE/DROP SUBSCRIPTION)
+// must not be sent through this helper.
+func (c *Client) withTx(database string, fn func(*sql.Tx) error) error {
+	txn, err := startTransaction(c, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	if err := fn(txn); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
 }
 
 func dbExists(db QueryAble, dbname string) (bool, error) {
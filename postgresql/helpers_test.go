@@ -1,8 +1,12 @@
 package postgresql
 
 import (
+	"context"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,3 +21,166 @@ func TestFindStringSubmatchMap(t *testing.T) {
 		},
 	)
 }
+
+func TestParseOwnerOID(t *testing.T) {
+	oid, isOID, err := parseOwnerOID("my_role")
+	assert.NoError(t, err)
+	assert.False(t, isOID)
+	assert.Equal(t, uint32(0), oid)
+
+	oid, isOID, err = parseOwnerOID("oid:16384")
+	assert.NoError(t, err)
+	assert.True(t, isOID)
+	assert.Equal(t, uint32(16384), oid)
+
+	_, isOID, err = parseOwnerOID("oid:my_role")
+	assert.Error(t, err)
+	assert.True(t, isOID)
+}
+
+func TestOwnerStateValue(t *testing.T) {
+	assert.Equal(t, "oid:16384", ownerStateValue("oid:16384", "renamed_role", 16384))
+	assert.Equal(t, "other_role", ownerStateValue("oid:16384", "other_role", 16385))
+	assert.Equal(t, "renamed_role", ownerStateValue("my_role", "renamed_role", 16384))
+	assert.Equal(t, "my_role", ownerStateValue("", "my_role", 16384))
+}
+
+func TestQuoteQualifiedIdentifier(t *testing.T) {
+	assert.Equal(t, `"public"."my_table"`, quoteQualifiedIdentifier("public", "my_table"))
+	assert.Equal(t, `"My.Schema"."Table"`, quoteQualifiedIdentifier("My.Schema", "Table"))
+	assert.Equal(t, `"sch""ema"."col"."umn"`, quoteQualifiedIdentifier(`sch"ema`, "col", "umn"))
+}
+
+func TestSplitQualifiedIdentifier(t *testing.T) {
+	parts, err := splitQualifiedIdentifier("public.my_table")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"public", "my_table"}, parts)
+
+	parts, err = splitQualifiedIdentifier(`"My.Schema".Table`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"My.Schema", "Table"}, parts)
+
+	parts, err = splitQualifiedIdentifier(`"sch""ema"."tab.le"`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`sch"ema`, "tab.le"}, parts)
+
+	parts, err = splitQualifiedIdentifier(quoteQualifiedIdentifier("a.b", `c"d`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.b", `c"d`}, parts)
+
+	_, err = splitQualifiedIdentifier(`"public.my_table`)
+	assert.Error(t, err)
+
+	_, err = splitQualifiedIdentifier("public..my_table")
+	assert.Error(t, err)
+}
+
+func TestQuoteIdentifyIdent(t *testing.T) {
+	assert.Equal(t, `"MyFunc"(text, char)`, quoteIdentifyIdent("MyFunc(text, char)"))
+	assert.Equal(t, `"my_func"(numeric(10,2), text)`, quoteIdentifyIdent("my_func(numeric(10,2), text)"))
+	assert.Equal(t, `"my.table"`, quoteIdentifyIdent("my.table"))
+}
+
+func TestSettingIsPositive(t *testing.T) {
+	assert.True(t, settingIsPositive("10"))
+	assert.False(t, settingIsPositive("0"))
+	assert.False(t, settingIsPositive("-1"))
+	assert.False(t, settingIsPositive("logical"))
+}
+
+func TestParseConfig(t *testing.T) {
+	assert.Equal(t,
+		map[string]string{
+			"pgaudit.log":      "write, ddl",
+			"search_path":      `"$user", public`,
+			"application_name": "a=b",
+		},
+		parseConfig(pq.ByteaArray{[]byte("pgaudit.log=write, ddl"), []byte(`search_path="$user", public`), []byte("application_name=a=b")}),
+	)
+}
+
+func TestSettingValueSQL(t *testing.T) {
+	assert.Equal(t, `'write, ddl'`, settingValueSQL("pgaudit.log", "write, ddl"))
+	assert.Equal(t, `'$user', 'public'`, settingValueSQL("search_path", `"$user", public`))
+	assert.Equal(t, `''`, settingValueSQL("search_path", ""))
+
+	assert.True(t, settingValuesEqual("search_path", `"$user", public`, "$user,public"))
+	assert.False(t, settingValuesEqual("pgaudit.log", "write, ddl", "write,ddl"))
+}
+
+func TestConfigQueries(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		"name": "auditor",
+		"config": map[string]interface{}{
+			"pgaudit.log":       "write, ddl",
+			"pgaudit.log_level": "notice",
+		},
+	})
+
+	assert.Equal(t,
+		[]string{
+			`ALTER ROLE "auditor" SET "pgaudit"."log" TO 'write, ddl'`,
+			`ALTER ROLE "auditor" SET "pgaudit"."log_level" TO 'notice'`,
+		},
+		configQueries(d, "config", `ALTER ROLE "auditor"`),
+	)
+}
+
+func TestResetAllConfigQueries(t *testing.T) {
+	assert.Equal(t,
+		[]string{
+			`ALTER DATABASE "app" RESET ALL`,
+			`ALTER DATABASE "app" SET "pgaudit"."log" TO 'write, ddl'`,
+			`ALTER DATABASE "app" SET "work_mem" TO '64MB'`,
+		},
+		resetAllConfigQueries(map[string]interface{}{"work_mem": "64MB", "pgaudit.log": "write, ddl"}, `ALTER DATABASE "app"`),
+	)
+	assert.Equal(t, []string{`ALTER DATABASE "app" RESET ALL`}, resetAllConfigQueries(map[string]interface{}{}, `ALTER DATABASE "app"`))
+}
+
+func TestGrantRoleMembershipQuery(t *testing.T) {
+	assert.Equal(t, `GRANT "owner" TO "conn"`, grantRoleMembershipQuery("owner", "conn", ""))
+	assert.Equal(t,
+		`GRANT "owner" TO "conn" WITH SET TRUE, INHERIT FALSE`,
+		grantRoleMembershipQuery("owner", "conn", dbOwnerMembershipOptions(false)),
+	)
+	assert.Equal(t,
+		`GRANT "owner" TO "conn" WITH INHERIT TRUE, SET FALSE`,
+		grantRoleMembershipQuery("owner", "conn", dbOwnerMembershipOptions(true)),
+	)
+}
+
+// Test that a comment set outside of Terraform doesn't show any diff when the comment attribute is not set.
+func TestCommentNotSetHasNoDiff(t *testing.T) {
+	for name, resource := range map[string]*schema.Resource{
+		"postgresql_database":   resourcePostgreSQLDatabase(),
+		"postgresql_role":       resourcePostgreSQLRole(),
+		"postgresql_schema":     resourcePostgreSQLSchema(),
+		"postgresql_table":      resourcePostgreSQLTable(),
+		"postgresql_tablespace": resourcePostgreSQLTablespace(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			state := &terraform.InstanceState{
+				ID:         "my_object",
+				Attributes: map[string]string{"id": "my_object", "name": "my_object", commentAttr: "set outside of Terraform"},
+			}
+			diff, err := schema.InternalMap(resource.Schema).Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+				"name": "my_object",
+			}), nil, nil, false)
+			if !assert.NoError(t, err) {
+				return
+			}
+			if diff != nil {
+				assert.NotContains(t, diff.Attributes, commentAttr)
+			}
+
+			diff, err = schema.InternalMap(resource.Schema).Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+				"name":      "my_object",
+				commentAttr: "managed",
+			}), nil, nil, false)
+			if assert.NoError(t, err) && assert.Contains(t, diff.Attributes, commentAttr) {
+				assert.Equal(t, "managed", diff.Attributes[commentAttr].New)
+			}
+		})
+	}
+}
@@ -2,10 +2,14 @@ package postgresql
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -36,11 +40,19 @@ func Provider() *schema.Provider {
 					"gcppostgres",
 				}, false),
 			},
+			"connection_string": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				Description: "libpq connection string (`key=value` pairs or `postgres://` URL) to connect with. " +
+					"The connection attributes which are not left to their default value take precedence over its parameters.",
+			},
 			"host": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("PGHOST", nil),
-				Description: "Name of PostgreSQL server address to connect to",
+				Description: "Name of PostgreSQL server address to connect to. " +
+					"With the `postgres` scheme, a comma-separated list of hosts can be specified, see `target_session_attrs`.",
 			},
 			"port": {
 				Type:        schema.TypeInt,
@@ -48,6 +60,21 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("PGPORT", 5432),
 				Description: "The PostgreSQL port number to connect to at the server host, or socket file name extension for Unix-domain connections",
 			},
+			"target_session_attrs": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGTARGETSESSIONATTRS", "any"),
+				Description: "Determines which of the hosts is used if multiple ones are specified. " +
+					"Hosts are tried in order and the first one matching this attribute is used " +
+					"(`read-write` or `primary` to always connect to the primary of a cluster).",
+				ValidateFunc: validation.StringInSlice([]string{
+					"any",
+					"read-write",
+					"read-only",
+					"primary",
+					"standby",
+				}, false),
+			},
 			"database": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -119,12 +146,44 @@ func Provider() *schema.Provider {
 					"If not, some feature might be disabled (e.g.: Refreshing state password from Postgres)",
 			},
 
+			"disabled_features": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(disableableFeatureNames(), false),
+				},
+				Description: "Features the provider must not use even if the server version supports them, " +
+					"e.g. on managed services blocking them (one of: " + strings.Join(disableableFeatureNames(), ", ") + ")",
+			},
+
 			"sslmode": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("PGSSLMODE", nil),
 				Description: "This option determines whether or with what priority a secure SSL TCP/IP connection will be negotiated with the PostgreSQL server",
 			},
+			"sslnegotiation": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGSSLNEGOTIATION", "postgres"),
+				Description: "How SSL encryption is negotiated with the server. Only `postgres` (SSLRequest) is supported by the PostgreSQL driver of the provider, `direct` is rejected",
+				ValidateFunc: validation.StringInSlice([]string{
+					"postgres",
+					"direct",
+				}, false),
+			},
+			"channel_binding": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGCHANNELBINDING", "prefer"),
+				Description: "Controls the use of SCRAM channel binding. The PostgreSQL driver of the provider never uses channel binding: `prefer` and `disable` both authenticate without it and `require` is rejected",
+				ValidateFunc: validation.StringInSlice([]string{
+					"disable",
+					"prefer",
+					"require",
+				}, false),
+			},
 			"ssl_mode": {
 				Type:       schema.TypeString,
 				Optional:   true,
@@ -156,16 +215,44 @@ func Provider() *schema.Provider {
 				MaxItems: 1,
 			},
 			"sslrootcert": {
-				Type:        schema.TypeString,
-				Description: "The SSL server root certificate file path. The file must contain PEM encoded data.",
-				Optional:    true,
+				Type:          schema.TypeString,
+				Description:   "The SSL server root certificate file path. The file must contain PEM encoded data.",
+				Optional:      true,
+				ConflictsWith: []string{"sslrootcert_content"},
+			},
+			"sslrootcert_content": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The PEM encoded SSL server root certificates (e.g. a CA bundle), instead of the file path of `sslrootcert`. It requires `sslmode` verify-ca or verify-full",
+				ConflictsWith: []string{"sslrootcert"},
+			},
+			"ssl_host_override": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name the certificate of the server is verified against (and sent with SNI) instead of `host`, " +
+					"e.g. to use `sslmode` verify-full through a load balancer or a proxy. It requires `sslmode` verify-ca or verify-full and the `postgres` scheme",
+			},
+			"tls_min_version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The minimum TLS version of the connections to the server (`1.2` or `1.3`). It requires `sslmode` require, verify-ca or verify-full and the `postgres` scheme",
+				ValidateFunc: validation.StringInSlice([]string{"1.2", "1.3"}, false),
+			},
+			"tls_cipher_suites": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Description: "The TLS 1.2 cipher suites allowed for the connections to the server, named as in the Go crypto/tls package " +
+					"(e.g. `TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384`). The TLS 1.3 cipher suites are not configurable. " +
+					"It requires `sslmode` require, verify-ca or verify-full and the `postgres` scheme",
 			},
 
 			"connect_timeout": {
-				Type:         schema.TypeInt,
-				Optional:     true,
-				DefaultFunc:  schema.EnvDefaultFunc("PGCONNECT_TIMEOUT", 180),
-				Description:  "Maximum wait for connection, in seconds. Zero or not specified means wait indefinitely.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGCONNECT_TIMEOUT", 180),
+				Description: "Maximum wait for connection, in seconds (libpq connect_timeout, DNS resolution included), so an unreachable host fails fast. " +
+					"It also bounds the first connection with the `awspostgres` and `gcppostgres` schemes. Defaults to `PGCONNECT_TIMEOUT` or 180, zero means wait indefinitely",
 				ValidateFunc: validation.IntAtLeast(-1),
 			},
 			"max_connections": {
@@ -175,6 +262,24 @@ func Provider() *schema.Provider {
 				Description:  "Maximum number of connections to establish to the database. Zero means unlimited.",
 				ValidateFunc: validation.IntAtLeast(-1),
 			},
+			"statement_cache_size": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+				Description: "Maximum number of prepared statements cached per database connection pool, so the queries " +
+					"repeated by each refresh are only planned once per server connection. Zero disables the cache. " +
+					"It keeps idle connections open and should stay disabled behind a connection pooler in transaction pooling mode " +
+					"(e.g. PgBouncer with `pool_mode = transaction`), which doesn't support prepared statements: the cache is " +
+					"ignored with a warning when such a pooler is detected, but a busy pooler may not be.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"citus": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Whether the server is a Citus coordinator: the databases (Citus 12.1 or later) and the roles (Citus 11.0 or later) " +
+					"are then managed on the worker nodes too, the citus extension must be installed in the provider database",
+			},
 			"expected_version": {
 				Type:         schema.TypeString,
 				Optional:     true,
@@ -199,12 +304,31 @@ func Provider() *schema.Provider {
 			"postgresql_function":                  resourcePostgreSQLFunction(),
 			"postgresql_server":                    resourcePostgreSQLServer(),
 			"postgresql_user_mapping":              resourcePostgreSQLUserMapping(),
+			"postgresql_security_label":            resourcePostgreSQLSecurityLabel(),
+			"postgresql_table":                     resourcePostgreSQLTable(),
+			"postgresql_notify":                    resourcePostgreSQLNotify(),
+			"postgresql_sql":                       resourcePostgreSQLSQL(),
+			"postgresql_system_setting":            resourcePostgreSQLSystemSetting(),
+			"postgresql_cron_job":                  resourcePostgreSQLCronJob(),
+			"postgresql_tablespace":                resourcePostgreSQLTablespace(),
+			"postgresql_reassign_owned":            resourcePostgreSQLReassignOwned(),
+			"postgresql_materialized_view_refresh": resourcePostgreSQLMaterializedViewRefresh(),
+			"postgresql_extension_member":          resourcePostgreSQLExtensionMember(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"postgresql_schemas":   dataSourcePostgreSQLDatabaseSchemas(),
-			"postgresql_tables":    dataSourcePostgreSQLDatabaseTables(),
-			"postgresql_sequences": dataSourcePostgreSQLDatabaseSequences(),
+			"postgresql_schema":              dataSourcePostgreSQLSchema(),
+			"postgresql_schemas":             dataSourcePostgreSQLDatabaseSchemas(),
+			"postgresql_tables":              dataSourcePostgreSQLDatabaseTables(),
+			"postgresql_sequences":           dataSourcePostgreSQLDatabaseSequences(),
+			"postgresql_sequence":            dataSourcePostgreSQLSequence(),
+			"postgresql_publication":         dataSourcePostgreSQLPublication(),
+			"postgresql_subscription_status": dataSourcePostgreSQLSubscriptionStatus(),
+			"postgresql_role_memberships":    dataSourcePostgreSQLRoleMemberships(),
+			"postgresql_settings":            dataSourcePostgreSQLSettings(),
+			"postgresql_pgbouncer":           dataSourcePostgreSQLPgBouncer(),
+			"postgresql_locks":               dataSourcePostgreSQLLocks(),
+			"postgresql_citus":               dataSourcePostgreSQLCitus(),
 		},
 
 		ConfigureFunc: providerConfigure,
@@ -266,6 +390,49 @@ func createGoogleCredsFileIfNeeded() error {
 	return os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", tmpFile.Name())
 }
 
+// createSSLRootCertFile writes the content of sslrootcert_content to a file, as lib/pq only
+// inlines the root certificates along with a client certificate (sslinline). The file is named after
+// the hash of the content, so the runs of the provider with the same certificates reuse it instead of
+// leaving a new file behind. It is kept in the cache directory of the user, the temporary directory
+// if there is none, where an existing file is not trusted and always written again.
+func createSSLRootCertFile(content string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err == nil {
+		dir = filepath.Join(dir, "terraform-provider-postgresql")
+		err = os.MkdirAll(dir, 0700)
+	}
+	private := err == nil
+	if !private {
+		dir = os.TempDir()
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("sslrootcert-%x.pem", sha256.Sum256([]byte(content))))
+	if private {
+		if existing, err := os.ReadFile(path); err == nil && string(existing) == content {
+			return path, nil
+		}
+	}
+
+	// The file is renamed once written, so the concurrent runs never read a partial file.
+	tmpFile, err := os.CreateTemp(dir, "sslrootcert")
+	if err != nil {
+		return "", fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		tmpFile.Close()
+		return "", fmt.Errorf("could not write in temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return "", fmt.Errorf("could not write in temporary file: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return "", fmt.Errorf("could not write sslrootcert file %s: %w", path, err)
+	}
+	return path, nil
+}
+
 func acquireAzureOauthToken(tenantId string) (string, error) {
 	credential, err := azidentity.NewDefaultAzureCredential(
 		&azidentity.DefaultAzureCredentialOptions{TenantID: tenantId})
@@ -282,7 +449,50 @@ func acquireAzureOauthToken(tenantId string) (string, error) {
 	return token.Token, nil
 }
 
+// isProviderDefault returns true if the provider attribute is left to its default value.
+func isProviderDefault(d *schema.ResourceData, attr string) bool {
+	defaultValue, err := Provider().Schema[attr].DefaultValue()
+	if err != nil || defaultValue == nil {
+		_, ok := d.GetOk(attr)
+		return !ok
+	}
+	return fmt.Sprint(d.Get(attr)) == fmt.Sprint(defaultValue)
+}
+
+// providerConnectionParam returns the value of a string connection attribute,
+// which is taken from the connection string if the attribute is left to its default value.
+func providerConnectionParam(d *schema.ResourceData, connParams map[string]string, attr, connParam string) string {
+	if value, ok := connParams[connParam]; ok && isProviderDefault(d, attr) {
+		delete(connParams, connParam)
+		return value
+	}
+	delete(connParams, connParam)
+	return d.Get(attr).(string)
+}
+
+// providerConnectionIntParam is the same as providerConnectionParam for integer attributes.
+func providerConnectionIntParam(d *schema.ResourceData, connParams map[string]string, attr, connParam string) (int, error) {
+	if value, ok := connParams[connParam]; ok && isProviderDefault(d, attr) {
+		delete(connParams, connParam)
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("postgresql: invalid %s %q in connection_string: %w", connParam, value, err)
+		}
+		return intValue, nil
+	}
+	delete(connParams, connParam)
+	return d.Get(attr).(int), nil
+}
+
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+	connParams := map[string]string{}
+	if connStr, ok := d.GetOk("connection_string"); ok {
+		var err error
+		if connParams, err = parseConnectionString(connStr.(string)); err != nil {
+			return nil, fmt.Errorf("postgresql: could not parse connection_string: %w", err)
+		}
+	}
+
 	var sslMode string
 	if sslModeRaw, ok := d.GetOk("sslmode"); ok {
 		sslMode = sslModeRaw.(string)
@@ -292,12 +502,25 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 			sslMode = sslModeDeprecated
 		}
 	}
+	if value, ok := connParams["sslmode"]; ok && sslMode == "" {
+		sslMode = value
+	}
+	delete(connParams, "sslmode")
+
 	versionStr := d.Get("expected_version").(string)
 	version, _ := semver.ParseTolerant(versionStr)
 
-	host := d.Get("host").(string)
-	port := d.Get("port").(int)
-	username := d.Get("username").(string)
+	host := providerConnectionParam(d, connParams, "host", "host")
+	port, err := providerConnectionIntParam(d, connParams, "port", "port")
+	if err != nil {
+		return nil, err
+	}
+	username := providerConnectionParam(d, connParams, "username", "user")
+	database := providerConnectionParam(d, connParams, "database", "dbname")
+	connectTimeout, err := providerConnectionIntParam(d, connParams, "connect_timeout", "connect_timeout")
+	if err != nil {
+		return nil, err
+	}
 
 	var password string
 	if d.Get("aws_rds_iam_auth").(bool) {
@@ -319,23 +542,60 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 			return nil, err
 		}
 	} else {
-		password = d.Get("password").(string)
+		password = providerConnectionParam(d, connParams, "password", "password")
 	}
+	delete(connParams, "password")
 
 	config := Config{
-		Scheme:            d.Get("scheme").(string),
-		Host:              host,
-		Port:              port,
-		Username:          username,
-		Password:          password,
-		DatabaseUsername:  d.Get("database_username").(string),
-		Superuser:         d.Get("superuser").(bool),
-		SSLMode:           sslMode,
-		ApplicationName:   "Terraform provider",
-		ConnectTimeoutSec: d.Get("connect_timeout").(int),
-		MaxConns:          d.Get("max_connections").(int),
-		ExpectedVersion:   version,
-		SSLRootCertPath:   d.Get("sslrootcert").(string),
+		Scheme:             d.Get("scheme").(string),
+		Host:               host,
+		Port:               port,
+		TargetSessionAttrs: providerConnectionParam(d, connParams, "target_session_attrs", "target_session_attrs"),
+		Username:           username,
+		Password:           password,
+		DatabaseUsername:   d.Get("database_username").(string),
+		Superuser:          d.Get("superuser").(bool),
+		SSLMode:            sslMode,
+		ApplicationName:    "Terraform provider",
+		ConnectTimeoutSec:  connectTimeout,
+		MaxConns:           d.Get("max_connections").(int),
+		StmtCacheSize:      d.Get("statement_cache_size").(int),
+		ExpectedVersion:    version,
+		SSLRootCertPath:    providerConnectionParam(d, connParams, "sslrootcert", "sslrootcert"),
+		SSLNegotiation:     providerConnectionParam(d, connParams, "sslnegotiation", "sslnegotiation"),
+		ChannelBinding:     providerConnectionParam(d, connParams, "channel_binding", "channel_binding"),
+		SSLHostOverride:    d.Get("ssl_host_override").(string),
+		TLSMinVersion:      d.Get("tls_min_version").(string),
+		Citus:              d.Get("citus").(bool),
+	}
+	for _, suite := range d.Get("tls_cipher_suites").([]interface{}) {
+		config.TLSCipherSuites = append(config.TLSCipherSuites, suite.(string))
+	}
+
+	if err := config.checkSSLHostOverride(); err != nil {
+		return nil, err
+	}
+	if err := config.checkTLSSettings(); err != nil {
+		return nil, err
+	}
+	if content := d.Get("sslrootcert_content").(string); content != "" {
+		if !sslModeVerifiesCA(sslMode) {
+			return nil, fmt.Errorf("postgresql: sslrootcert_content requires sslmode verify-ca or verify-full, got %q", sslMode)
+		}
+		if config.SSLRootCertPath, err = createSSLRootCertFile(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if features := d.Get("disabled_features").(*schema.Set); features.Len() > 0 {
+		config.DisabledFeatures = make(map[featureName]bool, features.Len())
+		for _, feature := range features.List() {
+			config.DisabledFeatures[disableableFeatures[feature.(string)]] = true
+		}
+	}
+
+	if err := config.checkDriverSupport(); err != nil {
+		return nil, err
 	}
 
 	if value, ok := d.GetOk("clientcert"); ok {
@@ -346,6 +606,22 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 				SSLInline:       spec["sslinline"].(bool),
 			}
 		}
+	} else if connParams["sslcert"] != "" && connParams["sslkey"] != "" {
+		config.SSLClientCert = &ClientCertificateConfig{
+			CertificatePath: connParams["sslcert"],
+			KeyPath:         connParams["sslkey"],
+			SSLInline:       connParams["sslinline"] == "true",
+		}
+	}
+	if config.SSLClientCert != nil {
+		delete(connParams, "sslcert")
+		delete(connParams, "sslkey")
+		delete(connParams, "sslinline")
+	}
+	config.ConnectionParams = connParams
+
+	if config.Scheme != "postgres" && (len(config.hosts()) > 1 || config.TargetSessionAttrs != "any") {
+		return nil, fmt.Errorf("postgresql: multiple hosts and target_session_attrs are only supported with the postgres scheme")
 	}
 
 	if config.Scheme == "gcppostgres" {
@@ -354,6 +630,6 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		}
 	}
 
-	client := config.NewClient(d.Get("database").(string))
+	client := config.NewClient(database)
 	return client, nil
 }
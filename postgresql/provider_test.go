@@ -3,6 +3,9 @@ package postgresql
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -29,6 +32,107 @@ func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = Provider()
 }
 
+func TestProviderConfigureConnectionString(t *testing.T) {
+	for _, env := range []string{"PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE", "PGSSLMODE", "PGCONNECT_TIMEOUT"} {
+		t.Setenv(env, "")
+	}
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"connection_string": "host=pg.example.com port=5433 user=dsn_user password=dsn_password dbname=dsn_db sslmode=verify-full application_name=tf",
+		"username":          "explicit_user",
+	})
+
+	meta, err := providerConfigure(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := meta.(*Client)
+
+	if client.config.Host != "pg.example.com" || client.config.Port != 5433 {
+		t.Errorf("host and port should be read from the connection string, got %s:%d", client.config.Host, client.config.Port)
+	}
+	if client.config.Username != "explicit_user" {
+		t.Errorf("username should take precedence over the connection string, got %s", client.config.Username)
+	}
+	if client.config.Password != "dsn_password" || client.databaseName != "dsn_db" || client.config.SSLMode != "verify-full" {
+		t.Errorf("password, dbname and sslmode should be read from the connection string, got %+v", client)
+	}
+	if !reflect.DeepEqual(client.config.ConnectionParams, map[string]string{"application_name": "tf"}) {
+		t.Errorf("unexpected additional connection parameters %#v", client.config.ConnectionParams)
+	}
+}
+
+func TestProviderConfigureSSLRootCertContent(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	for _, env := range []string{"PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE", "PGSSLMODE", "PGCONNECT_TIMEOUT"} {
+		t.Setenv(env, "")
+	}
+
+	const rootCert = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"sslmode":             "verify-full",
+		"sslrootcert_content": rootCert,
+		"ssl_host_override":   "db.internal",
+	})
+
+	meta, err := providerConfigure(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := meta.(*Client)
+	defer os.Remove(client.config.SSLRootCertPath)
+
+	content, err := os.ReadFile(client.config.SSLRootCertPath)
+	if err != nil || string(content) != rootCert {
+		t.Errorf("sslrootcert_content should be written to sslrootcert, got %q (%v)", content, err)
+	}
+
+	// The same content reuses the same file.
+	path, err := createSSLRootCertFile(rootCert)
+	if err != nil || path != client.config.SSLRootCertPath {
+		t.Errorf("sslrootcert_content should be written to %s again, got %s (%v)", client.config.SSLRootCertPath, path, err)
+	}
+	if client.config.SSLHostOverride != "db.internal" {
+		t.Errorf("unexpected ssl_host_override %q", client.config.SSLHostOverride)
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"sslmode":             "require",
+		"sslrootcert_content": rootCert,
+	})
+	if _, err := providerConfigure(d); err == nil || !strings.Contains(err.Error(), "requires sslmode verify-ca or verify-full") {
+		t.Errorf("sslrootcert_content should require sslmode verify-ca or verify-full, got %v", err)
+	}
+}
+
+// Test that without a cache directory, the root certificates written again in the temporary directory
+// don't leave a new file behind on each run.
+func TestCreateSSLRootCertFileTempDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("HOME", "")
+	t.Setenv("TMPDIR", tmpDir)
+
+	const rootCert = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	for i := 0; i < 2; i++ {
+		path, err := createSSLRootCertFile(rootCert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Dir(path) != tmpDir {
+			t.Errorf("expected sslrootcert to be written in %s, got %s", tmpDir, path)
+		}
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected a single sslrootcert file in %s, got %d files", tmpDir, len(entries))
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	var host string
 	if host = os.Getenv("PGHOST"); host == "" {
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/lib/pq"
@@ -30,6 +31,31 @@ func (d proxyDriver) DialTimeout(network, address string, timeout time.Duration)
 	return proxy.Dial(ctx, network, address)
 }
 
+// sslHostOverrideDialer dials address whatever the address requested by lib/pq, which connects
+// to the ssl_host_override to verify the certificate of the server against it.
+type sslHostOverrideDialer struct {
+	proxyDriver
+	address string
+}
+
+func (d sslHostOverrideDialer) Dial(network, _ string) (net.Conn, error) {
+	return d.proxyDriver.Dial(network, d.address)
+}
+
+func (d sslHostOverrideDialer) DialTimeout(network, _ string, timeout time.Duration) (net.Conn, error) {
+	return d.proxyDriver.DialTimeout(network, d.address, timeout)
+}
+
+// openWithSSLHostOverride opens a connection pool to host whose TLS server name is the ssl_host_override of config.
+func openWithSSLHostOverride(config Config, host, database string) (*sql.DB, error) {
+	connector, err := pq.NewConnector(config.connStrForHost(config.SSLHostOverride, database))
+	if err != nil {
+		return nil, err
+	}
+	connector.Dialer(sslHostOverrideDialer{address: net.JoinHostPort(host, strconv.Itoa(config.Port))})
+	return sql.OpenDB(connector), nil
+}
+
 func init() {
 	sql.Register(proxyDriverName, proxyDriver{})
 }
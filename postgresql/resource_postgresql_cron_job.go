@@ -0,0 +1,268 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	cronJobNameAttr         = "name"
+	cronJobScheduleAttr     = "schedule"
+	cronJobCommandAttr      = "command"
+	cronJobDatabaseAttr     = "database"
+	cronJobUsernameAttr     = "username"
+	cronJobActiveAttr       = "active"
+	cronJobCronDatabaseAttr = "cron_database"
+	cronJobJobIDAttr        = "job_id"
+
+	// The job names are only unique per user, the jobs of the other users are hidden (RLS)
+	// unless the connection user is superuser.
+	getCronJobQuery = `
+SELECT jobid, schedule, command, database, username, active
+FROM cron.job
+WHERE jobname = $1
+ORDER BY username = CURRENT_USER DESC, jobid
+LIMIT 1
+`
+)
+
+func resourcePostgreSQLCronJob() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLCronJobCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLCronJobRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLCronJobUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLCronJobDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLCronJobImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			cronJobNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The name of the job",
+			},
+			cronJobScheduleAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The schedule of the job, in cron syntax (e.g. `0 3 * * *`) or as an interval (e.g. `30 seconds`)",
+			},
+			cronJobCommandAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The SQL command run by the job",
+			},
+			cronJobDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database in which the command is run (cron.schedule_in_database). Defaults to `cron_database`",
+			},
+			cronJobUsernameAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The role the command is run as. Defaults to the connection user, only a superuser can set another one",
+			},
+			cronJobActiveAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the job is scheduled",
+			},
+			cronJobCronDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database in which pg_cron is installed (cron.database_name). Defaults to the provider database",
+			},
+			cronJobJobIDAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The ID of the job in cron.job",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLCronJobCreate(db *DBConnection, d *schema.ResourceData) error {
+	cronDatabase := getDatabaseForCronJob(d, db.client.databaseName)
+	name := d.Get(cronJobNameAttr).(string)
+
+	if err := db.client.withTx(cronDatabase, func(txn *sql.Tx) error {
+		if err := checkExtensionInstalled(txn, "pg_cron", cronDatabase); err != nil {
+			return err
+		}
+
+		// cron.schedule replaces the job of the same name instead of failing.
+		var exists bool
+		if err := txn.QueryRow("SELECT EXISTS(SELECT 1 FROM cron.job WHERE jobname = $1)", name).Scan(&exists); err != nil {
+			return fmt.Errorf("could not check if cron job %s exists: %w", name, err)
+		}
+		if exists {
+			return fmt.Errorf("cron job %s already exists in database %s, import it with the ID %s", name, cronDatabase, generateCronJobID(cronDatabase, name))
+		}
+
+		var jobID int64
+		var err error
+		if database, ok := d.GetOk(cronJobDatabaseAttr); ok {
+			err = txn.QueryRow(
+				"SELECT cron.schedule_in_database($1, $2, $3, $4, $5, $6)",
+				name, d.Get(cronJobScheduleAttr), d.Get(cronJobCommandAttr), database,
+				sql.NullString{String: d.Get(cronJobUsernameAttr).(string), Valid: d.Get(cronJobUsernameAttr).(string) != ""},
+				d.Get(cronJobActiveAttr),
+			).Scan(&jobID)
+		} else {
+			err = txn.QueryRow(
+				"SELECT cron.schedule($1, $2, $3)", name, d.Get(cronJobScheduleAttr), d.Get(cronJobCommandAttr),
+			).Scan(&jobID)
+			// cron.schedule has no username nor active parameter
+			if err == nil && (d.Get(cronJobUsernameAttr).(string) != "" || !d.Get(cronJobActiveAttr).(bool)) {
+				err = alterCronJob(txn, jobID, d)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("could not schedule cron job %s: %w", name, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.Set(cronJobCronDatabaseAttr, cronDatabase)
+	d.SetId(generateCronJobID(cronDatabase, name))
+
+	return resourcePostgreSQLCronJobReadImpl(db, d)
+}
+
+func resourcePostgreSQLCronJobRead(db *DBConnection, d *schema.ResourceData) error {
+	return resourcePostgreSQLCronJobReadImpl(db, d)
+}
+
+func resourcePostgreSQLCronJobReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	cronDatabase := getDatabaseForCronJob(d, db.client.databaseName)
+	name := d.Get(cronJobNameAttr).(string)
+
+	txn, err := startTransaction(db.client, cronDatabase)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	if err := checkExtensionInstalled(txn, "pg_cron", cronDatabase); err != nil {
+		return err
+	}
+
+	var jobID int64
+	var schedule, command, database, username string
+	var active bool
+	err = txn.QueryRow(getCronJobQuery, name).Scan(&jobID, &schedule, &command, &database, &username, &active)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL cron job (%s) not found in database %s", name, cronDatabase)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not read cron job %s: %w", name, err)
+	}
+
+	d.Set(cronJobJobIDAttr, jobID)
+	d.Set(cronJobScheduleAttr, schedule)
+	d.Set(cronJobCommandAttr, command)
+	d.Set(cronJobDatabaseAttr, database)
+	d.Set(cronJobUsernameAttr, username)
+	d.Set(cronJobActiveAttr, active)
+	d.Set(cronJobCronDatabaseAttr, cronDatabase)
+
+	return nil
+}
+
+func resourcePostgreSQLCronJobUpdate(db *DBConnection, d *schema.ResourceData) error {
+	cronDatabase := getDatabaseForCronJob(d, db.client.databaseName)
+
+	if err := db.client.withTx(cronDatabase, func(txn *sql.Tx) error {
+		if err := alterCronJob(txn, int64(d.Get(cronJobJobIDAttr).(int)), d); err != nil {
+			return fmt.Errorf("could not alter cron job %s: %w", d.Get(cronJobNameAttr).(string), err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return resourcePostgreSQLCronJobReadImpl(db, d)
+}
+
+func resourcePostgreSQLCronJobDelete(db *DBConnection, d *schema.ResourceData) error {
+	cronDatabase := getDatabaseForCronJob(d, db.client.databaseName)
+
+	if err := db.client.withTx(cronDatabase, func(txn *sql.Tx) error {
+		if _, err := txn.Exec("SELECT cron.unschedule($1::bigint)", d.Get(cronJobJobIDAttr).(int)); err != nil {
+			return fmt.Errorf("could not unschedule cron job %s: %w", d.Get(cronJobNameAttr).(string), err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// alterCronJob sets all the attributes of the job, the empty optional ones are left unchanged.
+func alterCronJob(txn *sql.Tx, jobID int64, d *schema.ResourceData) error {
+	nullIfEmpty := func(attr string) sql.NullString {
+		value := d.Get(attr).(string)
+		return sql.NullString{String: value, Valid: value != ""}
+	}
+
+	_, err := txn.Exec(
+		"SELECT cron.alter_job($1, $2, $3, $4, $5, $6)",
+		jobID,
+		d.Get(cronJobScheduleAttr),
+		d.Get(cronJobCommandAttr),
+		nullIfEmpty(cronJobDatabaseAttr),
+		nullIfEmpty(cronJobUsernameAttr),
+		d.Get(cronJobActiveAttr),
+	)
+	return err
+}
+
+// resourcePostgreSQLCronJobImport parses the import ID cron_database.name
+// (double quoted if they contain dots).
+func resourcePostgreSQLCronJobImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := splitQualifiedIdentifier(d.Id())
+	if err != nil || len(parts) != 2 {
+		return nil, fmt.Errorf("cron job ID %s has not the expected format 'cron_database.name'", d.Id())
+	}
+
+	d.Set(cronJobCronDatabaseAttr, parts[0])
+	d.Set(cronJobNameAttr, parts[1])
+	d.Set(cronJobActiveAttr, true)
+	d.SetId(generateCronJobID(parts[0], parts[1]))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func getDatabaseForCronJob(d *schema.ResourceData, databaseName string) string {
+	if v, ok := d.GetOk(cronJobCronDatabaseAttr); ok {
+		databaseName = v.(string)
+	}
+
+	return databaseName
+}
+
+func generateCronJobID(cronDatabase, name string) string {
+	return strings.Join([]string{quoteIdentifierIfNeeded(cronDatabase), quoteIdentifierIfNeeded(name)}, ".")
+}
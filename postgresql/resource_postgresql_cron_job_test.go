@@ -0,0 +1,178 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCheckExtensionAvailable skips the test if the extension can't be installed in the test server.
+func testCheckExtensionAvailable(t *testing.T, extension string) {
+	client := testAccProvider.Meta().(*Client)
+	db, err := client.Connect()
+	if err != nil {
+		t.Fatalf("could not connect to database: %v", err)
+	}
+
+	var installed bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_extension WHERE extname = $1)", extension).Scan(&installed); err != nil {
+		t.Fatalf("could not check if extension %s is installed: %v", extension, err)
+	}
+	if !installed {
+		t.Skipf("Skip test: extension %s is not installed in the test database", extension)
+	}
+}
+
+func TestCronJobIDRoundTrip(t *testing.T) {
+	id := generateCronJobID("my.db", "vacuum.events")
+	assert.Equal(t, `"my.db"."vacuum.events"`, id)
+
+	d := resourcePostgreSQLCronJob().Data(nil)
+	d.SetId(id)
+	imported, err := resourcePostgreSQLCronJobImport(context.Background(), d, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "my.db", imported[0].Get(cronJobCronDatabaseAttr))
+	assert.Equal(t, "vacuum.events", imported[0].Get(cronJobNameAttr))
+	assert.Equal(t, id, imported[0].Id())
+}
+
+// Test that an existing job of the same name is not replaced by cron.schedule.
+func TestCreateCronJobFailsIfExists(t *testing.T) {
+	fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+		if strings.HasPrefix(query, "SELECT EXISTS(") {
+			return &fakeRows{values: [][]driver.Value{{true}}}, nil
+		}
+		return nil, nil
+	}}
+	client := newFakeClient(t, fake, "16.0.0")
+	db, err := client.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLCronJob().Schema, map[string]interface{}{
+		cronJobNameAttr:     "vacuum-events",
+		cronJobScheduleAttr: "0 3 * * *",
+		cronJobCommandAttr:  "VACUUM",
+	})
+	err = resourcePostgreSQLCronJobCreate(db, d)
+	assert.ErrorContains(t, err, "cron job vacuum-events already exists in database postgres")
+	for _, statement := range fake.Statements() {
+		if strings.Contains(statement, "cron.schedule") {
+			t.Errorf("expected the job not to be scheduled, got %q", statement)
+		}
+	}
+}
+
+func TestAccPostgresqlCronJob_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	tfConfig := `
+resource "postgresql_cron_job" "test" {
+	name     = "tf_test_vacuum"
+	schedule = "%s"
+	command  = "VACUUM"
+	active   = %t
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckExtensionAvailable(t, "pg_cron")
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlCronJobDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, "0 3 * * *", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_cron_job.test", "schedule", "0 3 * * *"),
+					resource.TestCheckResourceAttr("postgresql_cron_job.test", "command", "VACUUM"),
+					resource.TestCheckResourceAttr("postgresql_cron_job.test", "active", "true"),
+					resource.TestCheckResourceAttrSet("postgresql_cron_job.test", "job_id"),
+					resource.TestCheckResourceAttrSet("postgresql_cron_job.test", "database"),
+					resource.TestCheckResourceAttrSet("postgresql_cron_job.test", "username"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(tfConfig, "30 seconds", false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_cron_job.test", "schedule", "30 seconds"),
+					resource.TestCheckResourceAttr("postgresql_cron_job.test", "active", "false"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_cron_job.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlCronJob_NotInstalled(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_cron_job" "test" {
+	cron_database = "%s"
+	name          = "tf_test_vacuum"
+	schedule      = "0 3 * * *"
+	command       = "VACUUM"
+}
+`, dbName),
+				ExpectError: regexp.MustCompile(fmt.Sprintf("extension pg_cron is not installed in database %s", dbName)),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlCronJobDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_cron_job" {
+			continue
+		}
+
+		txn, err := startTransaction(client, rs.Primary.Attributes[cronJobCronDatabaseAttr])
+		if err != nil {
+			return err
+		}
+		defer deferredRollback(txn)
+
+		var jobID int64
+		err = txn.QueryRow("SELECT jobid FROM cron.job WHERE jobname = $1", rs.Primary.Attributes[cronJobNameAttr]).Scan(&jobID)
+		switch {
+		case err == sql.ErrNoRows:
+			continue
+		case err != nil:
+			return err
+		}
+		return fmt.Errorf("cron job %s still exists after destroy", rs.Primary.ID)
+	}
+
+	return nil
+}
@@ -2,11 +2,15 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -24,17 +28,71 @@ const (
 	dbOwnerAttr      = "owner"
 	dbTablespaceAttr = "tablespace_name"
 	dbTemplateAttr   = "template"
+
+	dbActiveConnectionsAttr  = "active_connections"
+	dbOwnerGrantorRoleAttr   = "owner_grantor_role"
+	dbDeletionProtectionAttr = "deletion_protection"
+
+	dbTerminateConnectionsOnTablespaceChangeAttr = "terminate_connections_on_tablespace_change"
+
+	dbCollationVersionAttr         = "collation_version"
+	dbCollationVersionMismatchAttr = "collation_version_mismatch"
+
+	dbLocaleAttr         = "locale"
+	dbLocaleProviderAttr = "locale_provider"
+	dbICULocaleAttr      = "icu_locale"
+	dbBuiltinLocaleAttr  = "builtin_locale"
+
+	dbConfigAttr         = "config"
+	dbResetAllConfigAttr = "reset_all_config"
+
+	dbGrantAttr           = "grant"
+	dbGrantRoleAttr       = "role"
+	dbGrantPrivilegesAttr = "privileges"
 )
 
+// dbLocaleProviders are the locale providers which can be used by a database.
+var dbLocaleProviders = []string{"libc", "icu", "builtin"}
+
+// dbLocaleProviderCodes maps the codes of pg_database.datlocprovider to the locale providers.
+var dbLocaleProviderCodes = map[string]string{"c": "libc", "i": "icu", "b": "builtin"}
+
+// dbLocaleCodesetEncodings maps the codesets of the libc locales (normalized, see dbLocaleEncoding)
+// to the encoding they require.
+var dbLocaleCodesetEncodings = map[string]string{
+	"utf8":      "UTF8",
+	"iso88591":  "LATIN1",
+	"iso88592":  "LATIN2",
+	"iso88595":  "ISO_8859_5",
+	"iso88597":  "ISO_8859_7",
+	"iso885915": "LATIN9",
+	"eucjp":     "EUC_JP",
+	"euckr":     "EUC_KR",
+	"gb18030":   "GB18030",
+	"koi8r":     "KOI8R",
+}
+
+// dbGrantPrivileges are the privileges which can be granted on a database.
+var dbGrantPrivileges = []string{"CONNECT", "CREATE", "TEMPORARY"}
+
 func resourcePostgreSQLDatabase() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLDatabaseCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLDatabaseRead),
-		Update: PGResourceFunc(resourcePostgreSQLDatabaseUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLDatabaseDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLDatabaseExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLDatabaseCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLDatabaseRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLDatabaseUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLDatabaseDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLDatabaseExists),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourcePostgreSQLDatabaseImport,
+		},
+		CustomizeDiff: resourcePostgreSQLDatabaseCustomizeDiff,
+
+		// Creating a database from a big template or moving it to another
+		// tablespace copies all of its files and can take a long time.
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -44,24 +102,32 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Description: "The PostgreSQL database name to connect to",
 			},
 			dbOwnerAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The ROLE which owns the database, either its name or its OID as `oid:NNN` to be unaffected by renames",
+				ValidateFunc: validateOwner,
+			},
+			dbOwnerGrantorRoleAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Computed:    true,
-				Description: "The ROLE which owns the database",
+				Description: "A role, which the connection user is a member of, having ADMIN OPTION on `owner`. The provider switches to it (SET ROLE) to temporarily grant `owner` to the connection user when it cannot do it itself (PostgreSQL 16+). This membership only has the SET option to create the database, and the INHERIT one to drop it. On AWS RDS, `rds_superuser` is used by default if it has ADMIN OPTION on `owner`",
 			},
 			dbTemplateAttr: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    true,
-				Computed:    true,
-				Description: "The name of the template from which to create the new database",
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				Description: "The name of the template from which to create the new database. " +
+					"It is only used at creation, as PostgreSQL doesn't keep track of it (it is unknown for imported databases). " +
+					"Changing it on an existing database is an error instead of replacing the database",
+				DiffSuppressFunc: suppressDBTemplateDiff,
 			},
 			dbEncodingAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
 				ForceNew:    true,
-				Description: "Character set encoding to use in the new database",
+				Description: "Character set encoding to use in the new database, either its name (e.g. `UTF8`) or its numeric id. Defaults to `UTF8`, or to the encoding of the template if it is not `template0`",
 			},
 			dbCollationAttr: {
 				Type:        schema.TypeString,
@@ -77,6 +143,35 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				ForceNew:    true,
 				Description: "Character classification (LC_CTYPE) to use in the new database",
 			},
+			dbLocaleAttr: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Shorthand for `lc_collate` and `lc_ctype` (like `initdb --locale`), e.g. `en_US.UTF-8`. " +
+					"The encoding is derived from its codeset if `encoding` is not set. Setting `lc_collate`, `lc_ctype` or `encoding` to another value is an error",
+			},
+			dbLocaleProviderAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(dbLocaleProviders, false),
+				Description: "The locale provider of the new database: `libc`, `icu` or `builtin` (PostgreSQL 15+, 17+ for `builtin`). " +
+					"Defaults to the one of the template",
+			},
+			dbICULocaleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The ICU locale of the new database (e.g. `en-US`) if `locale_provider` is `icu` (PostgreSQL 15+)",
+			},
+			dbBuiltinLocaleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The locale of the new database (`C` or `C.UTF-8`) if `locale_provider` is `builtin` (PostgreSQL 17+)",
+			},
 			dbTablespaceAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -96,37 +191,303 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Default:     true,
 				Description: "If false then no one can connect to this database",
 			},
+			commentAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The comment of the database. It is set right after the creation of the database (CREATE DATABASE cannot run in a transaction). If not set, the comment is left as is, so a comment set outside of Terraform is kept",
+			},
 			dbIsTemplateAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Computed:    true,
 				Description: "If true, then this database can be cloned by any user with CREATEDB privileges",
 			},
+			dbActiveConnectionsAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of connections to this database at refresh time (from pg_stat_activity), useful to check the headroom before lowering `connection_limit`",
+			},
+			dbCollationVersionAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the collation recorded when the database was created, or refreshed outside of Terraform (PostgreSQL 15+)",
+			},
+			dbCollationVersionMismatchAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+				Description: "Whether `collation_version` differs from the version provided by the collation library of the server (PostgreSQL 15+). " +
+					"It happens after an upgrade of the operating system or of ICU, the indexes on text columns must then be rebuilt to not be corrupted",
+			},
+			dbDeletionProtectionAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Prevent the database from being dropped: destroying or replacing it fails until this is set back to false " +
+					"(and applied)",
+			},
+			dbTerminateConnectionsOnTablespaceChangeAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Terminate the other connections to the database before moving it to another tablespace (`tablespace_name`), " +
+					"which cannot be done while it is used. If false, the move fails with the PIDs of the active connections",
+			},
+			dbConfigAttr: {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				DiffSuppressFunc: suppressConfigDiff,
+				Description: "Configuration parameters of the database (ALTER DATABASE ... SET), e.g. `{ \"pgaudit.log\" = \"write, ddl\" }`. " +
+					"Only the parameters listed are managed",
+			},
+			dbResetAllConfigAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Manage all the configuration parameters of the database: the ones not listed in `config` are reset " +
+					"(ALTER DATABASE ... RESET ALL, then SET of each parameter of `config`)",
+			},
+			dbGrantAttr: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Description: "Privileges granted on the database, applied right after its creation. " +
+					"Only the roles listed are managed, use `postgresql_grant` for the other cases",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dbGrantRoleAttr: {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The role to grant the privileges to, `public` for all the roles. It must not be the owner of the database",
+						},
+						dbGrantPrivilegesAttr: {
+							Type:     schema.TypeSet,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringInSlice(dbGrantPrivileges, false),
+							},
+							Set:         schema.HashString,
+							Description: "The privileges to grant (CONNECT, CREATE or TEMPORARY)",
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
+// resourcePostgreSQLDatabaseCustomizeDiff rejects the changes of the template of an
+// existing database.
+func resourcePostgreSQLDatabaseCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if err := customizeDBLocaleDiff(diff); err != nil {
+		return err
+	}
+
+	if diff.Id() == "" {
+		return nil
+	}
+
+	// Replacing the database to apply the new template would silently drop its data.
+	if diff.HasChange(dbTemplateAttr) {
+		old, new := diff.GetChange(dbTemplateAttr)
+		return fmt.Errorf(
+			"cannot change %s of database %s from %q to %q: the template is only used when the database is created. "+
+				"Revert the change, or destroy the database explicitly to create it again from the new template",
+			dbTemplateAttr, diff.Id(), old, new,
+		)
+	}
+
+	return nil
+}
+
+// customizeDBLocaleDiff plans lc_collate, lc_ctype and encoding from locale:
+// if they differ from the ones of an existing database, it is replaced.
+func customizeDBLocaleDiff(diff *schema.ResourceDiff) error {
+	locale := diff.Get(dbLocaleAttr).(string)
+	if locale == "" {
+		return nil
+	}
+
+	collate, ctype, encoding, err := dbLocaleSettings(
+		locale,
+		dbConfigString(diff, dbCollationAttr),
+		dbConfigString(diff, dbCTypeAttr),
+		dbConfigString(diff, dbEncodingAttr),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, setting := range []struct{ attr, value string }{
+		{dbCollationAttr, collate},
+		{dbCTypeAttr, ctype},
+		{dbEncodingAttr, encoding},
+	} {
+		current := diff.Get(setting.attr).(string)
+		if setting.value == "" || current == setting.value {
+			continue
+		}
+		// The encoding may be stored in the state as its numeric id or with another spelling.
+		if setting.attr == dbEncodingAttr && (isDBEncodingID(current) || normalizeDBEncoding(current) == normalizeDBEncoding(setting.value)) {
+			continue
+		}
+		if err := diff.SetNew(setting.attr, setting.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dbConfigString returns the value of attr in the configuration, or "" if it is not set (or not known yet),
+// as the planned value of a computed attribute is the one of the state.
+func dbConfigString(diff *schema.ResourceDiff, attr string) string {
+	config := diff.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		// Without the raw configuration (e.g. in unit tests), only a new resource has no state.
+		if diff.Id() == "" {
+			return diff.Get(attr).(string)
+		}
+		return ""
+	}
+	value := config.GetAttr(attr)
+	if value.IsNull() || !value.IsKnown() {
+		return ""
+	}
+	return value.AsString()
+}
+
+// dbLocaleSettings returns LC_COLLATE, LC_CTYPE and the encoding of a database created with locale,
+// given the ones set in the configuration. The encoding is empty if it is not set and cannot be derived
+// from the codeset of locale.
+func dbLocaleSettings(locale, collate, ctype, encoding string) (string, string, string, error) {
+	for _, setting := range []struct{ attr, value string }{
+		{dbCollationAttr, collate},
+		{dbCTypeAttr, ctype},
+	} {
+		if setting.value != "" && setting.value != locale {
+			return "", "", "", fmt.Errorf("%s %q conflicts with %s %q: set only one of them", dbLocaleAttr, locale, setting.attr, setting.value)
+		}
+	}
+
+	localeEncoding := dbLocaleEncoding(locale)
+	switch {
+	case encoding == "":
+		encoding = localeEncoding
+	case localeEncoding != "" && !isDBEncodingID(encoding) && strings.ToUpper(encoding) != "DEFAULT" &&
+		normalizeDBEncoding(encoding) != normalizeDBEncoding(localeEncoding):
+		return "", "", "", fmt.Errorf("%s %q conflicts with %s %q, which requires %s: set only one of them", dbEncodingAttr, encoding, dbLocaleAttr, locale, localeEncoding)
+	}
+
+	return locale, locale, encoding, nil
+}
+
+// dbLocaleEncoding returns the encoding required by the codeset of a libc locale (e.g. `UTF8` for `en_US.UTF-8`),
+// or "" if it has none (e.g. `C`) or if it is unknown.
+func dbLocaleEncoding(locale string) string {
+	_, codeset, ok := strings.Cut(locale, ".")
+	if !ok {
+		return ""
+	}
+	codeset, _, _ = strings.Cut(codeset, "@")
+	return dbLocaleCodesetEncodings[strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(codeset))]
+}
+
+// normalizeDBEncoding normalizes the spelling of an encoding name (e.g. `utf-8` and `UTF8`).
+func normalizeDBEncoding(encoding string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToUpper(encoding))
+}
+
+// resourcePostgreSQLDatabaseImport imports a database by name or by OID (`oid:NNN`),
+// the ID is always normalized to the name of the database.
+func resourcePostgreSQLDatabaseImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	oid, isOID, err := parseOwnerOID(d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("invalid database import ID %q, expected a name or oid:NNN: %w", d.Id(), err)
+	}
+	if !isOID {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	db, err := meta.(*Client).withContext(ctx).Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	var dbName string
+	err = db.QueryRow("SELECT datname FROM pg_catalog.pg_database WHERE oid = $1", oid).Scan(&dbName)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, fmt.Errorf("could not find database with OID %d", oid)
+	case err != nil:
+		return nil, fmt.Errorf("could not resolve database with OID %d: %w", oid, err)
+	}
+
+	d.SetId(dbName)
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourcePostgreSQLDatabaseCreate(db *DBConnection, d *schema.ResourceData) error {
 	if err := createDatabase(db, d); err != nil {
 		return err
 	}
 
 	d.SetId(d.Get(dbNameAttr).(string))
+	if d.Get(dbTemplateAttr).(string) == "" {
+		d.Set(dbTemplateAttr, "template0")
+	}
+
+	// The ID is already set: if the grants fail, the new database is tainted
+	// and replaced on the next apply, as CREATE DATABASE cannot run in a transaction.
+	if err := db.client.withTx("", func(txn *sql.Tx) error {
+		return setDBGrants(db, txn, d)
+	}); err != nil {
+		return err
+	}
 
 	return resourcePostgreSQLDatabaseReadImpl(db, d)
 }
 
-func createDatabase(db *DBConnection, d *schema.ResourceData) error {
+func createDatabase(db *DBConnection, d *schema.ResourceData) (err error) {
 	currentUser := db.client.config.getDatabaseUsername()
-	owner := d.Get(dbOwnerAttr).(string)
+	owner, err := resolveOwner(db, d.Get(dbOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
+	dbName := d.Get(dbNameAttr).(string)
+
+	capabilities, err := db.roleCapabilities()
+	if err != nil {
+		return err
+	}
+	if !capabilities.superuser && !capabilities.createDB {
+		return fmt.Errorf("could not create database %s: %s has neither SUPERUSER nor CREATEDB", dbName, currentUser)
+	}
+
+	if owner != "" {
+		if err := checkRoleExistence(db, owner); err != nil {
+			return fmt.Errorf("invalid owner of database %s: %w", dbName, err)
+		}
+	}
+	if v, ok := d.GetOk(dbTablespaceAttr); ok && strings.ToUpper(v.(string)) != "DEFAULT" {
+		if err := checkTablespaceExistence(db, v.(string)); err != nil {
+			return fmt.Errorf("invalid tablespace of database %s: %w", dbName, err)
+		}
+	}
+
+	// Checked before granting the owner to the connection user, so nothing is left to revert if it fails.
+	if err := checkDBTemplateCompatibility(db, d); err != nil {
+		return err
+	}
 
-	var err error
 	if owner != "" {
 		// Take a lock on db currentUser to avoid multiple database creation at the same time
 		// It can fail if they grant the same owner to current at the same time as it's not done in transaction.
-		lockTxn, err := startTransaction(db.client, "")
-		if err != nil {
-			return err
+		lockTxn, txnErr := startTransaction(db.client, "")
+		if txnErr != nil {
+			return txnErr
 		}
 		if err := pgLockRole(lockTxn, currentUser); err != nil {
 			return err
@@ -135,26 +496,28 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 
 		// Needed in order to set the owner of the db if the connection user is not a
 		// superuser
-		ownerGranted, err := grantRoleMembership(db, owner, currentUser)
-		if err != nil {
-			return err
+		ownerGranted, grantor, grantErr := grantDBOwnerMembership(db, d, owner, currentUser, false)
+		if grantErr != nil {
+			return grantErr
 		}
 		if ownerGranted {
 			defer func() {
-				_, err = revokeRoleMembership(db, owner, currentUser)
+				// Don't leave the connection user member of the owner behind.
+				if revokeErr := revokeDBOwnerMembership(db, grantor, owner, currentUser); revokeErr != nil && err == nil {
+					err = revokeErr
+				}
 			}()
 		}
 	}
 
-	dbName := d.Get(dbNameAttr).(string)
 	b := bytes.NewBufferString("CREATE DATABASE ")
 	fmt.Fprint(b, pq.QuoteIdentifier(dbName))
 
 	// Handle each option individually and stream results into the query
 	// buffer.
-	switch v, ok := d.GetOk(dbOwnerAttr); {
-	case ok:
-		fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(v.(string)))
+	switch {
+	case owner != "":
+		fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(owner))
 	default:
 		// No owner specified in the config, default to using
 		// the connecting username.
@@ -173,6 +536,8 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 	switch v, ok := d.GetOk(dbEncodingAttr); {
 	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
 		fmt.Fprintf(b, " ENCODING DEFAULT")
+	case ok && isDBEncodingID(v.(string)):
+		fmt.Fprintf(b, " ENCODING %s", v)
 	case ok:
 		fmt.Fprintf(b, " ENCODING '%s' ", pqQuoteLiteral(v.(string)))
 	case v.(string) == "":
@@ -197,6 +562,10 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 		fmt.Fprintf(b, " LC_CTYPE '%s' ", pqQuoteLiteral(v.(string)))
 	}
 
+	if err := writeDBLocaleProviderClauses(db, d, b); err != nil {
+		return err
+	}
+
 	switch v, ok := d.GetOk(dbTablespaceAttr); {
 	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
 		fmt.Fprint(b, " TABLESPACE DEFAULT")
@@ -207,6 +576,8 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 	if db.featureSupported(featureDBAllowConnections) {
 		val := d.Get(dbAllowConnsAttr).(bool)
 		fmt.Fprint(b, " ALLOW_CONNECTIONS ", val)
+	} else if !d.Get(dbAllowConnsAttr).(bool) {
+		return db.unsupportedFeatureError(featureDBAllowConnections, "database ALLOW_CONNECTIONS")
 	}
 
 	{
@@ -217,26 +588,321 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 	if db.featureSupported(featureDBIsTemplate) {
 		val := d.Get(dbIsTemplateAttr).(bool)
 		fmt.Fprint(b, " IS_TEMPLATE ", val)
+	} else if d.Get(dbIsTemplateAttr).(bool) {
+		return db.unsupportedFeatureError(featureDBIsTemplate, "database IS_TEMPLATE")
 	}
 
-	sql := b.String()
-	if _, err := db.Exec(sql); err != nil {
+	if err := execDatabaseDDL(db, b.String()); err != nil {
 		return fmt.Errorf("Error creating database %q: %w", dbName, err)
 	}
 
-	// Set err outside of the return so that the deferred revoke can override err
-	// if necessary.
-	return err
+	comment := d.Get(commentAttr).(string)
+	if comment == "" && len(d.Get(dbConfigAttr).(map[string]interface{})) == 0 {
+		return nil
+	}
+
+	// The comment and the configuration need the privileges of the owner, so they are set before
+	// its membership is revoked from the connection user, which may only be able to SET ROLE to it.
+	return db.client.withTx("", func(txn *sql.Tx) error {
+		if owner != "" && !capabilities.superuser {
+			if _, err := txn.Exec(fmt.Sprintf("SET LOCAL ROLE %s", pq.QuoteIdentifier(owner))); err != nil {
+				return fmt.Errorf("could not set role %s to configure database %s: %w", owner, dbName, err)
+			}
+		}
+		if comment != "" {
+			if err := setObjectComment(txn, "DATABASE", pq.QuoteIdentifier(dbName), comment); err != nil {
+				return err
+			}
+		}
+		return setDBConfig(txn, d)
+	})
+}
+
+// writeDBLocaleProviderClauses adds the LOCALE_PROVIDER, ICU_LOCALE and BUILTIN_LOCALE
+// clauses of CREATE DATABASE to b, if they are set.
+func writeDBLocaleProviderClauses(db *DBConnection, d *schema.ResourceData, b *bytes.Buffer) error {
+	provider := d.Get(dbLocaleProviderAttr).(string)
+	icuLocale := d.Get(dbICULocaleAttr).(string)
+	builtinLocale := d.Get(dbBuiltinLocaleAttr).(string)
+	if provider == "" && icuLocale == "" && builtinLocale == "" {
+		return nil
+	}
+
+	if !db.featureSupported(featureDatabaseLocaleProvider) {
+		return db.unsupportedFeatureError(featureDatabaseLocaleProvider, "database LOCALE_PROVIDER")
+	}
+	if (provider == "builtin" || builtinLocale != "") && !db.featureSupported(featureDatabaseBuiltinLocale) {
+		return db.unsupportedFeatureError(featureDatabaseBuiltinLocale, "the builtin locale provider")
+	}
+
+	if provider != "" {
+		fmt.Fprint(b, " LOCALE_PROVIDER ", provider)
+	}
+	if icuLocale != "" {
+		fmt.Fprintf(b, " ICU_LOCALE '%s'", pqQuoteLiteral(icuLocale))
+	}
+	if builtinLocale != "" {
+		fmt.Fprintf(b, " BUILTIN_LOCALE '%s'", pqQuoteLiteral(builtinLocale))
+	}
+	return nil
+}
+
+// checkDBTemplateCompatibility returns an actionable error if the encoding or the locale
+// of the new database differ from the ones of its template, which PostgreSQL only allows with template0.
+// If the encoding is not specified, the one of a custom template is used instead of UTF8.
+func checkDBTemplateCompatibility(db *DBConnection, d *schema.ResourceData) error {
+	template := normalizeDBTemplate(d.Get(dbTemplateAttr).(string))
+	if template == "template0" {
+		return nil
+	}
+
+	encoding := d.Get(dbEncodingAttr).(string)
+
+	var templateEncoding, templateCollation, templateCType string
+	var sameEncoding bool
+	err := db.QueryRow(
+		"SELECT pg_catalog.pg_encoding_to_char(encoding), datcollate, datctype, "+
+			"upper($2) = 'DEFAULT' OR pg_catalog.pg_char_to_encoding($2) = encoding OR $2 = encoding::text "+
+			"FROM pg_catalog.pg_database WHERE datname = $1",
+		template, encoding,
+	).Scan(&templateEncoding, &templateCollation, &templateCType, &sameEncoding)
+	switch {
+	case err == sql.ErrNoRows:
+		// Let CREATE DATABASE report the missing template
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not read template database %s: %w", template, err)
+	}
+
+	if encoding == "" {
+		// Read back as the encoding of the template instead of defaulting to UTF8 (see createDatabase)
+		d.Set(dbEncodingAttr, templateEncoding)
+		sameEncoding = true
+	}
+
+	var mismatches []string
+	if !sameEncoding {
+		mismatches = append(mismatches, fmt.Sprintf("encoding %s (template: %s)", encoding, templateEncoding))
+	}
+	if v, ok := d.GetOk(dbCollationAttr); ok && strings.ToUpper(v.(string)) != "DEFAULT" && v.(string) != templateCollation {
+		mismatches = append(mismatches, fmt.Sprintf("lc_collate %s (template: %s)", v, templateCollation))
+	}
+	if v, ok := d.GetOk(dbCTypeAttr); ok && strings.ToUpper(v.(string)) != "DEFAULT" && v.(string) != templateCType {
+		mismatches = append(mismatches, fmt.Sprintf("lc_ctype %s (template: %s)", v, templateCType))
+	}
+
+	return dbTemplateMismatchError(d.Get(dbNameAttr).(string), template, mismatches)
+}
+
+func dbTemplateMismatchError(dbName, template string, mismatches []string) error {
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"cannot create database %s from template %s with a different %s: "+
+			"only template0 can be copied with another encoding or locale, set `template = \"template0\"` "+
+			"or remove these settings to use the ones of the template",
+		dbName, template, strings.Join(mismatches, ", "),
+	)
+}
+
+// grantDBOwnerMembership makes currentUser a member of owner, so it is allowed
+// to create or drop a database owned by it.
+// On PostgreSQL 16+ this is skipped if currentUser can already SET ROLE to
+// owner (or has its privileges, to drop the database), and the grant is done as the role configured in owner_grantor_role
+// if any, since granting a membership requires ADMIN OPTION on the role.
+// On AWS RDS, rds_superuser is used as grantor if it has ADMIN OPTION on owner
+// while the connection user (not a real superuser) doesn't.
+// On PostgreSQL 16+ the membership is also limited to what is needed: the SET
+// option to create the database (CREATE DATABASE ... OWNER), or the INHERIT one
+// to drop it (the owner privileges are checked).
+// It returns false if no grant was needed, and the role the grant was done as.
+func grantDBOwnerMembership(db *DBConnection, d *schema.ResourceData, owner, currentUser string, drop bool) (bool, string, error) {
+	capabilities, err := db.roleCapabilities()
+	if err != nil {
+		return false, "", err
+	}
+	if capabilities.superuser {
+		log.Printf("[DEBUG] %s is superuser, no need to grant it %s", currentUser, owner)
+		return false, "", nil
+	}
+
+	if !db.featureSupported(featureMembershipSetOption) {
+		granted, err := grantRoleMembership(db, owner, currentUser)
+		if err != nil {
+			return false, "", withRDSOwnerHint(db, err, owner, currentUser)
+		}
+		return granted, "", nil
+	}
+
+	if drop {
+		hasPrivileges, err := hasRolePrivileges(db, owner)
+		if err != nil {
+			return false, "", err
+		}
+		if hasPrivileges {
+			log.Printf("[DEBUG] %s already has the privileges of %s, no need to grant it", currentUser, owner)
+			return false, "", nil
+		}
+	} else {
+		canSet, err := canSetRole(db, owner)
+		if err != nil {
+			return false, "", err
+		}
+		if canSet {
+			log.Printf("[DEBUG] %s can already SET ROLE to %s, no need to grant it", currentUser, owner)
+			return false, "", nil
+		}
+	}
+
+	grantor := d.Get(dbOwnerGrantorRoleAttr).(string)
+	if grantor == "" {
+		if grantor, err = rdsOwnerGrantor(db, owner); err != nil {
+			return false, "", err
+		}
+	}
+
+	var granted bool
+	err = db.client.withTx("", func(txn *sql.Tx) error {
+		if grantor != "" {
+			if _, err := txn.Exec(fmt.Sprintf("SET LOCAL ROLE %s", pq.QuoteIdentifier(grantor))); err != nil {
+				return fmt.Errorf("could not set role %s to grant %s to %s: %w", grantor, owner, currentUser, err)
+			}
+		}
+
+		hasAdmin, err := hasRoleAdminOption(txn, owner)
+		if err != nil {
+			return err
+		}
+		if !hasAdmin {
+			if grantor != "" {
+				return fmt.Errorf(
+					"role %s configured in %s does not have ADMIN OPTION on role %s",
+					grantor, dbOwnerGrantorRoleAttr, owner,
+				)
+			}
+			return fmt.Errorf(
+				"%s cannot use role %s as database owner: since PostgreSQL 16 it needs to be a member of %s with SET option, "+
+					"or to have ADMIN OPTION on it to be granted temporarily. "+
+					"Grant one of them to %s, or set %s to a role having ADMIN OPTION on %s",
+				currentUser, owner, owner, currentUser, dbOwnerGrantorRoleAttr, owner,
+			)
+		}
+
+		granted, err = grantRoleMembershipWithOptions(txn, owner, currentUser, dbOwnerMembershipOptions(drop))
+		return err
+	})
+	return granted, grantor, err
+}
+
+// dbOwnerMembershipOptions returns the least privilege options of the temporary
+// membership granted by grantDBOwnerMembership on PostgreSQL 16+.
+func dbOwnerMembershipOptions(drop bool) string {
+	if drop {
+		return "INHERIT TRUE, SET FALSE"
+	}
+	return "SET TRUE, INHERIT FALSE"
+}
+
+// revokeDBOwnerMembership reverts grantDBOwnerMembership.
+func revokeDBOwnerMembership(db *DBConnection, grantor, owner, currentUser string) error {
+	if grantor == "" || !db.featureSupported(featureMembershipSetOption) {
+		_, err := revokeRoleMembership(db, owner, currentUser)
+		return err
+	}
+
+	// The membership has been granted by the grantor role, only it can revoke it.
+	return db.client.withTx("", func(txn *sql.Tx) error {
+		if _, err := txn.Exec(fmt.Sprintf("SET LOCAL ROLE %s", pq.QuoteIdentifier(grantor))); err != nil {
+			return fmt.Errorf("could not set role %s to revoke %s from %s: %w", grantor, owner, currentUser, err)
+		}
+		_, err := revokeRoleMembership(txn, owner, currentUser)
+		return err
+	})
 }
 
-func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData) error {
+// rdsSuperuserRole is the role AWS RDS and Aurora grant to the master user instead of SUPERUSER.
+const rdsSuperuserRole = "rds_superuser"
+
+// rdsOwnerGrantor returns rds_superuser if the connection user has no ADMIN OPTION on owner,
+// but can switch to rds_superuser which has it, or an empty string otherwise.
+func rdsOwnerGrantor(db *DBConnection, owner string) (string, error) {
+	if db.client.config.Superuser {
+		return "", nil
+	}
+
+	capabilities, err := db.roleCapabilities()
+	if err != nil || !capabilities.rdsSuperuser {
+		return "", err
+	}
+
+	hasAdmin, err := hasRoleAdminOption(db, owner)
+	if err != nil || hasAdmin {
+		return "", err
+	}
+
+	var rdsHasAdmin bool
+	if err := db.QueryRow(
+		"SELECT pg_has_role($1, oid, 'USAGE WITH ADMIN OPTION') AND pg_has_role(CURRENT_USER, $1, 'SET') FROM pg_catalog.pg_roles WHERE rolname = $2",
+		rdsSuperuserRole, owner,
+	).Scan(&rdsHasAdmin); err != nil {
+		return "", fmt.Errorf("could not check admin option of %s on role %s: %w", rdsSuperuserRole, owner, err)
+	}
+	if !rdsHasAdmin {
+		return "", nil
+	}
+
+	log.Printf("[DEBUG] granting %s as %s which has ADMIN OPTION on it", owner, rdsSuperuserRole)
+	return rdsSuperuserRole, nil
+}
+
+// withRDSOwnerHint explains permission errors of the owner membership grant on AWS RDS,
+// where the master user is not a real superuser and cannot grant some roles
+// (e.g. rdsadmin or other superusers).
+func withRDSOwnerHint(db *DBConnection, err error, owner, currentUser string) error {
+	if !isInsufficientPrivilege(err) || db.client.config.Superuser {
+		return err
+	}
+	if capabilities, capErr := db.roleCapabilities(); capErr != nil || !capabilities.rdsSuperuser {
+		return err
+	}
+	return rdsOwnerError(err, owner, currentUser)
+}
+
+func rdsOwnerError(err error, owner, currentUser string) error {
+	return fmt.Errorf(
+		"%w: on AWS RDS, %s is a member of %s but not a superuser, so it cannot grant role %s to itself to use it as database owner. "+
+			"Grant %s to %s beforehand, or use a role %s can administer as owner",
+		err, currentUser, rdsSuperuserRole, owner, owner, currentUser, currentUser,
+	)
+}
+
+// isInsufficientPrivilege returns true if err is a PostgreSQL permission error.
+func isInsufficientPrivilege(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "insufficient_privilege"
+}
+
+func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData) (err error) {
+	if d.Get(dbDeletionProtectionAttr).(bool) {
+		return fmt.Errorf(
+			"cannot drop database %s: %s is enabled. Set it to false and apply before destroying the database",
+			d.Get(dbNameAttr).(string), dbDeletionProtectionAttr,
+		)
+	}
+
 	currentUser := db.client.config.getDatabaseUsername()
-	owner := d.Get(dbOwnerAttr).(string)
+	owner, err := resolveOwner(db, d.Get(dbOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
 
 	var dropWithForce string
-	var err error
 	if owner != "" {
-		lockTxn, err := startTransaction(db.client, "")
+		lockTxn, txnErr := startTransaction(db.client, "")
+		if txnErr != nil {
+			return txnErr
+		}
 		if err := pgLockRole(lockTxn, currentUser); err != nil {
 			return err
 		}
@@ -244,32 +910,37 @@ func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData)
 
 		// Needed in order to set the owner of the db if the connection user is not a
 		// superuser
-		ownerGranted, err := grantRoleMembership(db, owner, currentUser)
-		if err != nil {
-			return err
+		ownerGranted, grantor, grantErr := grantDBOwnerMembership(db, d, owner, currentUser, true)
+		if grantErr != nil {
+			return grantErr
 		}
 		if ownerGranted {
 			defer func() {
-				_, err = revokeRoleMembership(db, owner, currentUser)
+				// Don't leave the connection user member of the owner behind.
+				if revokeErr := revokeDBOwnerMembership(db, grantor, owner, currentUser); revokeErr != nil && err == nil {
+					err = revokeErr
+				}
 			}()
 		}
 	}
 
 	dbName := d.Get(dbNameAttr).(string)
 	if db.featureSupported(featureDBIsTemplate) {
-		if isTemplate := d.Get(dbIsTemplateAttr).(bool); isTemplate {
-			// Template databases must have this attribute cleared before
-			// they can be dropped.
-			if err := doSetDBIsTemplate(db, dbName, false); err != nil {
+		// Template databases must have this attribute cleared before they can be dropped.
+		// The flag is read from the catalog as it may have been changed outside of Terraform.
+		// Neither ALTER DATABASE nor DROP DATABASE connect to the database, so this doesn't
+		// depend on ALLOW_CONNECTIONS.
+		isTemplate, err := dbIsTemplate(db, dbName)
+		if err != nil {
+			return err
+		}
+		if isTemplate {
+			if err := doSetDBIsTemplate(db, db, dbName, false); err != nil {
 				return fmt.Errorf("Error updating database IS_TEMPLATE during DROP DATABASE: %w", err)
 			}
 		}
 	}
 
-	if err := setDBIsTemplate(db, d); err != nil {
-		return err
-	}
-
 	// Terminate all active connections and block new one
 	if err := terminateBConnections(db, dbName); err != nil {
 		return err
@@ -281,14 +952,18 @@ func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData)
 	}
 
 	sql := fmt.Sprintf("DROP DATABASE %s %s", pq.QuoteIdentifier(dbName), dropWithForce)
-	if _, err := db.Exec(sql); err != nil {
+	if err := execDatabaseDDL(db, sql); err != nil {
 		return fmt.Errorf("Error dropping database: %w", err)
 	}
 
+	// The statements prepared on the connections to the dropped database can't be used anymore.
+	if err := closeDatabaseStmtCaches(dbName); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
+
 	d.SetId("")
 
-	// Returning err even if it's nil so defer func can modify it.
-	return err
+	return nil
 }
 
 func resourcePostgreSQLDatabaseExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
@@ -308,7 +983,8 @@ func resourcePostgreSQLDatabaseRead(db *DBConnection, d *schema.ResourceData) er
 func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData) error {
 	dbId := d.Id()
 	var dbName, ownerName string
-	err := db.QueryRow("SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba) from pg_database d WHERE datname=$1", dbId).Scan(&dbName, &ownerName)
+	var ownerOID uint32
+	err := db.QueryRow("SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba), d.datdba from pg_database d WHERE datname=$1", dbId).Scan(&dbName, &ownerName, &ownerOID)
 	switch {
 	case err == sql.ErrNoRows:
 		log.Printf("[WARN] PostgreSQL database (%q) not found", dbId)
@@ -318,15 +994,18 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 		return fmt.Errorf("Error reading database: %w", err)
 	}
 
-	var dbEncoding, dbCollation, dbCType, dbTablespaceName string
-	var dbConnLimit int
+	var dbEncoding, dbCollation, dbCType, dbTablespaceName, dbComment string
+	var dbEncodingID, dbConnLimit, dbActiveConns int
 
 	columns := []string{
 		"pg_catalog.pg_encoding_to_char(d.encoding)",
+		"d.encoding",
 		"d.datcollate",
 		"d.datctype",
 		"ts.spcname",
 		"d.datconnlimit",
+		"(SELECT count(*) FROM pg_catalog.pg_stat_activity AS a WHERE a.datid = d.oid)",
+		"COALESCE(pg_catalog.shobj_description(d.oid, 'pg_database'), '')",
 	}
 
 	dbSQLFmt := `SELECT %s ` +
@@ -336,10 +1015,13 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 	err = db.QueryRow(dbSQL, dbId).
 		Scan(
 			&dbEncoding,
+			&dbEncodingID,
 			&dbCollation,
 			&dbCType,
 			&dbTablespaceName,
 			&dbConnLimit,
+			&dbActiveConns,
+			&dbComment,
 		)
 	switch {
 	case err == sql.ErrNoRows:
@@ -351,17 +1033,18 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 	}
 
 	d.Set(dbNameAttr, dbName)
-	d.Set(dbOwnerAttr, ownerName)
-	d.Set(dbEncodingAttr, dbEncoding)
+	d.Set(dbOwnerAttr, ownerStateValue(d.Get(dbOwnerAttr).(string), ownerName, ownerOID))
+	d.Set(dbEncodingAttr, dbEncodingStateValue(d.Get(dbEncodingAttr).(string), dbEncoding, dbEncodingID))
 	d.Set(dbCollationAttr, dbCollation)
 	d.Set(dbCTypeAttr, dbCType)
+	d.Set(dbActiveConnectionsAttr, dbActiveConns)
 	d.Set(dbTablespaceAttr, dbTablespaceName)
 	d.Set(dbConnLimitAttr, dbConnLimit)
-	dbTemplate := d.Get(dbTemplateAttr).(string)
-	if dbTemplate == "" {
-		dbTemplate = "template0"
-	}
-	d.Set(dbTemplateAttr, dbTemplate)
+	d.Set(commentAttr, dbComment)
+	d.Set(dbDeletionProtectionAttr, d.Get(dbDeletionProtectionAttr).(bool))
+	d.Set(dbLocaleAttr, d.Get(dbLocaleAttr).(string))
+	d.Set(dbTerminateConnectionsOnTablespaceChangeAttr, d.Get(dbTerminateConnectionsOnTablespaceChangeAttr).(bool))
+	// The template is not stored in pg_database, the one used at creation is kept in the state.
 
 	if db.featureSupported(featureDBAllowConnections) {
 		var dbAllowConns bool
@@ -385,31 +1068,272 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 		d.Set(dbIsTemplateAttr, dbIsTemplate)
 	}
 
+	if db.featureSupported(featureDatabaseCollationVersion) {
+		// The actual version is NULL if the collation provider doesn't provide versions (e.g. the C locale).
+		var dbCollVersion, actualCollVersion sql.NullString
+		dbSQL := fmt.Sprintf(dbSQLFmt, "d.datcollversion, pg_catalog.pg_database_collation_actual_version(d.oid)")
+		err = db.QueryRow(dbSQL, dbId).Scan(&dbCollVersion, &actualCollVersion)
+		if err != nil {
+			return fmt.Errorf("Error reading collation version of DATABASE: %w", err)
+		}
+
+		d.Set(dbCollationVersionAttr, dbCollVersion.String)
+		d.Set(dbCollationVersionMismatchAttr, dbCollVersion.Valid && actualCollVersion.Valid && dbCollVersion.String != actualCollVersion.String)
+	}
+
+	if db.featureSupported(featureDatabaseLocaleProvider) {
+		// The ICU locale has been renamed datlocale in PostgreSQL 17, which also stores the builtin one.
+		localeColumn := "d.daticulocale"
+		if db.featureSupported(featureDatabaseBuiltinLocale) {
+			localeColumn = "d.datlocale"
+		}
+		var providerCode, locale string
+		dbSQL := fmt.Sprintf(dbSQLFmt, "d.datlocprovider::text, COALESCE("+localeColumn+", '')")
+		err = db.QueryRow(dbSQL, dbId).Scan(&providerCode, &locale)
+		if err != nil {
+			return fmt.Errorf("Error reading locale provider of DATABASE: %w", err)
+		}
+
+		provider := dbLocaleProviderCodes[providerCode]
+		d.Set(dbLocaleProviderAttr, provider)
+		d.Set(dbICULocaleAttr, "")
+		d.Set(dbBuiltinLocaleAttr, "")
+		switch provider {
+		case "icu":
+			d.Set(dbICULocaleAttr, locale)
+		case "builtin":
+			d.Set(dbBuiltinLocaleAttr, locale)
+		}
+	}
+
+	if err := readDBConfig(db, d); err != nil {
+		return err
+	}
+
+	return readDBGrants(db, d)
+}
+
+// readDBConfig reads the configuration parameters listed in the config attribute,
+// i.e. the ones set for all the roles (ALTER DATABASE ... SET).
+func readDBConfig(db QueryAble, d *schema.ResourceData) error {
+	resetAll := d.Get(dbResetAllConfigAttr).(bool)
+	if len(d.Get(dbConfigAttr).(map[string]interface{})) == 0 && !resetAll {
+		return nil
+	}
+
+	var config pq.ByteaArray
+	err := db.QueryRow(
+		"SELECT COALESCE((SELECT s.setconfig FROM pg_catalog.pg_db_role_setting AS s WHERE s.setdatabase = d.oid AND s.setrole = 0), '{}') "+
+			"FROM pg_catalog.pg_database AS d WHERE d.datname = $1",
+		d.Get(dbNameAttr).(string),
+	).Scan(&config)
+	if err != nil {
+		return fmt.Errorf("Error reading configuration of DATABASE: %w", err)
+	}
+
+	// With reset_all_config, the parameters which are not listed show up in the plan to be reset.
+	if resetAll {
+		settings := map[string]interface{}{}
+		for name, value := range parseConfig(config) {
+			settings[name] = value
+		}
+		return d.Set(dbConfigAttr, settings)
+	}
+	return d.Set(dbConfigAttr, managedConfig(d, dbConfigAttr, parseConfig(config)))
+}
+
+// setDBConfig applies the changes of the config attribute. With reset_all_config, all the parameters are reset
+// first, then the ones of config are set again, in the same transaction.
+func setDBConfig(txn *sql.Tx, d *schema.ResourceData) error {
+	dbName := d.Get(dbNameAttr).(string)
+	alterPrefix := fmt.Sprintf("ALTER DATABASE %s", pq.QuoteIdentifier(dbName))
+
+	queries := configQueries(d, dbConfigAttr, alterPrefix)
+	if d.Get(dbResetAllConfigAttr).(bool) && (d.HasChange(dbConfigAttr) || d.HasChange(dbResetAllConfigAttr)) {
+		queries = resetAllConfigQueries(d.Get(dbConfigAttr).(map[string]interface{}), alterPrefix)
+	}
+
+	for _, query := range queries {
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("Error updating configuration of database %s: %w", dbName, err)
+		}
+	}
 	return nil
 }
 
-func resourcePostgreSQLDatabaseUpdate(db *DBConnection, d *schema.ResourceData) error {
-	if err := setDBName(db, d); err != nil {
-		return err
+// readDBGrants reads the privileges of the roles listed in the grant blocks.
+// The other roles are not managed by the resource, so nothing is read if there is no grant block.
+func readDBGrants(db QueryAble, d *schema.ResourceData) error {
+	grants := d.Get(dbGrantAttr).(*schema.Set)
+	if grants.Len() == 0 {
+		return nil
 	}
 
-	if err := setDBOwner(db, d); err != nil {
+	privileges, err := readDBPrivileges(db, d.Get(dbNameAttr).(string))
+	if err != nil {
 		return err
 	}
 
-	if err := setDBTablespace(db, d); err != nil {
+	// A role which lost all its privileges is removed, so it is granted again.
+	var readGrants []interface{}
+	for role := range dbGrantsByRole(grants) {
+		if len(privileges[role]) == 0 {
+			continue
+		}
+		readGrants = append(readGrants, map[string]interface{}{
+			dbGrantRoleAttr:       role,
+			dbGrantPrivilegesAttr: stringSliceToSet(privileges[role]),
+		})
+	}
+
+	return d.Set(dbGrantAttr, readGrants)
+}
+
+// readDBPrivileges returns the privileges granted on the database by role (`public` for PUBLIC).
+func readDBPrivileges(db QueryAble, dbName string) (map[string][]string, error) {
+	// A NULL datacl means the default privileges (CONNECT and TEMPORARY for PUBLIC).
+	rows, err := db.Query(
+		"SELECT CASE WHEN a.grantee = 0 THEN 'public' ELSE pg_catalog.pg_get_userbyid(a.grantee) END, "+
+			"array_agg(a.privilege_type) "+
+			"FROM pg_catalog.pg_database d, aclexplode(COALESCE(d.datacl, acldefault('d', d.datdba))) a "+
+			"WHERE d.datname = $1 GROUP BY a.grantee",
+		dbName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the privileges of database %s: %w", dbName, err)
+	}
+	defer rows.Close()
+
+	privileges := map[string][]string{}
+	for rows.Next() {
+		var role string
+		var rolePrivileges []string
+		if err := rows.Scan(&role, pq.Array(&rolePrivileges)); err != nil {
+			return nil, fmt.Errorf("could not scan the privileges of database %s: %w", dbName, err)
+		}
+		privileges[role] = rolePrivileges
+	}
+
+	return privileges, rows.Err()
+}
+
+// setDBGrants grants and revokes the privileges needed for the roles of the grant blocks to have
+// exactly the privileges listed, compared to the ones they currently have (e.g. PUBLIC has CONNECT
+// by default), and revokes all the privileges of the roles removed from the grant blocks.
+// It is done as the owner of the database if needed, as only it can grant privileges on the database.
+func setDBGrants(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(dbGrantAttr) {
+		return nil
+	}
+
+	dbName := d.Get(dbNameAttr).(string)
+	oldGrants, newGrants := d.GetChange(dbGrantAttr)
+	oldPrivileges := dbGrantsByRole(oldGrants.(*schema.Set))
+	newPrivileges := dbGrantsByRole(newGrants.(*schema.Set))
+
+	roles := []string{}
+	for role := range oldPrivileges {
+		roles = append(roles, role)
+	}
+	for role := range newPrivileges {
+		if _, found := oldPrivileges[role]; !found {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+
+	currentPrivileges, err := readDBPrivileges(txn, dbName)
+	if err != nil {
 		return err
 	}
 
-	if err := setDBConnLimit(db, d); err != nil {
+	owner, err := getDatabaseOwner(txn, dbName)
+	if err != nil {
 		return err
 	}
 
-	if err := setDBAllowConns(db, d); err != nil {
+	return withRolesGranted(db, txn, []string{owner}, func() error {
+		for _, role := range roles {
+			toGrant, toRevoke := grantPrivilegesDiff(stringSliceToSet(currentPrivileges[role]), stringSliceToSet(newPrivileges[role]))
+			if len(toRevoke) > 0 {
+				query := fmt.Sprintf("REVOKE %s ON DATABASE %s FROM %s", strings.Join(toRevoke, ", "), pq.QuoteIdentifier(dbName), dbGrantRoleIdentifier(role))
+				if _, err := txn.Exec(query); err != nil {
+					return fmt.Errorf("could not revoke privileges on database %s from %s: %w", dbName, role, err)
+				}
+			}
+			if len(toGrant) > 0 {
+				query := fmt.Sprintf("GRANT %s ON DATABASE %s TO %s", strings.Join(toGrant, ", "), pq.QuoteIdentifier(dbName), dbGrantRoleIdentifier(role))
+				if _, err := txn.Exec(query); err != nil {
+					return fmt.Errorf("could not grant privileges on database %s to %s: %w", dbName, role, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// dbGrantsByRole returns the privileges of the grant blocks by role, merging the blocks of the same role.
+func dbGrantsByRole(grants *schema.Set) map[string][]string {
+	privileges := map[string][]string{}
+	for _, grant := range grants.List() {
+		grant := grant.(map[string]interface{})
+		role := grant[dbGrantRoleAttr].(string)
+		for _, privilege := range grant[dbGrantPrivilegesAttr].(*schema.Set).List() {
+			privileges[role] = append(privileges[role], privilege.(string))
+		}
+	}
+	return privileges
+}
+
+func dbGrantRoleIdentifier(role string) string {
+	if role == publicRole {
+		return "PUBLIC"
+	}
+	return pq.QuoteIdentifier(role)
+}
+
+func resourcePostgreSQLDatabaseUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if err := db.client.withTx("", func(txn *sql.Tx) error {
+		if err := setDBName(txn, d); err != nil {
+			return err
+		}
+
+		if err := setDBOwner(db, txn, d); err != nil {
+			return err
+		}
+
+		if err := setDBConnLimit(txn, d); err != nil {
+			return err
+		}
+
+		if err := setDBAllowConns(db, txn, d); err != nil {
+			return err
+		}
+
+		if d.HasChange(commentAttr) {
+			if err := setObjectComment(txn, "DATABASE", pq.QuoteIdentifier(d.Get(dbNameAttr).(string)), d.Get(commentAttr).(string)); err != nil {
+				return err
+			}
+		}
+
+		if err := setDBConfig(txn, d); err != nil {
+			return err
+		}
+
+		if err := setDBGrants(db, txn, d); err != nil {
+			return err
+		}
+
+		return setDBIsTemplate(db, txn, d)
+	}); err != nil {
 		return err
 	}
 
-	if err := setDBIsTemplate(db, d); err != nil {
+	// Only move the ID once the rename has been committed.
+	d.SetId(d.Get(dbNameAttr).(string))
+
+	// ALTER DATABASE ... SET TABLESPACE cannot run inside a transaction block.
+	if err := setDBTablespace(db, d); err != nil {
 		return err
 	}
 
@@ -434,49 +1358,57 @@ func setDBName(db QueryAble, d *schema.ResourceData) error {
 	if _, err := db.Exec(sql); err != nil {
 		return fmt.Errorf("Error updating database name: %w", err)
 	}
-	d.SetId(n)
 
 	return nil
 }
 
-func setDBOwner(db *DBConnection, d *schema.ResourceData) error {
+func setDBOwner(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(dbOwnerAttr) {
 		return nil
 	}
 
-	owner := d.Get(dbOwnerAttr).(string)
+	owner, err := resolveOwner(txn, d.Get(dbOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
 	if owner == "" {
 		return nil
 	}
+	dbName := d.Get(dbNameAttr).(string)
+	if err := checkRoleExistence(txn, owner); err != nil {
+		return fmt.Errorf("invalid owner of database %s: %w", dbName, err)
+	}
+
 	currentUser := db.client.config.getDatabaseUsername()
 
-	lockTxn, err := startTransaction(db.client, "")
-	if err := pgLockRole(lockTxn, currentUser); err != nil {
+	// Take a lock on db currentUser to avoid multiple owner changes granting
+	// the same role at the same time.
+	if err := pgLockRole(txn, currentUser); err != nil {
 		return err
 	}
-	defer deferredRollback(lockTxn)
 
-	//needed in order to set the owner of the db if the connection user is not a superuser
-	ownerGranted, err := grantRoleMembership(db, owner, currentUser)
+	// ALTER DATABASE OWNER requires the connection user to be able to SET ROLE to the new owner,
+	// which is granted temporarily if needed as for CREATE DATABASE.
+	ownerGranted, grantor, err := grantDBOwnerMembership(db, d, owner, currentUser, false)
 	if err != nil {
 		return err
 	}
-	if ownerGranted {
-		defer func() {
-			_, err = revokeRoleMembership(db, owner, currentUser)
-		}()
-	}
 
-	dbName := d.Get(dbNameAttr).(string)
-	sql := fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(owner))
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database OWNER: %w", err)
+	query := fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(owner))
+	if _, err = txn.Exec(query); err != nil {
+		err = fmt.Errorf("Error updating database OWNER: %w", err)
 	}
 
+	if ownerGranted {
+		// Don't leave the connection user member of the owner behind.
+		if revokeErr := revokeDBOwnerMembership(db, grantor, owner, currentUser); revokeErr != nil && err == nil {
+			err = revokeErr
+		}
+	}
 	return err
 }
 
-func setDBTablespace(db QueryAble, d *schema.ResourceData) error {
+func setDBTablespace(db *DBConnection, d *schema.ResourceData) error {
 	if !d.HasChange(dbTablespaceAttr) {
 		return nil
 	}
@@ -487,9 +1419,26 @@ func setDBTablespace(db QueryAble, d *schema.ResourceData) error {
 	if tbspName == "" || strings.ToUpper(tbspName) == "DEFAULT" {
 		sql = fmt.Sprintf("ALTER DATABASE %s RESET TABLESPACE", pq.QuoteIdentifier(dbName))
 	} else {
+		if err := checkTablespaceExistence(db, tbspName); err != nil {
+			return fmt.Errorf("invalid tablespace of database %s: %w", dbName, err)
+		}
 		sql = fmt.Sprintf("ALTER DATABASE %s SET TABLESPACE %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(tbspName))
 	}
 
+	// The files of the database can only be moved while nobody is connected to it.
+	pids, err := getDBConnectionPIDs(db, dbName)
+	if err != nil {
+		return err
+	}
+	if len(pids) > 0 {
+		if !d.Get(dbTerminateConnectionsOnTablespaceChangeAttr).(bool) {
+			return dbTablespaceConnectionsError(dbName, tbspName, pids)
+		}
+		if _, err := db.Exec("SELECT pg_catalog.pg_terminate_backend(pid) FROM unnest($1::int[]) AS pid", pq.Array(pids)); err != nil {
+			return fmt.Errorf("could not terminate the connections to database %s: %w", dbName, err)
+		}
+	}
+
 	if _, err := db.Exec(sql); err != nil {
 		return fmt.Errorf("Error updating database TABLESPACE: %w", err)
 	}
@@ -512,50 +1461,138 @@ func setDBConnLimit(db QueryAble, d *schema.ResourceData) error {
 	return nil
 }
 
-func setDBAllowConns(db *DBConnection, d *schema.ResourceData) error {
+func setDBAllowConns(db *DBConnection, txn QueryAble, d *schema.ResourceData) error {
 	if !d.HasChange(dbAllowConnsAttr) {
 		return nil
 	}
 
 	if !db.featureSupported(featureDBAllowConnections) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database ALLOW_CONNECTIONS", db.version.String())
+		return db.unsupportedFeatureError(featureDBAllowConnections, "database ALLOW_CONNECTIONS")
 	}
 
 	allowConns := d.Get(dbAllowConnsAttr).(bool)
 	dbName := d.Get(dbNameAttr).(string)
 	sql := fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS %t", pq.QuoteIdentifier(dbName), allowConns)
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database ALLOW_CONNECTIONS: %w", err)
+	if _, err := txn.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database ALLOW_CONNECTIONS: %w", withDisableFeatureHint(db, err, "database_allow_connections"))
 	}
 
 	return nil
 }
 
-func setDBIsTemplate(db *DBConnection, d *schema.ResourceData) error {
+func setDBIsTemplate(db *DBConnection, txn QueryAble, d *schema.ResourceData) error {
 	if !d.HasChange(dbIsTemplateAttr) {
 		return nil
 	}
 
-	if err := doSetDBIsTemplate(db, d.Get(dbNameAttr).(string), d.Get(dbIsTemplateAttr).(bool)); err != nil {
+	if err := doSetDBIsTemplate(db, txn, d.Get(dbNameAttr).(string), d.Get(dbIsTemplateAttr).(bool)); err != nil {
 		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", err)
 	}
 
 	return nil
 }
 
-func doSetDBIsTemplate(db *DBConnection, dbName string, isTemplate bool) error {
+func doSetDBIsTemplate(db *DBConnection, txn QueryAble, dbName string, isTemplate bool) error {
 	if !db.featureSupported(featureDBIsTemplate) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database IS_TEMPLATE", db.version.String())
+		return db.unsupportedFeatureError(featureDBIsTemplate, "database IS_TEMPLATE")
 	}
 
 	sql := fmt.Sprintf("ALTER DATABASE %s IS_TEMPLATE %t", pq.QuoteIdentifier(dbName), isTemplate)
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", err)
+	if _, err := txn.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", withDisableFeatureHint(db, err, "database_is_template"))
 	}
 
 	return nil
 }
 
+// withDisableFeatureHint adds a hint to disable the feature in the provider configuration
+// to permission errors when the provider is not configured as superuser,
+// as managed services (e.g. AWS RDS) block some operations regardless of the server version.
+func withDisableFeatureHint(db *DBConnection, err error, feature string) error {
+	if isInsufficientPrivilege(err) && !db.client.config.Superuser {
+		return fmt.Errorf("%w (if the server does not allow it, add %q to the provider disabled_features)", err, feature)
+	}
+	return err
+}
+
+// dbIsTemplate returns true if the database is marked as template in the catalog.
+func dbIsTemplate(db QueryAble, dbName string) (bool, error) {
+	var isTemplate bool
+	err := db.QueryRow("SELECT datistemplate FROM pg_catalog.pg_database WHERE datname = $1", dbName).Scan(&isTemplate)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("Error reading IS_TEMPLATE property of database %s: %w", dbName, err)
+	}
+	return isTemplate, nil
+}
+
+// suppressDBTemplateDiff suppresses the diff of the template when it is unknown
+// (imported database) or equivalent (no template is template0, DEFAULT is template1).
+func suppressDBTemplateDiff(k, old, new string, d *schema.ResourceData) bool {
+	if d.Id() != "" && old == "" {
+		return true
+	}
+	return normalizeDBTemplate(old) == normalizeDBTemplate(new)
+}
+
+func normalizeDBTemplate(template string) string {
+	switch {
+	case template == "":
+		return "template0"
+	case strings.ToUpper(template) == "DEFAULT":
+		return "template1"
+	}
+	return template
+}
+
+// isDBEncodingID returns true if the encoding is specified by its numeric id
+// (as in pg_database.encoding) instead of its name.
+func isDBEncodingID(encoding string) bool {
+	_, err := strconv.ParseUint(encoding, 10, 31)
+	return err == nil
+}
+
+// dbEncodingStateValue returns the value to store in the state for the encoding:
+// a numeric encoding id is kept as long as it matches the current encoding,
+// so it doesn't show any diff with the encoding name.
+func dbEncodingStateValue(current, encodingName string, encodingID int) string {
+	if isDBEncodingID(current) {
+		if id, _ := strconv.Atoi(current); id == encodingID {
+			return current
+		}
+	}
+	return encodingName
+}
+
+// getDBConnectionPIDs returns the PIDs of the other connections to the database.
+func getDBConnectionPIDs(db *DBConnection, dbName string) ([]int64, error) {
+	pid := "procpid"
+	if db.featureSupported(featurePid) {
+		pid = "pid"
+	}
+
+	var pids []int64
+	query := fmt.Sprintf("SELECT COALESCE(array_agg(%[1]s ORDER BY %[1]s), '{}') FROM pg_catalog.pg_stat_activity WHERE datname = $1 AND %[1]s <> pg_catalog.pg_backend_pid()", pid)
+	if err := db.QueryRow(query, dbName).Scan(pq.Array(&pids)); err != nil {
+		return nil, fmt.Errorf("could not read the connections to database %s: %w", dbName, err)
+	}
+	return pids, nil
+}
+
+func dbTablespaceConnectionsError(dbName, tbspName string, pids []int64) error {
+	pidList := make([]string, len(pids))
+	for i, pid := range pids {
+		pidList[i] = strconv.FormatInt(pid, 10)
+	}
+	return fmt.Errorf(
+		"cannot move database %s to tablespace %q while %d connections are active (PIDs: %s). "+
+			"Close them, or set %s to terminate them",
+		dbName, tbspName, len(pids), strings.Join(pidList, ", "), dbTerminateConnectionsOnTablespaceChangeAttr,
+	)
+}
+
 func terminateBConnections(db *DBConnection, dbName string) error {
 	var terminateSql string
 
@@ -1,14 +1,22 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
 )
 
 func TestAccPostgresqlDatabase_Basic(t *testing.T) {
@@ -160,6 +168,7 @@ resource postgresql_database test_db {
 					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
 					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
 					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "-1"),
+					testAccCheckPostgresqlDatabaseColumn("test_db", "datconnlimit", "-1"),
 					resource.TestCheckResourceAttr(
 						"postgresql_database.test_db", "allow_connections",
 						strconv.FormatBool(allowConnections),
@@ -178,11 +187,784 @@ resource postgresql_database test_db {
 					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
 					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
 					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "2"),
+					testAccCheckPostgresqlDatabaseColumn("test_db", "datconnlimit", "2"),
 					resource.TestCheckResourceAttr(
 						"postgresql_database.test_db", "allow_connections", "false",
 					),
 				),
 			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+	connection_limit = 42
+	allow_connections = false
+}
+	`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "42"),
+					testAccCheckPostgresqlDatabaseColumn("test_db", "datconnlimit", "42"),
+				),
+			},
+		},
+	})
+}
+
+// Test that a failing update is rolled back as a whole:
+// the database should not be renamed if changing its owner failed.
+func TestAccPostgresqlDatabase_UpdateRollback(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db_tx"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name  = "test_db_tx_renamed"
+	owner = "role_does_not_exist"
+}
+`,
+				ExpectError: regexp.MustCompile("role_does_not_exist"),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db_tx"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db_tx"),
+					func(*terraform.State) error {
+						client := testAccProvider.Meta().(*Client)
+						exists, err := checkDatabaseExists(client, "test_db_tx_renamed")
+						if err != nil {
+							return err
+						}
+						if exists {
+							return errors.New("database should not have been renamed")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_OwnerOID(t *testing.T) {
+	config := `
+resource postgresql_role owner {
+	name = "%s"
+}
+
+resource postgresql_database test_db {
+	name  = "test_db_owner_oid"
+	owner = "oid:${postgresql_role.owner.oid}"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "test_owner_oid"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestMatchResourceAttr("postgresql_database.test_db", "owner", regexp.MustCompile(`^oid:\d+$`)),
+					testAccCheckDatabaseOwner("test_db_owner_oid", "test_owner_oid"),
+				),
+			},
+			// Renaming the owner must not change the database
+			{
+				Config: fmt.Sprintf(config, "test_owner_oid_renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestMatchResourceAttr("postgresql_database.test_db", "owner", regexp.MustCompile(`^oid:\d+$`)),
+					testAccCheckDatabaseOwner("test_db_owner_oid", "test_owner_oid_renamed"),
+				),
+			},
+			{
+				Config:   fmt.Sprintf(config, "test_owner_oid_renamed"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_UnknownOwnerOrTablespace(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name  = "test_db_unknown_owner"
+	owner = "tf_test_unknown_role"
+}
+`,
+				ExpectError: regexp.MustCompile(`invalid owner of database test_db_unknown_owner: role tf_test_unknown_role does not exist`),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name            = "test_db_unknown_tablespace"
+	tablespace_name = "tf_test_unknown_tablespace"
+}
+`,
+				ExpectError: regexp.MustCompile(`tablespace tf_test_unknown_tablespace does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_TemplateMismatch(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name       = "test_db_template_mismatch"
+	template   = "template1"
+	encoding   = "SQL_ASCII"
+	lc_collate = "C"
+	lc_ctype   = "C"
+}
+`,
+				ExpectError: regexp.MustCompile(`only template0 can be copied with another encoding or locale`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_BuiltinLocaleProvider(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDatabaseBuiltinLocale)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name            = "test_db_builtin_locale"
+	template        = "template0"
+	locale_provider = "builtin"
+	builtin_locale  = "C.UTF-8"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "locale_provider", "builtin"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "builtin_locale", "C.UTF-8"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "icu_locale", ""),
+					testAccCheckPostgresqlDatabaseColumn("test_db_builtin_locale", "datlocprovider", "b"),
+					testAccCheckPostgresqlDatabaseColumn("test_db_builtin_locale", "datlocale", "C.UTF-8"),
+				),
+			},
+			{
+				ResourceName:            "postgresql_database.test_db",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"template"},
+			},
+		},
+	})
+}
+
+// Test that changing the template of an existing database fails instead of replacing it.
+func TestAccPostgresqlDatabase_TemplateChange(t *testing.T) {
+	config := `
+resource postgresql_database test_db {
+	name     = "test_db_template_change"
+	template = "%s"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "template0"),
+				Check:  resource.TestCheckResourceAttr("postgresql_database.test_db", "template", "template0"),
+			},
+			{
+				Config:      fmt.Sprintf(config, "template1"),
+				ExpectError: regexp.MustCompile(`cannot change template of database test_db_template_change`),
+			},
+			{
+				// Equivalent templates are still not a change.
+				Config:   fmt.Sprintf(config, ""),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_DeletionProtection(t *testing.T) {
+	config := `
+resource postgresql_database test_db {
+	name                = "test_db_deletion_protection"
+	deletion_protection = %t
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, true),
+				Check:  resource.TestCheckResourceAttr("postgresql_database.test_db", "deletion_protection", "true"),
+			},
+			{
+				Config:      fmt.Sprintf(config, true),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`cannot drop database test_db_deletion_protection: deletion_protection is enabled`),
+			},
+			{
+				Config: fmt.Sprintf(config, true),
+				Check:  testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+			},
+			{
+				Config: fmt.Sprintf(config, false),
+				Check:  resource.TestCheckResourceAttr("postgresql_database.test_db", "deletion_protection", "false"),
+			},
+		},
+	})
+}
+
+// Test that ALLOW_CONNECTIONS and IS_TEMPLATE are actually updated on the server.
+func TestAccPostgresqlDatabase_UpdateIsTemplate(t *testing.T) {
+	config := `
+resource postgresql_database test_db {
+	name              = "test_db_update_is_template"
+	is_template       = %[1]t
+	allow_connections = %[2]t
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDBIsTemplate)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, false, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseColumn("test_db_update_is_template", "datistemplate", "false"),
+					testAccCheckPostgresqlDatabaseColumn("test_db_update_is_template", "datallowconn", "true"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, true, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "is_template", "true"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "allow_connections", "false"),
+					testAccCheckPostgresqlDatabaseColumn("test_db_update_is_template", "datistemplate", "true"),
+					testAccCheckPostgresqlDatabaseColumn("test_db_update_is_template", "datallowconn", "false"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, false, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseColumn("test_db_update_is_template", "datistemplate", "false"),
+					testAccCheckPostgresqlDatabaseColumn("test_db_update_is_template", "datallowconn", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_CollationVersion(t *testing.T) {
+	skipIfNotAcc(t)
+
+	testConfig := getTestConfig(t)
+	dsn := testConfig.connStr("postgres")
+
+	config := `
+resource postgresql_database test_db {
+	name       = "test_db_collation_version"
+	template   = "template0"
+	lc_collate = "en_US.UTF-8"
+	lc_ctype   = "en_US.UTF-8"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDatabaseCollationVersion)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("postgresql_database.test_db", "collation_version"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "collation_version_mismatch", "false"),
+				),
+			},
+			// Simulate an upgrade of the collation library
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "UPDATE pg_catalog.pg_database SET datcollversion = '0.0' WHERE datname = 'test_db_collation_version'")
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "collation_version", "0.0"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "collation_version_mismatch", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Config(t *testing.T) {
+	config := `
+resource postgresql_database test_db {
+	name   = "test_db_config"
+	config = %s
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, `{ "pgaudit.log" = "write, ddl", "search_path" = "app,public" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "config.pgaudit.log", "write, ddl"),
+					testAccCheckPostgresqlDatabaseConfig("test_db_config", `{"pgaudit.log=write, ddl","search_path=app, public"}`),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, `{ "search_path" = "app" }`),
+				Check:  testAccCheckPostgresqlDatabaseConfig("test_db_config", `{search_path=app}`),
+			},
+		},
+	})
+}
+
+// Test that the configuration is set when the database is owned by another role than the connection user,
+// which is only granted the owner while the database is created.
+func TestAccPostgresqlDatabase_ConfigWithOwner(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	var stateConfig = `
+resource postgresql_role "test_owner" {
+	name  = "test_owner"
+	login = false
+}
+resource postgresql_database "test_db" {
+	name   = "test_db_config_owner"
+	owner  = "${postgresql_role.test_owner.name}"
+	config = { "search_path" = "app" }
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: stateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
+					testAccCheckPostgresqlDatabaseConfig("test_db_config_owner", `{search_path=app}`),
+					checkUserMembership(t, dsn, config.Username, "test_owner", false),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ResetAllConfig(t *testing.T) {
+	testConfig := getTestConfig(t)
+	config := `
+resource postgresql_database test_db {
+	name             = "test_db_reset_config"
+	config           = { "search_path" = "app" }
+	reset_all_config = %t
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, false),
+				Check:  testAccCheckPostgresqlDatabaseConfig("test_db_reset_config", `{search_path=app}`),
+			},
+			// A parameter set outside of Terraform is not managed without reset_all_config
+			{
+				PreConfig: func() {
+					dbExecute(t, testConfig.connStr("postgres"), "ALTER DATABASE test_db_reset_config SET work_mem = '64MB'")
+				},
+				Config: fmt.Sprintf(config, false),
+				Check:  testAccCheckPostgresqlDatabaseConfig("test_db_reset_config", `{search_path=app,work_mem=64MB}`),
+			},
+			{
+				Config: fmt.Sprintf(config, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "config.%", "1"),
+					testAccCheckPostgresqlDatabaseConfig("test_db_reset_config", `{search_path=app}`),
+				),
+			},
+			// With reset_all_config, it shows up in the plan to be reset
+			{
+				PreConfig: func() {
+					dbExecute(t, testConfig.connStr("postgres"), "ALTER DATABASE test_db_reset_config SET work_mem = '64MB'")
+				},
+				Config:             fmt.Sprintf(config, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: fmt.Sprintf(config, true),
+				Check:  testAccCheckPostgresqlDatabaseConfig("test_db_reset_config", `{search_path=app}`),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlDatabaseConfig(dbName, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var config string
+		if err := db.QueryRow(
+			"SELECT ARRAY(SELECT unnest(s.setconfig) ORDER BY 1)::text FROM pg_catalog.pg_db_role_setting AS s "+
+				"JOIN pg_catalog.pg_database AS d ON d.oid = s.setdatabase WHERE d.datname = $1 AND s.setrole = 0",
+			dbName,
+		).Scan(&config); err != nil {
+			return fmt.Errorf("could not read configuration of database %s: %w", dbName, err)
+		}
+		if config != expected {
+			return fmt.Errorf("expected configuration of database %s to be %s, got: %s", dbName, expected, config)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlDatabase_TemplateEncoding(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The encoding of the template is used instead of UTF8
+				Config: `
+resource postgresql_database template {
+	name       = "test_db_template_sql_ascii"
+	encoding   = "SQL_ASCII"
+	lc_collate = "C"
+	lc_ctype   = "C"
+}
+
+resource postgresql_database test_db {
+	name     = "test_db_from_sql_ascii"
+	template = postgresql_database.template.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "encoding", "SQL_ASCII"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "lc_collate", "C"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "lc_ctype", "C"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_EncodingID(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				// 6 is the id of UTF8
+				Config: `
+resource postgresql_database test_db {
+	name     = "test_db_encoding_id"
+	encoding = "6"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "encoding", "6"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ImportTemplate(t *testing.T) {
+	config := `
+resource postgresql_database test_db {
+	name     = "test_db_import_template"
+	template = "template1"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("postgresql_database.test_db", "template", "template1"),
+			},
+			{
+				ResourceName:            "postgresql_database.test_db",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"template"},
+				ImportStatePersist:      true,
+			},
+			// The template of an imported database is unknown, it must not be replaced.
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ImportOID(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db_import_oid"
+}
+`,
+			},
+			{
+				ResourceName: "postgresql_database.test_db",
+				ImportState:  true,
+				ImportStateIdFunc: func(*terraform.State) (string, error) {
+					db, err := testAccProvider.Meta().(*Client).Connect()
+					if err != nil {
+						return "", err
+					}
+					var oid uint32
+					if err := db.QueryRow("SELECT oid FROM pg_catalog.pg_database WHERE datname = $1", "test_db_import_oid").Scan(&oid); err != nil {
+						return "", err
+					}
+					return fmt.Sprintf("oid:%d", oid), nil
+				},
+				// The ID is normalized to the name of the database
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].ID != "test_db_import_oid" {
+						return fmt.Errorf("expected a database imported as test_db_import_oid, got: %v", states)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Grant(t *testing.T) {
+	config := `
+resource postgresql_role app {
+	name = "tf_test_db_grant_app"
+}
+
+resource postgresql_database test_db {
+	name = "test_db_grant"
+
+	grant {
+		role       = postgresql_role.app.name
+		privileges = [%s]
+	}
+
+	grant {
+		role       = "public"
+		privileges = ["TEMPORARY"]
+	}
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, `"CONNECT"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "grant.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_database.test_db", "grant.*", map[string]string{
+						"role":         "tf_test_db_grant_app",
+						"privileges.#": "1",
+					}),
+					testAccCheckPostgresqlDatabaseColumn("test_db_grant", "has_database_privilege('tf_test_db_grant_app', datname, 'CONNECT')", "true"),
+					testAccCheckPostgresqlDatabaseColumn("test_db_grant", "has_database_privilege('tf_test_db_grant_app', datname, 'CREATE')", "false"),
+					// CONNECT has been revoked from PUBLIC, which only keeps TEMPORARY
+					testAccCheckPostgresqlDatabaseColumn("test_db_grant", "has_database_privilege('public', datname, 'CONNECT')", "false"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, `"CONNECT", "CREATE"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_database.test_db", "grant.*", map[string]string{
+						"role":         "tf_test_db_grant_app",
+						"privileges.#": "2",
+					}),
+					testAccCheckPostgresqlDatabaseColumn("test_db_grant", "has_database_privilege('tf_test_db_grant_app', datname, 'CREATE')", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestDBGrantsByRole(t *testing.T) {
+	grants := schema.NewSet(schema.HashResource(resourcePostgreSQLDatabase().Schema[dbGrantAttr].Elem.(*schema.Resource)), []interface{}{
+		map[string]interface{}{
+			dbGrantRoleAttr:       "app",
+			dbGrantPrivilegesAttr: stringSliceToSet([]string{"CONNECT"}),
+		},
+		map[string]interface{}{
+			dbGrantRoleAttr:       "app",
+			dbGrantPrivilegesAttr: stringSliceToSet([]string{"TEMPORARY"}),
+		},
+		map[string]interface{}{
+			dbGrantRoleAttr:       "public",
+			dbGrantPrivilegesAttr: stringSliceToSet([]string{"CONNECT"}),
+		},
+	})
+
+	privileges := dbGrantsByRole(grants)
+	for role := range privileges {
+		sort.Strings(privileges[role])
+	}
+	expected := map[string][]string{
+		"app":    {"CONNECT", "TEMPORARY"},
+		"public": {"CONNECT"},
+	}
+	if !reflect.DeepEqual(privileges, expected) {
+		t.Errorf("expected %v, got: %v", expected, privileges)
+	}
+
+	if id := dbGrantRoleIdentifier("public"); id != "PUBLIC" {
+		t.Errorf("expected PUBLIC, got: %s", id)
+	}
+	if id := dbGrantRoleIdentifier("App"); id != `"App"` {
+		t.Errorf(`expected "App", got: %s`, id)
+	}
+}
+
+func TestSuppressDBTemplateDiff(t *testing.T) {
+	d := resourcePostgreSQLDatabase().TestResourceData()
+	tests := []struct {
+		id       string
+		old, new string
+		want     bool
+	}{
+		{"", "", "template1", false},
+		{"db", "", "template1", true},
+		{"db", "template0", "", true},
+		{"db", "DEFAULT", "template1", true},
+		{"db", "template0", "template1", false},
+	}
+	for _, test := range tests {
+		d.SetId(test.id)
+		if got := suppressDBTemplateDiff(dbTemplateAttr, test.old, test.new, d); got != test.want {
+			t.Errorf("suppressDBTemplateDiff(%q, %q) with id %q = %t, want %t", test.old, test.new, test.id, got, test.want)
+		}
+	}
+}
+
+func TestDBEncodingStateValue(t *testing.T) {
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{"", "UTF8"},
+		{"UTF8", "UTF8"},
+		{"6", "6"},
+		{"06", "06"},
+		{"8", "UTF8"},
+		{"-6", "UTF8"},
+	}
+	for _, test := range tests {
+		if got := dbEncodingStateValue(test.current, "UTF8", 6); got != test.want {
+			t.Errorf("dbEncodingStateValue(%q, UTF8, 6) = %q, want %q", test.current, got, test.want)
+		}
+	}
+}
+
+func TestAccPostgresqlDatabase_TemplateWithoutConnections(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDBIsTemplate)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				// Destroying the database at the end of the test must clear IS_TEMPLATE first.
+				Config: `
+resource postgresql_database test_db {
+	name              = "test_db_template_no_conns"
+	is_template       = true
+	allow_connections = false
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "is_template", "true"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "allow_connections", "false"),
+				),
+			},
 		},
 	})
 }
@@ -224,6 +1006,48 @@ resource postgresql_database "test_db" {
 	})
 }
 
+// Test that the owner granted to the connected user to change the owner is revoked.
+func TestAccPostgresqlDatabase_UpdateOwner(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	var stateConfig = `
+resource postgresql_role "test_owner" {
+       name = "test_owner"
+}
+resource postgresql_role "test_new_owner" {
+       name = "test_new_owner"
+}
+resource postgresql_database "test_db" {
+       name  = "test_db"
+       owner = "${postgresql_role.%s.name}"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(stateConfig, "test_owner"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(stateConfig, "test_new_owner"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_new_owner"),
+					checkUserMembership(t, dsn, config.Username, "test_new_owner", false),
+				),
+			},
+		},
+	})
+}
+
 // Test the case where the connected user is already a member of the owner.
 // There were a bug which was revoking the owner anyway.
 func TestAccPostgresqlDatabase_GrantOwnerNotNeeded(t *testing.T) {
@@ -354,6 +1178,29 @@ func testAccCheckPostgresqlDatabaseExists(n string) resource.TestCheckFunc {
 	}
 }
 
+// testAccCheckPostgresqlDatabaseColumn checks the value of a column of pg_database on the server,
+// so the update of an option is checked to be actually applied.
+func testAccCheckPostgresqlDatabaseColumn(dbName, column, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var value string
+		query := fmt.Sprintf("SELECT %s::text FROM pg_catalog.pg_database WHERE datname = $1", column)
+		if err := db.QueryRow(query, dbName).Scan(&value); err != nil {
+			return fmt.Errorf("could not read %s of database %s: %w", column, dbName, err)
+		}
+		if value != expected {
+			return fmt.Errorf("expected %s of database %s to be %s, got: %s", column, dbName, expected, value)
+		}
+
+		return nil
+	}
+}
+
 func checkDatabaseExists(client *Client, dbName string) (bool, error) {
 	db, err := client.Connect()
 	if err != nil {
@@ -447,3 +1294,386 @@ resource "postgresql_database" "mydb_default_owner" {
 }
 
 `
+
+func testAccCheckDatabaseOwner(dbName, expectedOwner string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var owner string
+		if err := db.QueryRow(
+			"SELECT pg_catalog.pg_get_userbyid(datdba) FROM pg_catalog.pg_database WHERE datname = $1", dbName,
+		).Scan(&owner); err != nil {
+			return fmt.Errorf("could not read owner of database %s: %w", dbName, err)
+		}
+		if owner != expectedOwner {
+			return fmt.Errorf("expected database %s to be owned by %s, got %s", dbName, expectedOwner, owner)
+		}
+		return nil
+	}
+}
+
+// Test that a non superuser changing the owner of a database on PostgreSQL 16 is granted
+// the new owner with the SET option only for the ALTER DATABASE.
+func TestSetDBOwnerGrantsMembership(t *testing.T) {
+	isMember := false
+	fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+		row := func(values ...driver.Value) (*fakeRows, error) {
+			return &fakeRows{values: [][]driver.Value{values}}, nil
+		}
+		switch {
+		case strings.HasPrefix(query, "SELECT rolsuper, rolcreatedb"):
+			return row(false, true, false)
+		case strings.Contains(query, "pg_has_role($1, 'SET')"):
+			return row(false)
+		case strings.Contains(query, "USAGE WITH ADMIN OPTION"), strings.Contains(query, "EXISTS(SELECT 1 FROM pg_catalog.pg_roles"):
+			return row(true)
+		case strings.HasPrefix(query, "SELECT 1 FROM pg_auth_members") && isMember:
+			return row(int64(1))
+		case strings.HasPrefix(query, "GRANT "):
+			isMember = true
+		case strings.HasPrefix(query, "REVOKE "):
+			isMember = false
+		}
+		return nil, nil
+	}}
+	client := newFakeClient(t, fake, "16.0.0")
+	db, err := client.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{
+		dbNameAttr:  "my_db",
+		dbOwnerAttr: "new_owner",
+	})
+	if err := client.withTx("", func(txn *sql.Tx) error {
+		return setDBOwner(db, txn, d)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []string
+	for _, statement := range fake.Statements() {
+		if strings.HasPrefix(statement, "GRANT ") || strings.HasPrefix(statement, "ALTER ") || strings.HasPrefix(statement, "REVOKE ") {
+			changes = append(changes, statement)
+		}
+	}
+	expected := []string{
+		`GRANT "new_owner" TO "postgres" WITH SET TRUE, INHERIT FALSE`,
+		`ALTER DATABASE "my_db" OWNER TO "new_owner"`,
+		`REVOKE "new_owner" FROM "postgres"`,
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("setDBOwner sent %#v, expected %#v", changes, expected)
+	}
+}
+
+// Test that a template mismatch is reported before the owner is granted to the connection user.
+func TestCreateDatabaseChecksTemplateBeforeGrant(t *testing.T) {
+	fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+		row := func(values ...driver.Value) (*fakeRows, error) {
+			return &fakeRows{values: [][]driver.Value{values}}, nil
+		}
+		switch {
+		case strings.HasPrefix(query, "SELECT rolsuper, rolcreatedb"):
+			return row(false, true, false)
+		case strings.HasPrefix(query, "SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_roles"):
+			return row(true)
+		case strings.Contains(query, "FROM pg_catalog.pg_database WHERE datname = $1"):
+			return row("UTF8", "en_US.UTF-8", "en_US.UTF-8", false)
+		}
+		return nil, nil
+	}}
+	client := newFakeClient(t, fake, "16.0.0")
+	db, err := client.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{
+		dbNameAttr:     "my_db",
+		dbOwnerAttr:    "new_owner",
+		dbTemplateAttr: "my_template",
+		dbEncodingAttr: "LATIN1",
+	})
+	err = createDatabase(db, d)
+	if err == nil || !strings.Contains(err.Error(), "only template0 can be copied with another encoding or locale") {
+		t.Fatalf("expected a template mismatch error, got %v", err)
+	}
+	for _, statement := range fake.Statements() {
+		if strings.HasPrefix(statement, "GRANT ") || strings.HasPrefix(statement, "BEGIN") {
+			t.Errorf("expected nothing to be granted or locked before the template check, got %q", statement)
+		}
+	}
+}
+
+// Test that the configuration is set as the owner, before its membership is revoked from the connection user.
+func TestCreateDatabaseSetsConfigBeforeRevoke(t *testing.T) {
+	isMember := false
+	fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+		row := func(values ...driver.Value) (*fakeRows, error) {
+			return &fakeRows{values: [][]driver.Value{values}}, nil
+		}
+		switch {
+		case strings.HasPrefix(query, "SELECT rolsuper, rolcreatedb"):
+			return row(false, true, false)
+		case strings.Contains(query, "pg_has_role($1, 'SET')"):
+			return row(false)
+		case strings.Contains(query, "USAGE WITH ADMIN OPTION"), strings.Contains(query, "EXISTS(SELECT 1 FROM pg_catalog.pg_roles"):
+			return row(true)
+		case strings.HasPrefix(query, "SELECT 1 FROM pg_auth_members") && isMember:
+			return row(int64(1))
+		case strings.HasPrefix(query, "GRANT "):
+			isMember = true
+		}
+		return nil, nil
+	}}
+	client := newFakeClient(t, fake, "16.0.0")
+	db, err := client.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{
+		dbNameAttr:   "my_db",
+		dbOwnerAttr:  "new_owner",
+		dbConfigAttr: map[string]interface{}{"search_path": "app"},
+	})
+	if err := createDatabase(db, d); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []string
+	for _, statement := range fake.Statements() {
+		if strings.HasPrefix(statement, "GRANT ") || strings.HasPrefix(statement, "ALTER ") ||
+			strings.HasPrefix(statement, "REVOKE ") || strings.HasPrefix(statement, "SET LOCAL ROLE ") {
+			changes = append(changes, statement)
+		}
+	}
+	expected := []string{
+		`GRANT "new_owner" TO "postgres" WITH SET TRUE, INHERIT FALSE`,
+		`SET LOCAL ROLE "new_owner"`,
+		`ALTER DATABASE "my_db" SET "search_path" TO 'app'`,
+		`REVOKE "new_owner" FROM "postgres"`,
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("createDatabase sent %#v, expected %#v", changes, expected)
+	}
+}
+
+func TestIsInsufficientPrivilege(t *testing.T) {
+	permissionErr := &pq.Error{Code: "42501", Message: "permission denied to grant role \"rdsadmin\""}
+
+	if !isInsufficientPrivilege(permissionErr) {
+		t.Error("42501 error should be detected as insufficient privilege")
+	}
+	if !isInsufficientPrivilege(fmt.Errorf("Error granting role rdsadmin to master: %w", permissionErr)) {
+		t.Error("wrapped 42501 error should be detected as insufficient privilege")
+	}
+	if isInsufficientPrivilege(&pq.Error{Code: "42704", Message: "role \"unknown\" does not exist"}) {
+		t.Error("42704 error should not be detected as insufficient privilege")
+	}
+	if isInsufficientPrivilege(errors.New("connection refused")) {
+		t.Error("non PostgreSQL error should not be detected as insufficient privilege")
+	}
+}
+
+func TestRDSOwnerHint(t *testing.T) {
+	permissionErr := fmt.Errorf("Error granting role owner to master: %w", &pq.Error{Code: "42501", Message: "permission denied to grant role \"owner\""})
+
+	// Errors are returned as is (without querying the server) for superusers and other errors.
+	superuserDB := &DBConnection{client: &Client{config: Config{Superuser: true}}}
+	if err := withRDSOwnerHint(superuserDB, permissionErr, "owner", "master"); err != permissionErr {
+		t.Errorf("error should be returned as is for superusers, got: %v", err)
+	}
+	otherErr := errors.New("connection refused")
+	if err := withRDSOwnerHint(&DBConnection{client: &Client{}}, otherErr, "owner", "master"); err != otherErr {
+		t.Errorf("non permission error should be returned as is, got: %v", err)
+	}
+
+	err := rdsOwnerError(permissionErr, "owner", "master")
+	if !errors.Is(err, permissionErr) {
+		t.Errorf("RDS error should wrap the original error, got: %v", err)
+	}
+	if !isInsufficientPrivilege(err) {
+		t.Error("RDS error should still be an insufficient privilege error")
+	}
+	for _, s := range []string{rdsSuperuserRole, "Grant owner to master"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("RDS error should contain %q, got: %v", s, err)
+		}
+	}
+}
+
+func TestDisableFeatureHint(t *testing.T) {
+	permissionErr := &pq.Error{Code: "42501", Message: "permission denied"}
+
+	err := withDisableFeatureHint(&DBConnection{client: &Client{}}, permissionErr, "database_is_template")
+	if !strings.Contains(err.Error(), "disabled_features") {
+		t.Errorf("permission error should hint disabled_features for non superusers, got: %v", err)
+	}
+
+	superuserDB := &DBConnection{client: &Client{config: Config{Superuser: true}}}
+	if err := withDisableFeatureHint(superuserDB, permissionErr, "database_is_template"); err != permissionErr {
+		t.Errorf("error should be returned as is for superusers, got: %v", err)
+	}
+}
+
+func TestDBTablespaceConnectionsError(t *testing.T) {
+	err := dbTablespaceConnectionsError("mydb", "fast", []int64{42, 1337})
+	expected := `cannot move database mydb to tablespace "fast" while 2 connections are active (PIDs: 42, 1337). ` +
+		"Close them, or set terminate_connections_on_tablespace_change to terminate them"
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestAccPostgresqlDatabase_TablespaceChangeWithConnections(t *testing.T) {
+	skipIfNotAcc(t)
+	location := testTablespaceLocation(t)
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("CREATE TABLESPACE tf_test_db_tablespace LOCATION '%s'", location))
+	defer dbExecute(t, config.connStr("postgres"), "DROP TABLESPACE IF EXISTS tf_test_db_tablespace")
+
+	tfConfig := `
+resource "postgresql_database" "test" {
+	name                                       = "tf_test_db_tablespace_move"
+	tablespace_name                            = "%s"
+	terminate_connections_on_tablespace_change = %t
+}
+`
+
+	// Keeps a connection open to the database until it is terminated.
+	var conn *sql.DB
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	openConnection := func() {
+		var err error
+		if conn, err = sql.Open("postgres", config.connStr("tf_test_db_tablespace_move")); err != nil {
+			t.Fatalf("could not open connection pool: %v", err)
+		}
+		if err := conn.Ping(); err != nil {
+			t.Fatalf("could not connect to database: %v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, "pg_default", false),
+			},
+			{
+				PreConfig:   openConnection,
+				Config:      fmt.Sprintf(tfConfig, "tf_test_db_tablespace", false),
+				ExpectError: regexp.MustCompile(`cannot move database tf_test_db_tablespace_move to tablespace "tf_test_db_tablespace" while 1 connections are active \(PIDs: \d+\)`),
+			},
+			{
+				Config: fmt.Sprintf(tfConfig, "tf_test_db_tablespace", true),
+				Check:  resource.TestCheckResourceAttr("postgresql_database.test", "tablespace_name", "tf_test_db_tablespace"),
+			},
+		},
+	})
+}
+
+func TestDBLocaleSettings(t *testing.T) {
+	for _, test := range []struct {
+		locale, collate, ctype, encoding string
+		expectedEncoding                 string
+		err                              string
+	}{
+		{locale: "en_US.UTF-8", expectedEncoding: "UTF8"},
+		{locale: "en_US.utf8", collate: "en_US.utf8", expectedEncoding: "UTF8"},
+		{locale: "de_DE.ISO-8859-15@euro", expectedEncoding: "LATIN9"},
+		{locale: "en_US.UTF-8", encoding: "utf-8", expectedEncoding: "utf-8"},
+		{locale: "en_US.UTF-8", encoding: "6", expectedEncoding: "6"},
+		{locale: "C", expectedEncoding: ""},
+		{locale: "C", encoding: "LATIN1", expectedEncoding: "LATIN1"},
+		{locale: "en_US.UTF-8", collate: "C", err: `locale "en_US.UTF-8" conflicts with lc_collate "C"`},
+		{locale: "en_US.UTF-8", ctype: "C", err: `locale "en_US.UTF-8" conflicts with lc_ctype "C"`},
+		{locale: "en_US.UTF-8", encoding: "LATIN1", err: `encoding "LATIN1" conflicts with locale "en_US.UTF-8", which requires UTF8`},
+	} {
+		collate, ctype, encoding, err := dbLocaleSettings(test.locale, test.collate, test.ctype, test.encoding)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected error %q, got %v", test.locale, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.locale, err)
+			continue
+		}
+		if collate != test.locale || ctype != test.locale || encoding != test.expectedEncoding {
+			t.Errorf("%s: expected %s, %s, %q, got %s, %s, %q", test.locale, test.locale, test.locale, test.expectedEncoding, collate, ctype, encoding)
+		}
+	}
+}
+
+func TestAccPostgresqlDatabase_Locale(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_database" "test" {
+	name   = "tf_test_db_locale"
+	locale = "C"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test", "lc_collate", "C"),
+					resource.TestCheckResourceAttr("postgresql_database.test", "lc_ctype", "C"),
+				),
+			},
+			{
+				Config: `
+resource "postgresql_database" "test" {
+	name       = "tf_test_db_locale"
+	locale     = "C"
+	lc_collate = "POSIX"
+}
+`,
+				ExpectError: regexp.MustCompile(`locale "C" conflicts with lc_collate "POSIX"`),
+			},
+		},
+	})
+}
+
+func TestDBLocaleDiff(t *testing.T) {
+	diff, err := resourcePostgreSQLDatabase().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":   "mydb",
+		"locale": "en_US.UTF-8",
+	}), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for attr, expected := range map[string]string{"lc_collate": "en_US.UTF-8", "lc_ctype": "en_US.UTF-8", "encoding": "UTF8"} {
+		if value := diff.Attributes[attr].New; value != expected {
+			t.Errorf("expected %s to be planned to %q, got %q", attr, expected, value)
+		}
+	}
+
+	_, err = resourcePostgreSQLDatabase().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":       "mydb",
+		"locale":     "en_US.UTF-8",
+		"lc_collate": "C",
+	}), nil)
+	if err == nil || !strings.Contains(err.Error(), `locale "en_US.UTF-8" conflicts with lc_collate "C"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
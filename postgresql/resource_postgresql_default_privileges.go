@@ -1,6 +1,7 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -13,12 +14,87 @@ import (
 	"github.com/lib/pq"
 )
 
+// scanDefaultACLQuery lists the entries of the default privileges of all the owners, global (namespace 0)
+// or in the schema $1 (none if empty), the grantee "public" being PUBLIC.
+const scanDefaultACLQuery = `
+SELECT
+	pg_get_userbyid(t.owner_oid), t.namespace = 0, t.objtype, pg_get_userbyid(t.grantor_oid),
+	CASE WHEN t.grantee_oid = 0 THEN 'public' ELSE pg_get_userbyid(t.grantee_oid) END,
+	t.prtype, t.grantable
+FROM (
+	SELECT defaclrole, defaclnamespace, defaclobjtype, (aclexplode(defaclacl)).* FROM pg_default_acl
+	WHERE defaclnamespace = 0 OR defaclnamespace = (SELECT oid FROM pg_namespace WHERE nspname = $1)
+) AS t (owner_oid, namespace, objtype, grantor_oid, grantee_oid, prtype, grantable)
+`
+
+// defaultACLEntry is a privilege of pg_default_acl granted by grantor to grantee on the new objects
+// of objectType (as in pg_default_acl.defaclobjtype) of owner.
+type defaultACLEntry struct {
+	owner      string
+	global     bool
+	objectType string
+	grantor    string
+	grantee    string
+	privilege  string
+	grantable  bool
+}
+
+func scanDefaultACL(txn *sql.Tx, pgSchema string) ([]defaultACLEntry, error) {
+	rows, err := txn.Query(scanDefaultACLQuery, pgSchema)
+	if err != nil {
+		return nil, fmt.Errorf("could not read default privileges: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []defaultACLEntry
+	for rows.Next() {
+		var entry defaultACLEntry
+		if err := rows.Scan(
+			&entry.owner, &entry.global, &entry.objectType, &entry.grantor, &entry.grantee, &entry.privilege, &entry.grantable,
+		); err != nil {
+			return nil, fmt.Errorf("could not scan default privileges: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read default privileges: %w", err)
+	}
+	return entries, nil
+}
+
+// defaultPrivilegesOf returns the default privileges granted by owner to role on its new objects of
+// objectType, global or in the scanned schema, and whether they are all granted WITH GRANT OPTION.
+// It also returns whether PUBLIC gets EXECUTE on the new functions of the owner: without global
+// default privileges for functions, it has it by default.
+func defaultPrivilegesOf(entries []defaultACLEntry, owner, role, objectType string, global bool) (pq.ByteaArray, bool, bool) {
+	var privileges pq.ByteaArray
+	grantable := true
+	publicExecute, functionDefaults := false, false
+	for _, entry := range entries {
+		if entry.owner != owner {
+			continue
+		}
+		if entry.global && entry.objectType == "f" {
+			functionDefaults = true
+			publicExecute = publicExecute || (entry.grantee == "public" && entry.privilege == "EXECUTE")
+		}
+		if entry.grantee == role && entry.objectType == objectType && entry.grantor == owner && entry.global == global {
+			privileges = append(privileges, []byte(entry.privilege))
+			grantable = grantable && entry.grantable
+		}
+	}
+	return privileges, grantable && len(privileges) > 0, publicExecute || !functionDefaults
+}
+
 func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
-		Update: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLDefaultPrivilegesRead),
-		Delete: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLDefaultPrivilegesRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLDefaultPrivilegesImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -63,15 +139,21 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 				Required:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Set:         schema.HashString,
-				Description: "The list of privileges to apply as default privileges",
+				Description: "The list of privileges to apply as default privileges, `[\"ALL\"]` for all the privileges of the object type supported by the server (the `MAINTAIN` table privilege requires PostgreSQL 17)",
 			},
 			"with_grant_option": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
 				Default:     false,
 				Description: "Permit the grant recipient to grant it to others",
 			},
+			"revoke_public_execute_on_functions": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Also revoke the default EXECUTE privilege of PUBLIC on the functions created by `owner` (only for object_type function). " +
+					"It is revoked in all the schemas, as it cannot be revoked per schema. It is not granted back when the resource is destroyed",
+			},
 		},
 	}
 }
@@ -102,7 +184,7 @@ func resourcePostgreSQLDefaultPrivilegesRead(db *DBConnection, d *schema.Resourc
 	}
 	defer deferredRollback(txn)
 
-	return readRoleDefaultPrivileges(txn, d)
+	return readRoleDefaultPrivileges(db, txn, d)
 }
 
 func resourcePostgreSQLDefaultPrivilegesCreate(db *DBConnection, d *schema.ResourceData) error {
@@ -123,54 +205,53 @@ func resourcePostgreSQLDefaultPrivilegesCreate(db *DBConnection, d *schema.Resou
 		return fmt.Errorf("with_grant_option cannot be true for role 'public'")
 	}
 
-	if err := validatePrivileges(d); err != nil {
-		return err
-	}
-
-	database := d.Get("database").(string)
-	owner := d.Get("owner").(string)
-
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
+	if d.Get("revoke_public_execute_on_functions").(bool) {
+		if objectType != "function" {
+			return fmt.Errorf("`revoke_public_execute_on_functions` can only be used when `object_type` is `function`")
+		}
+		if strings.ToLower(d.Get("role").(string)) == "public" {
+			return fmt.Errorf("`revoke_public_execute_on_functions` cannot be used when `role` is `public`")
+		}
 	}
-	defer deferredRollback(txn)
 
-	if err := pgLockRole(txn, owner); err != nil {
+	if err := validatePrivileges(db, d); err != nil {
 		return err
 	}
 
-	// Needed in order to set the owner of the db if the connection user is not a superuser
-	if err := withRolesGranted(txn, []string{owner}, func() error {
+	owner := d.Get("owner").(string)
 
-		// Revoke all privileges before granting otherwise reducing privileges will not work.
-		// We just have to revoke them in the same transaction so role will not lost his privileges
-		// between revoke and grant.
-		if err = revokeRoleDefaultPrivileges(txn, d); err != nil {
+	if err := withDefaultPrivilegesTx(db, d, func(txn *sql.Tx) error {
+		if err := pgLockRole(txn, owner); err != nil {
 			return err
 		}
 
-		if err = grantRoleDefaultPrivileges(txn, d); err != nil {
-			return err
-		}
-		return nil
+		// Needed in order to set the owner of the db if the connection user is not a superuser
+		return withRolesGranted(db, txn, []string{owner}, func() error {
+			// Revoke all privileges before granting otherwise reducing privileges will not work.
+			// We just have to revoke them in the same transaction so role will not lost his privileges
+			// between revoke and grant.
+			if err := revokeRoleDefaultPrivileges(txn, d); err != nil {
+				return err
+			}
+
+			if err := grantRoleDefaultPrivileges(txn, d); err != nil {
+				return err
+			}
+			return revokePublicDefaultExecute(txn, d)
+		})
 	}); err != nil {
 		return err
 	}
 
-	if err := txn.Commit(); err != nil {
-		return err
-	}
-
 	d.SetId(generateDefaultPrivilegesID(d))
 
-	txn, err = startTransaction(db.client, d.Get("database").(string))
+	txn, err := startTransaction(db.client, d.Get("database").(string))
 	if err != nil {
 		return err
 	}
 	defer deferredRollback(txn)
 
-	return readRoleDefaultPrivileges(txn, d)
+	return readRoleDefaultPrivileges(db, txn, d)
 }
 
 func resourcePostgreSQLDefaultPrivilegesDelete(db *DBConnection, d *schema.ResourceData) error {
@@ -185,31 +266,19 @@ func resourcePostgreSQLDefaultPrivilegesDelete(db *DBConnection, d *schema.Resou
 		)
 	}
 
-	txn, err := startTransaction(db.client, d.Get("database").(string))
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
-
-	if err := pgLockRole(txn, owner); err != nil {
-		return err
-	}
-
-	// Needed in order to set the owner of the db if the connection user is not a superuser
-	if err := withRolesGranted(txn, []string{owner}, func() error {
-		return revokeRoleDefaultPrivileges(txn, d)
-	}); err != nil {
-		return err
-	}
-
-	if err := txn.Commit(); err != nil {
-		return err
-	}
+	return withDefaultPrivilegesTx(db, d, func(txn *sql.Tx) error {
+		if err := pgLockRole(txn, owner); err != nil {
+			return err
+		}
 
-	return nil
+		// Needed in order to set the owner of the db if the connection user is not a superuser
+		return withRolesGranted(db, txn, []string{owner}, func() error {
+			return revokeRoleDefaultPrivileges(txn, d)
+		})
+	})
 }
 
-func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func readRoleDefaultPrivileges(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	role := d.Get("role").(string)
 	owner := d.Get("owner").(string)
 	pgSchema := d.Get("schema").(string)
@@ -220,43 +289,14 @@ func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 		return err
 	}
 
-	roleOID, err := getRoleOID(txn, role)
+	// The default privileges of the role and whether PUBLIC gets EXECUTE on the new functions
+	// (for revoke_public_execute_on_functions) are read from a scan of pg_default_acl shared
+	// with the other default privileges of the schema read at the same time.
+	entries, err := defaultACLScans.scan(defaultPrivilegesBatchKey(db, d), txn)
 	if err != nil {
 		return err
 	}
-
-	var query string
-	var queryArgs []interface{}
-
-	if pgSchema != "" {
-		query = `SELECT array_agg(prtype) FROM (
-		SELECT defaclnamespace, (aclexplode(defaclacl)).* FROM pg_default_acl
-		WHERE defaclobjtype = $3
-	) AS t (namespace, grantor_oid, grantee_oid, prtype, grantable)
-	JOIN pg_namespace ON pg_namespace.oid = namespace
-	WHERE grantee_oid = $1 AND nspname = $2 AND pg_get_userbyid(grantor_oid) = $4;
-`
-		queryArgs = []interface{}{roleOID, pgSchema, objectTypes[objectType], owner}
-	} else {
-		query = `SELECT array_agg(prtype) FROM (
-		SELECT defaclnamespace, (aclexplode(defaclacl)).* FROM pg_default_acl
-		WHERE defaclobjtype = $2
-	) AS t (namespace, grantor_oid, grantee_oid, prtype, grantable)
-	WHERE grantee_oid = $1 AND namespace = 0 AND pg_get_userbyid(grantor_oid) = $3;
-`
-		queryArgs = []interface{}{roleOID, objectTypes[objectType], owner}
-	}
-
-	// This query aggregates the list of default privileges type (prtype)
-	// for the role (grantee), owner (grantor), schema (namespace name)
-	// and the specified object type (defaclobjtype).
-
-	var privileges pq.ByteaArray
-	if err := txn.QueryRow(
-		query, queryArgs...,
-	).Scan(&privileges); err != nil {
-		return fmt.Errorf("could not read default privileges: %w", err)
-	}
+	privileges, grantable, publicExecute := defaultPrivilegesOf(entries, owner, role, objectTypes[objectType], pgSchema == "")
 
 	// We consider no privileges as "not exists" unless no privileges were provided as input
 	if len(privileges) == 0 {
@@ -267,8 +307,17 @@ func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 		}
 	}
 
-	privilegesSet := pgArrayToSet(privileges)
+	if d.Get("revoke_public_execute_on_functions").(bool) && publicExecute {
+		// PUBLIC gets EXECUTE on the new functions again, force an update to revoke it.
+		log.Printf("[DEBUG] the new functions of %s are executable by PUBLIC", owner)
+		d.Set("revoke_public_execute_on_functions", false)
+	}
+
+	privilegesSet := readPrivilegesSet(db, d, privileges)
 	d.Set("privileges", privilegesSet)
+	if len(privileges) > 0 {
+		d.Set("with_grant_option", grantable)
+	}
 	d.SetId(generateDefaultPrivilegesID(d))
 
 	return nil
@@ -317,6 +366,23 @@ func grantRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
+// revokePublicDefaultExecute revokes the default EXECUTE privilege of PUBLIC on the functions of the owner
+// if revoke_public_execute_on_functions is set. The per-schema default privileges are added to the global ones,
+// so it is revoked globally even if the default privileges of the resource are in a schema.
+func revokePublicDefaultExecute(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.Get("revoke_public_execute_on_functions").(bool) {
+		return nil
+	}
+	query := fmt.Sprintf(
+		"ALTER DEFAULT PRIVILEGES FOR ROLE %s REVOKE EXECUTE ON FUNCTIONS FROM PUBLIC",
+		pq.QuoteIdentifier(d.Get("owner").(string)),
+	)
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not revoke default EXECUTE privilege from PUBLIC: %w", err)
+	}
+	return nil
+}
+
 func revokeRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	pgSchema := d.Get("schema").(string)
 
@@ -340,6 +406,25 @@ func revokeRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
+// resourcePostgreSQLDefaultPrivilegesImport parses the import ID database/owner/schema/object_type/role,
+// the schema is empty for default privileges on all the schemas (e.g. db/owner//table/role).
+// The privileges are then read from pg_default_acl.
+func resourcePostgreSQLDefaultPrivilegesImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("default privileges ID %s has not the expected format 'database/owner/schema/object_type/role'", d.Id())
+	}
+
+	d.Set("database", parts[0])
+	d.Set("owner", parts[1])
+	d.Set("schema", parts[2])
+	d.Set("object_type", parts[3])
+	d.Set("role", parts[4])
+	d.SetId(generateDefaultPrivilegesID(d))
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func generateDefaultPrivilegesID(d *schema.ResourceData) string {
 	pgSchema := d.Get("schema").(string)
 	if pgSchema == "" {
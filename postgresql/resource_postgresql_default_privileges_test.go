@@ -1,13 +1,43 @@
 package postgresql
 
 import (
+	"database/sql"
 	"fmt"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 )
 
+func TestDefaultPrivilegesOf(t *testing.T) {
+	entries := []defaultACLEntry{
+		{owner: "owner", objectType: "r", grantor: "owner", grantee: "reader", privilege: "SELECT", grantable: true},
+		{owner: "owner", objectType: "r", grantor: "owner", grantee: "reader", privilege: "INSERT"},
+		{owner: "owner", global: true, objectType: "r", grantor: "owner", grantee: "reader", privilege: "UPDATE", grantable: true},
+		{owner: "other", objectType: "r", grantor: "other", grantee: "reader", privilege: "DELETE"},
+		{owner: "owner", global: true, objectType: "f", grantor: "owner", grantee: "owner", privilege: "EXECUTE"},
+	}
+
+	privileges, grantable, publicExecute := defaultPrivilegesOf(entries, "owner", "reader", "r", false)
+	assert.Equal(t, pq.ByteaArray{[]byte("SELECT"), []byte("INSERT")}, privileges)
+	assert.False(t, grantable)
+	// The global default privileges for functions of the owner don't include PUBLIC.
+	assert.False(t, publicExecute)
+
+	privileges, grantable, publicExecute = defaultPrivilegesOf(entries, "owner", "reader", "r", true)
+	assert.Equal(t, pq.ByteaArray{[]byte("UPDATE")}, privileges)
+	assert.True(t, grantable)
+	assert.False(t, publicExecute)
+
+	privileges, grantable, publicExecute = defaultPrivilegesOf(entries, "other", "writer", "r", false)
+	assert.Empty(t, privileges)
+	assert.False(t, grantable)
+	// Without global default privileges for functions, PUBLIC gets EXECUTE by default.
+	assert.True(t, publicExecute)
+}
+
 func TestAccPostgresqlDefaultPrivileges(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -100,6 +130,12 @@ resource "postgresql_default_privileges" "test_ro" {
 							resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "privileges.1", "UPDATE"),
 						),
 					},
+					{
+						ResourceName:      "postgresql_default_privileges.test_ro",
+						ImportState:       true,
+						ImportStateId:     fmt.Sprintf("%s/%s/test_schema/table/%s", dbName, config.Username, role),
+						ImportStateVerify: true,
+					},
 					{
 						Config: fmt.Sprintf(tfConfig, `[]`),
 						Check: resource.ComposeTestCheckFunc(
@@ -123,6 +159,59 @@ resource "postgresql_default_privileges" "test_ro" {
 	}
 }
 
+// Test that the grant option can be toggled without recreating the default privileges.
+func TestAccPostgresqlDefaultPrivileges_UpdateGrantOption(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var tfConfig = `
+resource "postgresql_default_privileges" "test_ro" {
+	database          = "%s"
+	owner             = "%s"
+	role              = "%s"
+	schema            = "test_schema"
+	object_type       = "table"
+	with_grant_option = %t
+	privileges        = ["SELECT"]
+}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, config.Username, roleName, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "with_grant_option", "false"),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "privileges.#", "1"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, config.Username, roleName, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "with_grant_option", "true"),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "privileges.#", "1"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, config.Username, roleName, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "with_grant_option", "false"),
+				),
+			},
+		},
+	})
+}
+
 // Test the case where we need to grant the owner to the connected user.
 // The owner should be revoked
 func TestAccPostgresqlDefaultPrivileges_GrantOwner(t *testing.T) {
@@ -350,3 +439,73 @@ resource "postgresql_default_privileges" "test_ro" {
 		})
 	}
 }
+
+func TestAccPostgresqlDefaultPrivileges_RevokePublicExecute(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	tfConfig := fmt.Sprintf(`
+resource "postgresql_default_privileges" "test" {
+	database                           = "%s"
+	owner                              = "%s"
+	role                               = "%s"
+	schema                             = "test_schema"
+	object_type                        = "function"
+	privileges                         = ["EXECUTE"]
+	revoke_public_execute_on_functions = true
+}
+`, dbName, config.Username, roleName)
+
+	// The new functions of the owner must be executable by the role only.
+	checkPublicExecute := func(*terraform.State) error {
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if _, err := db.Exec("CREATE FUNCTION test_schema.test() RETURNS int AS 'SELECT 1' LANGUAGE SQL"); err != nil {
+			return fmt.Errorf("could not create test function: %w", err)
+		}
+		defer db.Exec("DROP FUNCTION test_schema.test()")
+
+		var publicExecute, roleExecute bool
+		if err := db.QueryRow(
+			"SELECT has_function_privilege('public', 'test_schema.test()', 'EXECUTE'), has_function_privilege($1, 'test_schema.test()', 'EXECUTE')",
+			roleName,
+		).Scan(&publicExecute, &roleExecute); err != nil {
+			return err
+		}
+		if publicExecute || !roleExecute {
+			return fmt.Errorf("expected the new function to be executable by %s only, PUBLIC: %t, %s: %t", roleName, publicExecute, roleName, roleExecute)
+		}
+		return nil
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check:  checkPublicExecute,
+			},
+			// The default privilege granted back to PUBLIC outside of Terraform is revoked again.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s GRANT EXECUTE ON FUNCTIONS TO PUBLIC", config.Username))
+				},
+				Config: tfConfig,
+				Check:  checkPublicExecute,
+			},
+		},
+	})
+}
@@ -19,15 +19,27 @@ const (
 	extDatabaseAttr      = "database"
 	extDropCascadeAttr   = "drop_cascade"
 	extCreateCascadeAttr = "create_cascade"
+	extRequiredByAttr    = "required_by"
+
+	// extRequiredByQuery returns the extensions depending on the extension $1 (as listed in their `requires`).
+	extRequiredByQuery = `
+SELECT COALESCE(pg_catalog.array_agg(e.extname ORDER BY e.extname), '{}')
+FROM pg_catalog.pg_depend AS dep
+JOIN pg_catalog.pg_extension AS e ON e.oid = dep.objid
+WHERE dep.classid = 'pg_catalog.pg_extension'::regclass
+AND dep.refclassid = 'pg_catalog.pg_extension'::regclass
+AND dep.refobjid = (SELECT oid FROM pg_catalog.pg_extension WHERE extname = $1)
+AND dep.deptype = 'n'
+`
 )
 
 func resourcePostgreSQLExtension() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLExtensionCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLExtensionRead),
-		Update: PGResourceFunc(resourcePostgreSQLExtensionUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLExtensionDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLExtensionExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLExtensionCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLExtensionRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLExtensionUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLExtensionDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLExtensionExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -69,6 +81,13 @@ func resourcePostgreSQLExtension() *schema.Resource {
 				Default:     false,
 				Description: "When true, will also create any extensions that this extension depends on that are not already installed",
 			},
+			extRequiredByAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Description: "The extensions which depend on this one. Destroying the extension fails while there is any, " +
+					"unless `drop_cascade` is set (which drops them too)",
+			},
 		},
 	}
 }
@@ -99,24 +118,140 @@ func resourcePostgreSQLExtensionCreate(db *DBConnection, d *schema.ResourceData)
 		fmt.Fprint(b, " CASCADE")
 	}
 
-	txn, err := startTransaction(db.client, databaseName)
+	// An extension already installed (e.g. with CASCADE as a dependency of another extension) is adopted,
+	// unless another version is requested.
+	requestedVersion := d.Get(extVersionAttr).(string)
+	schemaName := d.Get(extSchemaAttr).(string)
+	err := db.client.withTx(databaseName, func(txn *sql.Tx) error {
+		installedVersion, err := getExtensionVersion(txn, extName)
+		if err != nil {
+			return err
+		}
+		if installedVersion != "" {
+			return checkAdoptedExtensionVersion(extName, installedVersion, requestedVersion)
+		}
+
+		// The scripts of some extensions (or of the extensions installed with CASCADE) create
+		// their objects in the first schema of the search_path rather than in the SCHEMA of CREATE EXTENSION.
+		if schemaName != "" {
+			if _, err := txn.Exec(fmt.Sprintf("SET LOCAL search_path TO %s", pq.QuoteIdentifier(schemaName))); err != nil {
+				return fmt.Errorf("could not set search_path to schema %s: %w", schemaName, err)
+			}
+		}
+		if _, err := txn.Exec(b.String()); err != nil {
+			return err
+		}
+		if schemaName != "" {
+			return reconcileExtensionSchema(txn, extName, schemaName)
+		}
+		return nil
+	})
+	if isDuplicateError(err) {
+		err = adoptConcurrentExtension(db, databaseName, extName, requestedVersion, err)
+	}
 	if err != nil {
-		return err
+		return fmt.Errorf("Error creating extension: %w", err)
 	}
-	defer deferredRollback(txn)
 
-	sql := b.String()
-	if _, err := txn.Exec(sql); err != nil {
+	d.SetId(generateExtensionID(d, databaseName))
+
+	return resourcePostgreSQLExtensionReadImpl(db, d)
+}
+
+// reconcileExtensionSchema moves the extension created to schemaName if it has been installed in another schema
+// (e.g. the one of its control file).
+func reconcileExtensionSchema(txn *sql.Tx, extName, schemaName string) error {
+	var installedSchema string
+	var relocatable bool
+	if err := txn.QueryRow(
+		"SELECT n.nspname, e.extrelocatable FROM pg_catalog.pg_extension AS e "+
+			"JOIN pg_catalog.pg_namespace AS n ON n.oid = e.extnamespace WHERE e.extname = $1",
+		extName,
+	).Scan(&installedSchema, &relocatable); err != nil {
+		return fmt.Errorf("could not read the schema of extension %s: %w", extName, err)
+	}
+
+	query, err := extensionSchemaReconcileQuery(extName, installedSchema, schemaName, relocatable)
+	if err != nil || query == "" {
 		return err
 	}
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not move extension %s to schema %s: %w", extName, schemaName, err)
+	}
+	return nil
+}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error creating extension: %w", err)
+// extensionSchemaReconcileQuery returns the statement moving the extension installed in installedSchema
+// to schemaName, if needed, or why it can't be moved.
+func extensionSchemaReconcileQuery(extName, installedSchema, schemaName string, relocatable bool) (string, error) {
+	if installedSchema == schemaName {
+		return "", nil
 	}
+	if !relocatable {
+		return "", fmt.Errorf(
+			"extension %s has been installed in schema %s instead of %s and is not relocatable, set schema to %s",
+			extName, installedSchema, schemaName, installedSchema,
+		)
+	}
+	return fmt.Sprintf("ALTER EXTENSION %s SET SCHEMA %s", pq.QuoteIdentifier(extName), pq.QuoteIdentifier(schemaName)), nil
+}
 
-	d.SetId(generateExtensionID(d, databaseName))
+// getExtensionVersion returns the installed version of the extension, or an empty string if it is not installed.
+func getExtensionVersion(txn *sql.Tx, extName string) (string, error) {
+	var version string
+	err := txn.QueryRow("SELECT extversion FROM pg_catalog.pg_extension WHERE extname = $1", extName).Scan(&version)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("could not read extension %s: %w", extName, err)
+	}
+	return version, nil
+}
 
-	return resourcePostgreSQLExtensionReadImpl(db, d)
+// checkAdoptedExtensionVersion returns an error if the extension is installed with another version than the requested one.
+func checkAdoptedExtensionVersion(extName, installedVersion, requestedVersion string) error {
+	if installedVersion == "" {
+		return fmt.Errorf("extension %s is not installed", extName)
+	}
+	if requestedVersion != "" && requestedVersion != installedVersion {
+		return fmt.Errorf(
+			"extension %s is already installed with version %s (e.g. as a dependency of another extension), not the requested version %s",
+			extName, installedVersion, requestedVersion,
+		)
+	}
+	log.Printf("[INFO] extension %s is already installed with version %s, adopting it", extName, installedVersion)
+	return nil
+}
+
+// adoptConcurrentExtension adopts the extension whose creation failed with the duplicate error createErr, if it
+// has been created by another transaction since it was checked. createErr is returned if it is not installed:
+// the duplicate is an object created by the script of the extension (e.g. a type which already exists).
+func adoptConcurrentExtension(db *DBConnection, databaseName, extName, requestedVersion string, createErr error) error {
+	return db.client.withTx(databaseName, func(txn *sql.Tx) error {
+		installedVersion, err := getExtensionVersion(txn, extName)
+		if err != nil {
+			return err
+		}
+		if installedVersion == "" && !isDuplicateExtensionError(createErr) {
+			return createErr
+		}
+		return checkAdoptedExtensionVersion(extName, installedVersion, requestedVersion)
+	})
+}
+
+// isDuplicateError returns true if err reports an object which already exists, the extension itself
+// or one of the objects created by its script.
+func isDuplicateError(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code.Name() == "unique_violation" || pqErr.Code.Name() == "duplicate_object")
+}
+
+// isDuplicateExtensionError returns true if err reports an extension created concurrently
+// by another transaction (duplicate in pg_extension_name_index).
+func isDuplicateExtensionError(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == "pg_extension_name_index"
 }
 
 func resourcePostgreSQLExtensionExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
@@ -195,10 +330,16 @@ func resourcePostgreSQLExtensionReadImpl(db *DBConnection, d *schema.ResourceDat
 		return fmt.Errorf("Error reading extension: %w", err)
 	}
 
+	var requiredBy []string
+	if err := txn.QueryRow(extRequiredByQuery, extName).Scan(pq.Array(&requiredBy)); err != nil {
+		return fmt.Errorf("could not read the extensions depending on extension %s: %w", extName, err)
+	}
+
 	d.Set(extNameAttr, extName)
 	d.Set(extSchemaAttr, extSchema)
 	d.Set(extVersionAttr, extVersion)
 	d.Set(extDatabaseAttr, database)
+	d.Set(extRequiredByAttr, requiredBy)
 	d.SetId(generateExtensionID(d, database))
 
 	return nil
@@ -249,26 +390,18 @@ func resourcePostgreSQLExtensionUpdate(db *DBConnection, d *schema.ResourceData)
 	}
 
 	database := getDatabaseForExtension(d, db.client.databaseName)
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		// Can't rename a schema
 
-	// Can't rename a schema
-
-	if err := setExtSchema(txn, d); err != nil {
-		return err
-	}
+		if err := setExtSchema(txn, d); err != nil {
+			return err
+		}
 
-	if err := setExtVersion(txn, d); err != nil {
+		return setExtVersion(txn, d)
+	}); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error updating extension: %w", err)
-	}
-
 	return resourcePostgreSQLExtensionReadImpl(db, d)
 }
 
@@ -0,0 +1,192 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	extMemberDatabaseAttr   = "database"
+	extMemberExtensionAttr  = "extension"
+	extMemberObjectTypeAttr = "object_type"
+	extMemberSchemaAttr     = "schema"
+	extMemberNameAttr       = "name"
+
+	// extensionMemberQuery returns whether the object $3 of the catalog $2 is a member of the extension $1.
+	extensionMemberQuery = `
+SELECT EXISTS (
+	SELECT 1 FROM pg_catalog.pg_depend AS d
+	JOIN pg_catalog.pg_extension AS e ON e.oid = d.refobjid
+	WHERE d.refclassid = 'pg_catalog.pg_extension'::regclass AND d.deptype = 'e'
+	AND e.extname = $1 AND d.classid = $2::regclass AND d.objid = $3
+)
+`
+)
+
+// extensionMemberObjectTypes are the catalogs of the object types which can be added to an extension.
+var extensionMemberObjectTypes = map[string]string{
+	"function": "pg_catalog.pg_proc",
+	"table":    "pg_catalog.pg_class",
+	"type":     "pg_catalog.pg_type",
+	"view":     "pg_catalog.pg_class",
+}
+
+func resourcePostgreSQLExtensionMember() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLExtensionMemberCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLExtensionMemberRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLExtensionMemberDelete),
+
+		Schema: map[string]*schema.Schema{
+			extMemberDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database of the extension. Defaults to the provider database",
+			},
+			extMemberExtensionAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The extension the object is added to",
+			},
+			extMemberObjectTypeAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"function", "table", "type", "view"}, false),
+				Description:  "The type of the object (one of: function, table, type, view)",
+			},
+			extMemberSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				ForceNew:    true,
+				Description: "The schema of the object",
+			},
+			extMemberNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description: "The name of the object. Functions can be specified with their argument types (e.g. `name(integer, text)`), " +
+					"which is required if the function is overloaded",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLExtensionMemberCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	extName := d.Get(extMemberExtensionAttr).(string)
+	object := extensionMemberObject(d)
+
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		if _, err := txn.Exec(alterExtensionMemberQuery(extName, "ADD", object)); err != nil {
+			return fmt.Errorf("could not add %s to extension %s: %w", object, extName, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.Set(extMemberDatabaseAttr, database)
+	d.SetId(strings.Join([]string{
+		database, extName, d.Get(extMemberObjectTypeAttr).(string), d.Get(extMemberSchemaAttr).(string), d.Get(extMemberNameAttr).(string),
+	}, "."))
+
+	return nil
+}
+
+// resourcePostgreSQLExtensionMemberRead removes the resource from the state if the object doesn't exist anymore
+// or is not a member of the extension anymore (e.g. dropped with ALTER EXTENSION ... DROP).
+func resourcePostgreSQLExtensionMemberRead(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	extName := d.Get(extMemberExtensionAttr).(string)
+	objectType := d.Get(extMemberObjectTypeAttr).(string)
+	object := extensionMemberObject(d)
+
+	var isMember bool
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		var oid uint32
+		err := txn.QueryRow(extensionMemberOIDQuery(objectType, d.Get(extMemberNameAttr).(string)), extensionMemberIdentifier(d)).Scan(&oid)
+		if err != nil {
+			return err
+		}
+		if oid == 0 {
+			log.Printf("[WARN] PostgreSQL %s %s not found in database %s", objectType, object, database)
+			return nil
+		}
+		return txn.QueryRow(extensionMemberQuery, extName, extensionMemberObjectTypes[objectType], oid).Scan(&isMember)
+	}); err != nil {
+		return fmt.Errorf("could not read membership of %s in extension %s: %w", object, extName, err)
+	}
+
+	if !isMember {
+		log.Printf("[WARN] PostgreSQL %s %s is not a member of extension %s", objectType, object, extName)
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourcePostgreSQLExtensionMemberDelete(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	extName := d.Get(extMemberExtensionAttr).(string)
+	object := extensionMemberObject(d)
+
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		if _, err := txn.Exec(alterExtensionMemberQuery(extName, "DROP", object)); err != nil {
+			return fmt.Errorf("could not drop %s from extension %s: %w", object, extName, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// extensionMemberIdentifier returns the qualified identifier of the object,
+// followed by its argument types for a function (e.g. `"public"."name"(integer)`).
+func extensionMemberIdentifier(d *schema.ResourceData) string {
+	schemaName := d.Get(extMemberSchemaAttr).(string)
+	name := d.Get(extMemberNameAttr).(string)
+
+	if d.Get(extMemberObjectTypeAttr).(string) == "function" {
+		return quoteFunctionObject(schemaName, name)
+	}
+	return quoteQualifiedIdentifier(schemaName, name)
+}
+
+// extensionMemberObject returns the object of ALTER EXTENSION, e.g. `FUNCTION "public"."name"(integer)`.
+func extensionMemberObject(d *schema.ResourceData) string {
+	return strings.ToUpper(d.Get(extMemberObjectTypeAttr).(string)) + " " + extensionMemberIdentifier(d)
+}
+
+func alterExtensionMemberQuery(extName, action, object string) string {
+	return fmt.Sprintf("ALTER EXTENSION %s %s %s", pq.QuoteIdentifier(extName), action, object)
+}
+
+// extensionMemberOIDQuery returns the query resolving the OID of the object from its identifier ($1),
+// 0 if it doesn't exist.
+func extensionMemberOIDQuery(objectType, name string) string {
+	resolve := "to_regclass"
+	switch {
+	case objectType == "type":
+		resolve = "to_regtype"
+	case objectType == "function" && isFunctionSignature(name):
+		resolve = "to_regprocedure"
+	case objectType == "function":
+		resolve = "to_regproc"
+	}
+	return fmt.Sprintf("SELECT COALESCE(%s($1)::oid, 0)", resolve)
+}
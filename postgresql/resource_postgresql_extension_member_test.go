@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensionMemberObject(t *testing.T) {
+	for _, test := range []struct {
+		objectType string
+		name       string
+		object     string
+		oidQuery   string
+	}{
+		{"function", "my_func", `FUNCTION "test_schema"."my_func"`, "SELECT COALESCE(to_regproc($1)::oid, 0)"},
+		{"function", "my_func(integer)", `FUNCTION "test_schema"."my_func"(integer)`, "SELECT COALESCE(to_regprocedure($1)::oid, 0)"},
+		{"function", `"my(func)"`, `FUNCTION "test_schema"."my(func)"`, "SELECT COALESCE(to_regproc($1)::oid, 0)"},
+		{"function", `"my(func)"(text)`, `FUNCTION "test_schema"."my(func)"(text)`, "SELECT COALESCE(to_regprocedure($1)::oid, 0)"},
+		{"table", "My Table", `TABLE "test_schema"."My Table"`, "SELECT COALESCE(to_regclass($1)::oid, 0)"},
+		{"type", "my_type", `TYPE "test_schema"."my_type"`, "SELECT COALESCE(to_regtype($1)::oid, 0)"},
+	} {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLExtensionMember().Schema, map[string]interface{}{
+			"extension":   "my_ext",
+			"object_type": test.objectType,
+			"schema":      "test_schema",
+			"name":        test.name,
+		})
+		assert.Equal(t, test.object, extensionMemberObject(d))
+		assert.Equal(t, test.oidQuery, extensionMemberOIDQuery(test.objectType, test.name))
+	}
+}
+
+func TestAlterExtensionMemberQuery(t *testing.T) {
+	assert.Equal(t, `ALTER EXTENSION "my_ext" ADD TABLE "public"."t"`, alterExtensionMemberQuery("my_ext", "ADD", `TABLE "public"."t"`))
+}
+
+func TestAccPostgresqlExtensionMember_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr(dbName), "CREATE EXTENSION cube")
+	dbExecute(t, config.connStr(dbName), "CREATE FUNCTION test_schema.member_func(integer) RETURNS integer LANGUAGE sql AS 'SELECT $1'")
+	dbExecute(t, config.connStr(dbName), "CREATE TABLE test_schema.member_table (id integer)")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(*terraform.State) error {
+			return testAccCheckExtensionMembersCount(t, dbName, "cube", 0)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_extension_member" "function" {
+	database    = "%[1]s"
+	extension   = "cube"
+	object_type = "function"
+	schema      = "test_schema"
+	name        = "member_func(integer)"
+}
+
+resource "postgresql_extension_member" "table" {
+	database    = "%[1]s"
+	extension   = "cube"
+	object_type = "table"
+	schema      = "test_schema"
+	name        = "member_table"
+}
+`, dbName),
+				Check: func(*terraform.State) error {
+					return testAccCheckExtensionMembersCount(t, dbName, "cube", 2)
+				},
+			},
+		},
+	})
+}
+
+// testAccCheckExtensionMembersCount checks the number of members of the extension in test_schema.
+func testAccCheckExtensionMembersCount(t *testing.T, dbName, extName string, expected int) error {
+	config := getTestConfig(t)
+	db, err := sql.Open("postgres", config.connStr(dbName))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow(`
+SELECT count(*) FROM pg_catalog.pg_depend AS d
+JOIN pg_catalog.pg_extension AS e ON e.oid = d.refobjid
+LEFT JOIN pg_catalog.pg_proc AS p ON d.classid = 'pg_catalog.pg_proc'::regclass AND p.oid = d.objid
+LEFT JOIN pg_catalog.pg_class AS c ON d.classid = 'pg_catalog.pg_class'::regclass AND c.oid = d.objid
+WHERE d.deptype = 'e' AND e.extname = $1
+AND COALESCE(p.pronamespace, c.relnamespace) = 'test_schema'::regnamespace
+`, extName).Scan(&count); err != nil {
+		return err
+	}
+	if count != expected {
+		return fmt.Errorf("expected %d members of extension %s in test_schema, got %d", expected, extName, count)
+	}
+	return nil
+}
@@ -2,11 +2,15 @@ package postgresql
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccPostgresqlExtension_Basic(t *testing.T) {
@@ -289,6 +293,146 @@ resource "postgresql_extension" "cascade" {
 	})
 }
 
+func TestAccPostgresqlExtension_RequiredBy(t *testing.T) {
+	skipIfNotAcc(t)
+
+	var testAccPostgresqlExtensionConfig = `
+resource "postgresql_extension" "cube" {
+  name = "cube"
+}
+
+resource "postgresql_extension" "earthdistance" {
+  name       = "earthdistance"
+  depends_on = [postgresql_extension.cube]
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlExtensionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_extension.earthdistance", "required_by.#", "0"),
+				),
+			},
+			// cube is read again once earthdistance depends on it
+			{
+				Config: testAccPostgresqlExtensionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_extension.cube", "required_by.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_extension.cube", "required_by.0", "earthdistance"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_AdoptInstalled(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := `
+resource "postgresql_extension" "adopted" {
+  name     = "cube"
+  database = "postgres"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				// Installed outside of the resource, e.g. with CASCADE by another extension.
+				PreConfig: func() {
+					dbConfig := getTestConfig(t)
+					dbExecute(t, dbConfig.connStr("postgres"), "CREATE EXTENSION cube")
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.adopted"),
+					resource.TestCheckResourceAttrSet("postgresql_extension.adopted", "version"),
+				),
+			},
+		},
+	})
+}
+
+func TestCheckAdoptedExtensionVersion(t *testing.T) {
+	if err := checkAdoptedExtensionVersion("cube", "1.5", ""); err != nil {
+		t.Errorf("unexpected error without requested version: %v", err)
+	}
+	if err := checkAdoptedExtensionVersion("cube", "1.5", "1.5"); err != nil {
+		t.Errorf("unexpected error with the installed version: %v", err)
+	}
+	if err := checkAdoptedExtensionVersion("cube", "1.5", "1.4"); err == nil {
+		t.Error("expected an error with another version than the installed one")
+	}
+}
+
+// Test that an extension is only adopted after a duplicate error if it has been created concurrently.
+func TestAdoptConcurrentExtension(t *testing.T) {
+	duplicateExtension := &pq.Error{
+		Code:       "23505",
+		Message:    `duplicate key value violates unique constraint "pg_extension_name_index"`,
+		Constraint: "pg_extension_name_index",
+	}
+	duplicateType := &pq.Error{Code: "42710", Message: `type "cube" already exists`}
+
+	for _, test := range []struct {
+		name             string
+		createErr        error
+		installedVersion string
+		err              string
+	}{
+		{name: "created concurrently", createErr: duplicateExtension, installedVersion: "1.5"},
+		{name: "dropped since", createErr: duplicateExtension, err: "extension cube is not installed"},
+		{name: "object of the script installed", createErr: duplicateType, installedVersion: "1.5"},
+		{name: "object of the script", createErr: duplicateType, err: `pq: type "cube" already exists`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+				if strings.HasPrefix(query, "SELECT extversion FROM pg_catalog.pg_extension") && test.installedVersion != "" {
+					return &fakeRows{values: [][]driver.Value{{test.installedVersion}}}, nil
+				}
+				return nil, nil
+			}}
+			db, err := newFakeClient(t, fake, "16.0.0").Connect()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = adoptConcurrentExtension(db, "postgres", "cube", "", test.createErr)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestExtensionSchemaReconcileQuery(t *testing.T) {
+	if query, err := extensionSchemaReconcileQuery("cube", "ext", "ext", false); err != nil || query != "" {
+		t.Errorf("nothing should be done if the extension is in the requested schema, got %q, %v", query, err)
+	}
+	if query, err := extensionSchemaReconcileQuery("cube", "public", "ext", true); err != nil || query != `ALTER EXTENSION "cube" SET SCHEMA "ext"` {
+		t.Errorf("a relocatable extension should be moved to the requested schema, got %q, %v", query, err)
+	}
+	if _, err := extensionSchemaReconcileQuery("cube", "public", "ext", false); err == nil || !strings.Contains(err.Error(), "set schema to public") {
+		t.Errorf("expected an error for a non relocatable extension, got %v", err)
+	}
+}
+
 func testAccCheckExtensionDependency(extName string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 
@@ -9,7 +9,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/lib/pq"
 )
 
 const (
@@ -37,11 +36,11 @@ const (
 
 func resourcePostgreSQLFunction() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLFunctionCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLFunctionRead),
-		Update: PGResourceFunc(resourcePostgreSQLFunctionUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLFunctionDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLFunctionExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLFunctionCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLFunctionRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLFunctionUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLFunctionDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLFunctionExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -391,9 +390,7 @@ func createFunction(db *DBConnection, d *schema.ResourceData, replace bool) erro
 
 	b.WriteString("FUNCTION ")
 
-	fmt.Fprint(b, pq.QuoteIdentifier(pgFunction.Schema), ".")
-
-	fmt.Fprint(b, pq.QuoteIdentifier(pgFunction.Name), " (")
+	fmt.Fprint(b, quoteQualifiedIdentifier(pgFunction.Schema, pgFunction.Name), " (")
 
 	for i, arg := range pgFunction.Args {
 		if i > 0 {
@@ -464,9 +461,9 @@ func generateFunctionID(db *DBConnection, d *schema.ResourceData) (string, error
 	b := bytes.NewBufferString("")
 
 	if dbAttr, ok := d.GetOk(funcDatabaseAttr); ok {
-		fmt.Fprint(b, dbAttr.(string), ".")
+		fmt.Fprint(b, quoteIdentifierIfNeeded(dbAttr.(string)), ".")
 	} else {
-		fmt.Fprint(b, db.client.databaseName, ".")
+		fmt.Fprint(b, quoteIdentifierIfNeeded(db.client.databaseName), ".")
 	}
 
 	var pgFunction PGFunction
@@ -475,7 +472,7 @@ func generateFunctionID(db *DBConnection, d *schema.ResourceData) (string, error
 		return "", err
 	}
 
-	fmt.Fprint(b, pgFunction.Schema, ".", pgFunction.Name, "(")
+	fmt.Fprint(b, quoteIdentifierIfNeeded(pgFunction.Schema), ".", quoteIdentifierIfNeeded(pgFunction.Name), "(")
 
 	argCount := 0
 
@@ -502,29 +499,22 @@ func generateFunctionID(db *DBConnection, d *schema.ResourceData) (string, error
 
 func expandFunctionID(functionId string, d *schema.ResourceData, db *DBConnection) (databaseName string, functionSignature string, err error) {
 
-	partsCount := strings.Count(functionId, ".") + 1
+	parts, args, err := splitFunctionSignature(functionId)
+	if err != nil {
+		return "", "", fmt.Errorf("function ID %s has not the expected format 'database.schema.function_name(arguments)': %w", functionId, err)
+	}
 
-	if partsCount == 2 {
+	if len(parts) == 2 {
 		clientDatabaseName := "postgres"
 		if db != nil {
 			clientDatabaseName = db.client.databaseName
 		}
 
-		signature, err := quoteSignature(functionId)
-		if err != nil {
-			return "", "", err
-		}
-
-		return getDatabase(d, clientDatabaseName), signature, nil
+		return getDatabase(d, clientDatabaseName), formatFunctionSignature(parts[0], parts[1], args), nil
 	}
 
-	if partsCount == 3 {
-		functionIdParts := strings.Split(functionId, ".")
-		signature, err := quoteSignature(strings.Join(functionIdParts[1:], "."))
-		if err != nil {
-			return "", "", err
-		}
-		return functionIdParts[0], signature, nil
+	if len(parts) == 3 {
+		return parts[0], formatFunctionSignature(parts[1], parts[2], args), nil
 	}
 
 	return "", "", fmt.Errorf("function ID %s has not the expected format 'database.schema.function_name(arguments)'", functionId)
@@ -532,14 +522,49 @@ func expandFunctionID(functionId string, d *schema.ResourceData, db *DBConnectio
 
 func quoteSignature(s string) (signature string, err error) {
 
-	signatureData := findStringSubmatchMap(`(?si)(?P<Schema>[^\.]+)\.(?P<Name>[^(]+)\((?P<Args>.*)\)`, s)
+	parts, args, err := splitFunctionSignature(s)
+	if err != nil || len(parts) != 2 {
+		return "", fmt.Errorf("Incorrect signature format \"%s\". The expected format is schema.function_name(arguments)", s)
+	}
+
+	return formatFunctionSignature(parts[0], parts[1], args), nil
+}
 
-	schemaName, schemaFound := signatureData["Schema"]
-	name, nameFound := signatureData["Name"]
-	args, argsFound := signatureData["Args"]
-	if schemaFound && nameFound && argsFound {
-		return fmt.Sprintf("%s.%s(%s)", pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(name), args), nil
+// splitFunctionSignature splits a signature like `schema.function_name(arguments)` in
+// the parts of the qualified function name and its arguments.
+// Dots and parentheses are allowed in the names if they are double quoted.
+func splitFunctionSignature(s string) (parts []string, args string, err error) {
+	argsStart := -1
+	quoted := false
+	for i, c := range s {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == '(' && !quoted {
+			argsStart = i
+			break
+		}
+	}
+
+	argsEnd := strings.LastIndex(s, ")")
+	if argsStart < 0 || argsEnd < argsStart {
+		return nil, "", fmt.Errorf("arguments not found in %q", s)
+	}
+
+	parts, err = splitQualifiedIdentifier(s[:argsStart])
+	if err != nil {
+		return nil, "", err
 	}
 
-	return "", fmt.Errorf("Incorrect signature format \"%s\". The expected format is schema.function_name(arguments)", s)
+	return parts, s[argsStart+1 : argsEnd], nil
+}
+
+// isFunctionSignature returns whether s is followed by its argument types, which
+// are not looked for in the double quoted names (e.g. `"name(1)"`).
+func isFunctionSignature(s string) bool {
+	_, _, err := splitFunctionSignature(s)
+	return err == nil
+}
+
+func formatFunctionSignature(schemaName, name, args string) string {
+	return fmt.Sprintf("%s(%s)", quoteQualifiedIdentifier(schemaName, name), args)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccPostgresqlFunction_Basic(t *testing.T) {
@@ -302,3 +303,35 @@ func checkFunctionExists(txn *sql.Tx, signature string) (bool, error) {
 
 	return _rez, nil
 }
+
+func TestExpandFunctionID(t *testing.T) {
+	databaseName, signature, err := expandFunctionID("mydb.public.increment(integer)", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "mydb", databaseName)
+	assert.Equal(t, `"public"."increment"(integer)`, signature)
+
+	databaseName, signature, err = expandFunctionID(`mydb."My.Schema"."Func"(public.my_type, numeric(10,2))`, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "mydb", databaseName)
+	assert.Equal(t, `"My.Schema"."Func"(public.my_type, numeric(10,2))`, signature)
+
+	databaseName, signature, err = expandFunctionID(`"my.db"."sch""ema"."f(x)"()`, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "my.db", databaseName)
+	assert.Equal(t, `"sch""ema"."f(x)"()`, signature)
+
+	_, _, err = expandFunctionID("increment(integer)", nil, nil)
+	assert.Error(t, err)
+
+	_, _, err = expandFunctionID("mydb.public.increment", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestQuoteSignature(t *testing.T) {
+	signature, err := quoteSignature("Public.Increment(integer)")
+	assert.NoError(t, err)
+	assert.Equal(t, `"Public"."Increment"(integer)`, signature)
+
+	_, err = quoteSignature("increment(integer)")
+	assert.Error(t, err)
+}
@@ -1,10 +1,13 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -36,12 +39,15 @@ var objectTypes = map[string]string{
 
 func resourcePostgreSQLGrant() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLGrantCreate),
-		// Since all of this resource's arguments force a recreation
-		// there's no need for an Update function
-		// Update:
-		Read:   PGResourceFunc(resourcePostgreSQLGrantRead),
-		Delete: PGResourceFunc(resourcePostgreSQLGrantDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLGrantCreate),
+		// Only privileges can be updated, all the other arguments force a recreation.
+		UpdateContext: PGResourceFunc(resourcePostgreSQLGrantUpdate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLGrantRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLGrantDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLGrantImport,
+		},
+		CustomizeDiff: resourcePostgreSQLGrantCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -60,7 +66,7 @@ func resourcePostgreSQLGrant() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				ForceNew:    true,
-				Description: "The database schema to grant privileges on for this role",
+				Description: "The database schema to grant privileges on for this role. It can be omitted for object_type table if all the `objects` are qualified with their schema (e.g. `schema.table`)",
 			},
 			"object_type": {
 				Type:         schema.TypeString,
@@ -70,12 +76,13 @@ func resourcePostgreSQLGrant() *schema.Resource {
 				Description:  "The PostgreSQL object type to grant the privileges on (one of: " + strings.Join(allowedObjectTypes, ", ") + ")",
 			},
 			"objects": {
-				Type:        schema.TypeSet,
-				Optional:    true,
-				ForceNew:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				Set:         schema.HashString,
-				Description: "The specific objects to grant privileges on for this role (empty means all objects of the requested type)",
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+				Description: "The specific objects to grant privileges on for this role (empty means all objects of the requested type). " +
+					"Functions, procedures and routines can be specified with their argument types (e.g. `name(integer, text)`) to target a specific overload",
 			},
 			"columns": {
 				Type:        schema.TypeSet,
@@ -88,10 +95,9 @@ func resourcePostgreSQLGrant() *schema.Resource {
 			"privileges": {
 				Type:        schema.TypeSet,
 				Required:    true,
-				ForceNew:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Set:         schema.HashString,
-				Description: "The list of privileges to grant",
+				Description: "The list of privileges to grant, `[\"ALL\"]` for all the privileges of the object type supported by the server (the `MAINTAIN` table privilege requires PostgreSQL 17). Changing them only grants the added privileges and revokes the removed ones",
 			},
 			"with_grant_option": {
 				Type:        schema.TypeBool,
@@ -100,6 +106,46 @@ func resourcePostgreSQLGrant() *schema.Resource {
 				Default:     false,
 				Description: "Permit the grant recipient to grant it to others",
 			},
+			"include_partitions": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+				Description: "When granting on all tables of the schema, whether to include the partitions of partitioned tables (only for object_type table)",
+			},
+			"recurse_partitions": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Also grant the privileges on the partitions of the partitioned tables listed in `objects` (only for object_type table)",
+			},
+			"revoke_public_execute_on_functions": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Also revoke EXECUTE from PUBLIC on the objects of the grant, which new functions grant by default " +
+					"(only for object_type function, procedure and routine). It is not granted back when the resource is destroyed",
+			},
+			"revoke_public_usage": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Also revoke USAGE from PUBLIC on the schema, e.g. to isolate the schemas of tenants " +
+					"(only for object_type schema). It is not granted back when the resource is destroyed",
+			},
+			"expires_at": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+				Description: "Date and time (RFC 3339, e.g. `2030-01-31T18:00:00Z`) after which the privileges are revoked by the next apply. " +
+					"There is no background process: the role keeps them until then",
+			},
+			"expired": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the privileges have been revoked because `expires_at` has passed",
+			},
 		},
 	}
 }
@@ -118,14 +164,20 @@ func resourcePostgreSQLGrantRead(db *DBConnection, d *schema.ResourceData) error
 		return nil
 	}
 	d.SetId(generateGrantID(d))
+	d.Set("expired", d.Get("expired").(bool))
 
-	txn, err := startTransaction(db.client, d.Get("database").(string))
+	// The privileges of an expired grant have been revoked on purpose, the configured ones are kept.
+	if d.Get("expired").(bool) {
+		return nil
+	}
+
+	txn, err := startCachedTransaction(db.client, d.Get("database").(string))
 	if err != nil {
 		return err
 	}
-	defer deferredRollback(txn)
+	defer deferredRollback(txn.Tx)
 
-	return readRolePrivileges(txn, d)
+	return readRolePrivileges(db, txn, d)
 }
 
 func resourcePostgreSQLGrantCreate(db *DBConnection, d *schema.ResourceData) error {
@@ -135,9 +187,18 @@ func resourcePostgreSQLGrantCreate(db *DBConnection, d *schema.ResourceData) err
 
 	// Validate parameters.
 	objectType := d.Get("object_type").(string)
-	if d.Get("schema").(string) == "" && !sliceContainsStr([]string{"database", "foreign_data_wrapper", "foreign_server"}, objectType) {
+	if d.Get("schema").(string) == "" && !sliceContainsStr([]string{"database", "foreign_data_wrapper", "foreign_server"}, objectType) &&
+		!isCrossSchemaTableGrant(d) {
 		return fmt.Errorf("parameter 'schema' is mandatory for postgresql_grant resource")
 	}
+	if isCrossSchemaTableGrant(d) {
+		if _, err := parseCrossSchemaTables(d.Get("objects").(*schema.Set)); err != nil {
+			return err
+		}
+		if d.Get("recurse_partitions").(bool) {
+			return fmt.Errorf("`recurse_partitions` cannot be used when `schema` is not set")
+		}
+	}
 	if d.Get("objects").(*schema.Set).Len() > 0 && (objectType == "database" || objectType == "schema") {
 		return fmt.Errorf("cannot specify `objects` when `object_type` is `database` or `schema`")
 	}
@@ -156,61 +217,143 @@ func resourcePostgreSQLGrantCreate(db *DBConnection, d *schema.ResourceData) err
 	if d.Get("objects").(*schema.Set).Len() != 1 && (objectType == "foreign_data_wrapper" || objectType == "foreign_server") {
 		return fmt.Errorf("one element must be specified in `objects` when `object_type` is `foreign_data_wrapper` or `foreign_server`")
 	}
-	if err := validatePrivileges(d); err != nil {
+	if (!d.Get("include_partitions").(bool) || d.Get("recurse_partitions").(bool)) && !db.featureSupported(featurePartitionedTables) {
+		return fmt.Errorf("`include_partitions` and `recurse_partitions` are not supported for this Postgres version (%s)", db.version)
+	}
+	if (!d.Get("include_partitions").(bool) || d.Get("recurse_partitions").(bool)) && objectType != "table" {
+		return fmt.Errorf("`include_partitions` and `recurse_partitions` can only be used when `object_type` is `table`")
+	}
+	if d.Get("recurse_partitions").(bool) && d.Get("objects").(*schema.Set).Len() == 0 {
+		return fmt.Errorf("must specify `objects` when using `recurse_partitions`")
+	}
+	if err := validateRevokePublicExecute(d); err != nil {
+		return err
+	}
+	if err := validateRevokePublicUsage(d); err != nil {
+		return err
+	}
+	if err := validatePrivileges(db, d); err != nil {
 		return err
 	}
 
-	database := d.Get("database").(string)
+	if grantExpired(d.Get("expires_at").(string), time.Now()) {
+		return fmt.Errorf("cannot grant privileges to %s: expires_at %s is in the past", d.Get("role").(string), d.Get("expires_at").(string))
+	}
 
-	txn, err := startTransaction(db.client, database)
+	if err := setGrantPrivileges(db, d); err != nil {
+		return err
+	}
+
+	d.SetId(generateGrantID(d))
+	d.Set("expired", false)
+
+	txn, err := startTransaction(db.client, d.Get("database").(string))
 	if err != nil {
 		return err
 	}
 	defer deferredRollback(txn)
 
-	role := d.Get("role").(string)
-	if err := pgLockRole(txn, role); err != nil {
+	return readRolePrivileges(db, txn, d)
+}
+
+func resourcePostgreSQLGrantUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if err := validateFeatureSupport(db, d); err != nil {
+		return fmt.Errorf("feature is not supported: %v", err)
+	}
+	if err := validatePrivileges(db, d); err != nil {
+		return err
+	}
+	if d.Get("object_type").(string) == "column" && d.Get("privileges").(*schema.Set).Len() != 1 {
+		return fmt.Errorf("must specify exactly 1 `privileges` when `object_type` is `column`")
+	}
+	if err := validateRevokePublicExecute(d); err != nil {
+		return err
+	}
+	if err := validateRevokePublicUsage(d); err != nil {
 		return err
 	}
 
-	if objectType == "database" {
-		if err := pgLockDatabase(txn, database); err != nil {
-			return err
+	database := d.Get("database").(string)
+	if d.Get("expired").(bool) {
+		if !d.HasChange("expired") {
+			return nil
 		}
+		log.Printf("[INFO] grant %s has expired at %s, revoking its privileges", d.Id(), d.Get("expires_at").(string))
+		return revokeGrantPrivileges(db, d)
 	}
+	if d.HasChange("expired") {
+		// expires_at has been moved to the future, all the privileges are granted again.
+		if err := setGrantPrivileges(db, d); err != nil {
+			return err
+		}
+		txn, err := startTransaction(db.client, database)
+		if err != nil {
+			return err
+		}
+		defer deferredRollback(txn)
 
-	owners, err := getRolesToGrant(txn, d)
-	if err != nil {
-		return err
+		return readRolePrivileges(db, txn, d)
 	}
-	if err := withRolesGranted(txn, owners, func() error {
-		// Revoke all privileges before granting otherwise reducing privileges will not work.
-		// We just have to revoke them in the same transaction so the role will not lost its
-		// privileges between the revoke and grant statements.
-		if err := revokeRolePrivileges(txn, d); err != nil {
+
+	if err := withGrantTx(db, d, func(txn *sql.Tx) error {
+		role := d.Get("role").(string)
+		if err := pgLockRole(txn, role); err != nil {
 			return err
 		}
-		if err := grantRolePrivileges(txn, d); err != nil {
+
+		if d.Get("object_type").(string) == "database" {
+			if err := pgLockDatabase(txn, database); err != nil {
+				return err
+			}
+		}
+
+		owners, err := getRolesToGrant(txn, d)
+		if err != nil {
 			return err
 		}
-		return nil
+
+		oldPrivileges, newPrivileges := d.GetChange("privileges")
+		toGrant, toRevoke := grantPrivilegesDiff(oldPrivileges.(*schema.Set), newPrivileges.(*schema.Set))
+
+		// Only the privileges which changed are granted or revoked,
+		// so the role never loses the privileges it keeps (even for the other transactions).
+		return withRolesGranted(db, txn, owners, func() error {
+			if err := revokePrivileges(txn, d, toRevoke); err != nil {
+				return err
+			}
+			if err := grantPrivileges(txn, d, toGrant); err != nil {
+				return err
+			}
+			if err := revokePublicExecute(txn, d); err != nil {
+				return err
+			}
+			return revokePublicUsage(txn, d)
+		})
 	}); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
-	}
-
-	d.SetId(generateGrantID(d))
-
-	txn, err = startTransaction(db.client, database)
+	txn, err := startTransaction(db.client, database)
 	if err != nil {
 		return err
 	}
 	defer deferredRollback(txn)
 
-	return readRolePrivileges(txn, d)
+	return readRolePrivileges(db, txn, d)
+}
+
+// grantPrivilegesDiff returns the privileges to grant and to revoke to go from oldPrivileges to newPrivileges.
+func grantPrivilegesDiff(oldPrivileges, newPrivileges *schema.Set) ([]string, []string) {
+	var toGrant, toRevoke []string
+	for _, priv := range newPrivileges.Difference(oldPrivileges).List() {
+		toGrant = append(toGrant, priv.(string))
+	}
+	for _, priv := range oldPrivileges.Difference(newPrivileges).List() {
+		toRevoke = append(toRevoke, priv.(string))
+	}
+	sort.Strings(toGrant)
+	sort.Strings(toRevoke)
+	return toGrant, toRevoke
 }
 
 func resourcePostgreSQLGrantDelete(db *DBConnection, d *schema.ResourceData) error {
@@ -218,49 +361,163 @@ func resourcePostgreSQLGrantDelete(db *DBConnection, d *schema.ResourceData) err
 		return fmt.Errorf("feature is not supported: %v", err)
 	}
 
+	return revokeGrantPrivileges(db, d)
+}
+
+// setGrantPrivileges revokes all the privileges of the role then grants the ones configured.
+func setGrantPrivileges(db *DBConnection, d *schema.ResourceData) error {
 	database := d.Get("database").(string)
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
+	return withGrantTx(db, d, func(txn *sql.Tx) error {
+		role := d.Get("role").(string)
+		if err := pgLockRole(txn, role); err != nil {
+			return err
+		}
 
-	role := d.Get("role").(string)
-	if err := pgLockRole(txn, role); err != nil {
-		return err
-	}
+		if d.Get("object_type").(string) == "database" {
+			if err := pgLockDatabase(txn, database); err != nil {
+				return err
+			}
+		}
 
-	objectType := d.Get("object_type").(string)
-	if objectType == "database" {
-		if err := pgLockDatabase(txn, database); err != nil {
+		owners, err := getRolesToGrant(txn, d)
+		if err != nil {
 			return err
 		}
-	}
+		return withRolesGranted(db, txn, owners, func() error {
+			// Revoke all privileges before granting otherwise reducing privileges will not work.
+			// We just have to revoke them in the same transaction so the role will not lost its
+			// privileges between the revoke and grant statements.
+			if err := revokeRolePrivileges(txn, d); err != nil {
+				return err
+			}
+			if err := grantRolePrivileges(txn, d); err != nil {
+				return err
+			}
+			if err := revokePublicExecute(txn, d); err != nil {
+				return err
+			}
+			return revokePublicUsage(txn, d)
+		})
+	})
+}
 
-	owners, err := getRolesToGrant(txn, d)
-	if err != nil {
-		return err
+// revokeGrantPrivileges revokes all the privileges of the role.
+func revokeGrantPrivileges(db *DBConnection, d *schema.ResourceData) error {
+	database := d.Get("database").(string)
+	return withGrantTx(db, d, func(txn *sql.Tx) error {
+		role := d.Get("role").(string)
+		if err := pgLockRole(txn, role); err != nil {
+			return err
+		}
+
+		objectType := d.Get("object_type").(string)
+		if objectType == "database" {
+			if err := pgLockDatabase(txn, database); err != nil {
+				return err
+			}
+		}
+
+		owners, err := getRolesToGrant(txn, d)
+		if err != nil {
+			return err
+		}
+
+		return withRolesGranted(db, txn, owners, func() error {
+			return revokeRolePrivileges(txn, d)
+		})
+	})
+}
+
+// validateRevokePublicExecute checks that revoke_public_execute_on_functions is only set on function grants.
+func validateRevokePublicExecute(d *schema.ResourceData) error {
+	if !d.Get("revoke_public_execute_on_functions").(bool) {
+		return nil
+	}
+	if !sliceContainsStr([]string{"function", "procedure", "routine"}, d.Get("object_type").(string)) {
+		return fmt.Errorf("`revoke_public_execute_on_functions` can only be used when `object_type` is `function`, `procedure` or `routine`")
 	}
+	if strings.ToLower(d.Get("role").(string)) == publicRole {
+		return fmt.Errorf("`revoke_public_execute_on_functions` cannot be used when `role` is `public`")
+	}
+	return nil
+}
 
-	if err := withRolesGranted(txn, owners, func() error {
-		return revokeRolePrivileges(txn, d)
-	}); err != nil {
-		return err
+// revokePublicExecute revokes EXECUTE from PUBLIC on the objects of the grant if revoke_public_execute_on_functions is set.
+func revokePublicExecute(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.Get("revoke_public_execute_on_functions").(bool) {
+		return nil
 	}
+	if _, err := txn.Exec(createRevokePublicExecuteQuery(d)); err != nil {
+		return fmt.Errorf("could not revoke EXECUTE from PUBLIC: %w", err)
+	}
+	return nil
+}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
+// validateRevokePublicUsage checks that revoke_public_usage is only set on schema grants.
+func validateRevokePublicUsage(d *schema.ResourceData) error {
+	if !d.Get("revoke_public_usage").(bool) {
+		return nil
 	}
+	if d.Get("object_type").(string) != "schema" {
+		return fmt.Errorf("`revoke_public_usage` can only be used when `object_type` is `schema`")
+	}
+	if strings.ToLower(d.Get("role").(string)) == publicRole {
+		return fmt.Errorf("`revoke_public_usage` cannot be used when `role` is `public`")
+	}
+	return nil
+}
 
+// revokePublicUsage revokes USAGE from PUBLIC on the schema of the grant if revoke_public_usage is set.
+func revokePublicUsage(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.Get("revoke_public_usage").(bool) {
+		return nil
+	}
+	query := fmt.Sprintf("REVOKE USAGE ON SCHEMA %s FROM PUBLIC", pq.QuoteIdentifier(d.Get("schema").(string)))
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not revoke USAGE from PUBLIC: %w", err)
+	}
 	return nil
 }
 
-func readDatabaseRolePriviges(txn *sql.Tx, d *schema.ResourceData, roleOID uint32) error {
+func createRevokePublicExecuteQuery(d *schema.ResourceData) string {
+	objectType := strings.ToUpper(d.Get("object_type").(string))
+	if d.Get("objects").(*schema.Set).Len() > 0 {
+		return fmt.Sprintf("REVOKE EXECUTE ON %s %s FROM PUBLIC", objectType, grantObjectsList(d))
+	}
+	return fmt.Sprintf("REVOKE EXECUTE ON ALL %sS IN SCHEMA %s FROM PUBLIC", objectType, pq.QuoteIdentifier(d.Get("schema").(string)))
+}
+
+// grantExpired returns true if expiresAt (RFC 3339) is set and not after now.
+func grantExpired(expiresAt string, now time.Time) bool {
+	if expiresAt == "" {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, expiresAt)
+	return err == nil && !now.Before(t)
+}
+
+// resourcePostgreSQLGrantCustomizeDiff plans the revocation of the privileges once
+// expires_at has passed, or their grant again if it has been moved to the future.
+func resourcePostgreSQLGrantCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if diff.Id() == "" {
+		return nil
+	}
+
+	expired := grantExpired(diff.Get("expires_at").(string), time.Now())
+	if expired == diff.Get("expired").(bool) {
+		return nil
+	}
+	return diff.SetNew("expired", expired)
+}
+
+func readDatabaseRolePriviges(db *DBConnection, txn QueryAble, d *schema.ResourceData, roleOID uint32) error {
 	dbName := d.Get("database").(string)
+	// A NULL datacl means the database still has the default privileges
+	// (including CONNECT and TEMPORARY for PUBLIC), use acldefault to get them.
 	query := `
 SELECT array_agg(privilege_type)
 FROM (
-	SELECT (aclexplode(datacl)).* FROM pg_database WHERE datname=$1
+	SELECT (aclexplode(COALESCE(datacl, acldefault('d', datdba)))).* FROM pg_database WHERE datname=$1
 ) as privileges
 WHERE grantee = $2
 `
@@ -270,30 +527,41 @@ WHERE grantee = $2
 		return fmt.Errorf("could not read privileges for database %s: %w", dbName, err)
 	}
 
-	d.Set("privileges", pgArrayToSet(privileges))
+	d.Set("privileges", readPrivilegesSet(db, d, privileges))
 	return nil
 }
 
-func readSchemaRolePriviges(txn *sql.Tx, d *schema.ResourceData, roleOID uint32) error {
+// readSchemaRolePriviges reads the privileges of the role on the schema.
+// A NULL nspacl means the default privileges (owner only, nothing for PUBLIC), while the ACL of the
+// public schema is set explicitly by initdb, and depends on the server version:
+// PUBLIC has CREATE and USAGE until PostgreSQL 14, only USAGE since PostgreSQL 15.
+func readSchemaRolePriviges(db *DBConnection, txn QueryAble, d *schema.ResourceData, roleOID uint32) error {
 	dbName := d.Get("schema").(string)
+	// PUBLIC is the grantee 0 of the ACL.
 	query := `
-SELECT array_agg(privilege_type)
+SELECT array_agg(privilege_type) FILTER (WHERE grantee = $2), COALESCE(bool_or(grantee = 0 AND privilege_type = 'USAGE'), false)
 FROM (
-	SELECT (aclexplode(nspacl)).* FROM pg_namespace WHERE nspname=$1
+	SELECT (aclexplode(COALESCE(nspacl, acldefault('n', nspowner)))).* FROM pg_namespace WHERE nspname=$1
 ) as privileges
-WHERE grantee = $2
 `
 
 	var privileges pq.ByteaArray
-	if err := txn.QueryRow(query, dbName, roleOID).Scan(&privileges); err != nil {
+	var publicUsage bool
+	if err := txn.QueryRow(query, dbName, roleOID).Scan(&privileges, &publicUsage); err != nil {
 		return fmt.Errorf("could not read privileges for schema %s: %w", dbName, err)
 	}
 
-	d.Set("privileges", pgArrayToSet(privileges))
+	if publicUsage && d.Get("revoke_public_usage").(bool) {
+		// PUBLIC has USAGE on the schema again, force an update to revoke it.
+		log.Printf("[DEBUG] schema %s is usable by PUBLIC", dbName)
+		d.Set("revoke_public_usage", false)
+	}
+
+	d.Set("privileges", readPrivilegesSet(db, d, privileges))
 	return nil
 }
 
-func readForeignDataWrapperRolePrivileges(txn *sql.Tx, d *schema.ResourceData, roleOID uint32) error {
+func readForeignDataWrapperRolePrivileges(db *DBConnection, txn QueryAble, d *schema.ResourceData, roleOID uint32) error {
 	objects := d.Get("objects").(*schema.Set).List()
 	fdwName := objects[0].(string)
 	query := `
@@ -309,11 +577,11 @@ WHERE grantee = $2
 		return fmt.Errorf("could not read privileges for foreign data wrapper %s: %w", fdwName, err)
 	}
 
-	d.Set("privileges", pgArrayToSet(privileges))
+	d.Set("privileges", readPrivilegesSet(db, d, privileges))
 	return nil
 }
 
-func readForeignServerRolePrivileges(txn *sql.Tx, d *schema.ResourceData, roleOID uint32) error {
+func readForeignServerRolePrivileges(db *DBConnection, txn QueryAble, d *schema.ResourceData, roleOID uint32) error {
 	objects := d.Get("objects").(*schema.Set).List()
 	srvName := objects[0].(string)
 	query := `
@@ -329,11 +597,11 @@ WHERE grantee = $2
 		return fmt.Errorf("could not read privileges for foreign server %s: %w", srvName, err)
 	}
 
-	d.Set("privileges", pgArrayToSet(privileges))
+	d.Set("privileges", readPrivilegesSet(db, d, privileges))
 	return nil
 }
 
-func readColumnRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func readColumnRolePrivileges(db *DBConnection, txn QueryAble, d *schema.ResourceData) error {
 	objects := d.Get("objects").(*schema.Set)
 
 	missingColumns := d.Get("columns").(*schema.Set) // Getting columns from state.
@@ -383,7 +651,7 @@ ORDER BY col_privs.attname
 			missingColumns.Remove(colName)
 		}
 
-		privilegesSet := pgArrayToSet(privileges)
+		privilegesSet := readPrivilegesSet(db, d, privileges)
 
 		if !privilegesSet.Equal(d.Get("privileges").(*schema.Set)) {
 			// If any object doesn't have the same privileges as saved in the state,
@@ -411,7 +679,7 @@ ORDER BY col_privs.attname
 	return nil
 }
 
-func readRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func readRolePrivileges(db *DBConnection, txn QueryAble, d *schema.ResourceData) error {
 	role := d.Get("role").(string)
 	objectType := d.Get("object_type").(string)
 	objects := d.Get("objects").(*schema.Set)
@@ -421,63 +689,64 @@ func readRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 		return err
 	}
 
-	var query string
 	var rows *sql.Rows
 
 	switch objectType {
 	case "database":
-		return readDatabaseRolePriviges(txn, d, roleOID)
+		return readDatabaseRolePriviges(db, txn, d, roleOID)
 
 	case "schema":
-		return readSchemaRolePriviges(txn, d, roleOID)
+		if err := schemaPrivilegesClaims.claimGrant(
+			db.client.config.connStr(d.Get("database").(string)), d.Get("schema").(string), role,
+		); err != nil {
+			return err
+		}
+		return readSchemaRolePriviges(db, txn, d, roleOID)
 
 	case "foreign_data_wrapper":
-		return readForeignDataWrapperRolePrivileges(txn, d, roleOID)
+		return readForeignDataWrapperRolePrivileges(db, txn, d, roleOID)
 
 	case "foreign_server":
-		return readForeignServerRolePrivileges(txn, d, roleOID)
+		return readForeignServerRolePrivileges(db, txn, d, roleOID)
 
 	case "function", "procedure", "routine":
-		query = `
-SELECT pg_proc.proname, array_remove(array_agg(privilege_type), NULL)
-FROM pg_proc
-JOIN pg_namespace ON pg_namespace.oid = pg_proc.pronamespace
-LEFT JOIN (
-    select acls.*
-    from (
-             SELECT proname, pronamespace, (aclexplode(proacl)).* FROM pg_proc
-         ) acls
-    WHERE grantee = $1
-) privs
-USING (proname, pronamespace)
-      WHERE nspname = $2
-GROUP BY pg_proc.proname
-`
-		rows, err = txn.Query(
-			query, roleOID, d.Get("schema"),
-		)
+		return readFunctionRolePrivileges(db, txn, d, roleOID)
 
 	case "column":
-		return readColumnRolePrivileges(txn, d)
+		return readColumnRolePrivileges(db, txn, d)
+
+	case "table":
+		if isCrossSchemaTableGrant(d) {
+			rows, err = readCrossSchemaTablesPrivileges(txn, d, roleOID)
+			break
+		}
+		if !db.featureSupported(featurePartitionedTables) {
+			rows, err = readRelationsPrivileges(txn, d, roleOID, "relkind = 'r'")
+			break
+		}
+
+		// Partitioned tables (relkind 'p') have their own ACL, distinct from
+		// the ones of their partitions (relkind 'r').
+		relFilter := "relkind IN ('r', 'p')"
+		if !d.Get("include_partitions").(bool) {
+			relFilter += " AND NOT relispartition"
+		}
+		if d.Get("recurse_partitions").(bool) {
+			tables, err := getPartitionAwareTables(txn, d)
+			if err != nil {
+				return err
+			}
+			objects = schema.NewSet(schema.HashString, nil)
+			for _, table := range tables {
+				if table.schema == d.Get("schema").(string) {
+					objects.Add(table.name)
+				}
+			}
+		}
+		rows, err = readRelationsPrivileges(txn, d, roleOID, relFilter)
 
 	default:
-		query = `
-SELECT pg_class.relname, array_remove(array_agg(privilege_type), NULL)
-FROM pg_class
-JOIN pg_namespace ON pg_namespace.oid = pg_class.relnamespace
-LEFT JOIN (
-    SELECT acls.* FROM (
-        SELECT relname, relnamespace, relkind, (aclexplode(relacl)).* FROM pg_class c
-    ) as acls
-    WHERE grantee=$1
-) privs
-USING (relname, relnamespace, relkind)
-WHERE nspname = $2 AND relkind = $3
-GROUP BY pg_class.relname
-`
-		rows, err = txn.Query(
-			query, roleOID, d.Get("schema"), objectTypes[objectType],
-		)
+		rows, err = readRelationsPrivileges(txn, d, roleOID, fmt.Sprintf("relkind = '%s'", objectTypes[objectType]))
 	}
 
 	// This returns, for the specified role (rolname),
@@ -501,7 +770,7 @@ GROUP BY pg_class.relname
 			continue
 		}
 
-		privilegesSet := pgArrayToSet(privileges)
+		privilegesSet := readPrivilegesSet(db, d, privileges)
 
 		if !privilegesSet.Equal(d.Get("privileges").(*schema.Set)) {
 			// If any object doesn't have the same privileges as saved in the state,
@@ -518,6 +787,270 @@ GROUP BY pg_class.relname
 	return nil
 }
 
+// readFunctionRolePrivileges reads the privileges of the role on the functions of the grant.
+// Each overload is checked separately, and objects specified with their argument types
+// are resolved with to_regprocedure, so the types don't have to be spelled as in the catalog
+// (e.g. `char` for `character`).
+func readFunctionRolePrivileges(db *DBConnection, txn QueryAble, d *schema.ResourceData, roleOID uint32) error {
+	schemaName := d.Get("schema").(string)
+	objects := d.Get("objects").(*schema.Set)
+
+	signatures := map[uint32]bool{}
+	signatureOIDs := []int64{}
+	for _, object := range objects.List() {
+		if !isFunctionSignature(object.(string)) {
+			continue
+		}
+		var oid uint32
+		if err := txn.QueryRow(
+			"SELECT COALESCE(to_regprocedure($1)::oid, 0)", quoteFunctionObject(schemaName, object.(string)),
+		).Scan(&oid); err != nil {
+			return fmt.Errorf("could not resolve function %s: %w", object, err)
+		}
+		if oid == 0 {
+			// Force an update, which will report the missing function.
+			log.Printf("[DEBUG] function %s not found in schema %s", object, schemaName)
+			d.Set("privileges", schema.NewSet(schema.HashString, nil))
+			return nil
+		}
+		signatures[oid] = true
+		signatureOIDs = append(signatureOIDs, int64(oid))
+	}
+
+	// A NULL proacl means the default privileges, i.e. EXECUTE for PUBLIC.
+	rows, err := txn.Query(`
+SELECT pg_proc.oid, pg_proc.proname, array_remove(array_agg(privilege_type), NULL),
+    bool_or(EXISTS (
+        SELECT 1 FROM aclexplode(COALESCE(pg_proc.proacl, acldefault('f', pg_proc.proowner)))
+        WHERE grantee = 0 AND privilege_type = 'EXECUTE'
+    ))
+FROM pg_proc
+JOIN pg_namespace ON pg_namespace.oid = pg_proc.pronamespace
+LEFT JOIN (
+    SELECT acls.* FROM (
+        SELECT oid AS prooid, (aclexplode(proacl)).* FROM pg_proc
+    ) acls
+    WHERE grantee = $1
+) privs ON privs.prooid = pg_proc.oid
+WHERE nspname = $2 OR pg_proc.oid = ANY($3)
+GROUP BY pg_proc.oid, pg_proc.proname
+`, roleOID, schemaName, pq.Array(signatureOIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var oid uint32
+		var name string
+		var privileges pq.ByteaArray
+		var publicExecute bool
+
+		if err := rows.Scan(&oid, &name, &privileges, &publicExecute); err != nil {
+			return err
+		}
+
+		if objects.Len() > 0 && !objects.Contains(name) && !signatures[oid] {
+			continue
+		}
+
+		if publicExecute && d.Get("revoke_public_execute_on_functions").(bool) {
+			// PUBLIC can execute it again, force an update to revoke it.
+			log.Printf("[DEBUG] %s %s is executable by PUBLIC", strings.ToTitle(d.Get("object_type").(string)), name)
+			d.Set("revoke_public_execute_on_functions", false)
+		}
+
+		privilegesSet := readPrivilegesSet(db, d, privileges)
+		if !privilegesSet.Equal(d.Get("privileges").(*schema.Set)) {
+			// If any function doesn't have the same privileges as saved in the state,
+			// we return its privileges to force an update.
+			log.Printf(
+				"[DEBUG] %s %s has not the expected privileges %v for role %s",
+				strings.ToTitle(d.Get("object_type").(string)), name, privileges, d.Get("role"),
+			)
+			d.Set("privileges", privilegesSet)
+			break
+		}
+	}
+
+	return rows.Err()
+}
+
+// readRelationsPrivileges returns the privileges of the role on each relation of
+// the schema matching relFilter (a condition on pg_class columns).
+func readRelationsPrivileges(txn QueryAble, d *schema.ResourceData, roleOID uint32, relFilter string) (*sql.Rows, error) {
+	query := fmt.Sprintf(`
+SELECT pg_class.relname, array_remove(array_agg(privilege_type), NULL)
+FROM pg_class
+JOIN pg_namespace ON pg_namespace.oid = pg_class.relnamespace
+LEFT JOIN (
+    SELECT acls.* FROM (
+        SELECT relname, relnamespace, relkind, (aclexplode(relacl)).* FROM pg_class c
+    ) as acls
+    WHERE grantee=$1
+) privs
+USING (relname, relnamespace, relkind)
+WHERE nspname = $2 AND %s
+GROUP BY pg_class.relname
+`, relFilter)
+	return txn.Query(query, roleOID, d.Get("schema"))
+}
+
+// readCrossSchemaTablesPrivileges returns the privileges of the role on each table of a grant without schema,
+// the rows are named after the objects (schema.table) of the grant. Like readRelationsPrivileges, the ACL is read
+// from pg_class rather than information_schema.role_table_grants, which only shows the grants related to the
+// roles of the current user.
+func readCrossSchemaTablesPrivileges(txn QueryAble, d *schema.ResourceData, roleOID uint32) (*sql.Rows, error) {
+	objects := d.Get("objects").(*schema.Set).List()
+	tables, err := parseCrossSchemaTables(d.Get("objects").(*schema.Set))
+	if err != nil {
+		return nil, err
+	}
+
+	objectNames := make([]string, len(objects))
+	schemaNames := make([]string, len(tables))
+	tableNames := make([]string, len(tables))
+	for i, table := range tables {
+		objectNames[i] = objects[i].(string)
+		schemaNames[i] = table.schema
+		tableNames[i] = table.name
+	}
+
+	return txn.Query(`
+SELECT o.object, array_remove(array_agg(privs.privilege_type), NULL)
+FROM unnest($2::text[], $3::text[], $4::text[]) AS o(object, nspname, relname)
+JOIN pg_namespace ON pg_namespace.nspname = o.nspname
+JOIN pg_class ON pg_class.relnamespace = pg_namespace.oid AND pg_class.relname = o.relname
+LEFT JOIN LATERAL (
+    SELECT privilege_type FROM aclexplode(pg_class.relacl) WHERE grantee = $1
+) privs ON true
+GROUP BY o.object
+`, roleOID, pq.Array(objectNames), pq.Array(schemaNames), pq.Array(tableNames))
+}
+
+type partitionTable struct {
+	schema string
+	name   string
+}
+
+// isCrossSchemaTableGrant returns true for a table grant without schema, whose objects are
+// qualified with their own schema (e.g. `schema.table`).
+func isCrossSchemaTableGrant(d *schema.ResourceData) bool {
+	return d.Get("object_type").(string) == "table" && d.Get("schema").(string) == "" && d.Get("objects").(*schema.Set).Len() > 0
+}
+
+// parseCrossSchemaTables returns the tables of the schema-qualified objects of a grant without schema.
+func parseCrossSchemaTables(objects *schema.Set) ([]partitionTable, error) {
+	tables := make([]partitionTable, 0, objects.Len())
+	for _, object := range objects.List() {
+		parts, err := splitQualifiedIdentifier(object.(string))
+		if err != nil || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("table %q must be qualified with its schema (schema.table) when `schema` is not set", object)
+		}
+		tables = append(tables, partitionTable{schema: parts[0], name: parts[1]})
+	}
+	return tables, nil
+}
+
+// groupTablesBySchema splits tables in one list per schema, sorted by schema name.
+func groupTablesBySchema(tables []partitionTable) [][]partitionTable {
+	bySchema := map[string][]partitionTable{}
+	schemaNames := []string{}
+	for _, table := range tables {
+		if _, ok := bySchema[table.schema]; !ok {
+			schemaNames = append(schemaNames, table.schema)
+		}
+		bySchema[table.schema] = append(bySchema[table.schema], table)
+	}
+	sort.Strings(schemaNames)
+
+	groups := make([][]partitionTable, len(schemaNames))
+	for i, schemaName := range schemaNames {
+		groups[i] = bySchema[schemaName]
+	}
+	return groups
+}
+
+// execTablesQueries runs the query built from the quoted list of tables once per schema.
+func execTablesQueries(txn *sql.Tx, tables []partitionTable, query func(tablesList string) string) error {
+	for _, group := range groupTablesBySchema(tables) {
+		if _, err := txn.Exec(query(partitionTablesToPgIdentList(group))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPartitionAwareTables returns the tables a table grant has to target when
+// partitions need a specific handling:
+//   - all the relations of the schema except partitions if include_partitions is false
+//   - the tables in objects and all their partitions (recursively) if recurse_partitions is true
+//   - the schema-qualified tables in objects if schema is not set
+//
+// It returns nil if the grant can target `objects` (or ALL TABLES IN SCHEMA) as is.
+func getPartitionAwareTables(txn QueryAble, d *schema.ResourceData) ([]partitionTable, error) {
+	if d.Get("object_type").(string) != "table" {
+		return nil, nil
+	}
+	if isCrossSchemaTableGrant(d) {
+		return parseCrossSchemaTables(d.Get("objects").(*schema.Set))
+	}
+
+	schemaName := d.Get("schema").(string)
+	objects := d.Get("objects").(*schema.Set)
+
+	var rows *sql.Rows
+	var err error
+	switch {
+	case objects.Len() > 0 && d.Get("recurse_partitions").(bool):
+		rows, err = txn.Query(`
+WITH RECURSIVE tree AS (
+    SELECT c.oid FROM pg_class c
+    JOIN pg_namespace n ON n.oid = c.relnamespace
+    WHERE n.nspname = $1 AND c.relname = ANY($2)
+    UNION
+    SELECT i.inhrelid FROM pg_inherits i JOIN tree t ON i.inhparent = t.oid
+)
+SELECT n.nspname, c.relname FROM tree
+JOIN pg_class c ON c.oid = tree.oid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+`, schemaName, pq.Array(objects.List()))
+
+	case objects.Len() == 0 && !d.Get("include_partitions").(bool):
+		// Same relations as ALL TABLES IN SCHEMA, without the partitions
+		rows, err = txn.Query(`
+SELECT n.nspname, c.relname FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'v', 'm', 'f') AND NOT c.relispartition
+`, schemaName)
+
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not list tables of schema %s: %w", schemaName, err)
+	}
+	defer rows.Close()
+
+	tables := []partitionTable{}
+	for rows.Next() {
+		var table partitionTable
+		if err := rows.Scan(&table.schema, &table.name); err != nil {
+			return nil, fmt.Errorf("could not scan table: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+func partitionTablesToPgIdentList(tables []partitionTable) string {
+	quotedIdents := make([]string, len(tables))
+	for i, table := range tables {
+		quotedIdents[i] = quoteQualifiedIdentifier(table.schema, table.name)
+	}
+	return strings.Join(quotedIdents, ",")
+}
+
 func createGrantQuery(d *schema.ResourceData, privileges []string) string {
 	var query string
 
@@ -568,7 +1101,7 @@ func createGrantQuery(d *schema.ResourceData, privileges []string) string {
 				"GRANT %s ON %s %s TO %s",
 				strings.Join(privileges, ","),
 				strings.ToUpper(d.Get("object_type").(string)),
-				setToPgIdentList(d.Get("schema").(string), objects),
+				grantObjectsList(d),
 				pq.QuoteIdentifier(d.Get("role").(string)),
 			)
 		} else {
@@ -646,14 +1179,14 @@ func createRevokeQuery(d *schema.ResourceData) string {
 					"REVOKE %s ON %s %s FROM %s",
 					setToPgIdentSimpleList(privileges),
 					strings.ToUpper(d.Get("object_type").(string)),
-					setToPgIdentList(d.Get("schema").(string), objects),
+					grantObjectsList(d),
 					pq.QuoteIdentifier(d.Get("role").(string)),
 				)
 			} else {
 				query = fmt.Sprintf(
 					"REVOKE ALL PRIVILEGES ON %s %s FROM %s",
 					strings.ToUpper(d.Get("object_type").(string)),
-					setToPgIdentList(d.Get("schema").(string), objects),
+					grantObjectsList(d),
 					pq.QuoteIdentifier(d.Get("role").(string)),
 				)
 			}
@@ -676,6 +1209,11 @@ func grantRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 		privileges = append(privileges, priv.(string))
 	}
 
+	return grantPrivileges(txn, d, privileges)
+}
+
+// grantPrivileges grants the privileges to the role on the objects of the grant.
+func grantPrivileges(txn *sql.Tx, d *schema.ResourceData, privileges []string) error {
 	if len(privileges) == 0 {
 		log.Printf("[DEBUG] no privileges to grant for role %s in database: %s,", d.Get("role").(string), d.Get("database"))
 		return nil
@@ -683,12 +1221,51 @@ func grantRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 
 	query := createGrantQuery(d, privileges)
 
-	_, err := txn.Exec(query)
+	tables, err := getPartitionAwareTables(txn, d)
+	if err != nil {
+		return err
+	}
+	if tables != nil {
+		return execTablesQueries(txn, tables, func(tablesList string) string {
+			query := fmt.Sprintf(
+				"GRANT %s ON TABLE %s TO %s",
+				strings.Join(privileges, ","),
+				tablesList,
+				pq.QuoteIdentifier(d.Get("role").(string)),
+			)
+			if d.Get("with_grant_option").(bool) {
+				query = query + " WITH GRANT OPTION"
+			}
+			return query
+		})
+	}
+
+	_, err = txn.Exec(query)
 	return err
 }
 
 func revokeRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	query := createRevokeQuery(d)
+
+	tables, err := getPartitionAwareTables(txn, d)
+	if err != nil {
+		return err
+	}
+	if tables != nil {
+		revoked := "ALL PRIVILEGES"
+		if privileges := d.Get("privileges").(*schema.Set); privileges.Len() > 0 && d.Get("objects").(*schema.Set).Len() > 0 {
+			// Revoking specific privileges instead of all privileges
+			// to avoid messing with column level grants
+			revoked = setToPgIdentSimpleList(privileges)
+		}
+		if err := execTablesQueries(txn, tables, func(tablesList string) string {
+			return fmt.Sprintf("REVOKE %s ON TABLE %s FROM %s", revoked, tablesList, pq.QuoteIdentifier(d.Get("role").(string)))
+		}); err != nil {
+			return fmt.Errorf("could not execute revoke query: %w", err)
+		}
+		return nil
+	}
+
 	if len(query) == 0 {
 		// Query is empty, don't run anything
 		return nil
@@ -699,6 +1276,107 @@ func revokeRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
+// revokePrivileges revokes only the privileges specified from the role on the objects of the grant.
+func revokePrivileges(txn *sql.Tx, d *schema.ResourceData, privileges []string) error {
+	if len(privileges) == 0 {
+		return nil
+	}
+
+	query := createRevokePrivilegesQuery(d, privileges)
+
+	tables, err := getPartitionAwareTables(txn, d)
+	if err != nil {
+		return err
+	}
+	if tables != nil {
+		if err := execTablesQueries(txn, tables, func(tablesList string) string {
+			return fmt.Sprintf("REVOKE %s ON TABLE %s FROM %s", strings.Join(privileges, ","), tablesList, pq.QuoteIdentifier(d.Get("role").(string)))
+		}); err != nil {
+			return fmt.Errorf("could not execute revoke query: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not execute revoke query: %w", err)
+	}
+	return nil
+}
+
+// createRevokePrivilegesQuery returns the query revoking only the privileges specified,
+// unlike createRevokeQuery which revokes all of them.
+func createRevokePrivilegesQuery(d *schema.ResourceData, privileges []string) string {
+	role := pq.QuoteIdentifier(d.Get("role").(string))
+	revoked := strings.Join(privileges, ",")
+
+	switch strings.ToUpper(d.Get("object_type").(string)) {
+	case "DATABASE":
+		return fmt.Sprintf("REVOKE %s ON DATABASE %s FROM %s", revoked, pq.QuoteIdentifier(d.Get("database").(string)), role)
+	case "SCHEMA":
+		return fmt.Sprintf("REVOKE %s ON SCHEMA %s FROM %s", revoked, pq.QuoteIdentifier(d.Get("schema").(string)), role)
+	case "FOREIGN_DATA_WRAPPER":
+		fdwName := d.Get("objects").(*schema.Set).List()[0]
+		return fmt.Sprintf("REVOKE %s ON FOREIGN DATA WRAPPER %s FROM %s", revoked, pq.QuoteIdentifier(fdwName.(string)), role)
+	case "FOREIGN_SERVER":
+		srvName := d.Get("objects").(*schema.Set).List()[0]
+		return fmt.Sprintf("REVOKE %s ON FOREIGN SERVER %s FROM %s", revoked, pq.QuoteIdentifier(srvName.(string)), role)
+	case "COLUMN":
+		return fmt.Sprintf(
+			"REVOKE %s (%s) ON TABLE %s FROM %s",
+			revoked,
+			setToPgIdentListWithoutSchema(d.Get("columns").(*schema.Set)),
+			setToPgIdentList(d.Get("schema").(string), d.Get("objects").(*schema.Set)),
+			role,
+		)
+	}
+
+	objectType := strings.ToUpper(d.Get("object_type").(string))
+	if objects := d.Get("objects").(*schema.Set); objects.Len() > 0 {
+		return fmt.Sprintf("REVOKE %s ON %s %s FROM %s", revoked, objectType, grantObjectsList(d), role)
+	}
+	return fmt.Sprintf("REVOKE %s ON ALL %sS IN SCHEMA %s FROM %s", revoked, objectType, pq.QuoteIdentifier(d.Get("schema").(string)), role)
+}
+
+// grantObjectsList returns the quoted list of the objects of the grant, qualified with the schema.
+// Functions, procedures and routines can be specified with their argument types to target a specific
+// overload (e.g. `name(integer, text)`), and be qualified with their own schema (e.g. `schema.name(integer)`).
+func grantObjectsList(d *schema.ResourceData) string {
+	schemaName := d.Get("schema").(string)
+	objects := d.Get("objects").(*schema.Set)
+
+	if !sliceContainsStr([]string{"function", "procedure", "routine"}, d.Get("object_type").(string)) {
+		return setToPgIdentList(schemaName, objects)
+	}
+
+	quotedObjects := make([]string, objects.Len())
+	for i, object := range objects.List() {
+		quotedObjects[i] = quoteFunctionObject(schemaName, object.(string))
+	}
+	return strings.Join(quotedObjects, ",")
+}
+
+// quoteFunctionObject quotes a function name, optionally schema-qualified and
+// followed by its argument types, and qualifies it with schemaName if needed.
+func quoteFunctionObject(schemaName, object string) string {
+	object = strings.TrimSpace(object)
+	parts, args, err := splitFunctionSignature(object)
+	if err == nil {
+		args = "(" + args + ")"
+	} else {
+		// Only the name of the function
+		parts, err = splitQualifiedIdentifier(object)
+		args = ""
+	}
+	if err != nil || len(parts) > 2 {
+		// Let PostgreSQL report the invalid name
+		parts = []string{object}
+	}
+	if len(parts) == 1 {
+		parts = []string{schemaName, parts[0]}
+	}
+	return quoteQualifiedIdentifier(parts...) + args
+}
+
 func checkRoleDBSchemaExists(client *Client, d *schema.ResourceData) (bool, error) {
 	txn, err := startTransaction(client, "")
 	if err != nil {
@@ -774,6 +1452,107 @@ func generateGrantID(d *schema.ResourceData) string {
 	return strings.Join(parts, "_")
 }
 
+// resourcePostgreSQLGrantImport parses the import ID, the privileges are then read from the catalog. The format is:
+//   - role/database/database
+//   - role/database/object_type/name for foreign_data_wrapper and foreign_server
+//   - role/database/schema/schema
+//   - role/database/schema/object_type[/objects] for table, sequence, function, procedure and routine
+//     (objects are comma separated, all the objects of the schema if omitted)
+//   - role/database/schema/column/table/columns (columns are comma separated)
+func resourcePostgreSQLGrantImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	attrs, err := parseGrantImportID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range attrs {
+		if err := d.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	d.Set("with_grant_option", false)
+	d.Set("include_partitions", true)
+	d.Set("recurse_partitions", false)
+	d.SetId(generateGrantID(d))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func parseGrantImportID(id string) (map[string]interface{}, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("grant ID %s has not the expected format 'role/database[/schema]/object_type[/objects]'", id)
+	}
+
+	attrs := map[string]interface{}{
+		"role":     parts[0],
+		"database": parts[1],
+	}
+
+	switch {
+	case len(parts) == 3 && parts[2] == "database":
+		attrs["object_type"] = "database"
+		return attrs, nil
+
+	case len(parts) == 4 && (parts[2] == "foreign_data_wrapper" || parts[2] == "foreign_server"):
+		attrs["object_type"] = parts[2]
+		attrs["objects"] = []interface{}{parts[3]}
+		return attrs, nil
+
+	case len(parts) < 4:
+		return nil, fmt.Errorf("grant ID %s has not the expected format 'role/database/schema/object_type[/objects]'", id)
+	}
+
+	objectType := parts[3]
+	attrs["schema"] = parts[2]
+	attrs["object_type"] = objectType
+
+	switch objectType {
+	case "schema":
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("grant ID %s has not the expected format 'role/database/schema/schema'", id)
+		}
+	case "table", "sequence", "function", "procedure", "routine":
+		if len(parts) > 5 {
+			return nil, fmt.Errorf("grant ID %s has not the expected format 'role/database/schema/%s[/objects]'", id, objectType)
+		}
+		if len(parts) == 5 {
+			attrs["objects"] = splitGrantImportList(parts[4])
+		}
+	case "column":
+		if len(parts) != 6 {
+			return nil, fmt.Errorf("grant ID %s has not the expected format 'role/database/schema/column/table/columns'", id)
+		}
+		attrs["objects"] = []interface{}{parts[4]}
+		attrs["columns"] = splitGrantImportList(parts[5])
+	default:
+		return nil, fmt.Errorf("unknown object type %s in grant ID %s", objectType, id)
+	}
+
+	return attrs, nil
+}
+
+// splitGrantImportList splits a comma separated list of the import ID,
+// ignoring the commas between parentheses (i.e. in function arguments).
+func splitGrantImportList(list string) []interface{} {
+	var items []interface{}
+	depth, start := 0, 0
+	for i, c := range list {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(items, strings.TrimSpace(list[start:]))
+}
+
 func getRolesToGrant(txn *sql.Tx, d *schema.ResourceData) ([]string, error) {
 	// If user we use for Terraform is not a superuser (e.g.: in RDS)
 	// we need to grant owner of the schema and owners of tables in the schema
@@ -785,25 +1564,41 @@ func getRolesToGrant(txn *sql.Tx, d *schema.ResourceData) ([]string, error) {
 		return owners, nil
 	}
 
-	schemaName := d.Get("schema").(string)
-
-	if objectType != "schema" {
-		var err error
-		owners, err = getTablesOwner(txn, schemaName)
+	schemaNames := []string{d.Get("schema").(string)}
+	if isCrossSchemaTableGrant(d) {
+		tables, err := parseCrossSchemaTables(d.Get("objects").(*schema.Set))
 		if err != nil {
 			return nil, err
 		}
+		schemaNames = schemaNames[:0]
+		for _, group := range groupTablesBySchema(tables) {
+			schemaNames = append(schemaNames, group[0].schema)
+		}
 	}
 
-	schemaOwner, err := getSchemaOwner(txn, schemaName)
-	if err != nil {
-		return nil, err
-	}
-	if !sliceContainsStr(owners, schemaOwner) {
-		owners = append(owners, schemaOwner)
+	for _, schemaName := range schemaNames {
+		if objectType != "schema" {
+			tablesOwners, err := getTablesOwner(txn, schemaName)
+			if err != nil {
+				return nil, err
+			}
+			for _, owner := range tablesOwners {
+				if !sliceContainsStr(owners, owner) {
+					owners = append(owners, owner)
+				}
+			}
+		}
+
+		schemaOwner, err := getSchemaOwner(txn, schemaName)
+		if err != nil {
+			return nil, err
+		}
+		if !sliceContainsStr(owners, schemaOwner) {
+			owners = append(owners, schemaOwner)
+		}
 	}
 
-	owners, err = resolveOwners(txn, owners)
+	owners, err := resolveOwners(txn, owners)
 	if err != nil {
 		return nil, err
 	}
@@ -1,12 +1,14 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 )
@@ -26,11 +28,33 @@ WHERE
 `
 )
 
+// predefinedRoles maps the predefined roles (formerly default roles) commonly granted
+// to the first PostgreSQL version providing them.
+var predefinedRoles = map[string]semver.Version{
+	"pg_signal_backend":           semver.MustParse("9.6.0"),
+	"pg_monitor":                  semver.MustParse("10.0.0"),
+	"pg_read_all_settings":        semver.MustParse("10.0.0"),
+	"pg_read_all_stats":           semver.MustParse("10.0.0"),
+	"pg_stat_scan_tables":         semver.MustParse("10.0.0"),
+	"pg_read_server_files":        semver.MustParse("11.0.0"),
+	"pg_write_server_files":       semver.MustParse("11.0.0"),
+	"pg_execute_server_program":   semver.MustParse("11.0.0"),
+	"pg_read_all_data":            semver.MustParse("14.0.0"),
+	"pg_write_all_data":           semver.MustParse("14.0.0"),
+	"pg_checkpoint":               semver.MustParse("15.0.0"),
+	"pg_use_reserved_connections": semver.MustParse("16.0.0"),
+	"pg_create_subscription":      semver.MustParse("16.0.0"),
+	"pg_maintain":                 semver.MustParse("17.0.0"),
+}
+
 func resourcePostgreSQLGrantRole() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLGrantRoleCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLGrantRoleRead),
-		Delete: PGResourceFunc(resourcePostgreSQLGrantRoleDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLGrantRoleCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLGrantRoleRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLGrantRoleDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLGrantRoleImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -43,7 +67,7 @@ func resourcePostgreSQLGrantRole() *schema.Resource {
 				Type:        schema.TypeString,
 				Required:    true,
 				ForceNew:    true,
-				Description: "The name of the role that is granted to role",
+				Description: "The name of the role that is granted to role. Predefined roles (e.g. `pg_monitor`, `pg_read_all_data`) are checked against the server version before being granted",
 			},
 			"with_admin_option": {
 				Type:        schema.TypeBool,
@@ -75,6 +99,10 @@ func resourcePostgreSQLGrantRoleCreate(db *DBConnection, d *schema.ResourceData)
 		)
 	}
 
+	if err := checkPredefinedRole(db, d.Get("grant_role").(string)); err != nil {
+		return err
+	}
+
 	txn, err := startTransaction(db.client, "")
 	if err != nil {
 		return err
@@ -155,6 +183,31 @@ func readGrantRole(db QueryAble, d *schema.ResourceData) error {
 	return nil
 }
 
+// checkPredefinedRole returns a clear error if role is a predefined role (pg_*)
+// which doesn't exist in the server version.
+func checkPredefinedRole(db *DBConnection, role string) error {
+	if !strings.HasPrefix(role, "pg_") {
+		return nil
+	}
+
+	since, ok := predefinedRoles[role]
+	if !ok {
+		// Not a well known predefined role, it may be a newer one or a role named pg_* by mistake.
+		if err := checkRoleExistence(db, role); err != nil {
+			return fmt.Errorf("invalid grant_role: %w", err)
+		}
+		return nil
+	}
+
+	if db.version.LT(since) {
+		return fmt.Errorf(
+			"predefined role %s is not available in PostgreSQL %s (it requires PostgreSQL %s or later)",
+			role, db.version, strings.TrimSuffix(fmt.Sprintf("%d.%d", since.Major, since.Minor), ".0"),
+		)
+	}
+	return nil
+}
+
 func createGrantRoleQuery(d *schema.ResourceData) string {
 	grantRole, _ := d.Get("grant_role").(string)
 	role, _ := d.Get("role").(string)
@@ -198,6 +251,21 @@ func revokeRole(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
+// resourcePostgreSQLGrantRoleImport parses the import ID grant_role.role (i.e. the granted role
+// then its member, double quoted if they contain dots), with_admin_option is then read from pg_auth_members.
+func resourcePostgreSQLGrantRoleImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := splitQualifiedIdentifier(d.Id())
+	if err != nil || len(parts) != 2 {
+		return nil, fmt.Errorf("grant role ID %s has not the expected format 'grant_role.role'", d.Id())
+	}
+
+	d.Set("grant_role", parts[0])
+	d.Set("role", parts[1])
+	d.SetId(generateGrantRoleID(d))
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func generateGrantRoleID(d *schema.ResourceData) string {
 	return strings.Join([]string{d.Get("role").(string), d.Get("grant_role").(string), strconv.FormatBool(d.Get("with_admin_option").(bool))}, "_")
 }
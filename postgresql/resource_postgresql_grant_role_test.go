@@ -3,9 +3,11 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
 	"strconv"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -135,10 +137,83 @@ func TestAccPostgresqlGrantRole(t *testing.T) {
 					checkGrantRole(t, dsn, roleName, grantedRoleName, true),
 				),
 			},
+			{
+				ResourceName:      "postgresql_grant_role.grant_role",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s.%s", grantedRoleName, roleName),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantRolePredefined(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbSuffix, teardown := setupTestDatabase(t, false, true)
+	defer teardown()
+
+	_, roleName := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+				resource postgresql_grant_role "grant_role" {
+					role       = "%s"
+					grant_role = "pg_monitor"
+				}
+				`, roleName),
+				Check: checkGrantRole(t, dsn, roleName, "pg_monitor", false),
+			},
+			{
+				Config: fmt.Sprintf(`
+				resource postgresql_grant_role "grant_role" {
+					role       = "%s"
+					grant_role = "pg_not_a_predefined_role"
+				}
+				`, roleName),
+				ExpectError: regexp.MustCompile("invalid grant_role: role pg_not_a_predefined_role does not exist"),
+			},
 		},
 	})
 }
 
+func TestCheckPredefinedRole(t *testing.T) {
+	db := &DBConnection{version: semver.MustParse("13.4.0")}
+
+	// Known predefined roles available in the server version are accepted without querying it
+	for _, role := range []string{"pg_monitor", "pg_read_all_settings", "pg_signal_backend"} {
+		if err := checkPredefinedRole(db, role); err != nil {
+			t.Errorf("checkPredefinedRole(%s) returned an unexpected error: %v", role, err)
+		}
+	}
+
+	// Other roles are not checked
+	if err := checkPredefinedRole(db, "app_owner"); err != nil {
+		t.Errorf("checkPredefinedRole(app_owner) returned an unexpected error: %v", err)
+	}
+
+	expected := "predefined role pg_read_all_data is not available in PostgreSQL 13.4.0 (it requires PostgreSQL 14 or later)"
+	if err := checkPredefinedRole(db, "pg_read_all_data"); err == nil || err.Error() != expected {
+		t.Errorf("checkPredefinedRole(pg_read_all_data) = %v, want %q", err, expected)
+	}
+
+	db = &DBConnection{version: semver.MustParse("9.5.0")}
+	expected = "predefined role pg_signal_backend is not available in PostgreSQL 9.5.0 (it requires PostgreSQL 9.6 or later)"
+	if err := checkPredefinedRole(db, "pg_signal_backend"); err == nil || err.Error() != expected {
+		t.Errorf("checkPredefinedRole(pg_signal_backend) = %v, want %q", err, expected)
+	}
+}
+
 func checkGrantRole(t *testing.T, dsn, role string, grantRole string, withAdmin bool) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		db, err := sql.Open("postgres", dsn)
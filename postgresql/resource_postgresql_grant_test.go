@@ -3,10 +3,13 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -300,6 +303,212 @@ func TestCreateRevokeQuery(t *testing.T) {
 	}
 }
 
+func TestGrantPrivilegesDiff(t *testing.T) {
+	toSet := func(privileges ...interface{}) *schema.Set {
+		return schema.NewSet(schema.HashString, privileges)
+	}
+
+	cases := []struct {
+		old, new         *schema.Set
+		toGrant, revoked []string
+	}{
+		{toSet("SELECT", "INSERT"), toSet("SELECT"), nil, []string{"INSERT"}},
+		{toSet("SELECT"), toSet("SELECT", "INSERT", "UPDATE"), []string{"INSERT", "UPDATE"}, nil},
+		{toSet("SELECT", "INSERT"), toSet("UPDATE", "SELECT"), []string{"UPDATE"}, []string{"INSERT"}},
+		{toSet("SELECT"), toSet(), nil, []string{"SELECT"}},
+	}
+
+	for _, c := range cases {
+		toGrant, toRevoke := grantPrivilegesDiff(c.old, c.new)
+		if !reflect.DeepEqual(toGrant, c.toGrant) || !reflect.DeepEqual(toRevoke, c.revoked) {
+			t.Errorf(
+				"grantPrivilegesDiff(%v, %v) = %v, %v, want %v, %v",
+				c.old.List(), c.new.List(), toGrant, toRevoke, c.toGrant, c.revoked,
+			)
+		}
+	}
+}
+
+func TestReadPrivilegesSet(t *testing.T) {
+	tablePrivileges := pq.ByteaArray{[]byte("SELECT"), []byte("INSERT"), []byte("UPDATE"), []byte("DELETE"), []byte("TRUNCATE"), []byte("REFERENCES"), []byte("TRIGGER")}
+	tablePrivilegesWithMaintain := append(pq.ByteaArray{[]byte("MAINTAIN")}, tablePrivileges...)
+
+	cases := []struct {
+		version    string
+		objectType string
+		state      []interface{}
+		read       pq.ByteaArray
+		expected   []interface{}
+	}{
+		// All the privileges of the object type are collapsed to ALL if it is in the state
+		{"16.0.0", "table", []interface{}{"ALL"}, tablePrivileges, []interface{}{"ALL"}},
+		{"16.0.0", "sequence", []interface{}{"ALL"}, pq.ByteaArray{[]byte("USAGE"), []byte("SELECT"), []byte("UPDATE")}, []interface{}{"ALL"}},
+		{"16.0.0", "function", []interface{}{"ALL"}, pq.ByteaArray{[]byte("EXECUTE")}, []interface{}{"ALL"}},
+		// ALL includes MAINTAIN from PostgreSQL 17
+		{"17.0.0", "table", []interface{}{"ALL"}, tablePrivilegesWithMaintain, []interface{}{"ALL"}},
+		{"17.0.0", "table", []interface{}{"ALL"}, tablePrivileges, []interface{}{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"}},
+		{"16.0.0", "database", []interface{}{"ALL"}, pq.ByteaArray{[]byte("CONNECT"), []byte("TEMPORARY"), []byte("CREATE")}, []interface{}{"ALL"}},
+		// A missing privilege forces an update
+		{"16.0.0", "database", []interface{}{"ALL"}, pq.ByteaArray{[]byte("CONNECT"), []byte("TEMPORARY")}, []interface{}{"CONNECT", "TEMPORARY"}},
+		{"16.0.0", "sequence", []interface{}{"ALL"}, pq.ByteaArray{[]byte("USAGE"), []byte("SELECT")}, []interface{}{"USAGE", "SELECT"}},
+		// The order of the privileges doesn't matter
+		{"16.0.0", "table", []interface{}{"INSERT", "SELECT"}, pq.ByteaArray{[]byte("SELECT"), []byte("INSERT")}, []interface{}{"INSERT", "SELECT"}},
+		// The full set is kept as is if ALL is not in the state
+		{"16.0.0", "sequence", []interface{}{"USAGE", "SELECT", "UPDATE"}, pq.ByteaArray{[]byte("USAGE"), []byte("SELECT"), []byte("UPDATE")}, []interface{}{"USAGE", "SELECT", "UPDATE"}},
+	}
+
+	for _, c := range cases {
+		db := &DBConnection{version: semver.MustParse(c.version)}
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+			"object_type": c.objectType,
+			"privileges":  c.state,
+		})
+		got := readPrivilegesSet(db, d, c.read)
+		if expected := schema.NewSet(schema.HashString, c.expected); !got.Equal(expected) {
+			t.Errorf("readPrivilegesSet(%s, %s, %v, %v) = %v, want %v", c.version, c.objectType, c.state, c.read, got.List(), expected.List())
+		}
+	}
+}
+
+func TestValidatePrivilegesMaintain(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+		"object_type": "table",
+		"privileges":  []interface{}{"SELECT", "MAINTAIN"},
+	})
+
+	if err := validatePrivileges(&DBConnection{version: semver.MustParse("17.0.0")}, d); err != nil {
+		t.Errorf("MAINTAIN should be allowed on PostgreSQL 17: %v", err)
+	}
+
+	err := validatePrivileges(&DBConnection{version: semver.MustParse("16.4.0")}, d)
+	if err == nil || !strings.Contains(err.Error(), "does not support the MAINTAIN privilege") {
+		t.Errorf("MAINTAIN should be rejected on PostgreSQL 16, got: %v", err)
+	}
+}
+
+func TestCreateRevokePrivilegesQuery(t *testing.T) {
+	var databaseName = "foo"
+	var roleName = "bar"
+
+	cases := []struct {
+		resource   map[string]interface{}
+		privileges []string
+		expected   string
+	}{
+		{
+			resource: map[string]interface{}{
+				"object_type": "table",
+				"schema":      databaseName,
+				"role":        roleName,
+			},
+			privileges: []string{"INSERT"},
+			expected:   fmt.Sprintf("REVOKE INSERT ON ALL TABLES IN SCHEMA %s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: map[string]interface{}{
+				"object_type": "table",
+				"schema":      databaseName,
+				"role":        roleName,
+				"objects":     []interface{}{"o1"},
+			},
+			privileges: []string{"INSERT", "UPDATE"},
+			expected:   fmt.Sprintf("REVOKE INSERT,UPDATE ON TABLE %s.%s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier("o1"), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: map[string]interface{}{
+				"object_type": "database",
+				"database":    databaseName,
+				"role":        roleName,
+			},
+			privileges: []string{"CREATE"},
+			expected:   fmt.Sprintf("REVOKE CREATE ON DATABASE %s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: map[string]interface{}{
+				"object_type": "column",
+				"schema":      databaseName,
+				"role":        roleName,
+				"objects":     []interface{}{"o1"},
+				"columns":     []interface{}{"col1"},
+			},
+			privileges: []string{"SELECT"},
+			expected: fmt.Sprintf(
+				"REVOKE SELECT (%s) ON TABLE %s.%s FROM %s",
+				pq.QuoteIdentifier("col1"), pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier("o1"), pq.QuoteIdentifier(roleName),
+			),
+		},
+	}
+
+	for _, c := range cases {
+		out := createRevokePrivilegesQuery(schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, c.resource), c.privileges)
+		if out != c.expected {
+			t.Fatalf("Error matching output and expected: %#v vs %#v", out, c.expected)
+		}
+	}
+}
+
+func TestParseGrantImportID(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			id:   "role/db/database",
+			want: map[string]interface{}{"role": "role", "database": "db", "object_type": "database"},
+		},
+		{
+			id:   "role/db/foreign_server/srv",
+			want: map[string]interface{}{"role": "role", "database": "db", "object_type": "foreign_server", "objects": []interface{}{"srv"}},
+		},
+		{
+			id:   "role/db/test_schema/schema",
+			want: map[string]interface{}{"role": "role", "database": "db", "schema": "test_schema", "object_type": "schema"},
+		},
+		{
+			id:   "role/db/test_schema/table",
+			want: map[string]interface{}{"role": "role", "database": "db", "schema": "test_schema", "object_type": "table"},
+		},
+		{
+			id: "role/db/test_schema/table/t1,t2",
+			want: map[string]interface{}{
+				"role": "role", "database": "db", "schema": "test_schema", "object_type": "table",
+				"objects": []interface{}{"t1", "t2"},
+			},
+		},
+		{
+			id: "role/db/test_schema/function/f(integer, text),g",
+			want: map[string]interface{}{
+				"role": "role", "database": "db", "schema": "test_schema", "object_type": "function",
+				"objects": []interface{}{"f(integer, text)", "g"},
+			},
+		},
+		{
+			id: "role/db/test_schema/column/t1/c1,c2",
+			want: map[string]interface{}{
+				"role": "role", "database": "db", "schema": "test_schema", "object_type": "column",
+				"objects": []interface{}{"t1"}, "columns": []interface{}{"c1", "c2"},
+			},
+		},
+		{id: "role/db", wantErr: true},
+		{id: "role/db/test_schema", wantErr: true},
+		{id: "role/db/test_schema/schema/extra", wantErr: true},
+		{id: "role/db/test_schema/column/t1", wantErr: true},
+		{id: "role/db/test_schema/unknown", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseGrantImportID(test.id)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseGrantImportID(%q) returned error %v, want error: %t", test.id, err, test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseGrantImportID(%q) = %#v, want %#v", test.id, got, test.want)
+		}
+	}
+}
+
 func TestAccPostgresqlGrant(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -346,6 +555,12 @@ func TestAccPostgresqlGrant(t *testing.T) {
 					},
 				),
 			},
+			{
+				ResourceName:      "postgresql_grant.test",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s/%s/test_schema/table", roleName, dbName),
+				ImportStateVerify: true,
+			},
 			{
 				Config: fmt.Sprintf(testGrant, `["SELECT", "INSERT", "UPDATE"]`),
 				Check: resource.ComposeTestCheckFunc(
@@ -369,6 +584,21 @@ func TestAccPostgresqlGrant(t *testing.T) {
 					},
 				),
 			},
+			// ALL is kept as is in the state (the plan after the apply is empty)
+			{
+				Config: fmt.Sprintf(testGrant, `["ALL"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.0", "ALL"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT", "INSERT", "UPDATE", "DELETE"})
+					},
+				),
+			},
+			{
+				Config:   fmt.Sprintf(testGrant, `["ALL"]`),
+				PlanOnly: true,
+			},
 			// We test to revoke everything
 			{
 				Config: fmt.Sprintf(testGrant, `[]`),
@@ -383,6 +613,154 @@ func TestAccPostgresqlGrant(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlGrantMaintain(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges  = %%s
+	}
+	`, dbName, roleName)
+
+	// REINDEX requires the MAINTAIN privilege (or the ownership of the table)
+	checkMaintain := func(allowed bool) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			db := connectAsTestRole(t, roleName, dbName)
+			defer db.Close()
+			return testHasGrantForQuery(db, "REINDEX TABLE test_schema.test_table", allowed)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureMaintainPrivilege)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, `["SELECT", "MAINTAIN"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "2"),
+					resource.TestCheckTypeSetElemAttr("postgresql_grant.test", "privileges.*", "MAINTAIN"),
+					checkMaintain(true),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, `["SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					checkMaintain(false),
+				),
+			},
+			// ALL includes MAINTAIN
+			{
+				Config: fmt.Sprintf(testGrant, `["ALL"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.0", "ALL"),
+					checkMaintain(true),
+				),
+			},
+		},
+	})
+}
+
+func TestGrantExpired(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expiresAt string
+		want      bool
+	}{
+		{"", false},
+		{"2026-10-14T12:00:01Z", false},
+		{"2026-10-14T12:00:00Z", true},
+		{"2026-10-14T13:00:00+02:00", true},
+	}
+	for _, test := range tests {
+		if got := grantExpired(test.expiresAt, now); got != test.want {
+			t.Errorf("grantExpired(%q) = %t, want %t", test.expiresAt, got, test.want)
+		}
+	}
+}
+
+func TestAccPostgresqlGrantExpiresAt(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges  = ["SELECT"]
+		expires_at  = "%%s"
+	}
+	`, dbName, roleName)
+
+	checkPrivileges := func(privileges []string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			return testCheckTablesPrivileges(t, dbName, roleName, testTables, privileges)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testGrant, "2000-01-01T00:00:00Z"),
+				ExpectError: regexp.MustCompile("expires_at 2000-01-01T00:00:00Z is in the past"),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, "2100-01-01T00:00:00Z"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "expired", "false"),
+					checkPrivileges([]string{"SELECT"}),
+				),
+			},
+			// The grant expires at the next apply
+			{
+				Config: fmt.Sprintf(testGrant, "2000-01-01T00:00:00Z"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "expired", "true"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					checkPrivileges([]string{}),
+				),
+			},
+			// Extending it grants the privileges again
+			{
+				Config: fmt.Sprintf(testGrant, "2100-01-01T00:00:00Z"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "expired", "false"),
+					checkPrivileges([]string{"SELECT"}),
+				),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlGrantColumns(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -538,30 +916,245 @@ func TestAccPostgresqlGrantObjects(t *testing.T) {
 						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[0]}, []string{"SELECT"})
 					},
 					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[1]}, []string{})
+						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[1]}, []string{})
+					},
+				),
+			},
+			{
+				// Empty list means that privileges will be applied on all tables.
+				Config: fmt.Sprintf(testGrant, `[]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "0"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+					},
+				),
+			},
+			{
+				Config:  fmt.Sprintf(testGrant, `[]`),
+				Destroy: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "0"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestParseCrossSchemaTables(t *testing.T) {
+	tables, err := parseCrossSchemaTables(schema.NewSet(schema.HashString, []interface{}{`"my.schema"."Test"`}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []partitionTable{{schema: "my.schema", name: "Test"}}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("parseCrossSchemaTables = %v, want %v", tables, want)
+	}
+
+	for _, object := range []string{"test_table", "a.b.c", ".test_table"} {
+		if _, err := parseCrossSchemaTables(schema.NewSet(schema.HashString, []interface{}{object})); err == nil {
+			t.Errorf("parseCrossSchemaTables(%q) should fail", object)
+		}
+	}
+}
+
+func TestGroupTablesBySchema(t *testing.T) {
+	tables := []partitionTable{
+		{schema: "test_schema", name: "a"},
+		{schema: "dev_schema", name: "b"},
+		{schema: "test_schema", name: "c"},
+	}
+	want := [][]partitionTable{
+		{{schema: "dev_schema", name: "b"}},
+		{{schema: "test_schema", name: "a"}, {schema: "test_schema", name: "c"}},
+	}
+	if got := groupTablesBySchema(tables); !reflect.DeepEqual(got, want) {
+		t.Errorf("groupTablesBySchema = %v, want %v", got, want)
+	}
+}
+
+func TestAccPostgresqlGrantCrossSchemaTables(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table", "dev_schema.test_table", "dev_schema.test_table2"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		object_type = "table"
+		objects     = %%s
+		privileges  = ["SELECT"]
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testGrant, `["test_table"]`),
+				ExpectError: regexp.MustCompile("must be qualified with its schema"),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, `["test_schema.test_table", "dev_schema.test_table"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "2"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables[:2], []string{"SELECT"})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables[2:], []string{})
+					},
+				),
+			},
+			// A privilege revoked outside of Terraform on one of the tables is granted again.
+			{
+				PreConfig: func() {
+					config := getTestConfig(t)
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("REVOKE SELECT ON dev_schema.test_table FROM %s", roleName))
+				},
+				Config: fmt.Sprintf(testGrant, `["test_schema.test_table", "dev_schema.test_table"]`),
+				Check: func(*terraform.State) error {
+					return testCheckTablesPrivileges(t, dbName, roleName, testTables[:2], []string{"SELECT"})
+				},
+			},
+			{
+				Config:  fmt.Sprintf(testGrant, `["test_schema.test_table", "dev_schema.test_table"]`),
+				Destroy: true,
+				Check: func(*terraform.State) error {
+					return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
+				},
+			},
+		},
+	})
+}
+
+// TestAccPostgresqlGrantPrivilegesOrder checks that the privileges are compared as a set, whatever the order
+// they are written in the configuration or returned by the catalog.
+func TestAccPostgresqlGrantPrivilegesOrder(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges  = %%s
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, `["INSERT", "SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "2"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"INSERT", "SELECT"})
+					},
+				),
+			},
+			{
+				Config:   fmt.Sprintf(testGrant, `["SELECT", "INSERT"]`),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantPartitions(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr(dbName), `
+		CREATE TABLE test_schema.measures (id int) PARTITION BY RANGE (id);
+		CREATE TABLE test_schema.measures_1 PARTITION OF test_schema.measures FOR VALUES FROM (0) TO (10);
+	`)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database           = "%s"
+		role               = "%s"
+		schema             = "test_schema"
+		object_type        = "table"
+		objects            = %%s
+		include_partitions = %%t
+		recurse_partitions = %%t
+		privileges         = ["SELECT"]
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePartitionedTables)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, `["measures"]`, true, true),
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, []string{"test_schema.measures", "test_schema.measures_1"}, []string{"SELECT"})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
 					},
 				),
 			},
 			{
-				// Empty list means that privileges will be applied on all tables.
-				Config: fmt.Sprintf(testGrant, `[]`),
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "0"),
-					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
-					},
-				),
+				Config:   fmt.Sprintf(testGrant, `["measures"]`, true, true),
+				PlanOnly: true,
 			},
 			{
-				Config:  fmt.Sprintf(testGrant, `[]`),
-				Destroy: true,
+				Config: fmt.Sprintf(testGrant, `[]`, false, false),
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "0"),
 					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
+						return testCheckTablesPrivileges(t, dbName, roleName, []string{"test_schema.measures", "test_schema.test_table"}, []string{"SELECT"})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, []string{"test_schema.measures_1"}, []string{})
 					},
 				),
 			},
+			{
+				Config:   fmt.Sprintf(testGrant, `[]`, false, false),
+				PlanOnly: true,
+			},
 		},
 	})
 }
@@ -948,6 +1541,266 @@ resource postgresql_grant "test" {
 	}
 }
 
+func TestAccPostgresqlGrantFunctionOverload(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE test_role LOGIN PASSWORD '%s'", testRolePassword))
+	dbExecute(t, dsn, "CREATE SCHEMA test_schema")
+	dbExecute(t, dsn, "GRANT USAGE ON SCHEMA test_schema TO test_role")
+	dbExecute(t, dsn, "ALTER DEFAULT PRIVILEGES REVOKE ALL ON FUNCTIONS FROM PUBLIC")
+
+	// Create two overloads of the same function
+	dbExecute(t, dsn, `
+CREATE FUNCTION test_schema.test(arg integer) RETURNS text
+	AS $$ select 'int'::text $$
+    LANGUAGE SQL;
+CREATE FUNCTION test_schema.test(arg text) RETURNS text
+	AS $$ select 'text'::text $$
+    LANGUAGE SQL;
+`)
+	defer func() {
+		dbExecute(t, dsn, "DROP SCHEMA test_schema CASCADE")
+		dbExecute(t, dsn, "DROP ROLE test_role")
+		dbExecute(t, dsn, "ALTER DEFAULT PRIVILEGES GRANT EXECUTE ON FUNCTIONS TO PUBLIC")
+	}()
+
+	// The function is schema-qualified and its argument type is not spelled as in the catalog (int / integer).
+	tfConfig := `
+resource postgresql_grant "test" {
+  database    = "postgres"
+  role        = "test_role"
+  schema      = "test_schema"
+  object_type = "function"
+  privileges  = ["EXECUTE"]
+  objects     = ["test_schema.test(int)"]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					testCheckFunctionWithArgsExecutable(t, "test_role", "test_schema.test", []string{"1"}),
+					func(*terraform.State) error {
+						db := connectAsTestRole(t, "test_role", "postgres")
+						defer db.Close()
+						return testHasGrantForQuery(db, "SELECT test_schema.test('foo'::text)", false)
+					},
+				),
+			},
+			// The privileges of the targeted overload are read back without drift.
+			{
+				Config:   tfConfig,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestCreateRevokePublicExecuteQuery(t *testing.T) {
+	cases := []struct {
+		resource *schema.ResourceData
+		expected string
+	}{
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "function",
+				"schema":      "test_schema",
+				"role":        "test_role",
+			}),
+			expected: `REVOKE EXECUTE ON ALL FUNCTIONS IN SCHEMA "test_schema" FROM PUBLIC`,
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "procedure",
+				"schema":      "test_schema",
+				"role":        "test_role",
+				"objects":     []interface{}{"p1"},
+			}),
+			expected: `REVOKE EXECUTE ON PROCEDURE "test_schema"."p1" FROM PUBLIC`,
+		},
+	}
+
+	for _, c := range cases {
+		if out := createRevokePublicExecuteQuery(c.resource); out != c.expected {
+			t.Fatalf("Error matching output and expected: %#v vs %#v", out, c.expected)
+		}
+	}
+}
+
+func TestAccPostgresqlGrantFunctionRevokePublicExecute(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	// New functions are executable by PUBLIC by default.
+	dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE test_role LOGIN PASSWORD '%s'", testRolePassword))
+	dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE test_role_other LOGIN PASSWORD '%s'", testRolePassword))
+	dbExecute(t, dsn, "CREATE SCHEMA test_schema")
+	dbExecute(t, dsn, "GRANT USAGE ON SCHEMA test_schema TO test_role, test_role_other")
+	dbExecute(t, dsn, `
+CREATE FUNCTION test_schema.test() RETURNS text
+	AS $$ select 'foo'::text $$
+    LANGUAGE SQL;
+`)
+	defer func() {
+		dbExecute(t, dsn, "DROP SCHEMA test_schema CASCADE")
+		dbExecute(t, dsn, "DROP ROLE test_role")
+		dbExecute(t, dsn, "DROP ROLE test_role_other")
+	}()
+
+	tfConfig := `
+resource postgresql_grant "test" {
+  database                           = "postgres"
+  role                               = "test_role"
+  schema                             = "test_schema"
+  object_type                        = "function"
+  privileges                         = ["EXECUTE"]
+  revoke_public_execute_on_functions = true
+}
+`
+
+	checks := resource.ComposeTestCheckFunc(
+		resource.TestCheckResourceAttr("postgresql_grant.test", "revoke_public_execute_on_functions", "true"),
+		testCheckFunctionExecutable(t, "test_role", "test_schema.test"),
+		func(*terraform.State) error {
+			db := connectAsTestRole(t, "test_role_other", "postgres")
+			defer db.Close()
+			return testHasGrantForQuery(db, "SELECT test_schema.test()", false)
+		},
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check:  checks,
+			},
+			// EXECUTE granted back to PUBLIC outside of Terraform is revoked again.
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "GRANT EXECUTE ON FUNCTION test_schema.test() TO PUBLIC")
+				},
+				Config: tfConfig,
+				Check:  checks,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantSchemaRevokePublicUsage(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE test_role LOGIN PASSWORD '%s'", testRolePassword))
+	dbExecute(t, dsn, "CREATE SCHEMA test_schema")
+	dbExecute(t, dsn, "GRANT USAGE ON SCHEMA test_schema TO PUBLIC")
+	defer func() {
+		dbExecute(t, dsn, "DROP SCHEMA test_schema CASCADE")
+		dbExecute(t, dsn, "DROP ROLE test_role")
+	}()
+
+	tfConfig := `
+resource postgresql_grant "test" {
+  database            = "postgres"
+  role                = "test_role"
+  schema              = "test_schema"
+  object_type         = "schema"
+  privileges          = ["USAGE"]
+  revoke_public_usage = true
+}
+`
+
+	checkUsage := func(role string, expected bool) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			db, err := sql.Open("postgres", dsn)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			var usage bool
+			if err := db.QueryRow("SELECT has_schema_privilege($1, 'test_schema', 'USAGE')", role).Scan(&usage); err != nil {
+				return fmt.Errorf("could not read the privileges of %s: %w", role, err)
+			}
+			if usage != expected {
+				return fmt.Errorf("expected USAGE of %s on test_schema to be %t, got %t", role, expected, usage)
+			}
+			return nil
+		}
+	}
+
+	checks := resource.ComposeTestCheckFunc(
+		resource.TestCheckResourceAttr("postgresql_grant.test", "revoke_public_usage", "true"),
+		checkUsage("test_role", true),
+		checkUsage("public", false),
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check:  checks,
+			},
+			// USAGE granted back to PUBLIC outside of Terraform is detected and revoked again.
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "GRANT USAGE ON SCHEMA test_schema TO PUBLIC")
+				},
+				Config:             tfConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: tfConfig,
+				Check:  checks,
+			},
+		},
+	})
+}
+
+func TestQuoteFunctionObject(t *testing.T) {
+	tests := []struct {
+		object string
+		want   string
+	}{
+		{"test", `"test_schema"."test"`},
+		{"test(text, char)", `"test_schema"."test"(text, char)`},
+		{"other.test(integer)", `"other"."test"(integer)`},
+		{`"my.schema"."Test"()`, `"my.schema"."Test"()`},
+		{`"test(1)"`, `"test_schema"."test(1)"`},
+		{`other."test(1)"(integer)`, `"other"."test(1)"(integer)`},
+	}
+	for _, test := range tests {
+		if got := quoteFunctionObject("test_schema", test.object); got != test.want {
+			t.Errorf("quoteFunctionObject(test_schema, %q) = %s, want %s", test.object, got, test.want)
+		}
+	}
+}
+
 func TestAccPostgresqlGrantProcedure(t *testing.T) {
 	skipIfNotAcc(t)
 	testCheckCompatibleVersion(t, featureProcedure)
@@ -1127,6 +1980,19 @@ resource "postgresql_grant" "test" {
 					testCheckDatabasesPrivileges(t, true),
 				),
 			},
+			// ALL includes CREATE and is kept as is in the state
+			{
+				Config: fmt.Sprintf(config, `["ALL"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckTypeSetElemAttr("postgresql_grant.test", "privileges.*", "ALL"),
+					testCheckDatabasesPrivileges(t, true),
+				),
+			},
+			{
+				Config:   fmt.Sprintf(config, `["ALL"]`),
+				PlanOnly: true,
+			},
 			// Revoke
 			{
 				Config: fmt.Sprintf(config, "[]"),
@@ -1139,6 +2005,153 @@ resource "postgresql_grant" "test" {
 	})
 }
 
+func TestAccPostgresqlGrantDatabasePublic(t *testing.T) {
+	// A fresh database has a NULL datacl, meaning PUBLIC has the default
+	// CONNECT and TEMPORARY privileges.
+	config := `
+resource "postgresql_database" "test_db" {
+	name = "test_grant_public_db"
+}
+
+resource "postgresql_grant" "test" {
+	database    = postgresql_database.test_db.name
+	role        = "public"
+	object_type = "database"
+	privileges  = %s
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, `["CONNECT", "TEMPORARY"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "2"),
+					testCheckPublicDatabasePrivilege(t, "test_grant_public_db", "CONNECT", true),
+					testCheckPublicDatabasePrivilege(t, "test_grant_public_db", "TEMPORARY", true),
+				),
+			},
+			// Read privileges must match the configuration, without perpetual diff
+			{
+				Config:   fmt.Sprintf(config, `["CONNECT", "TEMPORARY"]`),
+				PlanOnly: true,
+			},
+			{
+				Config: fmt.Sprintf(config, "[]"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
+					testCheckPublicDatabasePrivilege(t, "test_grant_public_db", "CONNECT", false),
+					testCheckPublicDatabasePrivilege(t, "test_grant_public_db", "TEMPORARY", false),
+				),
+			},
+			{
+				Config:   fmt.Sprintf(config, "[]"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testCheckPublicDatabasePrivilege(t *testing.T, dbName, privilege string, expected bool) func(*terraform.State) error {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr("postgres"))
+		if err != nil {
+			return fmt.Errorf("could not connect to database postgres: %w", err)
+		}
+		defer db.Close()
+
+		var granted bool
+		if err := db.QueryRow(
+			"SELECT has_database_privilege('public', $1, $2)", dbName, privilege,
+		).Scan(&granted); err != nil {
+			return fmt.Errorf("could not check %s privilege of PUBLIC on database %s: %w", privilege, dbName, err)
+		}
+		if granted != expected {
+			return fmt.Errorf("expected PUBLIC to have %s on database %s: %t, got %t", privilege, dbName, expected, granted)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlGrantSchemaPublic(t *testing.T) {
+	// The ACL of the public schema set by initdb depends on the server version:
+	// PUBLIC has CREATE and USAGE until PostgreSQL 14, only USAGE since PostgreSQL 15
+	// (which is also the version introducing pg_database_owner).
+	config := `
+resource "postgresql_grant" "test" {
+	database    = "%s"
+	role        = "public"
+	schema      = "public"
+	object_type = "schema"
+	privileges  = %s
+}
+`
+	for _, tc := range []struct {
+		name       string
+		pg15       bool
+		privileges string
+		count      string
+	}{
+		{name: "before 15", pg15: false, privileges: `["CREATE", "USAGE"]`, count: "2"},
+		{name: "since 15", pg15: true, privileges: `["USAGE"]`, count: "1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			skipIfNotAcc(t)
+
+			dbSuffix, teardown := setupTestDatabase(t, true, true)
+			defer teardown()
+
+			dbName, _ := getTestDBNames(dbSuffix)
+
+			resource.Test(t, resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(t)
+					testCheckCompatibleVersion(t, featurePrivileges)
+
+					client := testAccProvider.Meta().(*Client)
+					db, err := client.Connect()
+					if err != nil {
+						t.Fatalf("could not connect to database: %v", err)
+					}
+					if db.featureSupported(featureDatabaseOwnerRole) != tc.pg15 {
+						t.Skipf("Skip test for Postgres %s", db.version)
+					}
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					// The default privileges must be read without any diff.
+					{
+						Config: fmt.Sprintf(config, dbName, tc.privileges),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", tc.count),
+						),
+					},
+					{
+						Config:   fmt.Sprintf(config, dbName, tc.privileges),
+						PlanOnly: true,
+					},
+					{
+						Config: fmt.Sprintf(config, dbName, "[]"),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
+						),
+					},
+					{
+						Config:   fmt.Sprintf(config, dbName, "[]"),
+						PlanOnly: true,
+					},
+				},
+			})
+		})
+	}
+}
+
 func TestAccPostgresqlGrantSchema(t *testing.T) {
 	// create a TF config with placeholder for privileges
 	// it will be filled in each step.
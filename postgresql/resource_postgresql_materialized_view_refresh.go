@@ -0,0 +1,204 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	matviewRefreshDatabaseAttr     = "database"
+	matviewRefreshSchemaAttr       = "schema"
+	matviewRefreshNameAttr         = "name"
+	matviewRefreshModeAttr         = "refresh_mode"
+	matviewRefreshTriggersAttr     = "triggers"
+	matviewRefreshConcurrentlyAttr = "refreshed_concurrently"
+
+	matviewRefreshModeBlocking   = "blocking"
+	matviewRefreshModeConcurrent = "concurrent"
+	matviewRefreshModeAuto       = "auto"
+
+	// REFRESH ... CONCURRENTLY requires a valid unique index using only column names and no WHERE clause.
+	getMatviewRefreshStateQuery = `
+SELECT m.ispopulated, EXISTS (
+	SELECT 1 FROM pg_catalog.pg_index AS i
+	WHERE i.indrelid = c.oid AND i.indisunique AND i.indisvalid AND i.indpred IS NULL AND i.indexprs IS NULL
+)
+FROM pg_catalog.pg_matviews AS m
+JOIN pg_catalog.pg_namespace AS n ON n.nspname = m.schemaname
+JOIN pg_catalog.pg_class AS c ON c.relnamespace = n.oid AND c.relname = m.matviewname
+WHERE m.schemaname = $1 AND m.matviewname = $2
+`
+)
+
+func resourcePostgreSQLMaterializedViewRefresh() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLMaterializedViewRefreshCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLMaterializedViewRefreshRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLMaterializedViewRefreshDelete),
+
+		// Refreshing a big materialized view runs its whole query again.
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			matviewRefreshDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database of the materialized view. Defaults to the provider database",
+			},
+			matviewRefreshSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				ForceNew:    true,
+				Description: "The schema of the materialized view",
+			},
+			matviewRefreshNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the materialized view",
+			},
+			matviewRefreshModeAttr: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  matviewRefreshModeBlocking,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					matviewRefreshModeBlocking,
+					matviewRefreshModeConcurrent,
+					matviewRefreshModeAuto,
+				}, false),
+				Description: "How the materialized view is refreshed: `blocking` (REFRESH MATERIALIZED VIEW, which blocks the reads), " +
+					"`concurrent` (REFRESH MATERIALIZED VIEW CONCURRENTLY, which fails if the materialized view has no suitable unique index or is not populated) " +
+					"or `auto` (concurrently when possible, blocking otherwise)",
+			},
+			matviewRefreshTriggersAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values which refresh the materialized view again when they change (e.g. the ID of the resource loading its data)",
+			},
+			matviewRefreshConcurrentlyAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the materialized view was refreshed concurrently",
+			},
+		},
+	}
+}
+
+// resourcePostgreSQLMaterializedViewRefreshCreate refreshes the materialized view, it is only refreshed when the
+// resource is created, i.e. when any of its attributes changes.
+func resourcePostgreSQLMaterializedViewRefreshCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	schemaName := d.Get(matviewRefreshSchemaAttr).(string)
+	name := d.Get(matviewRefreshNameAttr).(string)
+
+	var concurrently bool
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		var populated, hasUniqueIndex bool
+		err := txn.QueryRow(getMatviewRefreshStateQuery, schemaName, name).Scan(&populated, &hasUniqueIndex)
+		switch {
+		case err == sql.ErrNoRows:
+			return fmt.Errorf("materialized view %s.%s does not exist in database %s", schemaName, name, database)
+		case err != nil:
+			return fmt.Errorf("could not read materialized view %s.%s: %w", schemaName, name, err)
+		}
+
+		concurrently, err = matviewRefreshConcurrently(d.Get(matviewRefreshModeAttr).(string), populated, hasUniqueIndex)
+		if err != nil {
+			return fmt.Errorf("cannot refresh materialized view %s.%s: %w", schemaName, name, err)
+		}
+
+		if _, err := txn.Exec(matviewRefreshQuery(schemaName, name, concurrently)); err != nil {
+			return fmt.Errorf("could not refresh materialized view %s.%s: %w", schemaName, name, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.Set(matviewRefreshDatabaseAttr, database)
+	d.Set(matviewRefreshConcurrentlyAttr, concurrently)
+	d.SetId(strings.Join([]string{database, schemaName, name}, "."))
+
+	return nil
+}
+
+// resourcePostgreSQLMaterializedViewRefreshRead removes the resource from the state if the materialized view
+// doesn't exist anymore, so it is refreshed again once it is created again.
+func resourcePostgreSQLMaterializedViewRefreshRead(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	schemaName := d.Get(matviewRefreshSchemaAttr).(string)
+	name := d.Get(matviewRefreshNameAttr).(string)
+
+	var exists bool
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		return txn.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_matviews WHERE schemaname = $1 AND matviewname = $2)",
+			schemaName, name,
+		).Scan(&exists)
+	}); err != nil {
+		return fmt.Errorf("could not read materialized view %s.%s: %w", schemaName, name, err)
+	}
+
+	if !exists {
+		log.Printf("[WARN] PostgreSQL materialized view (%s.%s) not found in database %s", schemaName, name, database)
+		d.SetId("")
+	}
+
+	return nil
+}
+
+// resourcePostgreSQLMaterializedViewRefreshDelete only removes the resource from the state.
+func resourcePostgreSQLMaterializedViewRefreshDelete(db *DBConnection, d *schema.ResourceData) error {
+	d.SetId("")
+	return nil
+}
+
+// matviewRefreshConcurrently returns whether the materialized view is refreshed concurrently with mode,
+// or why it can't be refreshed concurrently as requested.
+func matviewRefreshConcurrently(mode string, populated, hasUniqueIndex bool) (bool, error) {
+	switch mode {
+	case matviewRefreshModeBlocking:
+		return false, nil
+	case matviewRefreshModeConcurrent:
+		if !hasUniqueIndex {
+			return false, fmt.Errorf(
+				"refresh_mode is %s but it has no unique index using only column names and no WHERE clause. "+
+					"Create such an index, or set refresh_mode to %s or %s",
+				matviewRefreshModeConcurrent, matviewRefreshModeBlocking, matviewRefreshModeAuto,
+			)
+		}
+		if !populated {
+			return false, fmt.Errorf(
+				"refresh_mode is %s but it is not populated (WITH NO DATA), it must be refreshed once with refresh_mode set to %s or %s",
+				matviewRefreshModeConcurrent, matviewRefreshModeBlocking, matviewRefreshModeAuto,
+			)
+		}
+		return true, nil
+	case matviewRefreshModeAuto:
+		return populated && hasUniqueIndex, nil
+	}
+	return false, fmt.Errorf("unknown refresh_mode %q", mode)
+}
+
+func matviewRefreshQuery(schemaName, name string, concurrently bool) string {
+	concurrentlyClause := ""
+	if concurrently {
+		concurrentlyClause = "CONCURRENTLY "
+	}
+	return fmt.Sprintf("REFRESH MATERIALIZED VIEW %s%s.%s", concurrentlyClause, pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(name))
+}
@@ -0,0 +1,86 @@
+package postgresql
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatviewRefreshConcurrently(t *testing.T) {
+	for _, test := range []struct {
+		mode           string
+		populated      bool
+		hasUniqueIndex bool
+		concurrently   bool
+		err            string
+	}{
+		{mode: "blocking", populated: true, hasUniqueIndex: true, concurrently: false},
+		{mode: "concurrent", populated: true, hasUniqueIndex: true, concurrently: true},
+		{mode: "concurrent", populated: true, hasUniqueIndex: false, err: "no unique index"},
+		{mode: "concurrent", populated: false, hasUniqueIndex: true, err: "not populated"},
+		{mode: "auto", populated: true, hasUniqueIndex: true, concurrently: true},
+		{mode: "auto", populated: true, hasUniqueIndex: false, concurrently: false},
+		{mode: "auto", populated: false, hasUniqueIndex: true, concurrently: false},
+	} {
+		concurrently, err := matviewRefreshConcurrently(test.mode, test.populated, test.hasUniqueIndex)
+		if test.err != "" {
+			assert.ErrorContains(t, err, test.err, test.mode)
+			continue
+		}
+		assert.NoError(t, err, test.mode)
+		assert.Equal(t, test.concurrently, concurrently, test.mode)
+	}
+}
+
+func TestMatviewRefreshQuery(t *testing.T) {
+	assert.Equal(t, `REFRESH MATERIALIZED VIEW "public"."my view"`, matviewRefreshQuery("public", "my view", false))
+	assert.Equal(t, `REFRESH MATERIALIZED VIEW CONCURRENTLY "public"."my view"`, matviewRefreshQuery("public", "my view", true))
+}
+
+func TestAccPostgresqlMaterializedViewRefresh_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr(dbName), "CREATE MATERIALIZED VIEW test_schema.test_matview AS SELECT 1 AS id")
+
+	tfConfig := `
+resource "postgresql_materialized_view_refresh" "test" {
+	database     = "%s"
+	schema       = "test_schema"
+	name         = "test_matview"
+	refresh_mode = "%s"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(tfConfig, dbName, "concurrent"),
+				ExpectError: regexp.MustCompile("no unique index"),
+			},
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, "auto"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_materialized_view_refresh.test", "id", fmt.Sprintf("%s.test_schema.test_matview", dbName)),
+					resource.TestCheckResourceAttr("postgresql_materialized_view_refresh.test", "refreshed_concurrently", "false"),
+				),
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "CREATE UNIQUE INDEX ON test_schema.test_matview (id)")
+				},
+				Config: fmt.Sprintf(tfConfig, dbName, "concurrent"),
+				Check:  resource.TestCheckResourceAttr("postgresql_materialized_view_refresh.test", "refreshed_concurrently", "true"),
+			},
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	notifyDatabaseAttr = "database"
+	notifyChannelAttr  = "channel"
+	notifyPayloadAttr  = "payload"
+	notifyTriggersAttr = "triggers"
+
+	// The payload must be shorter than 8000 bytes in the default configuration.
+	notifyMaxPayloadLength = 7999
+)
+
+func resourcePostgreSQLNotify() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLNotifyCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLNotifyRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLNotifyDelete),
+
+		Schema: map[string]*schema.Schema{
+			notifyDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database in which the notification is sent. Defaults to the provider database",
+			},
+			notifyChannelAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringLenBetween(1, 63),
+				Description:  "The name of the notification channel",
+			},
+			notifyPayloadAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringLenBetween(0, notifyMaxPayloadLength),
+				Description:  "The payload of the notification",
+			},
+			notifyTriggersAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values which send the notification again when they change (e.g. the ID of the resource whose changes are notified)",
+			},
+		},
+	}
+}
+
+// resourcePostgreSQLNotifyCreate sends the notification, it is only sent when the resource is created,
+// i.e. when any of its attributes changes. It is delivered to the listeners once the transaction is committed.
+func resourcePostgreSQLNotifyCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	channel := d.Get(notifyChannelAttr).(string)
+
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		if _, err := txn.Exec("SELECT pg_notify($1, $2)", channel, d.Get(notifyPayloadAttr).(string)); err != nil {
+			return fmt.Errorf("could not notify channel %s: %w", channel, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.Set(notifyDatabaseAttr, database)
+	d.SetId(strings.Join([]string{database, channel}, "."))
+
+	return nil
+}
+
+// resourcePostgreSQLNotifyRead does nothing as a notification leaves nothing in the server.
+func resourcePostgreSQLNotifyRead(db *DBConnection, d *schema.ResourceData) error {
+	return nil
+}
+
+// resourcePostgreSQLNotifyDelete only removes the resource from the state, a sent notification can't be revoked.
+func resourcePostgreSQLNotifyDelete(db *DBConnection, d *schema.ResourceData) error {
+	d.SetId("")
+	return nil
+}
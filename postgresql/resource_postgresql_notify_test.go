@@ -0,0 +1,64 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
+)
+
+func TestAccPostgresqlNotify_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	listener := pq.NewListener(config.connStr("postgres"), time.Second, time.Minute, nil)
+	defer listener.Close()
+	if err := listener.Listen("tf_test_channel"); err != nil {
+		t.Fatalf("could not listen to channel: %v", err)
+	}
+
+	tfConfig := `
+resource "postgresql_notify" "test" {
+	database = "postgres"
+	channel  = "tf_test_channel"
+	payload  = "%s"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, "first"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_notify.test", "id", "postgres.tf_test_channel"),
+					testCheckNotificationReceived(listener, "tf_test_channel", "first"),
+				),
+			},
+			// Changing the payload sends the notification again
+			{
+				Config: fmt.Sprintf(tfConfig, "second"),
+				Check:  testCheckNotificationReceived(listener, "tf_test_channel", "second"),
+			},
+		},
+	})
+}
+
+func testCheckNotificationReceived(listener *pq.Listener, channel, payload string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		for {
+			select {
+			case notification := <-listener.Notify:
+				if notification != nil && notification.Channel == channel && notification.Extra == payload {
+					return nil
+				}
+			case <-time.After(5 * time.Second):
+				return fmt.Errorf("notification %q not received on channel %s", payload, channel)
+			}
+		}
+	}
+}
@@ -8,10 +8,10 @@ import (
 
 func resourcePostgreSQLPhysicalReplicationSlot() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLPhysicalReplicationSlotCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLPhysicalReplicationSlotRead),
-		Delete: PGResourceFunc(resourcePostgreSQLPhysicalReplicationSlotDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLPhysicalReplicationSlotExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLPhysicalReplicationSlotCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLPhysicalReplicationSlotRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLPhysicalReplicationSlotDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLPhysicalReplicationSlotExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -24,16 +24,22 @@ const (
 
 func resourcePostgreSQLPublication() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLPublicationCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLPublicationRead),
-		Delete: PGResourceFunc(resourcePostgreSQLPublicationDelete),
-		Update: PGResourceFunc(resourcePostgreSQLPublicationUpdate),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLPublicationExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLPublicationCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLPublicationRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLPublicationDelete),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLPublicationUpdate),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLPublicationExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: map[string]*schema.Schema{
+			skipReplicationChecksAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip the check, before creating the publication, that `wal_level` is `logical` and `max_replication_slots` and `max_wal_senders` are not 0",
+			},
 			pubNameAttr: {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -52,8 +58,8 @@ func resourcePostgreSQLPublication() *schema.Resource {
 				Optional:     true,
 				Computed:     true,
 				ForceNew:     false,
-				Description:  "Sets the owner of the publication",
-				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "Sets the owner of the publication, either its name or its OID as `oid:NNN` to be unaffected by renames",
+				ValidateFunc: validation.All(validation.StringIsNotEmpty, validateOwner),
 			},
 			pubTablesAttr: {
 				Type:          schema.TypeSet,
@@ -104,31 +110,26 @@ func resourcePostgreSQLPublicationUpdate(db *DBConnection, d *schema.ResourceDat
 	}
 
 	database := getDatabaseForPublication(d, db.client.databaseName)
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return fmt.Errorf("could not start transaction: %w", err)
-	}
-
-	defer deferredRollback(txn)
-
-	if err := setPubOwner(txn, d); err != nil {
-		return fmt.Errorf("could not update publication owner: %w", err)
-	}
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		if err := setPubOwner(db, txn, d); err != nil {
+			return fmt.Errorf("could not update publication owner: %w", err)
+		}
 
-	if err := setPubTables(txn, d); err != nil {
-		return fmt.Errorf("could not update publication tables: %w", err)
-	}
+		if err := setPubTables(txn, d); err != nil {
+			return fmt.Errorf("could not update publication tables: %w", err)
+		}
 
-	if err := setPubParams(txn, d, db.featureSupported(featurePublishViaRoot)); err != nil {
-		return fmt.Errorf("could not update publication tables: %w", err)
-	}
+		if err := setPubParams(txn, d, db.featureSupported(featurePublishViaRoot)); err != nil {
+			return fmt.Errorf("could not update publication tables: %w", err)
+		}
 
-	if err := setPubName(txn, d); err != nil {
-		return fmt.Errorf("could not update publication name: %w", err)
-	}
+		if err := setPubName(txn, d); err != nil {
+			return fmt.Errorf("could not update publication name: %w", err)
+		}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error updating publication: %w", err)
+		return nil
+	}); err != nil {
+		return err
 	}
 	return resourcePostgreSQLPublicationReadImpl(db, d)
 }
@@ -149,20 +150,29 @@ func setPubName(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setPubOwner(txn *sql.Tx, d *schema.ResourceData) error {
+func setPubOwner(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(pubOwnerAttr) {
 		return nil
 	}
 
 	_, nraw := d.GetChange(pubOwnerAttr)
-	n := nraw.(string)
+	n, err := resolveOwner(txn, nraw.(string))
+	if err != nil {
+		return err
+	}
+	if n == "" {
+		return nil
+	}
 	pubName := d.Get(pubNameAttr).(string)
 
-	sql := fmt.Sprintf("ALTER PUBLICATION %s OWNER TO %s", pubName, n)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating publication owner: %w", err)
-	}
-	return nil
+	// If the connected user is not a superuser, it needs to be a member of the new owner.
+	return withRolesGranted(db, txn, []string{n}, func() error {
+		sql := fmt.Sprintf("ALTER PUBLICATION %s OWNER TO %s", pq.QuoteIdentifier(pubName), pq.QuoteIdentifier(n))
+		if _, err := txn.Exec(sql); err != nil {
+			return fmt.Errorf("Error updating publication owner: %w", err)
+		}
+		return nil
+	})
 }
 
 func setPubTables(txn *sql.Tx, d *schema.ResourceData) error {
@@ -216,6 +226,13 @@ func setPubParams(txn *sql.Tx, d *schema.ResourceData, pubViaRootEnabled bool) e
 	return nil
 }
 
+// publicationPrerequisites are the settings the publisher needs for logical replication.
+var publicationPrerequisites = []replicationPrerequisite{
+	{name: "wal_level", expected: "= logical", ok: func(value string) bool { return value == "logical" }},
+	{name: "max_replication_slots", expected: "> 0", ok: settingIsPositive},
+	{name: "max_wal_senders", expected: "> 0", ok: settingIsPositive},
+}
+
 func resourcePostgreSQLPublicationCreate(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featurePublication) {
 		return fmt.Errorf(
@@ -240,12 +257,19 @@ func resourcePostgreSQLPublicationCreate(db *DBConnection, d *schema.ResourceDat
 	}
 	defer deferredRollback(txn)
 
+	// CREATE PUBLICATION succeeds whatever these settings are, but the publication can't be subscribed to.
+	if !d.Get(skipReplicationChecksAttr).(bool) {
+		if err := checkReplicationPrerequisites(txn, fmt.Sprintf("publication %s", name), publicationPrerequisites); err != nil {
+			return err
+		}
+	}
+
 	sql := fmt.Sprintf("CREATE PUBLICATION %s %s %s", name, tables, publicationParameters)
 
 	if _, err := txn.Exec(sql); err != nil {
 		return fmt.Errorf("Error creating Publication: %w", err)
 	}
-	if err := setPubOwner(txn, d); err != nil {
+	if err := setPubOwner(db, txn, d); err != nil {
 		return fmt.Errorf("could not set publication owner during creation: %w", err)
 	}
 
@@ -324,13 +348,15 @@ func resourcePostgreSQLPublicationReadImpl(db *DBConnection, d *schema.ResourceD
 	var publishParams []string
 	var puballtables, pubinsert, pubupdate, pubdelete, pubtruncate, pubviaroot bool
 	var pubowner string
-	columns := []string{"puballtables", "pubinsert", "pubupdate", "pubdelete", "r.rolname as pubownername"}
+	var pubownerOID uint32
+	columns := []string{"puballtables", "pubinsert", "pubupdate", "pubdelete", "r.rolname as pubownername", "p.pubowner"}
 	values := []interface{}{
 		&puballtables,
 		&pubinsert,
 		&pubupdate,
 		&pubdelete,
 		&pubowner,
+		&pubownerOID,
 	}
 
 	if db.featureSupported(featurePublishViaRoot) {
@@ -392,7 +418,7 @@ func resourcePostgreSQLPublicationReadImpl(db *DBConnection, d *schema.ResourceD
 	d.SetId(generatePublicationID(d, database))
 	d.Set(pubNameAttr, PublicationName)
 	d.Set(pubDatabaseAttr, database)
-	d.Set(pubOwnerAttr, pubowner)
+	d.Set(pubOwnerAttr, ownerStateValue(d.Get(pubOwnerAttr).(string), pubowner, pubownerOID))
 	d.Set(pubTablesAttr, tables)
 	d.Set(pubAllTablesAttr, puballtables)
 	d.Set(pubPublishAttr, publishParams)
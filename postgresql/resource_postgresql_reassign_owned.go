@@ -0,0 +1,233 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	reassignOwnedDatabaseAttr         = "database"
+	reassignOwnedSchemaAttr           = "schema"
+	reassignOwnedOldRoleAttr          = "old_role"
+	reassignOwnedNewRoleAttr          = "new_role"
+	reassignOwnedRemainingObjectsAttr = "remaining_objects"
+
+	// The objects of the database, and the shared objects (databases, tablespaces), owned by the role.
+	// The default privileges of the role are not counted, REASSIGN OWNED leaves them (only DROP OWNED removes them).
+	countOwnedObjectsQuery = `
+SELECT count(*)
+FROM pg_catalog.pg_shdepend
+WHERE deptype = 'o'
+AND classid <> 'pg_catalog.pg_default_acl'::regclass
+AND refclassid = 'pg_catalog.pg_authid'::regclass
+AND refobjid = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1)
+AND dbid IN (0, (SELECT oid FROM pg_catalog.pg_database WHERE datname = pg_catalog.current_database()))
+`
+
+	// The objects of the schema owned by the role, and the schema itself, with the keyword of their ALTER ... OWNER TO.
+	// The indexes, the sequences owned by a column, the row types of the tables and the array types are not listed,
+	// they follow the owner of their table or element type.
+	listSchemaOwnedObjectsQuery = `
+SELECT kind, identity FROM (
+	SELECT 1 AS position,
+		CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'S' THEN 'SEQUENCE' WHEN 'f' THEN 'FOREIGN TABLE' ELSE 'TABLE' END AS kind,
+		pg_catalog.quote_ident(n.nspname) || '.' || pg_catalog.quote_ident(c.relname) AS identity
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1
+	AND c.relowner = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $2)
+	AND c.relkind IN ('r', 'p', 'v', 'm', 'S', 'f')
+	AND NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend d
+		WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid
+		AND d.refclassid = 'pg_catalog.pg_class'::regclass AND d.deptype IN ('a', 'i')
+	)
+	UNION ALL
+	SELECT 2,
+		CASE p.prokind WHEN 'p' THEN 'PROCEDURE' WHEN 'a' THEN 'AGGREGATE' ELSE 'FUNCTION' END,
+		pg_catalog.quote_ident(n.nspname) || '.' || pg_catalog.quote_ident(p.proname) || '(' || pg_catalog.pg_get_function_identity_arguments(p.oid) || ')'
+	FROM pg_catalog.pg_proc p
+	JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+	WHERE n.nspname = $1
+	AND p.proowner = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $2)
+	UNION ALL
+	SELECT 3,
+		CASE t.typtype WHEN 'd' THEN 'DOMAIN' ELSE 'TYPE' END,
+		pg_catalog.quote_ident(n.nspname) || '.' || pg_catalog.quote_ident(t.typname)
+	FROM pg_catalog.pg_type t
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+	WHERE n.nspname = $1
+	AND t.typowner = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $2)
+	AND t.typtype IN ('b', 'c', 'd', 'e', 'r')
+	AND (t.typrelid = 0 OR (SELECT relkind FROM pg_catalog.pg_class WHERE oid = t.typrelid) = 'c')
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_type e WHERE e.oid = t.typelem AND e.typarray = t.oid)
+	UNION ALL
+	SELECT 4, 'SCHEMA', pg_catalog.quote_ident(nspname)
+	FROM pg_catalog.pg_namespace
+	WHERE nspname = $1
+	AND nspowner = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $2)
+) objects
+ORDER BY position, identity
+`
+)
+
+func resourcePostgreSQLReassignOwned() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLReassignOwnedCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLReassignOwnedRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLReassignOwnedDelete),
+
+		Schema: map[string]*schema.Schema{
+			reassignOwnedDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database in which the objects are reassigned. Defaults to the provider database",
+			},
+			reassignOwnedSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The schema whose objects (tables, views, sequences, functions, procedures, aggregates and types) are reassigned, as well as the schema itself, with one `ALTER ... OWNER TO` per object. If not set, all the objects of `old_role` in `database` are reassigned with `REASSIGN OWNED`",
+			},
+			reassignOwnedOldRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The role whose objects are reassigned",
+			},
+			reassignOwnedNewRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The role which becomes the owner of the objects",
+			},
+			reassignOwnedRemainingObjectsAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of objects of the database (and of shared objects), or of `schema` if set, still owned by `old_role` at refresh time. If it is not 0, the objects are reassigned again on the next apply",
+			},
+		},
+	}
+}
+
+// resourcePostgreSQLReassignOwnedCreate runs REASSIGN OWNED in the database, or alters the owner of each object
+// of the schema. It is only run when the resource is created, i.e. when any of its attributes changes,
+// or when objects owned by old_role are found again (see Read).
+func resourcePostgreSQLReassignOwnedCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	schemaName := d.Get(reassignOwnedSchemaAttr).(string)
+	oldRole := d.Get(reassignOwnedOldRoleAttr).(string)
+	newRole := d.Get(reassignOwnedNewRoleAttr).(string)
+
+	// The kind of the routines (prokind) is only known since PostgreSQL 11.
+	if schemaName != "" && !db.featureSupported(featureRoutine) {
+		return db.unsupportedFeatureError(featureRoutine, "reassigning the objects of a schema")
+	}
+
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		return withRolesGranted(db, txn, []string{oldRole, newRole}, func() error {
+			if schemaName != "" {
+				return reassignSchemaOwnedObjects(txn, schemaName, oldRole, newRole)
+			}
+			if _, err := txn.Exec(fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(oldRole), pq.QuoteIdentifier(newRole))); err != nil {
+				return fmt.Errorf("could not reassign owned by role %s to %s in database %s: %w", oldRole, newRole, database, err)
+			}
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+
+	d.Set(reassignOwnedDatabaseAttr, database)
+	parts := []string{database, oldRole, newRole}
+	if schemaName != "" {
+		parts = []string{database, schemaName, oldRole, newRole}
+	}
+	for i, part := range parts {
+		parts[i] = quoteIdentifierIfNeeded(part)
+	}
+	d.SetId(strings.Join(parts, "."))
+
+	return resourcePostgreSQLReassignOwnedReadImpl(db, d)
+}
+
+func resourcePostgreSQLReassignOwnedRead(db *DBConnection, d *schema.ResourceData) error {
+	if err := resourcePostgreSQLReassignOwnedReadImpl(db, d); err != nil {
+		return err
+	}
+
+	if remaining := d.Get(reassignOwnedRemainingObjectsAttr).(int); remaining > 0 {
+		log.Printf("[WARN] %d objects are owned again by role %s, they will be reassigned", remaining, d.Get(reassignOwnedOldRoleAttr))
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourcePostgreSQLReassignOwnedReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	oldRole := d.Get(reassignOwnedOldRoleAttr).(string)
+
+	var remaining int
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		if schemaName := d.Get(reassignOwnedSchemaAttr).(string); schemaName != "" {
+			objects, err := listSchemaOwnedObjects(txn, schemaName, oldRole)
+			remaining = len(objects)
+			return err
+		}
+		return txn.QueryRow(countOwnedObjectsQuery, oldRole).Scan(&remaining)
+	}); err != nil {
+		return fmt.Errorf("could not count objects owned by role %s in database %s: %w", oldRole, database, err)
+	}
+
+	d.Set(reassignOwnedRemainingObjectsAttr, remaining)
+
+	return nil
+}
+
+// reassignSchemaOwnedObjects alters the owner of the objects of the schema owned by oldRole, and of the schema itself.
+func reassignSchemaOwnedObjects(txn *sql.Tx, schemaName, oldRole, newRole string) error {
+	objects, err := listSchemaOwnedObjects(txn, schemaName, oldRole)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if _, err := txn.Exec(fmt.Sprintf("ALTER %s %s OWNER TO %s", object[0], object[1], pq.QuoteIdentifier(newRole))); err != nil {
+			return fmt.Errorf("could not reassign %s %s to %s: %w", strings.ToLower(object[0]), object[1], newRole, err)
+		}
+	}
+	return nil
+}
+
+// listSchemaOwnedObjects returns the kind and the quoted name of the objects of the schema owned by role.
+func listSchemaOwnedObjects(txn *sql.Tx, schemaName, role string) ([][2]string, error) {
+	rows, err := txn.Query(listSchemaOwnedObjectsQuery, schemaName, role)
+	if err != nil {
+		return nil, fmt.Errorf("could not list objects of schema %s owned by role %s: %w", schemaName, role, err)
+	}
+	defer rows.Close()
+
+	var objects [][2]string
+	for rows.Next() {
+		var object [2]string
+		if err := rows.Scan(&object[0], &object[1]); err != nil {
+			return nil, fmt.Errorf("could not scan object of schema %s: %w", schemaName, err)
+		}
+		objects = append(objects, object)
+	}
+	return objects, rows.Err()
+}
+
+// resourcePostgreSQLReassignOwnedDelete only removes the resource from the state, the previous owners are not known.
+func resourcePostgreSQLReassignOwnedDelete(db *DBConnection, d *schema.ResourceData) error {
+	d.SetId("")
+	return nil
+}
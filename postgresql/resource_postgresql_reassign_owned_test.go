@@ -0,0 +1,228 @@
+package postgresql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccPostgresqlReassignOwned_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, oldRole := getTestDBNames(dbSuffix)
+	newRole := oldRole + "_new"
+	defer createTestRole(t, newRole)()
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER SCHEMA test_schema OWNER TO %s", oldRole))
+	dbExecute(t, config.connStr(dbName), "CREATE TABLE test_schema.test_table (id int)")
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER TABLE test_schema.test_table OWNER TO %s", oldRole))
+	// The new role is dropped before the database, so its objects are given back first.
+	defer dbExecute(t, config.connStr(dbName), fmt.Sprintf("REASSIGN OWNED BY %s, %s TO CURRENT_USER", oldRole, newRole))
+	// The default privileges are not reassigned, they must be revoked before the roles are dropped.
+	defer dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s REVOKE ALL ON TABLES FROM %s", oldRole, newRole))
+
+	tfConfig := fmt.Sprintf(`
+resource "postgresql_reassign_owned" "test" {
+	database = "%s"
+	old_role = "%s"
+	new_role = "%s"
+}
+`, dbName, oldRole, newRole)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_reassign_owned.test", "id", fmt.Sprintf("%s.%s.%s", dbName, oldRole, newRole)),
+					resource.TestCheckResourceAttr("postgresql_reassign_owned.test", "remaining_objects", "0"),
+					testCheckReassignedOwner(t, dbName, "test_schema", newRole),
+				),
+			},
+			// An object owned again by the old role is reassigned on the next apply.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER SCHEMA test_schema OWNER TO %s", oldRole))
+				},
+				Config: tfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_reassign_owned.test", "remaining_objects", "0"),
+					testCheckReassignedOwner(t, dbName, "test_schema", newRole),
+				),
+			},
+			// The default privileges of the old role, left by REASSIGN OWNED, are not remaining objects:
+			// the resource is not recreated (the plan after the apply is empty).
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s GRANT SELECT ON TABLES TO %s", oldRole, newRole))
+				},
+				Config: tfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_reassign_owned.test", "id", fmt.Sprintf("%s.%s.%s", dbName, oldRole, newRole)),
+					resource.TestCheckResourceAttr("postgresql_reassign_owned.test", "remaining_objects", "0"),
+				),
+			},
+		},
+	})
+}
+
+// Test that only the objects of the schema are reassigned, one by one.
+func TestReassignOwnedSchema(t *testing.T) {
+	reassigned := false
+	fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+		switch {
+		case strings.HasPrefix(query, "SELECT rolsuper, rolcreatedb"):
+			return &fakeRows{values: [][]driver.Value{{true, true, false}}}, nil
+		case query == "SELECT CURRENT_USER":
+			return &fakeRows{values: [][]driver.Value{{"postgres"}}}, nil
+		case query == listSchemaOwnedObjectsQuery && !reassigned:
+			reassigned = true
+			return &fakeRows{values: [][]driver.Value{
+				{"TABLE", "reporting.events"},
+				{"FUNCTION", "reporting.refresh(integer)"},
+				{"SCHEMA", "reporting"},
+			}}, nil
+		}
+		return nil, nil
+	}}
+	db, err := newFakeClient(t, fake, "16.0.0").Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLReassignOwned().Schema, map[string]interface{}{
+		reassignOwnedSchemaAttr:  "reporting",
+		reassignOwnedOldRoleAttr: "app_legacy",
+		reassignOwnedNewRoleAttr: "reporting_owner",
+	})
+	if err := resourcePostgreSQLReassignOwnedCreate(db, d); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []string
+	for _, statement := range fake.Statements() {
+		if strings.HasPrefix(statement, "ALTER ") || strings.HasPrefix(statement, "REASSIGN ") {
+			changes = append(changes, statement)
+		}
+	}
+	expected := []string{
+		`ALTER TABLE reporting.events OWNER TO "reporting_owner"`,
+		`ALTER FUNCTION reporting.refresh(integer) OWNER TO "reporting_owner"`,
+		`ALTER SCHEMA reporting OWNER TO "reporting_owner"`,
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("the reassignment sent %#v, expected %#v", changes, expected)
+	}
+	if d.Id() != "postgres.reporting.app_legacy.reporting_owner" {
+		t.Errorf("unexpected ID %s", d.Id())
+	}
+	if remaining := d.Get(reassignOwnedRemainingObjectsAttr).(int); remaining != 0 {
+		t.Errorf("expected no remaining objects, got %d", remaining)
+	}
+}
+
+func TestAccPostgresqlReassignOwned_Schema(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, oldRole := getTestDBNames(dbSuffix)
+	newRole := oldRole + "_new"
+	defer createTestRole(t, newRole)()
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER SCHEMA test_schema OWNER TO %s", oldRole))
+	dbExecute(t, config.connStr(dbName), "CREATE TABLE test_schema.test_table (id serial)")
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER TABLE test_schema.test_table OWNER TO %s", oldRole))
+	dbExecute(t, config.connStr(dbName), "CREATE FUNCTION test_schema.test_function() RETURNS int LANGUAGE sql AS 'SELECT 1'")
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER FUNCTION test_schema.test_function() OWNER TO %s", oldRole))
+	dbExecute(t, config.connStr(dbName), "CREATE TABLE public.test_other_table (id int)")
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER TABLE public.test_other_table OWNER TO %s", oldRole))
+	defer dbExecute(t, config.connStr(dbName), fmt.Sprintf("REASSIGN OWNED BY %s, %s TO CURRENT_USER", oldRole, newRole))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureRoutine)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_reassign_owned" "test" {
+	database = "%s"
+	schema   = "test_schema"
+	old_role = "%s"
+	new_role = "%s"
+}
+`, dbName, oldRole, newRole),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_reassign_owned.test", "remaining_objects", "0"),
+					testCheckReassignedOwner(t, dbName, "test_schema", newRole),
+					testCheckTableOwner(t, dbName, "public", "test_other_table", oldRole),
+				),
+			},
+		},
+	})
+}
+
+// testCheckTableOwner checks the owner of the table schemaName.table.
+func testCheckTableOwner(t *testing.T, dbName, schemaName, table, owner string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %w", dbName, err)
+		}
+		defer db.Close()
+
+		var tableOwner string
+		if err := db.QueryRow(
+			"SELECT tableowner FROM pg_catalog.pg_tables WHERE schemaname = $1 AND tablename = $2", schemaName, table,
+		).Scan(&tableOwner); err != nil {
+			return fmt.Errorf("could not read owner of table %s.%s: %w", schemaName, table, err)
+		}
+		if tableOwner != owner {
+			return fmt.Errorf("expected table %s.%s to be owned by %s, got %s", schemaName, table, owner, tableOwner)
+		}
+		return nil
+	}
+}
+
+func testCheckReassignedOwner(t *testing.T, dbName, schemaName, owner string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %w", dbName, err)
+		}
+		defer db.Close()
+
+		var schemaOwner, tableOwner string
+		if err := db.QueryRow(
+			"SELECT pg_catalog.pg_get_userbyid(nspowner), (SELECT tableowner FROM pg_catalog.pg_tables WHERE schemaname = $1 AND tablename = 'test_table') "+
+				"FROM pg_catalog.pg_namespace WHERE nspname = $1",
+			schemaName,
+		).Scan(&schemaOwner, &tableOwner); err != nil {
+			return fmt.Errorf("could not read owners of schema %s: %w", schemaName, err)
+		}
+		if schemaOwner != owner || tableOwner != owner {
+			return fmt.Errorf("expected schema %s and its table to be owned by %s, got %s and %s", schemaName, owner, schemaOwner, tableOwner)
+		}
+		return nil
+	}
+}
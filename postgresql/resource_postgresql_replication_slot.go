@@ -11,10 +11,10 @@ import (
 
 func resourcePostgreSQLReplicationSlot() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLReplicationSlotCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLReplicationSlotRead),
-		Delete: PGResourceFunc(resourcePostgreSQLReplicationSlotDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLReplicationSlotExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLReplicationSlotCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLReplicationSlotRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLReplicationSlotDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLReplicationSlotExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -1,18 +1,22 @@
 package postgresql
 
 import (
+	"context"
 	"crypto/md5"
 	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -26,16 +30,28 @@ const (
 	roleLoginAttr                           = "login"
 	roleNameAttr                            = "name"
 	rolePasswordAttr                        = "password"
+	rolePasswordSourceEnvAttr               = "password_source_env"
+	rolePasswordSourceFileAttr              = "password_source_file"
+	rolePasswordSourceHashAttr              = "password_source_hash"
+	roleIgnorePasswordChangesAttr           = "ignore_password_changes"
 	roleReplicationAttr                     = "replication"
 	roleSkipDropRoleAttr                    = "skip_drop_role"
 	roleSkipReassignOwnedAttr               = "skip_reassign_owned"
 	roleSuperuserAttr                       = "superuser"
 	roleValidUntilAttr                      = "valid_until"
+	roleActiveConnectionsAttr               = "active_connections"
+	roleOIDAttr                             = "oid"
+	rolePasswordEncryptionInUseAttr         = "password_encryption_in_use"
+	rolePasswordEncryptionAttr              = "password_encryption"
 	roleRolesAttr                           = "roles"
+	roleAdminAttr                           = "admin"
 	roleSearchPathAttr                      = "search_path"
 	roleStatementTimeoutAttr                = "statement_timeout"
 	roleAssumeRoleAttr                      = "assume_role"
 	roleLockTimeoutAttr                     = "lock_timeout"
+	roleConfigAttr                          = "config"
+	roleDatabaseConfigAttr                  = "database_config"
+	roleEffectivePreloadLibrariesAttr       = "effective_session_preload_libraries"
 
 	// Deprecated options
 	roleDepEncryptedAttr = "encrypted"
@@ -43,14 +59,15 @@ const (
 
 func resourcePostgreSQLRole() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLRoleCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLRoleRead),
-		Update: PGResourceFunc(resourcePostgreSQLRoleUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLRoleDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLRoleExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLRoleCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLRoleRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLRoleUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLRoleDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLRoleExists),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourcePostgreSQLRoleImport,
 		},
+		CustomizeDiff: resourcePostgreSQLRoleCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			roleNameAttr: {
@@ -62,7 +79,38 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				Description: "Sets the role's password",
+				Description: "Sets the role's password. An empty password removes it (PASSWORD NULL)",
+				ConflictsWith: []string{
+					rolePasswordSourceEnvAttr,
+					rolePasswordSourceFileAttr,
+				},
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return d.Id() != "" && d.Get(roleIgnorePasswordChangesAttr).(bool)
+				},
+			},
+			rolePasswordSourceEnvAttr: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Name of an environment variable from which the role's password is read at apply time. The password is not stored in the state, see `password_source_hash`",
+				ConflictsWith: []string{rolePasswordSourceFileAttr},
+				ValidateFunc:  validation.StringIsNotEmpty,
+			},
+			rolePasswordSourceFileAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Path of a file from which the role's password is read at apply time (trailing newlines are removed). The password is not stored in the state, see `password_source_hash`",
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			roleIgnorePasswordChangesAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the password is only set when the role is created: it is neither compared with the one of the role nor applied again, so it can be rotated outside of Terraform",
+			},
+			rolePasswordSourceHashAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "bcrypt hash of the password read from `password_source_env` or `password_source_file`, used to detect password changes without storing it in the state",
 			},
 			roleDepEncryptedAttr: {
 				Type:       schema.TypeString,
@@ -75,7 +123,14 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Set:         schema.HashString,
 				MinItems:    0,
-				Description: "Role(s) to grant to this new role",
+				Description: "Role(s) to grant to this new role. They are granted in the CREATE ROLE statement (IN ROLE)",
+			},
+			roleAdminAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Role(s) which are members of this role WITH ADMIN OPTION. They are set in the CREATE ROLE statement (ADMIN). Only the configured members are read, and removing one only revokes its ADMIN OPTION",
 			},
 			roleSearchPathAttr: {
 				Type:        schema.TypeList,
@@ -103,6 +158,33 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Description:  "How many concurrent connections can be made with this role",
 				ValidateFunc: validation.IntAtLeast(-1),
 			},
+			roleOIDAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The OID of the role, which can be used to reference it as `oid:NNN` in the owner of other objects",
+			},
+			rolePasswordEncryptionAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"md5", "scram-sha-256"}, false),
+				Description:  "The password_encryption used to hash the password when it is set (`md5` or `scram-sha-256`). Defaults to the one of the server. Changing it sets the password again",
+			},
+			rolePasswordEncryptionInUseAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "How the password of the role is stored: `md5`, `scram-sha-256`, `plain` or `none` if it has no password. Empty if the connection user can't read it (not a superuser)",
+			},
+			commentAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The comment of the role. It is set in the same transaction as the creation of the role. If not set, the comment is left as is, so a comment set outside of Terraform is kept",
+			},
+			roleActiveConnectionsAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of sessions of this role at refresh time (from pg_stat_activity), useful to check the headroom before lowering `connection_limit`",
+			},
 			roleSuperuserAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -131,7 +213,7 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     true,
-				Description: `Determine whether a role "inherits" the privileges of roles it is a member of`,
+				Description: `Determine whether a role "inherits" the privileges of roles it is a member of. If false (NOINHERIT), the role has to SET ROLE explicitly to use them`,
 			},
 			roleLoginAttr: {
 				Type:        schema.TypeBool,
@@ -180,10 +262,65 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Description:  "Abort any statement that waits longer than the specified amount of time while attempting to acquire a lock on a table, index, row, or other database object",
 				ValidateFunc: validation.IntAtLeast(0),
 			},
+			roleConfigAttr: {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				ValidateFunc:     validateRoleConfig,
+				DiffSuppressFunc: suppressConfigDiff,
+				Description: "Configuration parameters of the role (ALTER ROLE ... SET), e.g. `{ \"pgaudit.log\" = \"write, ddl\" }`. " +
+					"Only the parameters listed are managed. The parameters having a dedicated attribute (e.g. `search_path`) cannot be set here",
+			},
+			roleEffectivePreloadLibrariesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Description: "The libraries loaded at the start of the sessions of the role: its session_preload_libraries " +
+					"(e.g. set in `config`) if any, the server one otherwise (read from the configuration files, or its default value if they cannot be read, when the connection user or database sets its own). The settings of the role in a specific database (`database_config`) are not included",
+			},
+			roleDatabaseConfigAttr: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"database": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The database in which the configuration parameters are set",
+						},
+						"config": {
+							Type:        schema.TypeMap,
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Configuration parameters of the role in the database, e.g. `{ search_path = \"app, public\" }`",
+						},
+					},
+				},
+				Description: "Configuration parameters of the role in specific databases (ALTER ROLE ... IN DATABASE ... SET). " +
+					"Only the databases and parameters listed are managed. They are all imported with the role",
+			},
 		},
 	}
 }
 
+// roleDedicatedSettings are the configuration parameters managed by a dedicated attribute of the role.
+var roleDedicatedSettings = map[string]string{
+	"search_path":                         roleSearchPathAttr,
+	"statement_timeout":                   roleStatementTimeoutAttr,
+	"lock_timeout":                        roleLockTimeoutAttr,
+	"idle_in_transaction_session_timeout": roleIdleInTransactionSessionTimeoutAttr,
+	"role":                                roleAssumeRoleAttr,
+}
+
+func validateRoleConfig(v interface{}, k string) (ws []string, es []error) {
+	for name := range v.(map[string]interface{}) {
+		if attr, ok := roleDedicatedSettings[name]; ok {
+			es = append(es, fmt.Errorf("%s: %s must be set with the %s attribute", k, name, attr))
+		}
+	}
+	return ws, es
+}
+
 func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) error {
 	txn, err := startTransaction(db.client, "")
 	if err != nil {
@@ -191,6 +328,62 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 	}
 	defer deferredRollback(txn)
 
+	if err := setCitusRolePropagation(db, txn); err != nil {
+		return err
+	}
+
+	password, err := getRolePassword(d)
+	if err != nil {
+		return err
+	}
+
+	query, err := createRoleQuery(db, d, password)
+	if err != nil {
+		return err
+	}
+	loggedQuery := query
+	if password != "" {
+		loggedQuery, _ = createRoleQuery(db, d, "<redacted>")
+	}
+	log.Printf("[DEBUG] creating role: %s", loggedQuery)
+
+	// The password is part of CREATE ROLE so the role never exists without it (no separate ALTER ROLE),
+	// hashed with the requested password_encryption of this transaction.
+	if err := setRolePasswordEncryption(txn, d, password); err != nil {
+		return err
+	}
+
+	roleName := d.Get(roleNameAttr).(string)
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("error creating role %s: %w", roleName, err)
+	}
+
+	if err = setRoleSettings(txn, d); err != nil {
+		return err
+	}
+
+	if comment := d.Get(commentAttr).(string); comment != "" {
+		if err := setObjectComment(txn, "ROLE", pq.QuoteIdentifier(roleName), comment); err != nil {
+			return err
+		}
+	}
+
+	if err = txn.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	d.SetId(roleName)
+
+	if err := setRolePasswordSourceHash(d, password); err != nil {
+		return err
+	}
+
+	return resourcePostgreSQLRoleReadImpl(db, d)
+}
+
+// createRoleQuery returns the CREATE ROLE statement of the role. Every attribute is explicit
+// (e.g. NOSUPERUSER, CONNECTION LIMIT -1), so the role doesn't depend on the defaults of the server.
+func createRoleQuery(db *DBConnection, d *schema.ResourceData, password string) (string, error) {
 	stringOpts := []struct {
 		hclKey string
 		sqlKey string
@@ -222,16 +415,23 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 
 	if db.featureSupported(featureRLS) {
 		boolOpts = append(boolOpts, boolOptType{roleBypassRLSAttr, "BYPASSRLS", "NOBYPASSRLS"})
+	} else if d.Get(roleBypassRLSAttr).(bool) {
+		return "", db.unsupportedFeatureError(featureRLS, "PostgreSQL Row-Level Security")
 	}
 
 	if db.featureSupported(featureReplication) {
 		boolOpts = append(boolOpts, boolOptType{roleReplicationAttr, "REPLICATION", "NOREPLICATION"})
+	} else if d.Get(roleReplicationAttr).(bool) {
+		return "", db.unsupportedFeatureError(featureReplication, "role REPLICATION")
 	}
 
 	createOpts := make([]string, 0, len(stringOpts)+len(intOpts)+len(boolOpts))
 
 	for _, opt := range stringOpts {
 		v, ok := d.GetOk(opt.hclKey)
+		if opt.hclKey == rolePasswordAttr {
+			v, ok = password, password != ""
+		}
 		if !ok {
 			continue
 		}
@@ -240,16 +440,12 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 		if val != "" {
 			switch {
 			case opt.hclKey == rolePasswordAttr:
-				if strings.ToUpper(v.(string)) == "NULL" {
-					createOpts = append(createOpts, "PASSWORD NULL")
+				if d.Get(roleEncryptedPassAttr).(bool) {
+					createOpts = append(createOpts, "ENCRYPTED")
 				} else {
-					if d.Get(roleEncryptedPassAttr).(bool) {
-						createOpts = append(createOpts, "ENCRYPTED")
-					} else {
-						createOpts = append(createOpts, "UNENCRYPTED")
-					}
-					createOpts = append(createOpts, fmt.Sprintf("%s '%s'", opt.sqlKey, pqQuoteLiteral(val)))
+					createOpts = append(createOpts, "UNENCRYPTED")
 				}
+				createOpts = append(createOpts, fmt.Sprintf("%s '%s'", opt.sqlKey, pqQuoteLiteral(val)))
 			case opt.hclKey == roleValidUntilAttr:
 				switch {
 				case v.(string) == "", strings.ToLower(v.(string)) == "infinity":
@@ -282,6 +478,14 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 		createOpts = append(createOpts, valStr)
 	}
 
+	// The memberships are set up by CREATE ROLE itself
+	if roles := d.Get(roleRolesAttr).(*schema.Set); roles.Len() > 0 {
+		createOpts = append(createOpts, "IN ROLE "+setToPgIdentListWithoutSchema(roles))
+	}
+	if admins := d.Get(roleAdminAttr).(*schema.Set); admins.Len() > 0 {
+		createOpts = append(createOpts, "ADMIN "+setToPgIdentListWithoutSchema(admins))
+	}
+
 	roleName := d.Get(roleNameAttr).(string)
 	createStr := strings.Join(createOpts, " ")
 	if len(createOpts) > 0 {
@@ -293,42 +497,7 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 		}
 	}
 
-	sql := fmt.Sprintf("CREATE ROLE %s%s", pq.QuoteIdentifier(roleName), createStr)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("error creating role %s: %w", roleName, err)
-	}
-
-	if err = grantRoles(txn, d); err != nil {
-		return err
-	}
-
-	if err = alterSearchPath(txn, d); err != nil {
-		return err
-	}
-
-	if err = setStatementTimeout(txn, d); err != nil {
-		return err
-	}
-
-	if err = setLockTimeout(txn, d); err != nil {
-		return err
-	}
-
-	if err = setIdleInTransactionSessionTimeout(txn, d); err != nil {
-		return err
-	}
-
-	if err = setAssumeRole(txn, d); err != nil {
-		return err
-	}
-
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
-	}
-
-	d.SetId(roleName)
-
-	return resourcePostgreSQLRoleReadImpl(db, d)
+	return fmt.Sprintf("CREATE ROLE %s%s", pq.QuoteIdentifier(roleName), createStr), nil
 }
 
 func resourcePostgreSQLRoleDelete(db *DBConnection, d *schema.ResourceData) error {
@@ -340,12 +509,16 @@ func resourcePostgreSQLRoleDelete(db *DBConnection, d *schema.ResourceData) erro
 	}
 	defer deferredRollback(txn)
 
+	if err := setCitusRolePropagation(db, txn); err != nil {
+		return err
+	}
+
 	if err := pgLockRole(txn, roleName); err != nil {
 		return err
 	}
 
 	if !d.Get(roleSkipReassignOwnedAttr).(bool) {
-		if err := withRolesGranted(txn, []string{roleName}, func() error {
+		if err := withRolesGranted(db, txn, []string{roleName}, func() error {
 			currentUser := db.client.config.getDatabaseUsername()
 			if _, err := txn.Exec(fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(currentUser))); err != nil {
 				return fmt.Errorf("could not reassign owned by role %s to %s: %w", roleName, currentUser, err)
@@ -387,18 +560,151 @@ func resourcePostgreSQLRoleExists(db *DBConnection, d *schema.ResourceData) (boo
 	return true, nil
 }
 
+// resourcePostgreSQLRoleImport imports the role with all its configuration parameters (config and database_config):
+// as only the parameters listed in the state are read afterwards, they would be lost otherwise.
+func resourcePostgreSQLRoleImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	db, err := meta.(*Client).withContext(ctx).Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	var roleConfig pq.ByteaArray
+	err = db.QueryRow("SELECT rolconfig FROM pg_catalog.pg_roles WHERE rolname = $1", d.Id()).Scan(&roleConfig)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, fmt.Errorf("could not find role %s", d.Id())
+	case err != nil:
+		return nil, fmt.Errorf("could not read role %s: %w", d.Id(), err)
+	}
+
+	config := map[string]interface{}{}
+	for name, value := range parseConfig(roleConfig) {
+		if _, ok := roleDedicatedSettings[name]; !ok {
+			config[name] = value
+		}
+	}
+	d.Set(roleConfigAttr, config)
+
+	databaseConfig, err := readRoleDatabaseConfig(db, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	databases := make([]interface{}, 0, len(databaseConfig))
+	for database, settings := range databaseConfig {
+		config := make(map[string]interface{}, len(settings))
+		for name, value := range settings {
+			config[name] = value
+		}
+		databases = append(databases, map[string]interface{}{"database": database, "config": config})
+	}
+	d.Set(roleDatabaseConfigAttr, databases)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// readRoleDatabaseConfig returns the configuration parameters of the role in each database (pg_db_role_setting).
+func readRoleDatabaseConfig(db QueryAble, role string) (map[string]map[string]string, error) {
+	rows, err := db.Query(`
+SELECT d.datname, s.setconfig
+FROM pg_catalog.pg_db_role_setting AS s
+JOIN pg_catalog.pg_database AS d ON d.oid = s.setdatabase
+WHERE s.setrole = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1)
+`, role)
+	if err != nil {
+		return nil, fmt.Errorf("could not read configuration parameters of role %s in databases: %w", role, err)
+	}
+	defer rows.Close()
+
+	config := map[string]map[string]string{}
+	for rows.Next() {
+		var database string
+		var settings pq.ByteaArray
+		if err := rows.Scan(&database, &settings); err != nil {
+			return nil, fmt.Errorf("could not scan configuration parameters of role %s: %w", role, err)
+		}
+		config[database] = parseConfig(settings)
+	}
+	return config, rows.Err()
+}
+
+// managedDatabaseConfig returns the database_config to store in the state from the settings read:
+// only the databases and parameters of the state are kept. The values equal to the ones of
+// the state (see settingValuesEqual) are kept as is, as diffs can't be suppressed in a set.
+func managedDatabaseConfig(d *schema.ResourceData, settings map[string]map[string]string) []interface{} {
+	var managed []interface{}
+	for _, raw := range d.Get(roleDatabaseConfigAttr).(*schema.Set).List() {
+		entry := raw.(map[string]interface{})
+		database := entry["database"].(string)
+		config := map[string]interface{}{}
+		for name, value := range entry["config"].(map[string]interface{}) {
+			current, ok := settings[database][name]
+			switch {
+			case !ok:
+			case settingValuesEqual(name, value.(string), current):
+				config[name] = value
+			default:
+				config[name] = current
+			}
+		}
+		managed = append(managed, map[string]interface{}{"database": database, "config": config})
+	}
+	return managed
+}
+
+// roleDatabaseConfigQueries returns the ALTER ROLE ... IN DATABASE statements applying the changes of database_config.
+func roleDatabaseConfigQueries(d *schema.ResourceData) []string {
+	if !d.HasChange(roleDatabaseConfigAttr) {
+		return nil
+	}
+
+	byDatabase := func(raw interface{}) map[string]map[string]interface{} {
+		config := map[string]map[string]interface{}{}
+		for _, entry := range raw.(*schema.Set).List() {
+			entry := entry.(map[string]interface{})
+			config[entry["database"].(string)] = entry["config"].(map[string]interface{})
+		}
+		return config
+	}
+	oldRaw, newRaw := d.GetChange(roleDatabaseConfigAttr)
+	old, new := byDatabase(oldRaw), byDatabase(newRaw)
+
+	databases := make([]string, 0, len(old)+len(new))
+	for database := range old {
+		if _, ok := new[database]; !ok {
+			databases = append(databases, database)
+		}
+	}
+	for database := range new {
+		databases = append(databases, database)
+	}
+	sort.Strings(databases)
+
+	role := pq.QuoteIdentifier(d.Get(roleNameAttr).(string))
+	var queries []string
+	for _, database := range databases {
+		alterPrefix := fmt.Sprintf("ALTER ROLE %s IN DATABASE %s", role, pq.QuoteIdentifier(database))
+		queries = append(queries, configMapQueries(old[database], new[database], alterPrefix)...)
+	}
+	return queries
+}
+
 func resourcePostgreSQLRoleRead(db *DBConnection, d *schema.ResourceData) error {
 	return resourcePostgreSQLRoleReadImpl(db, d)
 }
 
 func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) error {
 	var roleSuperuser, roleInherit, roleCreateRole, roleCreateDB, roleCanLogin, roleReplication, roleBypassRLS bool
-	var roleConnLimit int
+	var roleConnLimit, roleActiveConns int
+	var roleOID uint32
+	var roleComment string
+	var serverPreloadLibraries sql.NullString
 	var roleName, roleValidUntil string
-	var roleRoles, roleConfig pq.ByteaArray
+	var roleRoles, roleSelfGrantedRoles, roleAdmins, roleConfig pq.ByteaArray
 
 	roleID := d.Id()
 
+	// All the attributes are read back (pg_roles is the view of pg_authid readable without superuser),
+	// so the ones altered outside of Terraform show up in the plan and are reverted.
 	columns := []string{
 		"rolname",
 		"rolsuper",
@@ -409,10 +715,17 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 		"rolconnlimit",
 		`COALESCE(rolvaliduntil::TEXT, 'infinity')`,
 		"rolconfig",
+		"(SELECT count(*) FROM pg_catalog.pg_stat_activity AS a WHERE a.usesysid = pg_roles.oid)",
+		"oid",
+		"COALESCE(pg_catalog.shobj_description(oid, 'pg_authid'), '')",
+		// NULL if the value of the session comes from the connection user or database, see readServerSetting.
+		"(SELECT setting FROM pg_catalog.pg_settings WHERE name = 'session_preload_libraries' AND source IN (" + serverSettingSources + "))",
 	}
 
 	values := []interface{}{
 		&roleRoles,
+		&roleSelfGrantedRoles,
+		&roleAdmins,
 		&roleName,
 		&roleSuperuser,
 		&roleInherit,
@@ -422,6 +735,10 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 		&roleConnLimit,
 		&roleValidUntil,
 		&roleConfig,
+		&roleActiveConns,
+		&roleOID,
+		&roleComment,
+		&serverPreloadLibraries,
 	}
 
 	if db.featureSupported(featureReplication) {
@@ -434,8 +751,32 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 		values = append(values, &roleBypassRLS)
 	}
 
+	// Fetch the password in the same query to avoid another round trip per role.
+	// Only superusers can read pg_shadow, see readRolePassword.
+	var rolePassword sql.NullString
+	canReadPassword := false
+	if db.client.config.Superuser {
+		superuser, err := db.isSuperuser()
+		if err != nil {
+			return err
+		}
+		canReadPassword = superuser
+	}
+	if canReadPassword {
+		columns = append(columns, "(SELECT passwd FROM pg_catalog.pg_shadow AS s WHERE s.usename = pg_roles.rolname)")
+		values = append(values, &rolePassword)
+	}
+
+	// Since PostgreSQL 16, a membership is recorded once per grantor. The memberships a role
+	// only granted to itself are fetched separately, see filterTemporaryMemberships.
 	roleSQL := fmt.Sprintf(`SELECT ARRAY(
-			SELECT pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members members WHERE member = pg_roles.oid
+			SELECT DISTINCT pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members members WHERE member = pg_roles.oid
+		), ARRAY(
+			SELECT pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members members
+			WHERE member = pg_roles.oid GROUP BY roleid HAVING bool_and(grantor = member)
+		), ARRAY(
+			SELECT pg_get_userbyid(member) FROM pg_catalog.pg_auth_members members
+			WHERE roleid = pg_roles.oid AND admin_option
 		), %s
 		FROM pg_catalog.pg_roles WHERE rolname=$1`,
 		// select columns
@@ -454,9 +795,13 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 
 	d.Set(roleNameAttr, roleName)
 	d.Set(roleConnLimitAttr, roleConnLimit)
+	d.Set(roleActiveConnectionsAttr, roleActiveConns)
+	d.Set(roleOIDAttr, int(roleOID))
+	d.Set(commentAttr, roleComment)
 	d.Set(roleCreateDBAttr, roleCreateDB)
 	d.Set(roleCreateRoleAttr, roleCreateRole)
 	d.Set(roleEncryptedPassAttr, true)
+	d.Set(rolePasswordEncryptionAttr, d.Get(rolePasswordEncryptionAttr).(string))
 	d.Set(roleInheritAttr, roleInherit)
 	d.Set(roleLoginAttr, roleCanLogin)
 	d.Set(roleSkipDropRoleAttr, d.Get(roleSkipDropRoleAttr).(bool))
@@ -465,9 +810,19 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 	d.Set(roleValidUntilAttr, roleValidUntil)
 	d.Set(roleReplicationAttr, roleReplication)
 	d.Set(roleBypassRLSAttr, roleBypassRLS)
+	if roleName == db.client.config.getDatabaseUsername() {
+		roleRoles = filterTemporaryMemberships(roleRoles, roleSelfGrantedRoles, d.Get(roleRolesAttr).(*schema.Set))
+	}
 	d.Set(roleRolesAttr, pgArrayToSet(roleRoles))
+	d.Set(roleAdminAttr, pgArrayToSet(filterConfiguredAdmins(roleAdmins, d.Get(roleAdminAttr).(*schema.Set))))
 	d.Set(roleSearchPathAttr, readSearchPath(roleConfig))
 	d.Set(roleAssumeRoleAttr, readAssumeRole(roleConfig))
+	if !serverPreloadLibraries.Valid {
+		if serverPreloadLibraries.String, err = readServerSetting(db, "session_preload_libraries"); err != nil {
+			return err
+		}
+	}
+	d.Set(roleEffectivePreloadLibrariesAttr, effectivePreloadLibraries(roleConfig, serverPreloadLibraries.String))
 
 	statementTimeout, err := readStatementTimeout(roleConfig)
 	if err != nil {
@@ -489,18 +844,83 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 	}
 
 	d.Set(roleIdleInTransactionSessionTimeoutAttr, idleInTransactionSessionTimeout)
+	d.Set(roleConfigAttr, managedConfig(d, roleConfigAttr, parseConfig(roleConfig)))
+
+	if d.Get(roleDatabaseConfigAttr).(*schema.Set).Len() > 0 {
+		databaseConfig, err := readRoleDatabaseConfig(db, roleName)
+		if err != nil {
+			return err
+		}
+		d.Set(roleDatabaseConfigAttr, managedDatabaseConfig(d, databaseConfig))
+	}
 
 	d.SetId(roleName)
 
-	password, err := readRolePassword(db, d, roleCanLogin)
+	password, err := readRolePassword(db, d, roleCanLogin, canReadPassword, rolePassword.String)
 	if err != nil {
 		return err
 	}
 
 	d.Set(rolePasswordAttr, password)
+
+	if canReadPassword {
+		d.Set(rolePasswordEncryptionInUseAttr, passwordEncryptionInUse(rolePassword))
+	} else {
+		d.Set(rolePasswordEncryptionInUseAttr, "")
+	}
 	return nil
 }
 
+// passwordEncryptionInUse returns how a password read from pg_shadow is stored,
+// so roles still using md5 can be found and targeted for rotation.
+func passwordEncryptionInUse(rolePassword sql.NullString) string {
+	switch {
+	case !rolePassword.Valid || rolePassword.String == "":
+		return "none"
+	case strings.HasPrefix(rolePassword.String, "SCRAM-SHA-256$"):
+		return "scram-sha-256"
+	case strings.HasPrefix(rolePassword.String, "md5") && len(rolePassword.String) == 35:
+		return "md5"
+	default:
+		return "plain"
+	}
+}
+
+// filterTemporaryMemberships removes from the memberships of the connection user the ones
+// it granted to itself and which are not in the configuration.
+// The provider temporarily grants roles to the connection user when it's not a superuser
+// (e.g.: to create a database owned by another role), so they should not be reported as drift
+// if they are seen during a refresh (or left behind by a failed revoke).
+func filterTemporaryMemberships(roles, selfGranted pq.ByteaArray, configured *schema.Set) pq.ByteaArray {
+	temporary := make(map[string]bool, len(selfGranted))
+	for _, role := range selfGranted {
+		if !configured.Contains(string(role)) {
+			temporary[string(role)] = true
+		}
+	}
+
+	filtered := pq.ByteaArray{}
+	for _, role := range roles {
+		if !temporary[string(role)] {
+			filtered = append(filtered, role)
+		}
+	}
+	return filtered
+}
+
+// filterConfiguredAdmins only keeps the members with ADMIN OPTION which are configured, so the ones granted
+// outside of the role (e.g. by postgresql_grant_role, or the creator of the role which PostgreSQL 16+
+// makes an admin of it if not superuser) don't show any diff.
+func filterConfiguredAdmins(admins pq.ByteaArray, configured *schema.Set) pq.ByteaArray {
+	filtered := pq.ByteaArray{}
+	for _, admin := range admins {
+		if configured.Contains(string(admin)) {
+			filtered = append(filtered, admin)
+		}
+	}
+	return filtered
+}
+
 // readSearchPath searches for a search_path entry in the rolconfig array.
 // In case no such value is present, it returns nil.
 func readSearchPath(roleConfig pq.ByteaArray) []string {
@@ -568,6 +988,21 @@ func readLockTimeout(roleConfig pq.ByteaArray) (int, error) {
 	return 0, nil
 }
 
+// effectivePreloadLibraries returns the session_preload_libraries of the role if it sets them,
+// serverValue otherwise.
+func effectivePreloadLibraries(roleConfig pq.ByteaArray, serverValue string) []string {
+	value := serverValue
+	if roleValue, ok := parseConfig(roleConfig)["session_preload_libraries"]; ok {
+		value = roleValue
+	}
+
+	libraries := splitSettingList(value)
+	if libraries == nil {
+		return []string{}
+	}
+	return libraries
+}
+
 // readAssumeRole searches for a role entry in the rolconfig array.
 // In case no such value is present, it returns empty string.
 func readAssumeRole(roleConfig pq.ByteaArray) string {
@@ -584,9 +1019,20 @@ func readAssumeRole(roleConfig pq.ByteaArray) string {
 
 // readRolePassword reads password either from Postgres if admin user is a superuser
 // or only from Terraform state.
-func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin bool) (string, error) {
+// rolePassword is the value fetched from pg_shadow, only relevant if canReadPassword is true.
+func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin, canReadPassword bool, rolePassword string) (string, error) {
 	statePassword := d.Get(rolePasswordAttr).(string)
 
+	// The password is read from an external source, it must not end up in the state.
+	if hasRolePasswordSource(d) {
+		return "", nil
+	}
+
+	// The password is rotated outside of Terraform, it is not compared.
+	if d.Get(roleIgnorePasswordChangesAttr).(bool) {
+		return statePassword, nil
+	}
+
 	// Role which cannot login does not have password in pg_shadow.
 	// Also, if user specifies that admin is not a superuser we don't try to read pg_shadow
 	// (only superuser can read pg_shadow)
@@ -596,11 +1042,7 @@ func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin boo
 
 	// Otherwise we check if connected user is really a superuser
 	// (in order to warn user instead of having a permission denied error)
-	superuser, err := db.isSuperuser()
-	if err != nil {
-		return "", err
-	}
-	if !superuser {
+	if !canReadPassword {
 		return "", fmt.Errorf(
 			"could not read role password from Postgres as "+
 				"connected user %s is not a SUPERUSER. "+
@@ -610,15 +1052,11 @@ func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin boo
 		)
 	}
 
-	var rolePassword string
-	err = db.QueryRow("SELECT COALESCE(passwd, '') FROM pg_catalog.pg_shadow AS s WHERE s.usename = $1", d.Id()).Scan(&rolePassword)
-	switch {
-	case err == sql.ErrNoRows:
-		// They don't have a password
-		return "", nil
-	case err != nil:
-		return "", fmt.Errorf("Error reading role: %w", err)
+	// The role has no password (NULL in pg_shadow), as asked by an empty password.
+	if rolePassword == "" && statePassword == "" {
+		return statePassword, nil
 	}
+
 	// If the password isn't already in md5 format, but hashing the input
 	// matches the password in the database for the user, they are the same
 	if statePassword != "" && !strings.HasPrefix(statePassword, "md5") && !strings.HasPrefix(statePassword, "SCRAM-SHA-256") {
@@ -644,94 +1082,60 @@ func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin boo
 }
 
 func resourcePostgreSQLRoleUpdate(db *DBConnection, d *schema.ResourceData) error {
-	txn, err := startTransaction(db.client, "")
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
-
-	oldName, _ := d.GetChange(roleNameAttr)
-	if err := pgLockRole(txn, oldName.(string)); err != nil {
-		return err
-	}
-
-	if err := setRoleName(txn, d); err != nil {
-		return err
-	}
-
-	if err := setRolePassword(txn, d); err != nil {
-		return err
-	}
-
-	if err := setRoleBypassRLS(db, txn, d); err != nil {
-		return err
-	}
-
-	if err := setRoleConnLimit(txn, d); err != nil {
-		return err
-	}
-
-	if err := setRoleCreateDB(txn, d); err != nil {
-		return err
-	}
-
-	if err := setRoleCreateRole(txn, d); err != nil {
-		return err
-	}
-
-	if err := setRoleInherit(txn, d); err != nil {
-		return err
-	}
+	if err := db.client.withTx("", func(txn *sql.Tx) error {
+		if err := setCitusRolePropagation(db, txn); err != nil {
+			return err
+		}
 
-	if err := setRoleLogin(txn, d); err != nil {
-		return err
-	}
+		oldName, _ := d.GetChange(roleNameAttr)
+		if err := pgLockRole(txn, oldName.(string)); err != nil {
+			return err
+		}
 
-	if err := setRoleReplication(txn, d); err != nil {
-		return err
-	}
+		if err := setRoleName(txn, d); err != nil {
+			return err
+		}
 
-	if err := setRoleSuperuser(txn, d); err != nil {
-		return err
-	}
+		if err := setRolePassword(txn, d); err != nil {
+			return err
+		}
 
-	if err := setRoleValidUntil(txn, d); err != nil {
-		return err
-	}
+		if err := setRoleOptions(db, txn, d); err != nil {
+			return err
+		}
 
-	// applying roles: let's revoke all / grant the right ones
-	if err = revokeRoles(txn, d); err != nil {
-		return err
-	}
+		if err := setRoleValidUntil(txn, d); err != nil {
+			return err
+		}
 
-	if err = grantRoles(txn, d); err != nil {
-		return err
-	}
+		// applying roles: let's revoke all / grant the right ones
+		if err := revokeRoles(txn, d); err != nil {
+			return err
+		}
 
-	if err = alterSearchPath(txn, d); err != nil {
-		return err
-	}
+		if err := grantRoles(txn, d); err != nil {
+			return err
+		}
 
-	if err = setStatementTimeout(txn, d); err != nil {
-		return err
-	}
+		if err := setRoleAdmins(txn, d); err != nil {
+			return err
+		}
 
-	if err = setLockTimeout(txn, d); err != nil {
-		return err
-	}
+		if err := setRoleSettings(txn, d); err != nil {
+			return err
+		}
 
-	if err = setIdleInTransactionSessionTimeout(txn, d); err != nil {
-		return err
-	}
+		if d.HasChange(commentAttr) {
+			if err := setObjectComment(txn, "ROLE", pq.QuoteIdentifier(d.Get(roleNameAttr).(string)), d.Get(commentAttr).(string)); err != nil {
+				return err
+			}
+		}
 
-	if err = setAssumeRole(txn, d); err != nil {
+		return nil
+	}); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
-	}
-
 	return resourcePostgreSQLRoleReadImpl(db, d)
 }
 
@@ -760,170 +1164,201 @@ func setRoleName(txn *sql.Tx, d *schema.ResourceData) error {
 func setRolePassword(txn *sql.Tx, d *schema.ResourceData) error {
 	// If role is renamed, password is reset (as the md5 sum is also base on the role name)
 	// so we need to update it
-	if !d.HasChange(rolePasswordAttr) && !d.HasChange(roleNameAttr) {
+	if !d.HasChange(rolePasswordAttr) && !d.HasChange(rolePasswordSourceHashAttr) && !d.HasChange(roleNameAttr) &&
+		!d.HasChange(rolePasswordEncryptionAttr) {
 		return nil
 	}
 
-	roleName := d.Get(roleNameAttr).(string)
-	password := d.Get(rolePasswordAttr).(string)
-
-	sql := fmt.Sprintf("ALTER ROLE %s PASSWORD '%s'", pq.QuoteIdentifier(roleName), pqQuoteLiteral(password))
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role password: %w", err)
-	}
-	return nil
-}
-
-func setRoleBypassRLS(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleBypassRLSAttr) {
+	// The password is only set at creation
+	if d.Get(roleIgnorePasswordChangesAttr).(bool) {
 		return nil
 	}
 
-	if !db.featureSupported(featureRLS) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support PostgreSQL Row-Level Security", db.version.String())
+	roleName := d.Get(roleNameAttr).(string)
+	password, err := getRolePassword(d)
+	if err != nil {
+		return err
 	}
 
-	bypassRLS := d.Get(roleBypassRLSAttr).(bool)
-	tok := "NOBYPASSRLS"
-	if bypassRLS {
-		tok = "BYPASSRLS"
+	if err := setRolePasswordEncryption(txn, d, password); err != nil {
+		return err
 	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
+
+	sql := fmt.Sprintf("ALTER ROLE %s %s", pq.QuoteIdentifier(roleName), rolePasswordClause(password))
 	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role BYPASSRLS: %w", err)
+		return fmt.Errorf("Error updating role password: %w", err)
 	}
 
-	return nil
+	return setRolePasswordSourceHash(d, password)
 }
 
-func setRoleConnLimit(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleConnLimitAttr) {
+// setRolePasswordEncryption sets password_encryption for the rest of the transaction, so the password
+// set by the next CREATE or ALTER ROLE is hashed with it.
+func setRolePasswordEncryption(txn *sql.Tx, d *schema.ResourceData, password string) error {
+	encryption := d.Get(rolePasswordEncryptionAttr).(string)
+	if encryption == "" || password == "" {
 		return nil
 	}
 
-	connLimit := d.Get(roleConnLimitAttr).(int)
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s CONNECTION LIMIT %d", pq.QuoteIdentifier(roleName), connLimit)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role CONNECTION LIMIT: %w", err)
+	if _, err := txn.Exec(rolePasswordEncryptionQuery(encryption)); err != nil {
+		return fmt.Errorf("could not set password_encryption to %s: %w", encryption, err)
 	}
-
 	return nil
 }
 
-func setRoleCreateDB(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleCreateDBAttr) {
-		return nil
-	}
+func rolePasswordEncryptionQuery(encryption string) string {
+	return fmt.Sprintf("SET LOCAL password_encryption = '%s'", pqQuoteLiteral(encryption))
+}
 
-	createDB := d.Get(roleCreateDBAttr).(bool)
-	tok := "NOCREATEDB"
-	if createDB {
-		tok = "CREATEDB"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role CREATEDB: %w", err)
+// rolePasswordClause returns the PASSWORD clause of ALTER ROLE, PASSWORD NULL if the
+// password is removed so no password is stored anymore (e.g. to use certificate authentication).
+func rolePasswordClause(password string) string {
+	if password == "" {
+		return "PASSWORD NULL"
 	}
+	return fmt.Sprintf("PASSWORD '%s'", pqQuoteLiteral(password))
+}
 
-	return nil
+// hasRolePasswordSource returns true if the password is read from an environment variable or a file
+// instead of the password attribute.
+func hasRolePasswordSource(d interface{ Get(string) interface{} }) bool {
+	return d.Get(rolePasswordSourceEnvAttr).(string) != "" || d.Get(rolePasswordSourceFileAttr).(string) != ""
 }
 
-func setRoleCreateRole(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleCreateRoleAttr) {
-		return nil
+// readRolePasswordSource reads the password from the configured environment variable or file.
+func readRolePasswordSource(d interface{ Get(string) interface{} }) (string, error) {
+	if envName := d.Get(rolePasswordSourceEnvAttr).(string); envName != "" {
+		password, ok := os.LookupEnv(envName)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s for role password is not set", envName)
+		}
+		return password, nil
 	}
 
-	createRole := d.Get(roleCreateRoleAttr).(bool)
-	tok := "NOCREATEROLE"
-	if createRole {
-		tok = "CREATEROLE"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role CREATEROLE: %w", err)
+	path := d.Get(rolePasswordSourceFileAttr).(string)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read role password file: %w", err)
 	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
 
-	return nil
+// getRolePassword returns the password to set for the role, either from the
+// password attribute or from its external source.
+func getRolePassword(d *schema.ResourceData) (string, error) {
+	if !hasRolePasswordSource(d) {
+		return d.Get(rolePasswordAttr).(string), nil
+	}
+	return readRolePasswordSource(d)
 }
 
-func setRoleInherit(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleInheritAttr) {
+// setRolePasswordSourceHash stores the hash of the password read from its external source,
+// so changes can be detected in resourcePostgreSQLRoleCustomizeDiff.
+func setRolePasswordSourceHash(d *schema.ResourceData, password string) error {
+	if !hasRolePasswordSource(d) {
+		d.Set(rolePasswordSourceHashAttr, "")
 		return nil
 	}
 
-	inherit := d.Get(roleInheritAttr).(bool)
-	tok := "NOINHERIT"
-	if inherit {
-		tok = "INHERIT"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role INHERIT: %w", err)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("could not hash role password: %w", err)
 	}
-
+	d.Set(rolePasswordSourceHashAttr, string(hash))
 	return nil
 }
 
-func setRoleLogin(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleLoginAttr) {
+// resourcePostgreSQLRoleCustomizeDiff plans a password update if the password read
+// from its external source doesn't match the hash stored in the state.
+func resourcePostgreSQLRoleCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() != "" && d.Get(roleIgnorePasswordChangesAttr).(bool) {
 		return nil
 	}
 
-	login := d.Get(roleLoginAttr).(bool)
-	tok := "NOLOGIN"
-	if login {
-		tok = "LOGIN"
+	if !hasRolePasswordSource(d) {
+		if d.Get(rolePasswordSourceHashAttr).(string) != "" {
+			return d.SetNew(rolePasswordSourceHashAttr, "")
+		}
+		return nil
 	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role LOGIN: %w", err)
+
+	password, err := readRolePasswordSource(d)
+	if err != nil {
+		return err
 	}
 
+	hash := d.Get(rolePasswordSourceHashAttr).(string)
+	if hash == "" || bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return d.SetNewComputed(rolePasswordSourceHashAttr)
+	}
 	return nil
 }
 
-func setRoleReplication(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleReplicationAttr) {
+// setRoleOptions coalesces all the changed role options (SUPERUSER, CREATEDB, LOGIN, etc.)
+// into a single ALTER ROLE statement to reduce round trips and lock churn on pg_authid.
+func setRoleOptions(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+	query, err := alterRoleOptionsQuery(db, d)
+	if err != nil {
+		return err
+	}
+	if query == "" {
 		return nil
 	}
 
-	replication := d.Get(roleReplicationAttr).(bool)
-	tok := "NOREPLICATION"
-	if replication {
-		tok = "REPLICATION"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role REPLICATION: %w", err)
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("Error updating role options: %w", err)
 	}
 
 	return nil
 }
 
-func setRoleSuperuser(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleSuperuserAttr) {
-		return nil
+// alterRoleOptionsQuery returns the ALTER ROLE statement for the changed options,
+// or an empty string if none of them changed.
+func alterRoleOptionsQuery(db *DBConnection, d *schema.ResourceData) (string, error) {
+	boolOpts := []struct {
+		hclKey        string
+		sqlKeyEnable  string
+		sqlKeyDisable string
+	}{
+		{roleSuperuserAttr, "SUPERUSER", "NOSUPERUSER"},
+		{roleCreateDBAttr, "CREATEDB", "NOCREATEDB"},
+		{roleCreateRoleAttr, "CREATEROLE", "NOCREATEROLE"},
+		{roleInheritAttr, "INHERIT", "NOINHERIT"},
+		{roleLoginAttr, "LOGIN", "NOLOGIN"},
+		{roleReplicationAttr, "REPLICATION", "NOREPLICATION"},
+		{roleBypassRLSAttr, "BYPASSRLS", "NOBYPASSRLS"},
 	}
 
-	superuser := d.Get(roleSuperuserAttr).(bool)
-	tok := "NOSUPERUSER"
-	if superuser {
-		tok = "SUPERUSER"
+	opts := []string{}
+	for _, opt := range boolOpts {
+		if !d.HasChange(opt.hclKey) {
+			continue
+		}
+
+		if opt.hclKey == roleBypassRLSAttr && !db.featureSupported(featureRLS) {
+			return "", db.unsupportedFeatureError(featureRLS, "PostgreSQL Row-Level Security")
+		}
+		if opt.hclKey == roleReplicationAttr && !db.featureSupported(featureReplication) {
+			return "", db.unsupportedFeatureError(featureReplication, "role REPLICATION")
+		}
+
+		tok := opt.sqlKeyDisable
+		if d.Get(opt.hclKey).(bool) {
+			tok = opt.sqlKeyEnable
+		}
+		opts = append(opts, tok)
 	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role SUPERUSER: %w", err)
+
+	if d.HasChange(roleConnLimitAttr) {
+		opts = append(opts, fmt.Sprintf("CONNECTION LIMIT %d", d.Get(roleConnLimitAttr).(int)))
 	}
 
-	return nil
+	if len(opts) == 0 {
+		return "", nil
+	}
+
+	roleName := d.Get(roleNameAttr).(string)
+	return fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), strings.Join(opts, " ")), nil
 }
 
 func setRoleValidUntil(txn *sql.Tx, d *schema.ResourceData) error {
@@ -987,20 +1422,105 @@ func revokeRoles(txn *sql.Tx, d *schema.ResourceData) error {
 }
 
 func grantRoles(txn *sql.Tx, d *schema.ResourceData) error {
+	query := grantRolesQuery(d)
+	if query == "" {
+		return nil
+	}
+
 	role := d.Get(roleNameAttr).(string)
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not grant roles to %s: %w", role, err)
+	}
+	return nil
+}
 
-	for _, grantingRole := range d.Get("roles").(*schema.Set).List() {
-		query := fmt.Sprintf(
-			"GRANT %s TO %s", pq.QuoteIdentifier(grantingRole.(string)), pq.QuoteIdentifier(role),
-		)
+// grantRolesQuery returns a single GRANT statement for all the roles listed in
+// the `roles` attribute, or an empty string if there is nothing to grant.
+func grantRolesQuery(d *schema.ResourceData) string {
+	roles := d.Get(roleRolesAttr).(*schema.Set)
+	if roles.Len() == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"GRANT %s TO %s", setToPgIdentListWithoutSchema(roles), pq.QuoteIdentifier(d.Get(roleNameAttr).(string)),
+	)
+}
+
+// setRoleAdmins grants the role WITH ADMIN OPTION to the added admins
+// and only revokes the ADMIN OPTION from the removed ones, which stay members of the role.
+func setRoleAdmins(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(roleAdminAttr) {
+		return nil
+	}
+
+	role := pq.QuoteIdentifier(d.Get(roleNameAttr).(string))
+	oldRaw, newRaw := d.GetChange(roleAdminAttr)
+	removed := oldRaw.(*schema.Set).Difference(newRaw.(*schema.Set))
+	added := newRaw.(*schema.Set).Difference(oldRaw.(*schema.Set))
+
+	if removed.Len() > 0 {
+		query := fmt.Sprintf("REVOKE ADMIN OPTION FOR %s FROM %s", role, setToPgIdentListWithoutSchema(removed))
 		if _, err := txn.Exec(query); err != nil {
-			return fmt.Errorf("could not grant role %s to %s: %w", grantingRole, role, err)
+			return fmt.Errorf("could not revoke the admin option of role %s from its admins: %w", role, err)
 		}
 	}
+
+	if added.Len() > 0 {
+		query := fmt.Sprintf("GRANT %s TO %s WITH ADMIN OPTION", role, setToPgIdentListWithoutSchema(added))
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("could not grant role %s to its admins: %w", role, err)
+		}
+	}
+
+	return nil
+}
+
+// setRoleSettings applies all the role's configuration parameters
+// (search_path, statement_timeout, etc.) in a single round trip.
+// ALTER ROLE ... SET only accepts one parameter per statement, so the statements are
+// sent together as a multi-statement query. This is safe as we are already in a transaction
+// and none of the statements take bind parameters.
+func setRoleSettings(txn *sql.Tx, d *schema.ResourceData) error {
+	queries, err := roleSettingsQueries(d)
+	if err != nil {
+		return err
+	}
+
+	if len(queries) == 0 {
+		return nil
+	}
+
+	roleName := d.Get(roleNameAttr).(string)
+	if _, err := txn.Exec(strings.Join(queries, ";\n")); err != nil {
+		return fmt.Errorf("could not set configuration parameters for role %s: %w", roleName, err)
+	}
 	return nil
 }
 
-func alterSearchPath(txn *sql.Tx, d *schema.ResourceData) error {
+func roleSettingsQueries(d *schema.ResourceData) ([]string, error) {
+	searchPathQuery, err := alterSearchPathQuery(d)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := []string{searchPathQuery}
+	for _, query := range []string{
+		statementTimeoutQuery(d),
+		lockTimeoutQuery(d),
+		idleInTransactionSessionTimeoutQuery(d),
+		assumeRoleQuery(d),
+	} {
+		if query != "" {
+			queries = append(queries, query)
+		}
+	}
+	queries = append(queries, configQueries(d, roleConfigAttr, fmt.Sprintf("ALTER ROLE %s", pq.QuoteIdentifier(d.Get(roleNameAttr).(string))))...)
+	queries = append(queries, roleDatabaseConfigQueries(d)...)
+	return queries, nil
+}
+
+func alterSearchPathQuery(d *schema.ResourceData) (string, error) {
 	role := d.Get(roleNameAttr).(string)
 	searchPathInterface := d.Get(roleSearchPathAttr).([]interface{})
 
@@ -1009,7 +1529,7 @@ func alterSearchPath(txn *sql.Tx, d *schema.ResourceData) error {
 		searchPathString = make([]string, len(searchPathInterface))
 		for i, searchPathPart := range searchPathInterface {
 			if strings.Contains(searchPathPart.(string), ", ") {
-				return fmt.Errorf("search_path cannot contain `, `: %v", searchPathPart)
+				return "", fmt.Errorf("search_path cannot contain `, `: %v", searchPathPart)
 			}
 			searchPathString[i] = pq.QuoteIdentifier(searchPathPart.(string))
 		}
@@ -1018,111 +1538,49 @@ func alterSearchPath(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 	searchPath := strings.Join(searchPathString[:], ", ")
 
-	query := fmt.Sprintf(
+	return fmt.Sprintf(
 		"ALTER ROLE %s SET search_path TO %s", pq.QuoteIdentifier(role), searchPath,
-	)
-	if _, err := txn.Exec(query); err != nil {
-		return fmt.Errorf("could not set search_path %s for %s: %w", searchPath, role, err)
-	}
-	return nil
+	), nil
 }
 
-func setStatementTimeout(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleStatementTimeoutAttr) {
-		return nil
+// roleIntSettingQuery returns the statement to set (or reset if 0) an integer configuration
+// parameter of the role, or an empty string if the attribute did not change.
+func roleIntSettingQuery(d *schema.ResourceData, attr string) string {
+	if !d.HasChange(attr) {
+		return ""
 	}
 
 	roleName := d.Get(roleNameAttr).(string)
-	statementTimeout := d.Get(roleStatementTimeoutAttr).(int)
-	if statementTimeout != 0 {
-		sql := fmt.Sprintf(
-			"ALTER ROLE %s SET statement_timeout TO %d", pq.QuoteIdentifier(roleName), statementTimeout,
-		)
-		if _, err := txn.Exec(sql); err != nil {
-			return fmt.Errorf("could not set statement_timeout %d for %s: %w", statementTimeout, roleName, err)
-		}
-	} else {
-		sql := fmt.Sprintf(
-			"ALTER ROLE %s RESET statement_timeout", pq.QuoteIdentifier(roleName),
-		)
-		if _, err := txn.Exec(sql); err != nil {
-			return fmt.Errorf("could not reset statement_timeout for %s: %w", roleName, err)
-		}
+	value := d.Get(attr).(int)
+	if value != 0 {
+		return fmt.Sprintf("ALTER ROLE %s SET %s TO %d", pq.QuoteIdentifier(roleName), attr, value)
 	}
-	return nil
+	return fmt.Sprintf("ALTER ROLE %s RESET %s", pq.QuoteIdentifier(roleName), attr)
 }
 
-func setLockTimeout(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleLockTimeoutAttr) {
-		return nil
-	}
-
-	roleName := d.Get(roleNameAttr).(string)
-	lockTimeout := d.Get(roleLockTimeoutAttr).(int)
-	if lockTimeout != 0 {
-		sql := fmt.Sprintf(
-			"ALTER ROLE %s SET lock_timeout TO %d", pq.QuoteIdentifier(roleName), lockTimeout,
-		)
-		if _, err := txn.Exec(sql); err != nil {
-			return fmt.Errorf("could not set lock_timeout %d for %s: %w", lockTimeout, roleName, err)
-		}
-	} else {
-		sql := fmt.Sprintf(
-			"ALTER ROLE %s RESET lock_timeout", pq.QuoteIdentifier(roleName),
-		)
-		if _, err := txn.Exec(sql); err != nil {
-			return fmt.Errorf("could not reset lock_timeout for %s: %w", roleName, err)
-		}
-	}
-	return nil
+func statementTimeoutQuery(d *schema.ResourceData) string {
+	return roleIntSettingQuery(d, roleStatementTimeoutAttr)
 }
 
-func setIdleInTransactionSessionTimeout(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleIdleInTransactionSessionTimeoutAttr) {
-		return nil
-	}
+func lockTimeoutQuery(d *schema.ResourceData) string {
+	return roleIntSettingQuery(d, roleLockTimeoutAttr)
+}
 
-	roleName := d.Get(roleNameAttr).(string)
-	idleInTransactionSessionTimeout := d.Get(roleIdleInTransactionSessionTimeoutAttr).(int)
-	if idleInTransactionSessionTimeout != 0 {
-		sql := fmt.Sprintf(
-			"ALTER ROLE %s SET idle_in_transaction_session_timeout TO %d", pq.QuoteIdentifier(roleName), idleInTransactionSessionTimeout,
-		)
-		if _, err := txn.Exec(sql); err != nil {
-			return fmt.Errorf("could not set idle_in_transaction_session_timeout %d for %s: %w", idleInTransactionSessionTimeout, roleName, err)
-		}
-	} else {
-		sql := fmt.Sprintf(
-			"ALTER ROLE %s RESET idle_in_transaction_session_timeout", pq.QuoteIdentifier(roleName),
-		)
-		if _, err := txn.Exec(sql); err != nil {
-			return fmt.Errorf("could not reset idle_in_transaction_session_timeout for %s: %w", roleName, err)
-		}
-	}
-	return nil
+func idleInTransactionSessionTimeoutQuery(d *schema.ResourceData) string {
+	return roleIntSettingQuery(d, roleIdleInTransactionSessionTimeoutAttr)
 }
 
-func setAssumeRole(txn *sql.Tx, d *schema.ResourceData) error {
+func assumeRoleQuery(d *schema.ResourceData) string {
 	if !d.HasChange(roleAssumeRoleAttr) {
-		return nil
+		return ""
 	}
 
 	roleName := d.Get(roleNameAttr).(string)
 	assumeRole := d.Get(roleAssumeRoleAttr).(string)
 	if assumeRole != "" {
-		sql := fmt.Sprintf(
+		return fmt.Sprintf(
 			"ALTER ROLE %s SET ROLE TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(assumeRole),
 		)
-		if _, err := txn.Exec(sql); err != nil {
-			return fmt.Errorf("could not set role %s for %s: %w", assumeRole, roleName, err)
-		}
-	} else {
-		sql := fmt.Sprintf(
-			"ALTER ROLE %s RESET ROLE", pq.QuoteIdentifier(roleName),
-		)
-		if _, err := txn.Exec(sql); err != nil {
-			return fmt.Errorf("could not reset role for %s: %w", roleName, err)
-		}
 	}
-	return nil
+	return fmt.Sprintf("ALTER ROLE %s RESET ROLE", pq.QuoteIdentifier(roleName))
 }
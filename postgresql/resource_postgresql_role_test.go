@@ -1,16 +1,26 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestAccPostgresqlRole_Basic(t *testing.T) {
@@ -97,6 +107,55 @@ resource "postgresql_role" "role_with_superuser" {
 	})
 }
 
+// Test that toggling LOGIN, REPLICATION and BYPASSRLS alters the role in place.
+func TestAccPostgresqlRole_ToggleAttributes(t *testing.T) {
+	roleConfig := `
+resource "postgresql_role" "toggle_role" {
+  name                      = "toggle_role"
+  login                     = %[1]t
+  replication               = %[1]t
+  bypass_row_level_security = %[1]t
+}`
+
+	var roleOID string
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureRLS)
+			// Need to a be a superuser to set REPLICATION and BYPASSRLS
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(roleConfig, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.toggle_role", "login", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.toggle_role", "replication", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.toggle_role", "bypass_row_level_security", "false"),
+					func(s *terraform.State) error {
+						roleOID = s.RootModule().Resources["postgresql_role.toggle_role"].Primary.Attributes["oid"]
+						return nil
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(roleConfig, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.toggle_role", "login", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.toggle_role", "replication", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.toggle_role", "bypass_row_level_security", "true"),
+					func(s *terraform.State) error {
+						// The role has not been recreated
+						return resource.TestCheckResourceAttr("postgresql_role.toggle_role", "oid", roleOID)(s)
+					},
+				),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlRole_Update(t *testing.T) {
 
 	var configCreate = `
@@ -116,6 +175,7 @@ resource "postgresql_role" "group_role" {
 resource "postgresql_role" "update_role" {
   name = "update_role2"
   login = true
+  inherit = false
   connection_limit = 5
   password = "titi"
   roles = ["${postgresql_role.group_role.name}"]
@@ -161,6 +221,7 @@ resource "postgresql_role" "update_role" {
 					),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "connection_limit", "5"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "login", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.update_role", "inherit", "false"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "password", "titi"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "valid_until", "infinity"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "roles.#", "1"),
@@ -182,6 +243,7 @@ resource "postgresql_role" "update_role" {
 					testAccCheckPostgresqlRoleExists("update_role", []string{}, nil),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "name", "update_role"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "login", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.update_role", "inherit", "true"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "connection_limit", "-1"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "password", "toto"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "roles.#", "0"),
@@ -233,6 +295,1008 @@ resource "postgresql_role" "test_role" {
 	})
 }
 
+// Test that memberships granted or revoked outside of Terraform are detected as drift.
+func TestAccPostgresqlRole_MembershipDrift(t *testing.T) {
+	config := `
+resource "postgresql_role" "group_role" {
+  name = "group_role"
+}
+
+resource "postgresql_role" "member_role" {
+  name  = "member_role"
+  roles = [postgresql_role.group_role.name]
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("member_role", []string{"group_role"}, nil),
+				),
+			},
+			{
+				PreConfig: func() {
+					dbConfig := getTestConfig(t)
+					dbExecute(t, dbConfig.connStr("postgres"), "REVOKE group_role FROM member_role")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("member_role", []string{"group_role"}, nil),
+				),
+			},
+		},
+	})
+}
+
+// Test that the role attributes altered outside of Terraform are detected as drift and reverted.
+func TestAccPostgresqlRole_AttributesDrift(t *testing.T) {
+	config := `
+resource "postgresql_role" "drift_role" {
+  name             = "drift_role"
+  login            = true
+  connection_limit = 5
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("postgresql_role.drift_role", "superuser", "false"),
+			},
+			{
+				PreConfig: func() {
+					dbConfig := getTestConfig(t)
+					dbExecute(t, dbConfig.connStr("postgres"), "ALTER ROLE drift_role SUPERUSER")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					dbConfig := getTestConfig(t)
+					dbExecute(t, dbConfig.connStr("postgres"), "ALTER ROLE drift_role NOLOGIN CREATEDB CREATEROLE CONNECTION LIMIT 10 VALID UNTIL '2030-01-01'")
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "superuser", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "login", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "create_database", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "create_role", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "connection_limit", "5"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "valid_until", "infinity"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// Test that lowering the connection limit is applied in place (ALTER ROLE doesn't accept a bind parameter for it).
+func TestAccPostgresqlRole_LowerConnectionLimit(t *testing.T) {
+	config := `
+resource "postgresql_role" "conn_limit_role" {
+  name             = "conn_limit_role"
+  login            = true
+  connection_limit = %d
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, 10),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.conn_limit_role", "connection_limit", "10"),
+					testAccCheckRoleConnLimit("conn_limit_role", 10),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.conn_limit_role", "connection_limit", "2"),
+					testAccCheckRoleConnLimit("conn_limit_role", 2),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(config, -2),
+				ExpectError: regexp.MustCompile("expected connection_limit to be at least \\(-1\\), got -2"),
+			},
+		},
+	})
+}
+
+func testAccCheckRoleConnLimit(roleName string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var connLimit int
+		if err := db.QueryRow("SELECT rolconnlimit FROM pg_catalog.pg_roles WHERE rolname = $1", roleName).Scan(&connLimit); err != nil {
+			return fmt.Errorf("could not read connection limit of role %s: %w", roleName, err)
+		}
+		if connLimit != expected {
+			return fmt.Errorf("expected connection limit of role %s to be %d, got %d", roleName, expected, connLimit)
+		}
+		return nil
+	}
+}
+
+// The libraries are only loaded at login, so they don't have to be installed.
+func TestAccPostgresqlRole_SessionPreloadLibraries(t *testing.T) {
+	config := `
+resource "postgresql_role" "preload_role" {
+  name = "preload_role"
+  config = {
+    session_preload_libraries = "%s"
+  }
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "auto_explain, $libdir/plugins/audit"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.preload_role", "effective_session_preload_libraries.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_role.preload_role", "effective_session_preload_libraries.0", "auto_explain"),
+					resource.TestCheckResourceAttr("postgresql_role.preload_role", "effective_session_preload_libraries.1", "$libdir/plugins/audit"),
+				),
+			},
+			// The elements are quoted separately, so the spaces don't cause a diff.
+			{
+				Config:   fmt.Sprintf(config, "auto_explain,$libdir/plugins/audit"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestEffectivePreloadLibraries(t *testing.T) {
+	roleConfig := pq.ByteaArray{[]byte("session_preload_libraries=auto_explain, \"$libdir/plugins/audit\"")}
+	assert.Equal(t, []string{"auto_explain", "$libdir/plugins/audit"}, effectivePreloadLibraries(roleConfig, "pg_stat_statements"))
+	assert.Equal(t, []string{"pg_stat_statements"}, effectivePreloadLibraries(nil, "pg_stat_statements"))
+	assert.Equal(t, []string{}, effectivePreloadLibraries(nil, ""))
+}
+
+// Test that the session_preload_libraries set on the connection user are not read as the server value.
+func TestRoleReadServerPreloadLibraries(t *testing.T) {
+	for _, test := range []struct {
+		canReadFiles bool
+		expected     []interface{}
+	}{
+		{canReadFiles: true, expected: []interface{}{"pg_stat_statements"}},
+		{canReadFiles: false, expected: []interface{}{}},
+	} {
+		fake := &fakeDB{answer: func(query string, args []driver.NamedValue) (*fakeRows, error) {
+			row := func(value driver.Value) (*fakeRows, error) {
+				return &fakeRows{values: [][]driver.Value{{value}}}, nil
+			}
+			switch {
+			case strings.Contains(query, "has_table_privilege('pg_catalog.pg_file_settings'"):
+				return row(test.canReadFiles)
+			case strings.Contains(query, "FROM pg_catalog.pg_file_settings"):
+				return row("pg_stat_statements")
+			case strings.HasPrefix(query, "SELECT boot_val"):
+				return row("")
+			}
+			rows, err := fakeRoleAnswer(query, args)
+			if rows != nil {
+				// The value of the session comes from the connection user.
+				rows.values[0][15] = nil
+			}
+			return rows, err
+		}}
+		db, err := newFakeClient(t, fake, "16.0.0").Connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{roleNameAttr: "my_role"})
+		d.SetId("my_role")
+		if err := resourcePostgreSQLRoleRead(db, d); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, test.expected, d.Get(roleEffectivePreloadLibrariesAttr), "canReadFiles=%t", test.canReadFiles)
+	}
+}
+
+// pgaudit is not required: the settings of an extension which is not loaded are kept as placeholders.
+func TestAccPostgresqlRole_Config(t *testing.T) {
+	config := `
+resource "postgresql_role" "auditor" {
+  name   = "tf_test_auditor"
+  config = %s
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The comma-separated list is kept as a single value, without requoting drift.
+				Config: fmt.Sprintf(config, `{ "pgaudit.log" = "write, ddl", "pgaudit.log_level" = "notice" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.auditor", "config.pgaudit.log", "write, ddl"),
+					resource.TestCheckResourceAttr("postgresql_role.auditor", "config.pgaudit.log_level", "notice"),
+					testAccCheckRoleConfig("tf_test_auditor", []string{"pgaudit.log=write, ddl", "pgaudit.log_level=notice"}),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, `{ "pgaudit.log" = "read, write" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.auditor", "config.%", "1"),
+					testAccCheckRoleConfig("tf_test_auditor", []string{"pgaudit.log=read, write"}),
+				),
+			},
+			// Settings not listed in config are not managed
+			{
+				PreConfig: func() {
+					dbConfig := getTestConfig(t)
+					dbExecute(t, dbConfig.connStr("postgres"), "ALTER ROLE tf_test_auditor SET work_mem = '8MB'")
+				},
+				Config:   fmt.Sprintf(config, `{ "pgaudit.log" = "read, write" }`),
+				PlanOnly: true,
+			},
+			{
+				Config:      fmt.Sprintf(config, `{ "statement_timeout" = "5s" }`),
+				ExpectError: regexp.MustCompile("statement_timeout must be set with the statement_timeout attribute"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_DatabaseConfig(t *testing.T) {
+	config := `
+resource "postgresql_role" "app" {
+  name = "tf_test_app"
+  database_config {
+    database = "postgres"
+    config   = %s
+  }
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, `{ work_mem = "64MB", search_path = "app, public" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.app", "database_config.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_role.app", "database_config.0.config.work_mem", "64MB"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, `{ work_mem = "32MB" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.app", "database_config.0.config.%", "1"),
+					resource.TestCheckResourceAttr("postgresql_role.app", "database_config.0.config.work_mem", "32MB"),
+				),
+			},
+			// All the settings, including the ones of the databases, are imported.
+			{
+				PreConfig: func() {
+					dbConfig := getTestConfig(t)
+					dbExecute(t, dbConfig.connStr("postgres"), "ALTER ROLE tf_test_app SET lock_timeout = '1s'")
+					dbExecute(t, dbConfig.connStr("postgres"), "ALTER ROLE tf_test_app SET \"pgaudit.log\" = 'ddl'")
+				},
+				ResourceName:  "postgresql_role.app",
+				ImportState:   true,
+				ImportStateId: "tf_test_app",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					attributes := states[0].Attributes
+					for name, expected := range map[string]string{
+						"config.%":                          "1",
+						"config.pgaudit.log":                "ddl",
+						"lock_timeout":                      "1000",
+						"database_config.#":                 "1",
+						"database_config.0.database":        "postgres",
+						"database_config.0.config.work_mem": "32MB",
+					} {
+						if attributes[name] != expected {
+							return fmt.Errorf("expected %s to be imported as %q, got %q", name, expected, attributes[name])
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func testAccCheckRoleConfig(roleName string, expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var config pq.ByteaArray
+		if err := db.QueryRow("SELECT COALESCE(rolconfig, '{}') FROM pg_catalog.pg_roles WHERE rolname = $1", roleName).Scan(&config); err != nil {
+			return fmt.Errorf("could not read configuration of role %s: %w", roleName, err)
+		}
+
+		var got []string
+		for _, setting := range config {
+			got = append(got, string(setting))
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, expected) {
+			return fmt.Errorf("expected configuration of role %s to be %v, got %v", roleName, expected, got)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlRole_InRoleAndAdmin(t *testing.T) {
+	config := `
+resource "postgresql_role" "group_role" {
+  name = "group_role"
+}
+
+resource "postgresql_role" "admin_role" {
+  name = "admin_role"
+}
+
+resource "postgresql_role" "member_role" {
+  name  = "member_role"
+  roles = [postgresql_role.group_role.name]
+  admin = [%s]
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "postgresql_role.admin_role.name"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("member_role", []string{"group_role"}, nil),
+					resource.TestCheckResourceAttr("postgresql_role.member_role", "admin.#", "1"),
+					resource.TestCheckTypeSetElemAttr("postgresql_role.member_role", "admin.*", "admin_role"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.member_role", "admin.#", "0"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, "postgresql_role.admin_role.name"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.member_role", "admin.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+// Test that with ignore_password_changes the password is only set at creation.
+func TestAccPostgresqlRole_IgnorePasswordChanges(t *testing.T) {
+	config := `
+resource "postgresql_role" "rotated_role" {
+  name                    = "rotated_role"
+  login                   = true
+  password                = "%s"
+  ignore_password_changes = true
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "initial"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRoleCanLogin(t, "rotated_role", "initial"),
+				),
+			},
+			// The password rotated outside of Terraform is not detected as drift
+			{
+				PreConfig: func() {
+					dbConfig := getTestConfig(t)
+					dbExecute(t, dbConfig.connStr("postgres"), "ALTER ROLE rotated_role PASSWORD 'rotated'")
+				},
+				Config:   fmt.Sprintf(config, "initial"),
+				PlanOnly: true,
+			},
+			// and a new password in the configuration is not applied
+			{
+				Config: fmt.Sprintf(config, "updated"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRoleCanLogin(t, "rotated_role", "rotated"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_RemovePassword(t *testing.T) {
+	config := `
+resource "postgresql_role" "no_password_role" {
+  name     = "no_password_role"
+  login    = true
+  password = "%s"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "initial"),
+				Check:  testAccCheckRoleCanLogin(t, "no_password_role", "initial"),
+			},
+			{
+				Config: fmt.Sprintf(config, ""),
+				Check:  resource.TestCheckResourceAttr("postgresql_role.no_password_role", "password_encryption_in_use", "none"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_PasswordEncryption(t *testing.T) {
+	config := `
+resource "postgresql_role" "encryption_role" {
+  name                = "encryption_role"
+  login               = true
+  password            = "mypass"
+  password_encryption = "%s"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "scram-sha-256"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.encryption_role", "password_encryption_in_use", "scram-sha-256"),
+					testAccCheckRoleCanLogin(t, "encryption_role", "mypass"),
+				),
+			},
+			// Changing password_encryption sets the password again
+			{
+				Config: fmt.Sprintf(config, "md5"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.encryption_role", "password_encryption_in_use", "md5"),
+					testAccCheckRoleCanLogin(t, "encryption_role", "mypass"),
+				),
+			},
+		},
+	})
+}
+
+func TestRolePasswordEncryptionQuery(t *testing.T) {
+	assert.Equal(t, "SET LOCAL password_encryption = 'scram-sha-256'", rolePasswordEncryptionQuery("scram-sha-256"))
+}
+
+func TestRolePasswordClause(t *testing.T) {
+	assert.Equal(t, "PASSWORD NULL", rolePasswordClause(""))
+	// A password "null" is a password, only an empty one removes it.
+	assert.Equal(t, "PASSWORD 'null'", rolePasswordClause("null"))
+	assert.Equal(t, "PASSWORD 'it''s'", rolePasswordClause("it's"))
+}
+
+func TestFilterConfiguredAdmins(t *testing.T) {
+	admins := pq.ByteaArray{[]byte("admin_role"), []byte("creator"), []byte("granted_outside")}
+
+	assert.Equal(t, pq.ByteaArray{[]byte("admin_role")}, filterConfiguredAdmins(admins, schema.NewSet(schema.HashString, []interface{}{"admin_role"})))
+	assert.Equal(t, pq.ByteaArray{[]byte("admin_role"), []byte("creator")}, filterConfiguredAdmins(admins, schema.NewSet(schema.HashString, []interface{}{"admin_role", "creator"})))
+	assert.Equal(t, pq.ByteaArray{}, filterConfiguredAdmins(admins, schema.NewSet(schema.HashString, nil)))
+}
+
+// Test that a removed admin only loses its ADMIN OPTION and stays a member of the role.
+func TestSetRoleAdminsRevokesAdminOption(t *testing.T) {
+	fake := &fakeDB{answer: func(string, []driver.NamedValue) (*fakeRows, error) { return nil, nil }}
+	db, err := newFakeClient(t, fake, "16.0.0").Connect()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	roleResource := resourcePostgreSQLRole()
+	state := &terraform.InstanceState{
+		ID:         "my_role",
+		Attributes: map[string]string{"id": "my_role", roleNameAttr: "my_role", roleAdminAttr + ".#": "1", fmt.Sprintf("%s.%d", roleAdminAttr, schema.HashString("old_admin")): "old_admin"},
+	}
+	diff, err := schema.InternalMap(roleResource.Schema).Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		roleNameAttr:  "my_role",
+		roleAdminAttr: []interface{}{"new_admin"},
+	}), nil, nil, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	d, err := schema.InternalMap(roleResource.Schema).Data(state, diff)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, db.client.withTx("", func(txn *sql.Tx) error {
+		return setRoleAdmins(txn, d)
+	}))
+	assert.Equal(t, []string{
+		"BEGIN",
+		`REVOKE ADMIN OPTION FOR "my_role" FROM "old_admin"`,
+		`GRANT "my_role" TO "new_admin" WITH ADMIN OPTION`,
+		"COMMIT",
+	}, fake.Statements())
+}
+
+func TestFilterTemporaryMemberships(t *testing.T) {
+	roles := pq.ByteaArray{[]byte("configured"), []byte("external"), []byte("temporary"), []byte("self_configured")}
+	selfGranted := pq.ByteaArray{[]byte("temporary"), []byte("self_configured")}
+	configured := schema.NewSet(schema.HashString, []interface{}{"configured", "self_configured"})
+
+	filtered := filterTemporaryMemberships(roles, selfGranted, configured)
+	assert.Equal(t, pq.ByteaArray{[]byte("configured"), []byte("external"), []byte("self_configured")}, filtered)
+}
+
+func TestPasswordEncryptionInUse(t *testing.T) {
+	assert.Equal(t, "none", passwordEncryptionInUse(sql.NullString{}))
+	assert.Equal(t, "none", passwordEncryptionInUse(sql.NullString{Valid: true}))
+	assert.Equal(t, "md5", passwordEncryptionInUse(sql.NullString{Valid: true, String: "md5a3556571e93b0d20722ba62be61e8c2d"}))
+	assert.Equal(t, "scram-sha-256", passwordEncryptionInUse(sql.NullString{Valid: true, String: "SCRAM-SHA-256$4096:c2FsdA==$c3RvcmVk:c2VydmVy"}))
+	assert.Equal(t, "plain", passwordEncryptionInUse(sql.NullString{Valid: true, String: "md5_but_not_a_hash"}))
+}
+
+func TestAccPostgresqlRole_PasswordSource(t *testing.T) {
+	config := `
+resource "postgresql_role" "password_source_role" {
+  name                = "password_source_role"
+  login               = true
+  password_source_env = "TF_TEST_ROLE_PASSWORD"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { t.Setenv("TF_TEST_ROLE_PASSWORD", "toto") },
+				Config:    config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("password_source_role", nil, nil),
+					resource.TestCheckResourceAttr("postgresql_role.password_source_role", "password", ""),
+					resource.TestCheckResourceAttrSet("postgresql_role.password_source_role", "password_source_hash"),
+					testAccCheckRoleCanLogin(t, "password_source_role", "toto"),
+				),
+			},
+			{
+				PreConfig: func() { t.Setenv("TF_TEST_ROLE_PASSWORD", "titi") },
+				Config:    config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.password_source_role", "password", ""),
+					testAccCheckRoleCanLogin(t, "password_source_role", "titi"),
+				),
+			},
+		},
+	})
+}
+
+func TestReadRolePasswordSource(t *testing.T) {
+	t.Setenv("TF_TEST_ROLE_PASSWORD", "from_env")
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:              "my_role",
+		rolePasswordSourceEnvAttr: "TF_TEST_ROLE_PASSWORD",
+	})
+	password, err := getRolePassword(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "from_env", password)
+
+	path := filepath.Join(t.TempDir(), "password")
+	assert.NoError(t, os.WriteFile(path, []byte("from_file\n"), 0o600))
+	d = schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:               "my_role",
+		rolePasswordSourceFileAttr: path,
+	})
+	password, err = getRolePassword(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "from_file", password)
+
+	// The hash stored in the state can be used to check the password but is not the password.
+	assert.NoError(t, setRolePasswordSourceHash(d, password))
+	hash := d.Get(rolePasswordSourceHashAttr).(string)
+	assert.NotContains(t, hash, password)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)))
+
+	d = schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:              "my_role",
+		rolePasswordSourceEnvAttr: "TF_TEST_ROLE_PASSWORD_UNSET",
+	})
+	_, err = getRolePassword(d)
+	assert.Error(t, err)
+}
+
+func TestRoleDatabaseConfigQueries(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		"name": "app",
+		"database_config": []interface{}{
+			map[string]interface{}{"database": "db2", "config": map[string]interface{}{"work_mem": "64MB"}},
+			map[string]interface{}{"database": "db1", "config": map[string]interface{}{"search_path": "app, public", "work_mem": "8MB"}},
+		},
+	})
+
+	assert.Equal(t,
+		[]string{
+			`ALTER ROLE "app" IN DATABASE "db1" SET "search_path" TO 'app', 'public'`,
+			`ALTER ROLE "app" IN DATABASE "db1" SET "work_mem" TO '8MB'`,
+			`ALTER ROLE "app" IN DATABASE "db2" SET "work_mem" TO '64MB'`,
+		},
+		roleDatabaseConfigQueries(d),
+	)
+}
+
+// Test that a failed statement rolls back the whole update of the role.
+func TestRoleUpdateRollsBack(t *testing.T) {
+	fake := &fakeDB{answer: func(query string, args []driver.NamedValue) (*fakeRows, error) {
+		if strings.Contains(query, "SET statement_timeout") {
+			return nil, &pq.Error{Code: "42501", Message: "permission denied to set parameter"}
+		}
+		return nil, nil
+	}}
+	db, err := newFakeClient(t, fake, "16.0.0").Connect()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:             "my_role",
+		roleStatementTimeoutAttr: 1000,
+	})
+	d.SetId("my_role")
+
+	assert.ErrorContains(t, resourcePostgreSQLRoleUpdate(db, d), "permission denied to set parameter")
+	statements := fake.Statements()
+	assert.Equal(t, "BEGIN", statements[0])
+	assert.Equal(t, "ROLLBACK", statements[len(statements)-1])
+	assert.NotContains(t, statements, "COMMIT")
+}
+
+func TestRoleSettingsQueries(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:             "my_role",
+		roleRolesAttr:            []interface{}{"group_role"},
+		roleSearchPathAttr:       []interface{}{"foo", "bar"},
+		roleStatementTimeoutAttr: 1000,
+		roleAssumeRoleAttr:       "group_role",
+	})
+
+	assert.Equal(t, `GRANT "group_role" TO "my_role"`, grantRolesQuery(d))
+
+	queries, err := roleSettingsQueries(d)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER ROLE "my_role" SET search_path TO "foo", "bar"`,
+		`ALTER ROLE "my_role" SET statement_timeout TO 1000`,
+		`ALTER ROLE "my_role" SET ROLE TO "group_role"`,
+	}, queries)
+}
+
+func TestCreateRoleQuery(t *testing.T) {
+	db := &DBConnection{version: semver.MustParse("15.0.0")}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:  "my_role",
+		roleLoginAttr: true,
+	})
+
+	query, err := createRoleQuery(db, d, "")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`CREATE ROLE "my_role" WITH VALID UNTIL 'infinity' CONNECTION LIMIT -1 NOSUPERUSER NOCREATEDB NOCREATEROLE INHERIT LOGIN NOBYPASSRLS NOREPLICATION`,
+		query,
+	)
+
+	// BYPASSRLS is only emitted if the server supports it
+	db = &DBConnection{version: semver.MustParse("9.4.0")}
+	query, err = createRoleQuery(db, d, "")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`CREATE ROLE "my_role" WITH VALID UNTIL 'infinity' CONNECTION LIMIT -1 NOSUPERUSER NOCREATEDB NOCREATEROLE INHERIT LOGIN NOREPLICATION`,
+		query,
+	)
+}
+
+func TestAlterRoleOptionsQuery(t *testing.T) {
+	db := &DBConnection{version: semver.MustParse("15.0.0")}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:      "my_role",
+		roleSuperuserAttr: true,
+		roleCreateDBAttr:  true,
+		roleLoginAttr:     true,
+		roleBypassRLSAttr: true,
+		roleConnLimitAttr: 5,
+	})
+
+	query, err := alterRoleOptionsQuery(db, d)
+	assert.NoError(t, err)
+	assert.Equal(t, `ALTER ROLE "my_role" WITH SUPERUSER CREATEDB INHERIT LOGIN BYPASSRLS CONNECTION LIMIT 5`, query)
+
+	// BYPASSRLS is not supported before 9.5
+	db = &DBConnection{version: semver.MustParse("9.4.0")}
+	d = schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:      "my_role",
+		roleBypassRLSAttr: true,
+	})
+	_, err = alterRoleOptionsQuery(db, d)
+	assert.Error(t, err)
+
+	// REPLICATION is not sent if role_replication is disabled
+	client := &Client{config: Config{DisabledFeatures: map[featureName]bool{featureReplication: true}}}
+	db = &DBConnection{client: client, version: semver.MustParse("15.0.0")}
+	d = schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:        "my_role",
+		roleReplicationAttr: true,
+	})
+	_, err = alterRoleOptionsQuery(db, d)
+	assert.EqualError(t, err, "role REPLICATION is disabled in the provider configuration (disabled_features)")
+}
+
+// BenchmarkAccPostgresqlRole_CreateRead measures the time needed to create then refresh 100 roles.
+// To compare two revisions, run it on each of them and compare the results with benchstat:
+//
+//	TF_ACC=1 go test ./postgresql -run '^$' -bench PostgresqlRole_CreateRead -count 5
+func BenchmarkAccPostgresqlRole_CreateRead(b *testing.B) {
+	if os.Getenv(resource.EnvTfAcc) == "" {
+		b.Skipf("Acceptance tests skipped unless env '%s' set", resource.EnvTfAcc)
+	}
+
+	if err := testAccProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(nil)); err != nil {
+		b.Fatal(err)
+	}
+	db, err := testAccProvider.Meta().(*Client).Connect()
+	if err != nil {
+		b.Fatalf("could not connect to database: %v", err)
+	}
+
+	const roleCount = 100
+
+	for n := 0; n < b.N; n++ {
+		roles := make([]*schema.ResourceData, roleCount)
+		for i := range roles {
+			d := benchmarkRoleData(b, fmt.Sprintf("tf_bench_role_%d", i))
+			if err := resourcePostgreSQLRoleCreate(db, d); err != nil {
+				b.Fatalf("could not create role: %v", err)
+			}
+			roles[i] = d
+		}
+
+		for _, d := range roles {
+			if err := resourcePostgreSQLRoleRead(db, d); err != nil {
+				b.Fatalf("could not read role: %v", err)
+			}
+		}
+
+		b.StopTimer()
+		for _, d := range roles {
+			if err := resourcePostgreSQLRoleDelete(db, d); err != nil {
+				b.Fatalf("could not delete role: %v", err)
+			}
+		}
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*roleCount), "ns/role")
+}
+
+// BenchmarkRoleCreateRead counts the round trips needed to create then refresh a role,
+// against a fake server answering each of them after 1ms. It doesn't need PostgreSQL:
+//
+//	go test ./postgresql -run '^$' -bench RoleCreateRead
+func BenchmarkRoleCreateRead(b *testing.B) {
+	fake := &fakeDB{answer: fakeRoleAnswer, latency: time.Millisecond}
+	db, err := newFakeClient(b, fake, "16.0.0").Connect()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	fake.Statements()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		d := benchmarkRoleData(b, "tf_bench_role", "group_a", "group_b")
+		if err := resourcePostgreSQLRoleCreate(db, d); err != nil {
+			b.Fatalf("could not create role: %v", err)
+		}
+		if err := resourcePostgreSQLRoleRead(db, d); err != nil {
+			b.Fatalf("could not read role: %v", err)
+		}
+	}
+	b.ReportMetric(float64(len(fake.Statements()))/float64(b.N), "round-trips/op")
+}
+
+// benchmarkRoleData returns the planned creation of a role with a password and some configuration parameters.
+func benchmarkRoleData(b *testing.B, name string, roles ...interface{}) *schema.ResourceData {
+	return schema.TestResourceDataRaw(benchmarkT{b}, resourcePostgreSQLRole().Schema, map[string]interface{}{
+		roleNameAttr:             name,
+		roleLoginAttr:            true,
+		rolePasswordAttr:         testRolePassword,
+		roleInheritAttr:          true,
+		roleConnLimitAttr:        -1,
+		roleValidUntilAttr:       "infinity",
+		roleStatementTimeoutAttr: 30000,
+		roleLockTimeoutAttr:      10000,
+		roleRolesAttr:            roles,
+	})
+}
+
+// benchmarkT lets the benchmarks use schema.TestResourceDataRaw.
+type benchmarkT struct {
+	*testing.B
+}
+
+func (benchmarkT) Parallel() {}
+
+// fakeRoleAnswer answers the role refresh query of fakeDB with the role of benchmarkRoleData.
+func fakeRoleAnswer(query string, args []driver.NamedValue) (*fakeRows, error) {
+	if !strings.Contains(query, "FROM pg_catalog.pg_roles WHERE rolname=$1") {
+		return nil, nil
+	}
+	return &fakeRows{values: [][]driver.Value{{
+		[]byte("{group_a,group_b}"), []byte("{}"), []byte("{}"),
+		args[0].Value, false, true, false, false, true, int64(-1), "infinity",
+		[]byte("{statement_timeout=30000,lock_timeout=10000}"), int64(0), int64(16384), "", "",
+		false, false,
+	}}}, nil
+}
+
+// BenchmarkRoleRefresh measures the refresh of 1000 roles against a fake server which parses the
+// parameterized queries in their own round trip as lib/pq does, with and without the prepared statement cache.
+func BenchmarkRoleRefresh(b *testing.B) {
+	const roleCount = 1000
+	roles := make([]*schema.ResourceData, roleCount)
+	for i := range roles {
+		roles[i] = benchmarkRoleData(b, fmt.Sprintf("tf_bench_refresh_role_%d", i), "group_a", "group_b")
+		roles[i].SetId(roles[i].Get(roleNameAttr).(string))
+	}
+
+	for _, cacheSize := range []int{0, 100} {
+		b.Run(fmt.Sprintf("statement_cache_size=%d", cacheSize), func(b *testing.B) {
+			fake := &fakeDB{answer: fakeRoleAnswer, latency: 100 * time.Microsecond, parseRoundTrips: true}
+			db, err := newFakeClient(b, fake, "16.0.0", func(config *Config) {
+				config.StmtCacheSize = cacheSize
+			}).Connect()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			fake.Statements()
+			parses := fake.Parses()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				for _, d := range roles {
+					if err := resourcePostgreSQLRoleRead(db, d); err != nil {
+						b.Fatalf("could not read role: %v", err)
+					}
+				}
+			}
+			b.ReportMetric(float64(len(fake.Statements()))/float64(b.N), "queries/op")
+			b.ReportMetric(float64(fake.Parses()-parses)/float64(b.N), "parses/op")
+		})
+	}
+}
+
+// BenchmarkAccPostgresqlRole_Refresh measures the time needed to refresh 1000 roles,
+// with and without the prepared statement cache (statement_cache_size):
+//
+//	TF_ACC=1 go test ./postgresql -run '^$' -bench PostgresqlRole_Refresh -count 5
+func BenchmarkAccPostgresqlRole_Refresh(b *testing.B) {
+	if os.Getenv(resource.EnvTfAcc) == "" {
+		b.Skipf("Acceptance tests skipped unless env '%s' set", resource.EnvTfAcc)
+	}
+
+	if err := testAccProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(nil)); err != nil {
+		b.Fatal(err)
+	}
+	client := testAccProvider.Meta().(*Client)
+	db, err := client.Connect()
+	if err != nil {
+		b.Fatalf("could not connect to database: %v", err)
+	}
+
+	const roleCount = 1000
+	res := resourcePostgreSQLRole()
+
+	roles := make([]*schema.ResourceData, roleCount)
+	for i := range roles {
+		d := res.TestResourceData()
+		d.Set(roleNameAttr, fmt.Sprintf("tf_bench_refresh_role_%d", i))
+		if err := resourcePostgreSQLRoleCreate(db, d); err != nil {
+			b.Fatalf("could not create role: %v", err)
+		}
+		roles[i] = d
+	}
+	defer func() {
+		for _, d := range roles {
+			if err := resourcePostgreSQLRoleDelete(db, d); err != nil {
+				b.Errorf("could not delete role: %v", err)
+			}
+		}
+	}()
+
+	for _, cacheSize := range []int{0, 100} {
+		b.Run(fmt.Sprintf("statement_cache_size=%d", cacheSize), func(b *testing.B) {
+			// Each configuration uses its own connection pool (they are shared by connection string).
+			config := client.config
+			config.StmtCacheSize = cacheSize
+			config.ConnectionParams = map[string]string{}
+			for key, value := range client.config.ConnectionParams {
+				config.ConnectionParams[key] = value
+			}
+			config.ConnectionParams["application_name"] = fmt.Sprintf("tf_bench_cache_%d", cacheSize)
+			db, err := config.NewClient(client.databaseName).Connect()
+			if err != nil {
+				b.Fatalf("could not connect to database: %v", err)
+			}
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				for _, d := range roles {
+					if err := resourcePostgreSQLRoleRead(db, d); err != nil {
+						b.Fatalf("could not read role: %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
 func testAccCheckPostgresqlRoleDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client)
 
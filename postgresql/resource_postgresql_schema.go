@@ -22,6 +22,8 @@ const (
 	schemaIfNotExists  = "if_not_exists"
 	schemaDropCascade  = "drop_cascade"
 
+	schemaFailOnGrantConflictAttr = "fail_on_grant_conflict"
+
 	schemaPolicyCreateAttr          = "create"
 	schemaPolicyCreateWithGrantAttr = "create_with_grant"
 	schemaPolicyRoleAttr            = "role"
@@ -31,11 +33,11 @@ const (
 
 func resourcePostgreSQLSchema() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLSchemaCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLSchemaRead),
-		Update: PGResourceFunc(resourcePostgreSQLSchemaUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLSchemaDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLSchemaExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLSchemaCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSchemaRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLSchemaUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSchemaDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLSchemaExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -54,10 +56,17 @@ func resourcePostgreSQLSchema() *schema.Resource {
 				Description: "The database name to alter schema",
 			},
 			schemaOwnerAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The ROLE name who owns the schema, or its OID as `oid:NNN` to be unaffected by renames",
+				ValidateFunc: validateOwner,
+			},
+			commentAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "The ROLE name who owns the schema",
+				Description: "The comment of the schema. It is set in the same transaction as the creation of the schema. If not set, the comment is left as is, so a comment set outside of Terraform is kept",
 			},
 			schemaIfNotExists: {
 				Type:        schema.TypeBool,
@@ -71,6 +80,13 @@ func resourcePostgreSQLSchema() *schema.Resource {
 				Default:     false,
 				Description: "When true, will also drop all the objects that are contained in the schema",
 			},
+			schemaFailOnGrantConflictAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "When true, fail instead of logging a warning if a `postgresql_grant` on this schema manages the privileges " +
+					"of a role of `policy`, as they revert each other's changes",
+			},
 			schemaPolicyAttr: {
 				Type:       schema.TypeSet,
 				Optional:   true,
@@ -118,40 +134,37 @@ func resourcePostgreSQLSchema() *schema.Resource {
 
 func resourcePostgreSQLSchemaCreate(db *DBConnection, d *schema.ResourceData) error {
 	database := getDatabase(d, db.client.databaseName)
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
-
-	// If the authenticated user is not a superuser (e.g. on AWS RDS)
-	// we'll need to temporarily grant it membership in the following roles:
-	//  * the owner of the db (to have the permissions to create the schema)
-	//  * the owner of the schema, if it has one (in order to change its owner)
-	var rolesToGrant []string
-
-	dbOwner, err := getDatabaseOwner(txn, database)
-	if err != nil {
-		return err
-	}
-	rolesToGrant = append(rolesToGrant, dbOwner)
-
-	schemaOwner := d.Get("owner").(string)
-	if schemaOwner != "" && schemaOwner != dbOwner {
-		rolesToGrant = append(rolesToGrant, schemaOwner)
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		// If the authenticated user is not a superuser (e.g. on AWS RDS)
+		// we'll need to temporarily grant it membership in the following roles:
+		//  * the owner of the db (to have the permissions to create the schema)
+		//  * the owner of the schema, if it has one (in order to change its owner)
+		var rolesToGrant []string
+
+		dbOwner, err := getDatabaseOwner(txn, database)
+		if err != nil {
+			return err
+		}
+		rolesToGrant = append(rolesToGrant, dbOwner)
 
-	}
+		schemaOwner, err := resolveOwner(txn, d.Get(schemaOwnerAttr).(string))
+		if err != nil {
+			return err
+		}
+		if schemaOwner != "" && schemaOwner != dbOwner {
+			if err := checkRoleExistence(txn, schemaOwner); err != nil {
+				return fmt.Errorf("invalid owner of schema %s: %w", d.Get(schemaNameAttr).(string), err)
+			}
+			rolesToGrant = append(rolesToGrant, schemaOwner)
+		}
 
-	if err := withRolesGranted(txn, rolesToGrant, func() error {
-		return createSchema(db, txn, d)
+		return withRolesGranted(db, txn, rolesToGrant, func() error {
+			return createSchema(db, txn, d)
+		})
 	}); err != nil {
 		return err
 	}
 
-	if err := txn.Commit(); err != nil {
-		return fmt.Errorf("Error committing schema: %w", err)
-	}
-
 	d.SetId(generateSchemaID(d, database))
 
 	return resourcePostgreSQLSchemaReadImpl(db, d)
@@ -175,9 +188,12 @@ func createSchema(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 		}
 		fmt.Fprint(b, pq.QuoteIdentifier(schemaName))
 
-		switch v, ok := d.GetOk(schemaOwnerAttr); {
-		case ok:
-			fmt.Fprint(b, " AUTHORIZATION ", pq.QuoteIdentifier(v.(string)))
+		owner, err := resolveOwner(txn, d.Get(schemaOwnerAttr).(string))
+		if err != nil {
+			return err
+		}
+		if owner != "" {
+			fmt.Fprint(b, " AUTHORIZATION ", pq.QuoteIdentifier(owner))
 		}
 		queries = append(queries, b.String())
 
@@ -225,6 +241,12 @@ func createSchema(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 		}
 	}
 
+	if comment := d.Get(commentAttr).(string); comment != "" {
+		if err := setObjectComment(txn, "SCHEMA", pq.QuoteIdentifier(schemaName), comment); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -248,9 +270,12 @@ func resourcePostgreSQLSchemaDelete(db *DBConnection, d *schema.ResourceData) er
 		return nil
 	}
 
-	owner := d.Get("owner").(string)
+	owner, err := resolveOwner(txn, d.Get(schemaOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
 
-	if err = withRolesGranted(txn, []string{owner}, func() error {
+	if err = withRolesGranted(db, txn, []string{owner}, func() error {
 		dropMode := "RESTRICT"
 		if d.Get(schemaDropCascade).(bool) {
 			dropMode = "CASCADE"
@@ -321,8 +346,14 @@ func resourcePostgreSQLSchemaReadImpl(db *DBConnection, d *schema.ResourceData)
 	defer deferredRollback(txn)
 
 	var schemaOwner string
+	var schemaOwnerOID uint32
+	var schemaComment string
 	var schemaACLs []string
-	err = txn.QueryRow("SELECT pg_catalog.pg_get_userbyid(n.nspowner), COALESCE(n.nspacl, '{}'::aclitem[])::TEXT[] FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", schemaName).Scan(&schemaOwner, pq.Array(&schemaACLs))
+	err = txn.QueryRow(
+		"SELECT pg_catalog.pg_get_userbyid(n.nspowner), n.nspowner, COALESCE(pg_catalog.obj_description(n.oid, 'pg_namespace'), ''), "+
+			"COALESCE(n.nspacl, '{}'::aclitem[])::TEXT[] FROM pg_catalog.pg_namespace n WHERE n.nspname=$1",
+		schemaName,
+	).Scan(&schemaOwner, &schemaOwnerOID, &schemaComment, pq.Array(&schemaACLs))
 	switch {
 	case err == sql.ErrNoRows:
 		log.Printf("[WARN] PostgreSQL schema (%s) not found in database %s", schemaName, database)
@@ -355,10 +386,20 @@ func resourcePostgreSQLSchemaReadImpl(db *DBConnection, d *schema.ResourceData)
 		}
 
 		d.Set(schemaNameAttr, schemaName)
-		d.Set(schemaOwnerAttr, schemaOwner)
+		d.Set(schemaOwnerAttr, ownerStateValue(d.Get(schemaOwnerAttr).(string), schemaOwner, schemaOwnerOID))
 		d.Set(schemaDatabaseAttr, database)
+		d.Set(commentAttr, schemaComment)
 		d.SetId(generateSchemaID(d, database))
 
+		for _, policy := range d.Get(schemaPolicyAttr).(*schema.Set).List() {
+			if err := schemaPrivilegesClaims.claimPolicy(
+				db.client.config.connStr(database), schemaName,
+				policy.(map[string]interface{})[schemaPolicyRoleAttr].(string), d.Get(schemaFailOnGrantConflictAttr).(bool),
+			); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}
 }
@@ -366,26 +407,28 @@ func resourcePostgreSQLSchemaReadImpl(db *DBConnection, d *schema.ResourceData)
 func resourcePostgreSQLSchemaUpdate(db *DBConnection, d *schema.ResourceData) error {
 	databaseName := getDatabase(d, db.client.databaseName)
 
-	txn, err := startTransaction(db.client, databaseName)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
+	if err := db.client.withTx(databaseName, func(txn *sql.Tx) error {
+		if err := setSchemaName(txn, d, databaseName); err != nil {
+			return err
+		}
 
-	if err := setSchemaName(txn, d, databaseName); err != nil {
-		return err
-	}
+		if err := setSchemaOwner(txn, d); err != nil {
+			return err
+		}
 
-	if err := setSchemaOwner(txn, d); err != nil {
-		return err
-	}
+		if err := setSchemaPolicy(db, txn, d); err != nil {
+			return err
+		}
 
-	if err := setSchemaPolicy(txn, d); err != nil {
-		return err
-	}
+		if d.HasChange(commentAttr) {
+			if err := setObjectComment(txn, "SCHEMA", pq.QuoteIdentifier(d.Get(schemaNameAttr).(string)), d.Get(commentAttr).(string)); err != nil {
+				return err
+			}
+		}
 
-	if err := txn.Commit(); err != nil {
-		return fmt.Errorf("Error committing schema: %w", err)
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	return resourcePostgreSQLSchemaReadImpl(db, d)
@@ -418,11 +461,17 @@ func setSchemaOwner(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 
 	schemaName := d.Get(schemaNameAttr).(string)
-	schemaOwner := d.Get(schemaOwnerAttr).(string)
+	schemaOwner, err := resolveOwner(txn, d.Get(schemaOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
 
 	if schemaOwner == "" {
 		return errors.New("Error setting schema owner to an empty string")
 	}
+	if err := checkRoleExistence(txn, schemaOwner); err != nil {
+		return fmt.Errorf("invalid owner of schema %s: %w", schemaName, err)
+	}
 
 	sql := fmt.Sprintf("ALTER SCHEMA %s OWNER TO %s", pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(schemaOwner))
 	if _, err := txn.Exec(sql); err != nil {
@@ -432,13 +481,16 @@ func setSchemaOwner(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setSchemaPolicy(txn *sql.Tx, d *schema.ResourceData) error {
+func setSchemaPolicy(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(schemaPolicyAttr) {
 		return nil
 	}
 
 	schemaName := d.Get(schemaNameAttr).(string)
-	owner := d.Get(schemaOwnerAttr).(string)
+	owner, err := resolveOwner(txn, d.Get(schemaOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
 
 	oraw, nraw := d.GetChange(schemaPolicyAttr)
 	oldList := oraw.(*schema.Set).List()
@@ -497,7 +549,7 @@ func setSchemaPolicy(txn *sql.Tx, d *schema.ResourceData) error {
 		rolesToGrant = append(rolesToGrant, owner)
 	}
 
-	return withRolesGranted(txn, rolesToGrant, func() error {
+	return withRolesGranted(db, txn, rolesToGrant, func() error {
 		for _, query := range queries {
 			if _, err := txn.Exec(query); err != nil {
 				return fmt.Errorf("Error updating schema DCL: %w", err)
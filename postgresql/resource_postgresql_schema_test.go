@@ -3,6 +3,7 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -52,6 +53,25 @@ func TestAccPostgresqlSchema_Basic(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlSchema_UnknownOwner(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_schema" "test" {
+	name  = "test_schema_unknown_owner"
+	owner = "tf_test_unknown_role"
+}
+`,
+				ExpectError: regexp.MustCompile(`invalid owner of schema test_schema_unknown_owner: role tf_test_unknown_role does not exist`),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlSchema_AddPolicy(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -215,6 +235,92 @@ func TestAccPostgresqlSchema_Database(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlSchema_Comment(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	config := `
+	resource "postgresql_schema" "test_comment" {
+		name     = "%s"
+		database = "%s"
+		comment  = "%s"
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "test_comment", dbName, "first comment"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.test_comment", "test_comment"),
+					resource.TestCheckResourceAttr("postgresql_schema.test_comment", "comment", "first comment"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, "test_comment", dbName, "it's updated"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_schema.test_comment", "comment", "it's updated"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, "test_comment", dbName, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_schema.test_comment", "comment", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_PolicyGrantConflict(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := fmt.Sprintf(`
+	resource "postgresql_schema" "test_conflict" {
+		name                   = "test_conflict"
+		database               = "%s"
+		fail_on_grant_conflict = true
+
+		policy {
+			role  = "%s"
+			usage = true
+		}
+	}
+
+	resource "postgresql_grant" "test_conflict" {
+		database    = "%s"
+		role        = "%s"
+		schema      = postgresql_schema.test_conflict.name
+		object_type = "schema"
+		privileges  = ["USAGE", "CREATE"]
+	}
+	`, dbName, roleName, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile("managed by both a policy of postgresql_schema and a postgresql_grant"),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlSchema_DropCascade(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -0,0 +1,279 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	securityLabelProviderAttr   = "label_provider"
+	securityLabelDatabaseAttr   = "database"
+	securityLabelObjectTypeAttr = "object_type"
+	securityLabelSchemaAttr     = "schema"
+	securityLabelObjectNameAttr = "object_name"
+	securityLabelColumnAttr     = "column"
+	securityLabelLabelAttr      = "label"
+)
+
+var allowedSecurityLabelObjectTypes = []string{
+	"column",
+	"schema",
+	"table",
+}
+
+func resourcePostgreSQLSecurityLabel() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLSecurityLabelCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSecurityLabelRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLSecurityLabelUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSecurityLabelDelete),
+		CustomizeDiff: resourcePostgreSQLSecurityLabelCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			securityLabelProviderAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The name of the label provider (e.g. `anon` or `selinux`), which must be loaded in the server",
+			},
+			securityLabelDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database in which the labeled object is. Defaults to the provider database",
+			},
+			securityLabelObjectTypeAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(allowedSecurityLabelObjectTypes, false),
+				Description:  "The type of the labeled object (one of: " + strings.Join(allowedSecurityLabelObjectTypes, ", ") + ")",
+			},
+			securityLabelSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The schema of the labeled table or column. Required unless object_type is schema",
+			},
+			securityLabelObjectNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The name of the labeled schema or table (the table of the column for object_type column)",
+			},
+			securityLabelColumnAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of the labeled column. Required if object_type is column",
+			},
+			securityLabelLabelAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The security label. It is not re-applied if the labeled object is replaced by another resource, use `replace_triggered_by` on that resource to recreate the label with it",
+			},
+		},
+	}
+}
+
+// resourcePostgreSQLSecurityLabelCustomizeDiff checks that schema and column are set as required by object_type,
+// so the plan fails rather than the apply.
+func resourcePostgreSQLSecurityLabelCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	for _, attr := range []string{securityLabelObjectTypeAttr, securityLabelSchemaAttr, securityLabelColumnAttr} {
+		if !diff.NewValueKnown(attr) {
+			return nil
+		}
+	}
+
+	return validateSecurityLabelObject(
+		diff.Get(securityLabelObjectTypeAttr).(string),
+		diff.Get(securityLabelSchemaAttr).(string),
+		diff.Get(securityLabelColumnAttr).(string),
+	)
+}
+
+func validateSecurityLabelObject(objectType, schemaName, column string) error {
+	switch {
+	case objectType != "schema" && schemaName == "":
+		return fmt.Errorf("parameter `schema` is mandatory when `object_type` is %s", objectType)
+	case objectType == "schema" && schemaName != "":
+		return fmt.Errorf("cannot specify `schema` when `object_type` is schema, use `object_name` instead")
+	case objectType == "column" && column == "":
+		return fmt.Errorf("parameter `column` is mandatory when `object_type` is column")
+	case objectType != "column" && column != "":
+		return fmt.Errorf("cannot specify `column` when `object_type` is not column")
+	}
+	return nil
+}
+
+func resourcePostgreSQLSecurityLabelCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	if err := setSecurityLabel(db, database, d, d.Get(securityLabelLabelAttr).(string)); err != nil {
+		return err
+	}
+
+	d.Set(securityLabelDatabaseAttr, database)
+	d.SetId(generateSecurityLabelID(d, database))
+
+	return resourcePostgreSQLSecurityLabelReadImpl(db, d)
+}
+
+func resourcePostgreSQLSecurityLabelRead(db *DBConnection, d *schema.ResourceData) error {
+	return resourcePostgreSQLSecurityLabelReadImpl(db, d)
+}
+
+func resourcePostgreSQLSecurityLabelReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	objectType := d.Get(securityLabelObjectTypeAttr).(string)
+	schemaName := d.Get(securityLabelSchemaAttr).(string)
+	objectName := d.Get(securityLabelObjectNameAttr).(string)
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	// Labels on columns are stored with the table in classoid/objoid
+	// and the column number in objsubid.
+	var query string
+	var args []interface{}
+	switch objectType {
+	case "schema":
+		query = `SELECT l.label FROM pg_catalog.pg_seclabel l
+JOIN pg_catalog.pg_namespace n ON n.oid = l.objoid
+WHERE l.classoid = 'pg_catalog.pg_namespace'::regclass AND l.objsubid = 0
+AND l.provider = $1 AND n.nspname = $2`
+		args = []interface{}{d.Get(securityLabelProviderAttr), objectName}
+	case "table":
+		query = `SELECT l.label FROM pg_catalog.pg_seclabel l
+JOIN pg_catalog.pg_class c ON c.oid = l.objoid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE l.classoid = 'pg_catalog.pg_class'::regclass AND l.objsubid = 0
+AND l.provider = $1 AND n.nspname = $2 AND c.relname = $3`
+		args = []interface{}{d.Get(securityLabelProviderAttr), schemaName, objectName}
+	case "column":
+		query = `SELECT l.label FROM pg_catalog.pg_seclabel l
+JOIN pg_catalog.pg_class c ON c.oid = l.objoid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attnum = l.objsubid
+WHERE l.classoid = 'pg_catalog.pg_class'::regclass
+AND l.provider = $1 AND n.nspname = $2 AND c.relname = $3 AND a.attname = $4`
+		args = []interface{}{d.Get(securityLabelProviderAttr), schemaName, objectName, d.Get(securityLabelColumnAttr)}
+	}
+
+	var label string
+	err = txn.QueryRow(query, args...).Scan(&label)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL security label on %s %s not found in database %s", objectType, d.Id(), database)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return fmt.Errorf("Error reading security label: %w", err)
+	}
+
+	d.Set(securityLabelLabelAttr, label)
+	d.Set(securityLabelDatabaseAttr, database)
+	d.SetId(generateSecurityLabelID(d, database))
+
+	return nil
+}
+
+func resourcePostgreSQLSecurityLabelUpdate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	if d.HasChange(securityLabelLabelAttr) {
+		if err := setSecurityLabel(db, database, d, d.Get(securityLabelLabelAttr).(string)); err != nil {
+			return err
+		}
+	}
+
+	return resourcePostgreSQLSecurityLabelReadImpl(db, d)
+}
+
+func resourcePostgreSQLSecurityLabelDelete(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	if err := setSecurityLabel(db, database, d, ""); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// setSecurityLabel sets the label of the object, an empty label removes it.
+func setSecurityLabel(db *DBConnection, database string, d *schema.ResourceData, label string) error {
+	labelProvider := d.Get(securityLabelProviderAttr).(string)
+
+	quotedLabel := "NULL"
+	if label != "" {
+		quotedLabel = pq.QuoteLiteral(label)
+	}
+
+	query := fmt.Sprintf(
+		"SECURITY LABEL FOR %s ON %s IS %s",
+		pq.QuoteIdentifier(labelProvider),
+		securityLabelObject(
+			d.Get(securityLabelObjectTypeAttr).(string),
+			d.Get(securityLabelSchemaAttr).(string),
+			d.Get(securityLabelObjectNameAttr).(string),
+			d.Get(securityLabelColumnAttr).(string),
+		),
+		quotedLabel,
+	)
+
+	return db.client.withTx(database, func(txn *sql.Tx) error {
+		if _, err := txn.Exec(query); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && strings.Contains(pqErr.Message, "is not loaded") {
+				return fmt.Errorf(
+					"security label provider %s is not loaded in the server, it needs to be added to shared_preload_libraries: %w",
+					labelProvider, err,
+				)
+			}
+			return fmt.Errorf("could not set security label: %w", err)
+		}
+		return nil
+	})
+}
+
+// securityLabelObject returns the object part of a SECURITY LABEL statement.
+func securityLabelObject(objectType, schemaName, objectName, column string) string {
+	switch objectType {
+	case "schema":
+		return "SCHEMA " + pq.QuoteIdentifier(objectName)
+	case "column":
+		return "COLUMN " + quoteQualifiedIdentifier(schemaName, objectName, column)
+	default:
+		return "TABLE " + quoteQualifiedIdentifier(schemaName, objectName)
+	}
+}
+
+func generateSecurityLabelID(d *schema.ResourceData, database string) string {
+	parts := []string{
+		database,
+		d.Get(securityLabelProviderAttr).(string),
+		d.Get(securityLabelObjectTypeAttr).(string),
+	}
+	if schemaName := d.Get(securityLabelSchemaAttr).(string); schemaName != "" {
+		parts = append(parts, schemaName)
+	}
+	parts = append(parts, d.Get(securityLabelObjectNameAttr).(string))
+	if column := d.Get(securityLabelColumnAttr).(string); column != "" {
+		parts = append(parts, column)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityLabelObject(t *testing.T) {
+	assert.Equal(t, `SCHEMA "my_schema"`, securityLabelObject("schema", "", "my_schema", ""))
+	assert.Equal(t, `TABLE "my_schema"."my_table"`, securityLabelObject("table", "my_schema", "my_table", ""))
+	assert.Equal(t, `COLUMN "my_schema"."my_table"."my_column"`, securityLabelObject("column", "my_schema", "my_table", "my_column"))
+}
+
+func TestValidateSecurityLabelObject(t *testing.T) {
+	assert.NoError(t, validateSecurityLabelObject("schema", "", ""))
+	assert.NoError(t, validateSecurityLabelObject("table", "my_schema", ""))
+	assert.NoError(t, validateSecurityLabelObject("column", "my_schema", "my_column"))
+
+	assert.EqualError(t, validateSecurityLabelObject("table", "", ""), "parameter `schema` is mandatory when `object_type` is table")
+	assert.EqualError(t, validateSecurityLabelObject("schema", "my_schema", ""), "cannot specify `schema` when `object_type` is schema, use `object_name` instead")
+	assert.EqualError(t, validateSecurityLabelObject("column", "my_schema", ""), "parameter `column` is mandatory when `object_type` is column")
+	assert.EqualError(t, validateSecurityLabelObject("table", "my_schema", "my_column"), "cannot specify `column` when `object_type` is not column")
+
+	// The object is checked when planning.
+	_, err := resourcePostgreSQLSecurityLabel().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		securityLabelProviderAttr:   "anon",
+		securityLabelObjectTypeAttr: "column",
+		securityLabelSchemaAttr:     "my_schema",
+		securityLabelObjectNameAttr: "my_table",
+		securityLabelLabelAttr:      "MASKED WITH VALUE NULL",
+	}), nil)
+	assert.EqualError(t, err, "parameter `column` is mandatory when `object_type` is column")
+}
+
+// There's no label provider loaded in the test server, so we only check
+// that the error is explicit.
+func TestAccPostgresqlSecurityLabel_ProviderNotLoaded(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	createTestTables(t, dbSuffix, []string{"test_schema.test_table"}, "")
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	config := fmt.Sprintf(`
+resource "postgresql_security_label" "test" {
+	database       = "%s"
+	label_provider = "provider_not_loaded"
+	object_type    = "column"
+	schema         = "test_schema"
+	object_name    = "test_table"
+	column         = "val"
+	label          = "MASKED WITH VALUE NULL"
+}
+`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile("security label provider provider_not_loaded is not loaded"),
+			},
+		},
+	})
+}
@@ -23,10 +23,10 @@ const (
 
 func resourcePostgreSQLServer() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLServerCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLServerRead),
-		Update: PGResourceFunc(resourcePostgreSQLServerUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLServerDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLServerCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLServerRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLServerUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLServerDelete),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -55,10 +55,11 @@ func resourcePostgreSQLServer() *schema.Resource {
 				Description: "The name of the foreign-data wrapper that manages the server",
 			},
 			serverOwnerAttr: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Computed:    true,
-				Description: "The user name of the new owner of the foreign server",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The user name of the new owner of the foreign server, or its OID as `oid:NNN` to be unaffected by renames",
+				ValidateFunc: validateOwner,
 			},
 			serverOptionsAttr: {
 				Type: schema.TypeMap,
@@ -167,11 +168,12 @@ func resourcePostgreSQLServerReadImpl(db *DBConnection, d *schema.ResourceData)
 	defer deferredRollback(txn)
 
 	var serverType, serverVersion, serverOwner, serverFDW string
+	var serverOwnerOID uint32
 	var serverOptions []string
-	query := `SELECT COALESCE(fs.srvtype, ''), COALESCE(fs.srvversion, ''), fs.srvowner::regrole, fs.srvoptions, w.fdwname ` +
+	query := `SELECT COALESCE(fs.srvtype, ''), COALESCE(fs.srvversion, ''), fs.srvowner::regrole, fs.srvowner, fs.srvoptions, w.fdwname ` +
 		`FROM pg_foreign_server fs JOIN pg_foreign_data_wrapper w on w.oid = fs.srvfdw ` +
 		`WHERE fs.srvname = $1`
-	err = txn.QueryRow(query, serverName).Scan(&serverType, &serverVersion, &serverOwner, pq.Array(&serverOptions), &serverFDW)
+	err = txn.QueryRow(query, serverName).Scan(&serverType, &serverVersion, &serverOwner, &serverOwnerOID, pq.Array(&serverOptions), &serverFDW)
 	switch {
 	case err == sql.ErrNoRows:
 		log.Printf("[WARN] PostgreSQL foreign server (%s) not found", serverName)
@@ -190,7 +192,7 @@ func resourcePostgreSQLServerReadImpl(db *DBConnection, d *schema.ResourceData)
 	d.Set(serverNameAttr, serverName)
 	d.Set(serverTypeAttr, serverType)
 	d.Set(serverVersionAttr, serverVersion)
-	d.Set(serverOwnerAttr, serverOwner)
+	d.Set(serverOwnerAttr, ownerStateValue(d.Get(serverOwnerAttr).(string), serverOwner, serverOwnerOID))
 	d.Set(serverOptionsAttr, mappedOptions)
 	d.Set(serverFDWAttr, serverFDW)
 	d.SetId(serverName)
@@ -241,28 +243,20 @@ func resourcePostgreSQLServerUpdate(db *DBConnection, d *schema.ResourceData) er
 		)
 	}
 
-	txn, err := startTransaction(db.client, "")
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
-
-	if err := setServerNameIfChanged(txn, d); err != nil {
-		return err
-	}
+	if err := db.client.withTx("", func(txn *sql.Tx) error {
+		if err := setServerNameIfChanged(txn, d); err != nil {
+			return err
+		}
 
-	if err := setServerOwnerIfChanged(txn, d); err != nil {
-		return err
-	}
+		if err := setServerOwnerIfChanged(txn, d); err != nil {
+			return err
+		}
 
-	if err := setServerVersionOptionsIfChanged(txn, d); err != nil {
+		return setServerVersionOptionsIfChanged(txn, d)
+	}); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error updating foreign server: %w", err)
-	}
-
 	return resourcePostgreSQLServerReadImpl(db, d)
 }
 
@@ -345,7 +339,10 @@ func setServerOwnerIfChanged(txn *sql.Tx, d *schema.ResourceData) error {
 
 func setServerOwner(txn *sql.Tx, d *schema.ResourceData) error {
 	serverName := d.Get(serverNameAttr).(string)
-	serverNewOwner := d.Get(serverOwnerAttr).(string)
+	serverNewOwner, err := resolveOwner(txn, d.Get(serverOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
 
 	b := bytes.NewBufferString("ALTER SERVER ")
 	fmt.Fprintf(b, "%s OWNER TO %s", pq.QuoteIdentifier(serverName), pq.QuoteIdentifier(serverNewOwner))
@@ -0,0 +1,172 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	sqlDatabaseAttr   = "database"
+	sqlCreateSQLAttr  = "create_sql"
+	sqlReadSQLAttr    = "read_sql"
+	sqlUpdateSQLAttr  = "update_sql"
+	sqlDestroySQLAttr = "destroy_sql"
+	sqlResultAttr     = "result"
+)
+
+// catalogLock runs the create, update and destroy statements of the postgresql_sql resources one at a time
+// in this provider process, the read_sql queries (and the data sources reading the catalog, e.g.
+// postgresql_publication) take it for reading so they run concurrently between them.
+// It is a process-local lock: the other resources are not blocked by it, nor are other provider
+// processes or clients of the server, the statements must take their own locks if needed.
+var catalogLock sync.RWMutex
+
+func resourcePostgreSQLSQL() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLSQLCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSQLRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLSQLUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSQLDelete),
+		CustomizeDiff: resourcePostgreSQLSQLCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			sqlDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database in which the statements are run. Defaults to the provider database",
+			},
+			sqlCreateSQLAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The statements run when the resource is created. Changing them recreates the resource unless `update_sql` is set",
+			},
+			sqlReadSQLAttr: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "A query returning a single value, stored in `result`. " +
+					"If it returns no row or NULL, the resource is considered as deleted and is created again",
+			},
+			sqlUpdateSQLAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The statements run instead of recreating the resource when `create_sql` or `update_sql` change",
+			},
+			sqlDestroySQLAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The statements run when the resource is destroyed",
+			},
+			sqlResultAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The value returned by `read_sql`",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLSQLCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if diff.Id() != "" && diff.HasChange(sqlCreateSQLAttr) && diff.Get(sqlUpdateSQLAttr).(string) == "" {
+		return diff.ForceNew(sqlCreateSQLAttr)
+	}
+	return nil
+}
+
+func resourcePostgreSQLSQLCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	if err := execSQLResourceStatements(db, database, sqlCreateSQLAttr, d.Get(sqlCreateSQLAttr).(string)); err != nil {
+		return err
+	}
+
+	d.Set(sqlDatabaseAttr, database)
+	d.SetId(fmt.Sprintf("%s.%d", database, schema.HashString(d.Get(sqlCreateSQLAttr).(string))))
+
+	return resourcePostgreSQLSQLReadImpl(db, d)
+}
+
+func resourcePostgreSQLSQLRead(db *DBConnection, d *schema.ResourceData) error {
+	return resourcePostgreSQLSQLReadImpl(db, d)
+}
+
+func resourcePostgreSQLSQLReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	readSQL := d.Get(sqlReadSQLAttr).(string)
+	if readSQL == "" {
+		return nil
+	}
+
+	database := getDatabase(d, db.client.databaseName)
+
+	catalogLock.RLock()
+	defer catalogLock.RUnlock()
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var result sql.NullString
+	err = txn.QueryRow(readSQL).Scan(&result)
+	switch {
+	case err == sql.ErrNoRows || (err == nil && !result.Valid):
+		log.Printf("[WARN] read_sql of PostgreSQL SQL resource %s returned nothing, considering it as deleted", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not run read_sql: %w", err)
+	}
+
+	d.Set(sqlResultAttr, result.String)
+
+	return nil
+}
+
+func resourcePostgreSQLSQLUpdate(db *DBConnection, d *schema.ResourceData) error {
+	// Without update_sql, a change of create_sql recreates the resource (see CustomizeDiff).
+	if d.HasChanges(sqlCreateSQLAttr, sqlUpdateSQLAttr) {
+		database := getDatabase(d, db.client.databaseName)
+		if err := execSQLResourceStatements(db, database, sqlUpdateSQLAttr, d.Get(sqlUpdateSQLAttr).(string)); err != nil {
+			return err
+		}
+	}
+
+	return resourcePostgreSQLSQLReadImpl(db, d)
+}
+
+func resourcePostgreSQLSQLDelete(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	if err := execSQLResourceStatements(db, database, sqlDestroySQLAttr, d.Get(sqlDestroySQLAttr).(string)); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// execSQLResourceStatements runs the statements of attr in a transaction in the database,
+// with catalogLock held. Empty statements are ignored.
+func execSQLResourceStatements(db *DBConnection, database, attr, statements string) error {
+	if statements == "" {
+		return nil
+	}
+
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+
+	return db.client.withTx(database, func(txn *sql.Tx) error {
+		if _, err := txn.Exec(statements); err != nil {
+			return fmt.Errorf("could not run %s: %w", attr, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,133 @@
+package postgresql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccPostgresqlSQL_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+
+	tfConfig := `
+resource "postgresql_sql" "test" {
+	database    = "postgres"
+	create_sql  = "CREATE TABLE tf_test_sql (id int)"
+	read_sql    = "SELECT to_regclass('tf_test_sql')::text"
+	update_sql  = "%s"
+	destroy_sql = "DROP TABLE IF EXISTS tf_test_sql"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSQLTableDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, "COMMENT ON TABLE tf_test_sql IS 'first'"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_sql.test", "database", "postgres"),
+					resource.TestCheckResourceAttr("postgresql_sql.test", "result", "tf_test_sql"),
+				),
+			},
+			// Changing update_sql runs it in place
+			{
+				Config: fmt.Sprintf(tfConfig, "COMMENT ON TABLE tf_test_sql IS 'second'"),
+				Check:  resource.TestCheckResourceAttr("postgresql_sql.test", "result", "tf_test_sql"),
+			},
+			// Dropping the table outside of Terraform creates it again
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr("postgres"), "DROP TABLE tf_test_sql")
+				},
+				Config: fmt.Sprintf(tfConfig, "COMMENT ON TABLE tf_test_sql IS 'second'"),
+				Check:  resource.TestCheckResourceAttr("postgresql_sql.test", "result", "tf_test_sql"),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlSQLTableDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_sql" {
+			continue
+		}
+
+		txn, err := startTransaction(client, rs.Primary.Attributes[sqlDatabaseAttr])
+		if err != nil {
+			return err
+		}
+		defer deferredRollback(txn)
+
+		var exists bool
+		if err := txn.QueryRow("SELECT to_regclass('tf_test_sql') IS NOT NULL").Scan(&exists); err != nil {
+			return fmt.Errorf("Error checking table %s", err)
+		}
+
+		if exists {
+			return fmt.Errorf("Table still exists after destroy_sql")
+		}
+	}
+
+	return nil
+}
+
+// Test that the read_sql queries run concurrently, while the other statements wait for them.
+func TestSQLCatalogLock(t *testing.T) {
+	fake := &fakeDB{answer: func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+		if query == "SELECT 'exists'" {
+			return &fakeRows{values: [][]driver.Value{{"exists"}}}, nil
+		}
+		return nil, nil
+	}}
+	db, err := newFakeClient(t, fake, "16.0.0").Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLSQL().Schema, map[string]interface{}{
+		sqlCreateSQLAttr: "CREATE TABLE test_table (id int)",
+		sqlReadSQLAttr:   "SELECT 'exists'",
+	})
+
+	// Another read_sql is running.
+	catalogLock.RLock()
+
+	read := make(chan error)
+	go func() { read <- resourcePostgreSQLSQLRead(db, d) }()
+	select {
+	case err := <-read:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("read_sql waited for the other read_sql")
+	}
+	if result := d.Get(sqlResultAttr).(string); result != "exists" {
+		t.Errorf("expected result exists, got %q", result)
+	}
+
+	created := make(chan error)
+	go func() {
+		created <- execSQLResourceStatements(db, "", sqlCreateSQLAttr, d.Get(sqlCreateSQLAttr).(string))
+	}()
+	select {
+	case <-created:
+		t.Fatal("create_sql did not wait for the running read_sql")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	catalogLock.RUnlock()
+	if err := <-created; err != nil {
+		t.Fatal(err)
+	}
+}
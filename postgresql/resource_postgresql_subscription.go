@@ -13,13 +13,20 @@ import (
 
 func resourcePostgreSQLSubscription() *schema.Resource {
 	return &schema.Resource{
-		Create:   PGResourceFunc(resourcePostgreSQLSubscriptionCreate),
-		Read:     PGResourceFunc(resourcePostgreSQLSubscriptionRead),
-		Delete:   PGResourceFunc(resourcePostgreSQLSubscriptionDelete),
-		Exists:   PGResourceExistsFunc(resourcePostgreSQLSubscriptionExists),
-		Importer: &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
+		CreateContext: PGResourceFunc(resourcePostgreSQLSubscriptionCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSubscriptionRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLSubscriptionUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSubscriptionDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLSubscriptionExists),
+		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
 
 		Schema: map[string]*schema.Schema{
+			skipReplicationChecksAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip the check, before creating the subscription, that `max_logical_replication_workers` and `max_replication_slots` (used for the replication origins before PostgreSQL 18) are not 0 on the subscriber",
+			},
 			"name": {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -34,6 +41,13 @@ func resourcePostgreSQLSubscription() *schema.Resource {
 				ForceNew:    true,
 				Description: "Sets the database to add the subscription for",
 			},
+			"owner": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The owner of the subscription, either its name or its OID as `oid:NNN` to be unaffected by renames",
+				ValidateFunc: validation.All(validation.StringIsNotEmpty, validateOwner),
+			},
 			"conninfo": {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -74,6 +88,19 @@ func resourcePostgreSQLSubscription() *schema.Resource {
 	}
 }
 
+// subscriptionPrerequisites returns the settings the subscriber needs for logical replication.
+// The settings of the publisher are checked by the publication resource.
+func subscriptionPrerequisites(db *DBConnection) []replicationPrerequisite {
+	prerequisites := []replicationPrerequisite{
+		{name: "max_logical_replication_workers", expected: "> 0", ok: settingIsPositive},
+	}
+	// Replication origins are limited by max_active_replication_origins since PostgreSQL 18.
+	if !db.featureSupported(featureMaxActiveReplicationOrigins) {
+		prerequisites = append(prerequisites, replicationPrerequisite{name: "max_replication_slots", expected: "> 0", ok: settingIsPositive})
+	}
+	return prerequisites
+}
+
 func resourcePostgreSQLSubscriptionCreate(db *DBConnection, d *schema.ResourceData) error {
 	subName := d.Get("name").(string)
 	databaseName := getDatabaseForSubscription(d, db.client.databaseName)
@@ -90,27 +117,76 @@ func resourcePostgreSQLSubscriptionCreate(db *DBConnection, d *schema.ResourceDa
 	optionalParams := getOptionalParameters(d)
 
 	// Creating of a subscription can not be done in an transaction
-	client := db.client.config.NewClient(databaseName)
+	client := db.client.config.NewClient(databaseName).withContext(db.client.ctx)
 	conn, err := client.Connect()
 	if err != nil {
 		return fmt.Errorf("could not establish database connection: %w", err)
 	}
 
-	sql := fmt.Sprintf("CREATE SUBSCRIPTION %s CONNECTION %s PUBLICATION %s %s;",
+	if !d.Get(skipReplicationChecksAttr).(bool) {
+		if err := checkReplicationPrerequisites(conn, fmt.Sprintf("subscription %s", subName), subscriptionPrerequisites(conn)); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf("CREATE SUBSCRIPTION %s CONNECTION %s PUBLICATION %s %s;",
 		pq.QuoteIdentifier(subName),
 		pq.QuoteLiteral(connInfo),
 		publications,
 		optionalParams,
 	)
-	if _, err := conn.Exec(sql); err != nil {
+	if _, err := conn.Exec(query); err != nil {
 		return fmt.Errorf("could not execute sql: %w", err)
 	}
 
 	d.SetId(generateSubscriptionID(d, databaseName))
 
+	if err := db.client.withTx(databaseName, func(txn *sql.Tx) error {
+		return setSubOwner(db, txn, d)
+	}); err != nil {
+		return err
+	}
+
+	return resourcePostgreSQLSubscriptionReadImpl(db, d)
+}
+
+// Only the owner can be updated, all the other attributes force a new subscription.
+func resourcePostgreSQLSubscriptionUpdate(db *DBConnection, d *schema.ResourceData) error {
+	databaseName := getDatabaseForSubscription(d, db.client.databaseName)
+
+	if err := db.client.withTx(databaseName, func(txn *sql.Tx) error {
+		return setSubOwner(db, txn, d)
+	}); err != nil {
+		return err
+	}
+
 	return resourcePostgreSQLSubscriptionReadImpl(db, d)
 }
 
+func setSubOwner(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange("owner") {
+		return nil
+	}
+
+	owner, err := resolveOwner(txn, d.Get("owner").(string))
+	if err != nil {
+		return err
+	}
+	if owner == "" {
+		return nil
+	}
+	subName := d.Get("name").(string)
+
+	// If the connected user is not a superuser, it needs to be a member of the new owner.
+	return withRolesGranted(db, txn, []string{owner}, func() error {
+		sql := fmt.Sprintf("ALTER SUBSCRIPTION %s OWNER TO %s", pq.QuoteIdentifier(subName), pq.QuoteIdentifier(owner))
+		if _, err := txn.Exec(sql); err != nil {
+			return fmt.Errorf("Error updating subscription owner: %w", err)
+		}
+		return nil
+	})
+}
+
 func resourcePostgreSQLSubscriptionRead(db *DBConnection, d *schema.ResourceData) error {
 	return resourcePostgreSQLSubscriptionReadImpl(db, d)
 }
@@ -130,6 +206,8 @@ func resourcePostgreSQLSubscriptionReadImpl(db *DBConnection, d *schema.Resource
 	var publications []string
 	var connInfo string
 	var slotName string
+	var owner string
+	var ownerOID uint32
 
 	var subExists bool
 	queryExists := "SELECT TRUE FROM pg_catalog.pg_stat_subscription WHERE subname = $1"
@@ -144,6 +222,14 @@ func resourcePostgreSQLSubscriptionReadImpl(db *DBConnection, d *schema.Resource
 		return nil
 	}
 
+	// pg_subscription is a shared catalog, so we filter on the current database.
+	// subowner is readable by everyone, unlike subconninfo.
+	queryOwner := "SELECT pg_catalog.pg_get_userbyid(subowner), subowner FROM pg_catalog.pg_subscription " +
+		"WHERE subname = $1 AND subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database())"
+	if err := txn.QueryRow(queryOwner, subName).Scan(&owner, &ownerOID); err != nil {
+		return fmt.Errorf("Failed to read subscription owner: %w", err)
+	}
+
 	// pg_subscription requires superuser permissions, it is okay to fail here
 	query := "SELECT subconninfo, subpublications, subslotname FROM pg_catalog.pg_subscription WHERE subname = $1"
 	err = txn.QueryRow(query, pqQuoteLiteral(subName)).Scan(&connInfo, pq.Array(&publications), &slotName)
@@ -168,6 +254,7 @@ func resourcePostgreSQLSubscriptionReadImpl(db *DBConnection, d *schema.Resource
 	}
 	d.Set("name", subName)
 	d.Set("database", databaseName)
+	d.Set("owner", ownerStateValue(d.Get("owner").(string), owner, ownerOID))
 	d.SetId(generateSubscriptionID(d, databaseName))
 
 	createSlot, okCreate := d.GetOkExists("create_slot") //nolint:staticcheck
@@ -193,7 +280,7 @@ func resourcePostgreSQLSubscriptionDelete(db *DBConnection, d *schema.ResourceDa
 	databaseName := getDatabaseForSubscription(d, db.client.databaseName)
 
 	// Dropping a subscription can not be done in a transaction
-	client := db.client.config.NewClient(databaseName)
+	client := db.client.config.NewClient(databaseName).withContext(db.client.ctx)
 	conn, err := client.Connect()
 	if err != nil {
 		return fmt.Errorf("could not establish database connection: %w", err)
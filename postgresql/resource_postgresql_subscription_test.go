@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -408,3 +409,84 @@ func TestAccPostgresqlSubscription_CopyDataDisabled(t *testing.T) {
 	)
 	coolDown()
 }
+
+func TestAccPostgresqlSubscription_UpdateOwner(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffixPub, teardownPub := setupTestDatabase(t, true, true)
+	dbSuffixSub, teardownSub := setupTestDatabase(t, true, true)
+
+	defer teardownPub()
+	defer teardownSub()
+	testTables := []string{"test_schema.test_table_1"}
+	createTestTables(t, dbSuffixPub, testTables, "")
+	createTestTables(t, dbSuffixSub, testTables, "")
+
+	dbNamePub, _ := getTestDBNames(dbSuffixPub)
+	dbNameSub, _ := getTestDBNames(dbSuffixSub)
+
+	conninfo := getConnInfo(t, dbNamePub)
+
+	// Before PostgreSQL 16, the owner of a subscription has to be a superuser.
+	configTemplate := `
+	resource "postgresql_role" "sub_owner" {
+		name      = "sub_owner"
+		superuser = true
+	}
+	resource "postgresql_publication" "test_pub" {
+		name     = "test_publication"
+		database = "%s"
+		tables   = ["test_schema.test_table_1"]
+	}
+	resource "postgresql_subscription" "test_sub" {
+		name         = "subscription"
+		database     = "%s"
+		conninfo     = "%s"
+		publications = [postgresql_publication.test_pub.name]
+		%s
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSubscriptionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(configTemplate, dbNamePub, dbNameSub, conninfo, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSubscriptionExists("postgresql_subscription.test_sub"),
+					resource.TestCheckResourceAttrSet("postgresql_subscription.test_sub", "owner"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(configTemplate, dbNamePub, dbNameSub, conninfo, "owner = postgresql_role.sub_owner.name"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSubscriptionExists("postgresql_subscription.test_sub"),
+					resource.TestCheckResourceAttr("postgresql_subscription.test_sub", "owner", "sub_owner"),
+				),
+			},
+		},
+	})
+	coolDown()
+}
+
+func TestSubscriptionPrerequisites(t *testing.T) {
+	names := func(prerequisites []replicationPrerequisite) []string {
+		var names []string
+		for _, prerequisite := range prerequisites {
+			names = append(names, prerequisite.name)
+		}
+		return names
+	}
+
+	if got := names(subscriptionPrerequisites(&DBConnection{version: semver.MustParse("17.0.0")})); len(got) != 2 || got[1] != "max_replication_slots" {
+		t.Errorf("max_replication_slots should be checked before PostgreSQL 18, got: %v", got)
+	}
+	if got := names(subscriptionPrerequisites(&DBConnection{version: semver.MustParse("18.0.0")})); len(got) != 1 || got[0] != "max_logical_replication_workers" {
+		t.Errorf("only max_logical_replication_workers should be checked since PostgreSQL 18, got: %v", got)
+	}
+}
@@ -0,0 +1,162 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	systemSettingNameAttr           = "name"
+	systemSettingValueAttr          = "value"
+	systemSettingReloadAttr         = "reload"
+	systemSettingContextAttr        = "context"
+	systemSettingRestartPendingAttr = "restart_pending"
+
+	// The pending value is the last one written by ALTER SYSTEM in postgresql.auto.conf.
+	getSystemSettingQuery = `
+SELECT f.setting, s.context, s.pending_restart
+FROM pg_catalog.pg_file_settings AS f
+JOIN pg_catalog.pg_settings AS s ON s.name = f.name
+WHERE f.name = $1 AND f.sourcefile LIKE '%postgresql.auto.conf'
+ORDER BY f.seqno DESC
+LIMIT 1
+`
+)
+
+func resourcePostgreSQLSystemSetting() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLSystemSettingCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSystemSettingRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLSystemSettingUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSystemSettingDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			systemSettingNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The name of the setting (e.g. `work_mem`)",
+			},
+			systemSettingValueAttr: {
+				Type:     schema.TypeString,
+				Required: true,
+				DiffSuppressFunc: func(_, old, new string, d *schema.ResourceData) bool {
+					return settingValuesEqual(d.Get(systemSettingNameAttr).(string), old, new)
+				},
+				Description: "The value of the setting, as it would be written in postgresql.conf (e.g. `64MB`). " +
+					"The elements of list settings (e.g. `shared_preload_libraries`) are separated by commas",
+			},
+			systemSettingReloadAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Reload the server configuration (pg_reload_conf()) after the setting is changed",
+			},
+			systemSettingContextAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "How the setting can be changed (pg_settings.context), e.g. `postmaster` settings require a restart",
+			},
+			systemSettingRestartPendingAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the server has to be restarted to apply the value",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLSystemSettingCreate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureAlterSystem) {
+		return db.unsupportedFeatureError(featureAlterSystem, "postgresql_system_setting")
+	}
+
+	name := d.Get(systemSettingNameAttr).(string)
+	if err := alterSystemSetting(db, d, alterSystemSetQuery(name, d.Get(systemSettingValueAttr).(string))); err != nil {
+		return err
+	}
+
+	d.SetId(name)
+
+	return resourcePostgreSQLSystemSettingReadImpl(db, d)
+}
+
+func resourcePostgreSQLSystemSettingRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureAlterSystem) {
+		return db.unsupportedFeatureError(featureAlterSystem, "postgresql_system_setting")
+	}
+
+	return resourcePostgreSQLSystemSettingReadImpl(db, d)
+}
+
+func resourcePostgreSQLSystemSettingReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	name := d.Id()
+
+	var value, context string
+	var restartPending bool
+	err := db.QueryRow(getSystemSettingQuery, name).Scan(&value, &context, &restartPending)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL system setting (%s) not found in postgresql.auto.conf", name)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not read system setting %s: %w", name, err)
+	}
+
+	d.Set(systemSettingNameAttr, name)
+	d.Set(systemSettingValueAttr, value)
+	d.Set(systemSettingContextAttr, context)
+	d.Set(systemSettingRestartPendingAttr, restartPending)
+
+	return nil
+}
+
+func resourcePostgreSQLSystemSettingUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if d.HasChange(systemSettingValueAttr) {
+		if err := alterSystemSetting(db, d, alterSystemSetQuery(d.Id(), d.Get(systemSettingValueAttr).(string))); err != nil {
+			return err
+		}
+	}
+
+	return resourcePostgreSQLSystemSettingReadImpl(db, d)
+}
+
+func resourcePostgreSQLSystemSettingDelete(db *DBConnection, d *schema.ResourceData) error {
+	if err := alterSystemSetting(db, d, fmt.Sprintf("ALTER SYSTEM RESET %s", quoteSettingName(d.Id()))); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// alterSystemSetting runs query, then reloads the configuration if asked.
+// ALTER SYSTEM cannot run in a transaction block.
+func alterSystemSetting(db *DBConnection, d *schema.ResourceData, query string) error {
+	name := d.Get(systemSettingNameAttr).(string)
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("could not alter system setting %s: %w", name, err)
+	}
+
+	if d.Get(systemSettingReloadAttr).(bool) {
+		if _, err := db.Exec("SELECT pg_catalog.pg_reload_conf()"); err != nil {
+			return fmt.Errorf("could not reload the configuration after altering system setting %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func alterSystemSetQuery(name, value string) string {
+	return fmt.Sprintf("ALTER SYSTEM SET %s = %s", quoteSettingName(name), settingValueSQL(name, value))
+}
@@ -0,0 +1,147 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAlterSystemSetQuery(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"work_mem", "64MB", `ALTER SYSTEM SET "work_mem" = '64MB'`},
+		{"log_line_prefix", "%m,%p ", `ALTER SYSTEM SET "log_line_prefix" = '%m,%p '`},
+		{"pg_stat_statements.max", "10000", `ALTER SYSTEM SET "pg_stat_statements"."max" = '10000'`},
+		{"shared_preload_libraries", "pg_stat_statements, auto_explain", `ALTER SYSTEM SET "shared_preload_libraries" = 'pg_stat_statements', 'auto_explain'`},
+		{"shared_preload_libraries", "", `ALTER SYSTEM SET "shared_preload_libraries" = ''`},
+	}
+
+	for _, c := range cases {
+		if got := alterSystemSetQuery(c.name, c.value); got != c.expected {
+			t.Errorf("alterSystemSetQuery(%q, %q) = %s, want %s", c.name, c.value, got, c.expected)
+		}
+	}
+}
+
+func TestAccPostgresqlSystemSetting_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	tfConfig := `
+resource "postgresql_system_setting" "test" {
+	name  = "log_min_duration_statement"
+	value = "%s"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureAlterSystem)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSystemSettingDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, "250ms"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_system_setting.test", "id", "log_min_duration_statement"),
+					resource.TestCheckResourceAttr("postgresql_system_setting.test", "value", "250ms"),
+					resource.TestCheckResourceAttr("postgresql_system_setting.test", "context", "superuser"),
+					resource.TestCheckResourceAttr("postgresql_system_setting.test", "restart_pending", "false"),
+					testAccCheckSystemSettingCurrentValue("log_min_duration_statement", "250"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(tfConfig, "1s"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_system_setting.test", "value", "1s"),
+					testAccCheckSystemSettingCurrentValue("log_min_duration_statement", "1000"),
+				),
+			},
+			{
+				ResourceName:            "postgresql_system_setting.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"reload"},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSystemSetting_List(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureAlterSystem)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSystemSettingDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_system_setting" "test" {
+	name  = "search_path"
+	value = "public,pg_catalog"
+}
+`,
+				// The spaces added by PostgreSQL between the elements don't produce a diff
+				Check: testAccCheckSystemSettingCurrentValue("search_path", "public, pg_catalog"),
+			},
+		},
+	})
+}
+
+func testAccCheckSystemSettingCurrentValue(name, expected string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var value string
+		if err := db.QueryRow("SELECT setting FROM pg_catalog.pg_settings WHERE name = $1", name).Scan(&value); err != nil {
+			return fmt.Errorf("could not read setting %s: %w", name, err)
+		}
+		if value != expected {
+			return fmt.Errorf("setting %s is %q, expected %q", name, value, expected)
+		}
+		return nil
+	}
+}
+
+func testAccCheckPostgresqlSystemSettingDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+	db, err := client.Connect()
+	if err != nil {
+		return err
+	}
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_system_setting" {
+			continue
+		}
+
+		var unused string
+		err := db.QueryRow(getSystemSettingQuery, rs.Primary.ID).Scan(&unused, &unused, new(bool))
+		switch {
+		case err == sql.ErrNoRows:
+			continue
+		case err != nil:
+			return err
+		}
+		return fmt.Errorf("system setting %s still exists after destroy", rs.Primary.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,674 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	tableNameAttr        = "name"
+	tableSchemaAttr      = "schema"
+	tableDatabaseAttr    = "database"
+	tableOwnerAttr       = "owner"
+	tableColumnAttr      = "column"
+	tablePrimaryKeyAttr  = "primary_key"
+	tableDropCascadeAttr = "drop_cascade"
+
+	tableColumnNameAttr     = "name"
+	tableColumnTypeAttr     = "type"
+	tableColumnNullableAttr = "nullable"
+	tableColumnDefaultAttr  = "default"
+)
+
+func resourcePostgreSQLTable() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLTableCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLTableRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLTableUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLTableDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			tableNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "The name of the table",
+			},
+			tableSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema of the table",
+			},
+			tableDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database of the table. Defaults to the provider database",
+			},
+			tableOwnerAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validateOwner,
+				Description:  "The ROLE name who owns the table, or its OID as `oid:NNN` to be unaffected by renames",
+			},
+			tableColumnAttr: {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "The columns of the table. New columns are added at the end of the table, whatever their position in the list",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						tableColumnNameAttr: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+							Description:  "The name of the column",
+						},
+						tableColumnTypeAttr: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+								return normalizeColumnType(old) == normalizeColumnType(new)
+							},
+							Description: "The data type of the column (e.g. `text` or `varchar(255)`). Changing it runs ALTER COLUMN TYPE",
+						},
+						tableColumnNullableAttr: {
+							Type:             schema.TypeBool,
+							Optional:         true,
+							Default:          true,
+							DiffSuppressFunc: suppressPrimaryKeyNullableDiff,
+							Description:      "Whether the column accepts NULL values. Columns of the primary key are always NOT NULL",
+						},
+						tableColumnDefaultAttr: {
+							Type:     schema.TypeString,
+							Optional: true,
+							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+								return columnDefaultsEqual(old, new)
+							},
+							Description: "The default value of the column, as an SQL expression (e.g. `'active'` or `now()`)",
+						},
+					},
+				},
+			},
+			tablePrimaryKeyAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The columns of the primary key of the table",
+			},
+			commentAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The comment of the table. It is set in the same transaction as the creation of the table. If not set, the comment is left as is, so a comment set outside of Terraform is kept",
+			},
+			tableDropCascadeAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When true, will also drop the objects that depend on the table (e.g. views or foreign keys)",
+			},
+		},
+	}
+}
+
+// tableColumn is a column of the table managed by the resource.
+type tableColumn struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+}
+
+func tableColumnsFromList(raw []interface{}) []tableColumn {
+	columns := make([]tableColumn, 0, len(raw))
+	for _, r := range raw {
+		c := r.(map[string]interface{})
+		columns = append(columns, tableColumn{
+			Name:     c[tableColumnNameAttr].(string),
+			Type:     c[tableColumnTypeAttr].(string),
+			Nullable: c[tableColumnNullableAttr].(bool),
+			Default:  c[tableColumnDefaultAttr].(string),
+		})
+	}
+	return columns
+}
+
+// definition returns the definition of the column in CREATE TABLE or ADD COLUMN.
+func (c tableColumn) definition() string {
+	def := pq.QuoteIdentifier(c.Name) + " " + c.Type
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	return def
+}
+
+func resourcePostgreSQLTableCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	qualifiedName := quoteQualifiedIdentifier(d.Get(tableSchemaAttr).(string), d.Get(tableNameAttr).(string))
+
+	definitions := []string{}
+	for _, column := range tableColumnsFromList(d.Get(tableColumnAttr).([]interface{})) {
+		definitions = append(definitions, column.definition())
+	}
+	if primaryKey := d.Get(tablePrimaryKeyAttr).([]interface{}); len(primaryKey) > 0 {
+		definitions = append(definitions, primaryKeyDefinition(primaryKey))
+	}
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	query := fmt.Sprintf("CREATE TABLE %s (%s)", qualifiedName, strings.Join(definitions, ", "))
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not create table %s: %w", qualifiedName, err)
+	}
+
+	if err := setTableOwner(db, txn, d, qualifiedName); err != nil {
+		return err
+	}
+
+	if comment := d.Get(commentAttr).(string); comment != "" {
+		if err := setObjectComment(txn, "TABLE", qualifiedName, comment); err != nil {
+			return err
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("Error committing table: %w", err)
+	}
+
+	d.Set(tableDatabaseAttr, database)
+	d.SetId(generateTableID(d, database))
+
+	return resourcePostgreSQLTableReadImpl(db, d)
+}
+
+func resourcePostgreSQLTableRead(db *DBConnection, d *schema.ResourceData) error {
+	return resourcePostgreSQLTableReadImpl(db, d)
+}
+
+func resourcePostgreSQLTableReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	database, schemaName, tableName, err := getDBTableName(d, db.client.databaseName)
+	if err != nil {
+		return err
+	}
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var tableOID, ownerOID uint32
+	var owner, comment string
+	err = txn.QueryRow(
+		"SELECT c.oid, pg_catalog.pg_get_userbyid(c.relowner), c.relowner, COALESCE(pg_catalog.obj_description(c.oid, 'pg_class'), '') "+
+			"FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace "+
+			"WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'p')",
+		schemaName, tableName,
+	).Scan(&tableOID, &owner, &ownerOID, &comment)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL table (%s.%s) not found in database %s", schemaName, tableName, database)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return fmt.Errorf("Error reading table: %w", err)
+	}
+
+	columns, err := readTableColumns(txn, tableOID, schemaName, tableName)
+	if err != nil {
+		return err
+	}
+
+	_, primaryKey, err := readTablePrimaryKey(txn, tableOID)
+	if err != nil {
+		return err
+	}
+
+	// Columns are kept in the order of the configuration (columns cannot be moved
+	// and are always added at the end), the unknown ones are appended.
+	columnsByName := map[string]map[string]interface{}{}
+	for _, c := range columns {
+		columnsByName[c[tableColumnNameAttr].(string)] = c
+	}
+	stateColumns := []interface{}{}
+	for _, column := range tableColumnsFromList(d.Get(tableColumnAttr).([]interface{})) {
+		if c, ok := columnsByName[column.Name]; ok {
+			stateColumns = append(stateColumns, c)
+			delete(columnsByName, column.Name)
+		}
+	}
+	for _, c := range columns {
+		if _, ok := columnsByName[c[tableColumnNameAttr].(string)]; ok {
+			stateColumns = append(stateColumns, c)
+		}
+	}
+
+	d.Set(tableNameAttr, tableName)
+	d.Set(tableSchemaAttr, schemaName)
+	d.Set(tableDatabaseAttr, database)
+	d.Set(tableOwnerAttr, ownerStateValue(d.Get(tableOwnerAttr).(string), owner, ownerOID))
+	d.Set(tableColumnAttr, stateColumns)
+	d.Set(tablePrimaryKeyAttr, primaryKey)
+	d.Set(commentAttr, comment)
+	d.SetId(generateTableID(d, database))
+
+	return nil
+}
+
+// readTableColumns returns the columns of the table in their order.
+func readTableColumns(txn *sql.Tx, tableOID uint32, schemaName, tableName string) ([]map[string]interface{}, error) {
+	rows, err := txn.Query(
+		`SELECT col.column_name, pg_catalog.format_type(a.atttypid, a.atttypmod), col.is_nullable = 'YES',
+	COALESCE(col.column_default, '')
+FROM information_schema.columns col
+JOIN pg_catalog.pg_attribute a ON a.attrelid = $1 AND a.attname = col.column_name
+WHERE col.table_schema = $2 AND col.table_name = $3
+ORDER BY col.ordinal_position`,
+		tableOID, schemaName, tableName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not read columns of table %s.%s: %w", schemaName, tableName, err)
+	}
+	defer rows.Close()
+
+	columns := []map[string]interface{}{}
+	for rows.Next() {
+		var name, dataType, columnDefault string
+		var nullable bool
+		if err := rows.Scan(&name, &dataType, &nullable, &columnDefault); err != nil {
+			return nil, fmt.Errorf("could not scan column of table %s.%s: %w", schemaName, tableName, err)
+		}
+		columns = append(columns, map[string]interface{}{
+			tableColumnNameAttr:     name,
+			tableColumnTypeAttr:     dataType,
+			tableColumnNullableAttr: nullable,
+			tableColumnDefaultAttr:  columnDefault,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// readTablePrimaryKey returns the name and the columns of the primary key of the table, if any.
+func readTablePrimaryKey(txn *sql.Tx, tableOID uint32) (string, []string, error) {
+	var name string
+	var columns []string
+	err := txn.QueryRow(
+		`SELECT con.conname, array_agg(a.attname ORDER BY k.ord)::text[]
+FROM pg_catalog.pg_constraint con
+CROSS JOIN LATERAL unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+WHERE con.conrelid = $1 AND con.contype = 'p'
+GROUP BY con.conname`,
+		tableOID,
+	).Scan(&name, pq.Array(&columns))
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil, nil
+	case err != nil:
+		return "", nil, fmt.Errorf("could not read primary key of table: %w", err)
+	}
+	return name, columns, nil
+}
+
+func resourcePostgreSQLTableUpdate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	if err := db.client.withTx(database, func(txn *sql.Tx) error {
+		if err := setTableSchemaAndName(txn, d); err != nil {
+			return err
+		}
+
+		qualifiedName := quoteQualifiedIdentifier(d.Get(tableSchemaAttr).(string), d.Get(tableNameAttr).(string))
+
+		// The primary key is dropped before updating the columns (e.g. to drop one of its columns)
+		// and created afterwards (e.g. on new columns).
+		if d.HasChange(tablePrimaryKeyAttr) {
+			if err := dropTablePrimaryKey(txn, qualifiedName); err != nil {
+				return err
+			}
+		}
+
+		if err := setTableColumns(txn, d, qualifiedName); err != nil {
+			return err
+		}
+
+		if primaryKey := d.Get(tablePrimaryKeyAttr).([]interface{}); d.HasChange(tablePrimaryKeyAttr) && len(primaryKey) > 0 {
+			query := fmt.Sprintf("ALTER TABLE %s ADD %s", qualifiedName, primaryKeyDefinition(primaryKey))
+			if _, err := txn.Exec(query); err != nil {
+				return fmt.Errorf("could not set primary key of table %s: %w", qualifiedName, err)
+			}
+		}
+
+		if d.HasChange(tableOwnerAttr) {
+			if err := setTableOwner(db, txn, d, qualifiedName); err != nil {
+				return err
+			}
+		}
+
+		if d.HasChange(commentAttr) {
+			if err := setObjectComment(txn, "TABLE", qualifiedName, d.Get(commentAttr).(string)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.SetId(generateTableID(d, database))
+
+	return resourcePostgreSQLTableReadImpl(db, d)
+}
+
+func setTableSchemaAndName(txn *sql.Tx, d *schema.ResourceData) error {
+	oldSchema, newSchema := d.GetChange(tableSchemaAttr)
+	oldName, newName := d.GetChange(tableNameAttr)
+
+	if d.HasChange(tableNameAttr) {
+		query := fmt.Sprintf(
+			"ALTER TABLE %s RENAME TO %s",
+			quoteQualifiedIdentifier(oldSchema.(string), oldName.(string)), pq.QuoteIdentifier(newName.(string)),
+		)
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("could not rename table %s: %w", oldName, err)
+		}
+	}
+
+	if d.HasChange(tableSchemaAttr) {
+		query := fmt.Sprintf(
+			"ALTER TABLE %s SET SCHEMA %s",
+			quoteQualifiedIdentifier(oldSchema.(string), newName.(string)), pq.QuoteIdentifier(newSchema.(string)),
+		)
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("could not move table %s to schema %s: %w", newName, newSchema, err)
+		}
+	}
+
+	return nil
+}
+
+func setTableOwner(db *DBConnection, txn *sql.Tx, d *schema.ResourceData, qualifiedName string) error {
+	owner, err := resolveOwner(txn, d.Get(tableOwnerAttr).(string))
+	if err != nil || owner == "" {
+		return err
+	}
+
+	return withRolesGranted(db, txn, []string{owner}, func() error {
+		query := fmt.Sprintf("ALTER TABLE %s OWNER TO %s", qualifiedName, pq.QuoteIdentifier(owner))
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("could not set owner of table %s: %w", qualifiedName, err)
+		}
+		return nil
+	})
+}
+
+func dropTablePrimaryKey(txn *sql.Tx, qualifiedName string) error {
+	var tableOID uint32
+	if err := txn.QueryRow("SELECT $1::regclass::oid", qualifiedName).Scan(&tableOID); err != nil {
+		return fmt.Errorf("could not find table %s: %w", qualifiedName, err)
+	}
+
+	constraintName, _, err := readTablePrimaryKey(txn, tableOID)
+	if err != nil || constraintName == "" {
+		return err
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", qualifiedName, pq.QuoteIdentifier(constraintName))
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not drop primary key of table %s: %w", qualifiedName, err)
+	}
+	return nil
+}
+
+// setTableColumns adds, drops and alters the columns of the table.
+func setTableColumns(txn *sql.Tx, d *schema.ResourceData, qualifiedName string) error {
+	if !d.HasChange(tableColumnAttr) {
+		return nil
+	}
+
+	oldRaw, newRaw := d.GetChange(tableColumnAttr)
+	oldColumns := map[string]tableColumn{}
+	for _, column := range tableColumnsFromList(oldRaw.([]interface{})) {
+		oldColumns[column.Name] = column
+	}
+	newColumns := tableColumnsFromList(newRaw.([]interface{}))
+
+	primaryKey := map[string]bool{}
+	for _, column := range d.Get(tablePrimaryKeyAttr).([]interface{}) {
+		primaryKey[column.(string)] = true
+	}
+
+	var alterations []string
+	newNames := map[string]bool{}
+	for _, column := range newColumns {
+		newNames[column.Name] = true
+	}
+	for name := range oldColumns {
+		if !newNames[name] {
+			alterations = append(alterations, "DROP COLUMN "+pq.QuoteIdentifier(name))
+		}
+	}
+
+	for _, column := range newColumns {
+		oldColumn, exists := oldColumns[column.Name]
+		if !exists {
+			alterations = append(alterations, "ADD COLUMN "+column.definition())
+			continue
+		}
+		alterations = append(alterations, tableColumnAlterations(oldColumn, column, primaryKey[column.Name])...)
+	}
+
+	if len(alterations) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s %s", qualifiedName, strings.Join(alterations, ", "))
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not update columns of table %s: %w", qualifiedName, err)
+	}
+	return nil
+}
+
+// tableColumnAlterations returns the ALTER TABLE actions to change a column from old to new.
+func tableColumnAlterations(old, new tableColumn, inPrimaryKey bool) []string {
+	column := "ALTER COLUMN " + pq.QuoteIdentifier(new.Name)
+
+	var alterations []string
+	if normalizeColumnType(old.Type) != normalizeColumnType(new.Type) {
+		alterations = append(alterations, column+" TYPE "+new.Type)
+	}
+	if !columnDefaultsEqual(old.Default, new.Default) {
+		if new.Default == "" {
+			alterations = append(alterations, column+" DROP DEFAULT")
+		} else {
+			alterations = append(alterations, column+" SET DEFAULT "+new.Default)
+		}
+	}
+	if old.Nullable != new.Nullable && !inPrimaryKey {
+		if new.Nullable {
+			alterations = append(alterations, column+" DROP NOT NULL")
+		} else {
+			alterations = append(alterations, column+" SET NOT NULL")
+		}
+	}
+	return alterations
+}
+
+func resourcePostgreSQLTableDelete(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	qualifiedName := quoteQualifiedIdentifier(d.Get(tableSchemaAttr).(string), d.Get(tableNameAttr).(string))
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	owner, err := resolveOwner(txn, d.Get(tableOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
+
+	if err := withRolesGranted(db, txn, []string{owner}, func() error {
+		dropMode := "RESTRICT"
+		if d.Get(tableDropCascadeAttr).(bool) {
+			dropMode = "CASCADE"
+		}
+
+		if _, err := txn.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s %s", qualifiedName, dropMode)); err != nil {
+			return fmt.Errorf("Error deleting table: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("Error committing table: %w", err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func primaryKeyDefinition(columns []interface{}) string {
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = pq.QuoteIdentifier(column.(string))
+	}
+	return fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(quotedColumns, ", "))
+}
+
+// suppressPrimaryKeyNullableDiff ignores the nullable diff of the primary key columns,
+// as PostgreSQL makes them NOT NULL.
+func suppressPrimaryKeyNullableDiff(k, old, new string, d *schema.ResourceData) bool {
+	// k is column.N.nullable
+	parts := strings.Split(k, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	if _, err := strconv.Atoi(parts[1]); err != nil {
+		return false
+	}
+	name := d.Get(fmt.Sprintf("%s.%s.%s", tableColumnAttr, parts[1], tableColumnNameAttr)).(string)
+	for _, column := range d.Get(tablePrimaryKeyAttr).([]interface{}) {
+		if column.(string) == name {
+			return true
+		}
+	}
+	return false
+}
+
+var columnTypeAliases = map[string]string{
+	"int":         "integer",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"int2":        "smallint",
+	"bool":        "boolean",
+	"float4":      "real",
+	"float8":      "double precision",
+	"float":       "double precision",
+	"decimal":     "numeric",
+	"varchar":     "character varying",
+	"char":        "character",
+	"varbit":      "bit varying",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+}
+
+var columnTypeRegexp = regexp.MustCompile(`^([a-z0-9_ ]+?)\s*(\([0-9, ]+\))?\s*((?:\[\])*)$`)
+
+// normalizeColumnType returns the type name as formatted by PostgreSQL
+// (e.g. `varchar(10)` is `character varying(10)`), to compare it with the type read from the database.
+func normalizeColumnType(columnType string) string {
+	columnType = strings.Join(strings.Fields(strings.ToLower(columnType)), " ")
+
+	m := columnTypeRegexp.FindStringSubmatch(columnType)
+	if m == nil {
+		return columnType
+	}
+	name, modifier, array := m[1], strings.ReplaceAll(m[2], " ", ""), m[3]
+	if alias, ok := columnTypeAliases[name]; ok {
+		name = alias
+	}
+	if name == "character" && modifier == "" {
+		modifier = "(1)"
+	}
+
+	// The modifier of time types is written before "with(out) time zone".
+	for _, suffix := range []string{" without time zone", " with time zone"} {
+		if strings.HasSuffix(name, suffix) && modifier != "" {
+			return strings.TrimSuffix(name, suffix) + modifier + suffix + array
+		}
+	}
+	return name + modifier + array
+}
+
+var columnDefaultCastRegexp = regexp.MustCompile(`::[a-z ]+(\([0-9, ]+\))?(\[\])*$`)
+
+// columnDefaultsEqual compares default expressions, ignoring the cast which PostgreSQL
+// adds to constants (e.g. `'active'` is read as `'active'::text`).
+func columnDefaultsEqual(a, b string) bool {
+	return columnDefaultCastRegexp.ReplaceAllString(strings.TrimSpace(a), "") ==
+		columnDefaultCastRegexp.ReplaceAllString(strings.TrimSpace(b), "")
+}
+
+// generateTableID returns the ID database.schema.table of the table, the names are double quoted
+// if they contain dots so getDBTableName can split the ID back.
+func generateTableID(d *schema.ResourceData, databaseName string) string {
+	return strings.Join([]string{
+		quoteIdentifierIfNeeded(getDatabase(d, databaseName)),
+		quoteIdentifierIfNeeded(d.Get(tableSchemaAttr).(string)),
+		quoteIdentifierIfNeeded(d.Get(tableNameAttr).(string)),
+	}, ".")
+}
+
+func getDBTableName(d *schema.ResourceData, databaseName string) (string, string, string, error) {
+	database := getDatabase(d, databaseName)
+	schemaName := d.Get(tableSchemaAttr).(string)
+	tableName := d.Get(tableNameAttr).(string)
+
+	// When importing, we have to parse the ID to find database, schema and table names.
+	if tableName == "" {
+		parsed, err := splitQualifiedIdentifier(d.Id())
+		if err != nil || len(parsed) != 3 {
+			return "", "", "", fmt.Errorf("table ID %s has not the expected format 'database.schema.table' (double quote the names containing dots)", d.Id())
+		}
+		database = parsed[0]
+		schemaName = parsed[1]
+		tableName = parsed[2]
+	}
+	return database, schemaName, tableName, nil
+}
@@ -0,0 +1,196 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeColumnType(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{"text", "text"},
+		{"INT", "integer"},
+		{"int8", "bigint"},
+		{"varchar(255)", "character varying(255)"},
+		{"VARCHAR (255)", "character varying(255)"},
+		{"character varying(255)", "character varying(255)"},
+		{"char", "character(1)"},
+		{"numeric(10, 2)", "numeric(10,2)"},
+		{"decimal(10,2)", "numeric(10,2)"},
+		{"timestamptz", "timestamp with time zone"},
+		{"timestamptz(3)", "timestamp(3) with time zone"},
+		{"timestamp", "timestamp without time zone"},
+		{"text[]", "text[]"},
+		{"int4[]", "integer[]"},
+		{"public.my_type", "public.my_type"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, normalizeColumnType(test.input), test.input)
+	}
+}
+
+func TestColumnDefaultsEqual(t *testing.T) {
+	assert.True(t, columnDefaultsEqual("'active'::text", "'active'"))
+	assert.True(t, columnDefaultsEqual("'active'::character varying", "'active'"))
+	assert.True(t, columnDefaultsEqual("now()", "now()"))
+	assert.True(t, columnDefaultsEqual("", ""))
+	assert.False(t, columnDefaultsEqual("'active'::text", "'inactive'"))
+	assert.False(t, columnDefaultsEqual("0", ""))
+}
+
+func TestTableColumnAlterations(t *testing.T) {
+	old := tableColumn{Name: "status", Type: "varchar(10)", Nullable: true, Default: "'active'::character varying"}
+
+	assert.Empty(t, tableColumnAlterations(old, tableColumn{Name: "status", Type: "character varying(10)", Nullable: true, Default: "'active'"}, false))
+
+	assert.Equal(t,
+		[]string{
+			`ALTER COLUMN "status" TYPE text`,
+			`ALTER COLUMN "status" DROP DEFAULT`,
+			`ALTER COLUMN "status" SET NOT NULL`,
+		},
+		tableColumnAlterations(old, tableColumn{Name: "status", Type: "text", Nullable: false}, false),
+	)
+
+	assert.Equal(t,
+		[]string{`ALTER COLUMN "status" SET DEFAULT 'inactive'`},
+		tableColumnAlterations(old, tableColumn{Name: "status", Type: "varchar(10)", Nullable: false, Default: "'inactive'"}, true),
+	)
+}
+
+// Test that the ID of a table whose names contain dots can be split back when importing it.
+func TestTableIDRoundTrip(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLTable().Schema, map[string]interface{}{
+		tableDatabaseAttr: "my.db",
+		tableSchemaAttr:   "my.schema",
+		tableNameAttr:     "my_table",
+	})
+	id := generateTableID(d, "postgres")
+	assert.Equal(t, `"my.db"."my.schema".my_table`, id)
+
+	imported := resourcePostgreSQLTable().Data(nil)
+	imported.SetId(id)
+	database, schemaName, tableName, err := getDBTableName(imported, "postgres")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"my.db", "my.schema", "my_table"}, []string{database, schemaName, tableName})
+
+	imported.SetId("my.db.my.schema.my_table")
+	_, _, _, err = getDBTableName(imported, "postgres")
+	assert.ErrorContains(t, err, "has not the expected format 'database.schema.table'")
+}
+
+func TestAccPostgresqlTable_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := `
+resource "postgresql_table" "test" {
+	database    = "%s"
+	schema      = "test_schema"
+	name        = "test_table"
+	owner       = "%s"
+	primary_key = ["id"]
+
+	column {
+		name = "id"
+		type = "bigint"
+	}
+	column {
+		name     = "status"
+		type     = "%s"
+		nullable = false
+		default  = "'active'"
+	}
+	%s
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlTableDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, dbName, roleName, "varchar(10)", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_table.test", "id", fmt.Sprintf("%s.test_schema.test_table", dbName)),
+					resource.TestCheckResourceAttr("postgresql_table.test", "owner", roleName),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.0.nullable", "false"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.1.type", "character varying(10)"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "primary_key.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "primary_key.0", "id"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, dbName, roleName, "text", `
+	column {
+		name    = "created_at"
+		type    = "timestamptz"
+		default = "now()"
+	}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.#", "3"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.1.type", "text"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.2.name", "created_at"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.2.type", "timestamp with time zone"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.2.default", "now()"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_table.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"drop_cascade",
+				},
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlTableDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_table" {
+			continue
+		}
+
+		txn, err := startTransaction(client, rs.Primary.Attributes[tableDatabaseAttr])
+		if err != nil {
+			return err
+		}
+		defer deferredRollback(txn)
+
+		var exists bool
+		err = txn.QueryRow(
+			"SELECT to_regclass($1) IS NOT NULL",
+			quoteQualifiedIdentifier(rs.Primary.Attributes[tableSchemaAttr], rs.Primary.Attributes[tableNameAttr]),
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("Error checking table %s", err)
+		}
+
+		if exists {
+			return fmt.Errorf("Table still exists after destroy")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package postgresql
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	tablespaceNameAttr     = "name"
+	tablespaceOwnerAttr    = "owner"
+	tablespaceLocationAttr = "location"
+	tablespaceOptionsAttr  = "options"
+)
+
+// tablespaceOptions are the options which can be set on a tablespace (ALTER TABLESPACE ... SET).
+var tablespaceOptions = []string{"seq_page_cost", "random_page_cost", "effective_io_concurrency", "maintenance_io_concurrency"}
+
+func resourcePostgreSQLTablespace() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLTablespaceCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLTablespaceRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLTablespaceUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLTablespaceDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			tablespaceNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the tablespace",
+			},
+			tablespaceOwnerAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The ROLE which owns the tablespace, or its OID as `oid:NNN` to be unaffected by renames",
+				ValidateFunc: validateOwner,
+			},
+			tablespaceLocationAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The directory of the tablespace on the server. It must exist, be empty and be owned by the PostgreSQL system user",
+			},
+			tablespaceOptionsAttr: {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: validateTablespaceOptions,
+				Description: "The options of the tablespace (" + strings.Join(tablespaceOptions, ", ") + "), e.g. `{ random_page_cost = \"1.1\" }`. " +
+					"Only the options which change are set or reset",
+			},
+			commentAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The comment of the tablespace. It is set right after the creation of the tablespace (CREATE TABLESPACE cannot run in a transaction). If not set, the comment is left as is, so a comment set outside of Terraform is kept",
+			},
+		},
+	}
+}
+
+func validateTablespaceOptions(v interface{}, key string) (warnings []string, errors []error) {
+	for option := range v.(map[string]interface{}) {
+		if !sliceContainsStr(tablespaceOptions, option) {
+			errors = append(errors, fmt.Errorf("%s: unknown tablespace option %q, expected one of %s", key, option, strings.Join(tablespaceOptions, ", ")))
+		}
+	}
+	return
+}
+
+func resourcePostgreSQLTablespaceCreate(db *DBConnection, d *schema.ResourceData) error {
+	name := d.Get(tablespaceNameAttr).(string)
+
+	owner, err := resolveOwner(db, d.Get(tablespaceOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
+
+	b := bytes.NewBufferString("CREATE TABLESPACE ")
+	fmt.Fprint(b, pq.QuoteIdentifier(name))
+	if owner != "" {
+		fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(owner))
+	}
+	fmt.Fprint(b, " LOCATION ", pq.QuoteLiteral(d.Get(tablespaceLocationAttr).(string)))
+	if options := tablespaceOptionsList(d.Get(tablespaceOptionsAttr).(map[string]interface{})); len(options) > 0 {
+		fmt.Fprintf(b, " WITH (%s)", strings.Join(options, ", "))
+	}
+
+	// CREATE TABLESPACE cannot run in a transaction block.
+	if _, err := db.Exec(b.String()); err != nil {
+		return fmt.Errorf("could not create tablespace %s: %w", name, err)
+	}
+
+	d.SetId(name)
+
+	if comment := d.Get(commentAttr).(string); comment != "" {
+		if err := setObjectComment(db, "TABLESPACE", pq.QuoteIdentifier(name), comment); err != nil {
+			return err
+		}
+	}
+
+	return resourcePostgreSQLTablespaceReadImpl(db, d)
+}
+
+func resourcePostgreSQLTablespaceRead(db *DBConnection, d *schema.ResourceData) error {
+	return resourcePostgreSQLTablespaceReadImpl(db, d)
+}
+
+func resourcePostgreSQLTablespaceReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	name := d.Id()
+
+	var owner, location, comment string
+	var ownerOID uint32
+	var options []string
+	err := db.QueryRow(
+		"SELECT pg_catalog.pg_get_userbyid(spcowner), spcowner, pg_catalog.pg_tablespace_location(oid), "+
+			"COALESCE(spcoptions, '{}'), COALESCE(pg_catalog.shobj_description(oid, 'pg_tablespace'), '') "+
+			"FROM pg_catalog.pg_tablespace WHERE spcname = $1",
+		name,
+	).Scan(&owner, &ownerOID, &location, pq.Array(&options), &comment)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL tablespace (%s) not found", name)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not read tablespace %s: %w", name, err)
+	}
+
+	d.Set(tablespaceNameAttr, name)
+	d.Set(tablespaceOwnerAttr, ownerStateValue(d.Get(tablespaceOwnerAttr).(string), owner, ownerOID))
+	d.Set(tablespaceLocationAttr, location)
+	d.Set(tablespaceOptionsAttr, parseTablespaceOptions(options))
+	d.Set(commentAttr, comment)
+
+	return nil
+}
+
+func resourcePostgreSQLTablespaceUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if err := db.client.withTx("", func(txn *sql.Tx) error {
+		if d.HasChange(tablespaceNameAttr) {
+			oldName, newName := d.GetChange(tablespaceNameAttr)
+			query := fmt.Sprintf("ALTER TABLESPACE %s RENAME TO %s", pq.QuoteIdentifier(oldName.(string)), pq.QuoteIdentifier(newName.(string)))
+			if _, err := txn.Exec(query); err != nil {
+				return fmt.Errorf("could not rename tablespace %s: %w", oldName, err)
+			}
+		}
+
+		name := d.Get(tablespaceNameAttr).(string)
+		if d.HasChange(tablespaceOwnerAttr) {
+			owner, err := resolveOwner(txn, d.Get(tablespaceOwnerAttr).(string))
+			if err != nil {
+				return err
+			}
+			if owner != "" {
+				query := fmt.Sprintf("ALTER TABLESPACE %s OWNER TO %s", pq.QuoteIdentifier(name), pq.QuoteIdentifier(owner))
+				if _, err := txn.Exec(query); err != nil {
+					return fmt.Errorf("could not change owner of tablespace %s: %w", name, err)
+				}
+			}
+		}
+
+		if d.HasChange(tablespaceOptionsAttr) {
+			oldOptions, newOptions := d.GetChange(tablespaceOptionsAttr)
+			for _, query := range tablespaceOptionsQueries(name, oldOptions.(map[string]interface{}), newOptions.(map[string]interface{})) {
+				if _, err := txn.Exec(query); err != nil {
+					return fmt.Errorf("could not alter options of tablespace %s: %w", name, err)
+				}
+			}
+		}
+
+		if d.HasChange(commentAttr) {
+			return setObjectComment(txn, "TABLESPACE", pq.QuoteIdentifier(name), d.Get(commentAttr).(string))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.SetId(d.Get(tablespaceNameAttr).(string))
+
+	return resourcePostgreSQLTablespaceReadImpl(db, d)
+}
+
+func resourcePostgreSQLTablespaceDelete(db *DBConnection, d *schema.ResourceData) error {
+	name := d.Get(tablespaceNameAttr).(string)
+
+	// DROP TABLESPACE cannot run in a transaction block.
+	if _, err := db.Exec(fmt.Sprintf("DROP TABLESPACE %s", pq.QuoteIdentifier(name))); err != nil {
+		return fmt.Errorf("could not drop tablespace %s: %w", name, err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// tablespaceOptionsList returns the sorted `option = value` list of options.
+func tablespaceOptionsList(options map[string]interface{}) []string {
+	list := make([]string, 0, len(options))
+	for option, value := range options {
+		list = append(list, fmt.Sprintf("%s = %s", option, pq.QuoteLiteral(value.(string))))
+	}
+	sort.Strings(list)
+	return list
+}
+
+// tablespaceOptionsQueries returns the statements changing the options of the tablespace
+// from oldOptions to newOptions: the options which are not changed are left untouched.
+func tablespaceOptionsQueries(name string, oldOptions, newOptions map[string]interface{}) []string {
+	toSet := map[string]interface{}{}
+	for option, value := range newOptions {
+		if oldValue, ok := oldOptions[option]; !ok || oldValue != value {
+			toSet[option] = value
+		}
+	}
+	var toReset []string
+	for option := range oldOptions {
+		if _, ok := newOptions[option]; !ok {
+			toReset = append(toReset, option)
+		}
+	}
+	sort.Strings(toReset)
+
+	var queries []string
+	if len(toSet) > 0 {
+		queries = append(queries, fmt.Sprintf("ALTER TABLESPACE %s SET (%s)", pq.QuoteIdentifier(name), strings.Join(tablespaceOptionsList(toSet), ", ")))
+	}
+	if len(toReset) > 0 {
+		queries = append(queries, fmt.Sprintf("ALTER TABLESPACE %s RESET (%s)", pq.QuoteIdentifier(name), strings.Join(toReset, ", ")))
+	}
+	return queries
+}
+
+// parseTablespaceOptions parses pg_tablespace.spcoptions (option=value).
+func parseTablespaceOptions(options []string) map[string]interface{} {
+	parsed := make(map[string]interface{}, len(options))
+	for _, option := range options {
+		if name, value, ok := strings.Cut(option, "="); ok {
+			parsed[name] = value
+		}
+	}
+	return parsed
+}
@@ -0,0 +1,132 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTablespaceOptionsQueries(t *testing.T) {
+	oldOptions := map[string]interface{}{"seq_page_cost": "1", "random_page_cost": "4", "effective_io_concurrency": "1"}
+	newOptions := map[string]interface{}{"seq_page_cost": "1", "random_page_cost": "1.1", "maintenance_io_concurrency": "10"}
+
+	assert.Equal(t, []string{
+		`ALTER TABLESPACE "fast" SET (maintenance_io_concurrency = '10', random_page_cost = '1.1')`,
+		`ALTER TABLESPACE "fast" RESET (effective_io_concurrency)`,
+	}, tablespaceOptionsQueries("fast", oldOptions, newOptions))
+	assert.Empty(t, tablespaceOptionsQueries("fast", oldOptions, oldOptions))
+}
+
+func TestParseTablespaceOptions(t *testing.T) {
+	assert.Equal(t,
+		map[string]interface{}{"random_page_cost": "1.1", "effective_io_concurrency": "200"},
+		parseTablespaceOptions([]string{"random_page_cost=1.1", "effective_io_concurrency=200"}),
+	)
+}
+
+// testTablespaceLocation returns the directory of the server in which the test tablespace is created.
+// It has to be created beforehand, the test is skipped if TF_TEST_TABLESPACE_LOCATION is not set.
+func testTablespaceLocation(t *testing.T) string {
+	location := os.Getenv("TF_TEST_TABLESPACE_LOCATION")
+	if location == "" {
+		t.Skip("Skip test: TF_TEST_TABLESPACE_LOCATION is not set")
+	}
+	return location
+}
+
+func TestAccPostgresqlTablespace_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+	location := testTablespaceLocation(t)
+
+	config := `
+resource "postgresql_tablespace" "test" {
+	name     = "%s"
+	location = "%s"
+	options  = %s
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlTablespaceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "tf_test_tablespace", location, `{ random_page_cost = "1.1", effective_io_concurrency = "200" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_tablespace.test", "location", location),
+					resource.TestCheckResourceAttr("postgresql_tablespace.test", "options.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_tablespace.test", "options.random_page_cost", "1.1"),
+					testAccCheckTablespaceOptions("tf_test_tablespace", "{effective_io_concurrency=200,random_page_cost=1.1}"),
+				),
+			},
+			// Only the changed option is set, the removed one is reset
+			{
+				Config: fmt.Sprintf(config, "tf_test_tablespace", location, `{ random_page_cost = "1.5", seq_page_cost = "0.5" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_tablespace.test", "options.%", "2"),
+					testAccCheckTablespaceOptions("tf_test_tablespace", "{random_page_cost=1.5,seq_page_cost=0.5}"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, "tf_test_tablespace_renamed", location, `{}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_tablespace.test", "name", "tf_test_tablespace_renamed"),
+					resource.TestCheckResourceAttr("postgresql_tablespace.test", "options.%", "0"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_tablespace.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckTablespaceOptions(name, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var options sql.NullString
+		if err := db.QueryRow("SELECT spcoptions::text FROM pg_catalog.pg_tablespace WHERE spcname = $1", name).Scan(&options); err != nil {
+			return fmt.Errorf("could not read options of tablespace %s: %w", name, err)
+		}
+		if options.String != expected {
+			return fmt.Errorf("expected options of tablespace %s to be %s, got: %s", name, expected, options.String)
+		}
+		return nil
+	}
+}
+
+func testAccCheckPostgresqlTablespaceDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+	db, err := client.Connect()
+	if err != nil {
+		return err
+	}
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_tablespace" {
+			continue
+		}
+
+		if err := checkTablespaceExistence(db, rs.Primary.ID); err == nil {
+			return fmt.Errorf("tablespace %s still exists after destroy", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
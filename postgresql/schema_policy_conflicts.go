@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// schemaPrivilegesClaims records, for the lifetime of the provider, which resources manage the privileges
+// of a role on a schema: a policy of postgresql_schema and a postgresql_grant on the same schema and role
+// revert each other's changes on every apply.
+var schemaPrivilegesClaims = &schemaPrivilegesClaimer{
+	claims: map[schemaPrivilegesKey]*schemaPrivilegesClaim{},
+}
+
+type schemaPrivilegesClaimer struct {
+	mu     sync.Mutex
+	claims map[schemaPrivilegesKey]*schemaPrivilegesClaim
+}
+
+type schemaPrivilegesKey struct {
+	connStr string
+	schema  string
+	role    string
+}
+
+type schemaPrivilegesClaim struct {
+	policy bool
+	grant  bool
+	// failOnConflict is the fail_on_grant_conflict setting of the postgresql_schema.
+	failOnConflict bool
+}
+
+// claimPolicy records that a policy of a postgresql_schema manages the privileges of role on schemaName.
+func (c *schemaPrivilegesClaimer) claimPolicy(connStr, schemaName, role string, failOnConflict bool) error {
+	return c.claim(connStr, schemaName, role, func(claim *schemaPrivilegesClaim) {
+		claim.policy = true
+		claim.failOnConflict = failOnConflict
+	})
+}
+
+// claimGrant records that a postgresql_grant manages the privileges of role on schemaName.
+func (c *schemaPrivilegesClaimer) claimGrant(connStr, schemaName, role string) error {
+	return c.claim(connStr, schemaName, role, func(claim *schemaPrivilegesClaim) {
+		claim.grant = true
+	})
+}
+
+// claim updates the claim of role on schemaName and returns an error if both a policy and a grant manage
+// its privileges and the schema fails on conflicts, a warning is logged otherwise.
+func (c *schemaPrivilegesClaimer) claim(connStr, schemaName, role string, update func(*schemaPrivilegesClaim)) error {
+	// The PUBLIC role of a policy is an empty role. The other role names are case-sensitive, as they are quoted.
+	if role == "" || strings.ToLower(role) == publicRole {
+		role = publicRole
+	}
+	key := schemaPrivilegesKey{connStr: connStr, schema: schemaName, role: role}
+
+	c.mu.Lock()
+	claim, ok := c.claims[key]
+	if !ok {
+		claim = &schemaPrivilegesClaim{}
+		c.claims[key] = claim
+	}
+	update(claim)
+	conflict, failOnConflict := claim.policy && claim.grant, claim.failOnConflict
+	c.mu.Unlock()
+
+	if !conflict {
+		return nil
+	}
+
+	msg := fmt.Sprintf(
+		"the privileges of role %s on schema %s are managed by both a policy of postgresql_schema and a postgresql_grant, "+
+			"which revert each other's changes: move the policy to the postgresql_grant resource",
+		role, schemaName,
+	)
+	if failOnConflict {
+		return errors.New(msg)
+	}
+	log.Printf("[WARN] %s", msg)
+	return nil
+}
@@ -0,0 +1,29 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaPrivilegesClaims(t *testing.T) {
+	claims := &schemaPrivilegesClaimer{claims: map[schemaPrivilegesKey]*schemaPrivilegesClaim{}}
+
+	// Different roles or schemas don't conflict
+	assert.NoError(t, claims.claimPolicy("db", "app", "reader", true))
+	assert.NoError(t, claims.claimGrant("db", "app", "writer"))
+	assert.NoError(t, claims.claimGrant("db", "other", "reader"))
+	assert.NoError(t, claims.claimGrant("other_db", "app", "reader"))
+
+	// The role names are case-sensitive
+	assert.NoError(t, claims.claimGrant("db", "app", "Reader"))
+
+	// The same role is a conflict, whatever the order of the claims
+	assert.ErrorContains(t, claims.claimGrant("db", "app", "reader"), "role reader on schema app are managed by both")
+	assert.ErrorContains(t, claims.claimPolicy("db", "app", "writer", true), "role writer on schema app are managed by both")
+
+	// The PUBLIC role of a policy is empty, the conflict is only a warning without fail_on_grant_conflict
+	assert.NoError(t, claims.claimPolicy("db", "app", "", false))
+	assert.NoError(t, claims.claimGrant("db", "app", "PUBLIC"))
+	assert.True(t, claims.claims[schemaPrivilegesKey{connStr: "db", schema: "app", role: publicRole}].grant)
+}
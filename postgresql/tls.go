@@ -0,0 +1,252 @@
+package postgresql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// sslRequestCode is the code of the SSLRequest message, which asks the server to negotiate TLS.
+const sslRequestCode = 80877103
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// usesCustomTLS returns whether tls_min_version or tls_cipher_suites are set, which lib/pq doesn't support:
+// the provider then negotiates TLS itself (see tlsDialer).
+func (c *Config) usesCustomTLS() bool {
+	return c.TLSMinVersion != "" || len(c.TLSCipherSuites) > 0
+}
+
+// checkTLSSettings returns an error if tls_min_version or tls_cipher_suites are invalid or cannot be used.
+func (c *Config) checkTLSSettings() error {
+	if !c.usesCustomTLS() {
+		return nil
+	}
+	if c.Scheme != "postgres" {
+		return fmt.Errorf("postgresql: tls_min_version and tls_cipher_suites are only supported with the postgres scheme")
+	}
+	if c.SSLMode != "require" && !sslModeVerifiesCA(c.SSLMode) {
+		return fmt.Errorf("postgresql: tls_min_version and tls_cipher_suites require sslmode require, verify-ca or verify-full, got %q", c.SSLMode)
+	}
+	if _, err := parseTLSVersion(c.TLSMinVersion); err != nil {
+		return err
+	}
+	_, err := parseTLSCipherSuites(c.TLSCipherSuites)
+	return err
+}
+
+// parseTLSVersion returns the TLS version of tls_min_version, TLS 1.2 if it is empty.
+func parseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		return tls.VersionTLS12, nil
+	}
+	v, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("postgresql: unsupported tls_min_version %q, expected 1.2 or 1.3", version)
+	}
+	return v, nil
+}
+
+// parseTLSCipherSuites returns the IDs of the cipher suites named as in the Go crypto/tls package
+// (e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256). The insecure cipher suites are rejected.
+func parseTLSCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	suites := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			known := make([]string, 0, len(suites))
+			for suite := range suites {
+				known = append(known, suite)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("postgresql: unknown or insecure TLS cipher suite %q in tls_cipher_suites, expected one of: %s", name, strings.Join(known, ", "))
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// tlsConfig returns the TLS configuration of the connections to the server named serverName,
+// verified as lib/pq does for the sslmode (a root certificate makes require verify the CA).
+func (c *Config) tlsConfig(serverName string) (*tls.Config, error) {
+	minVersion, err := parseTLSVersion(c.TLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := parseTLSCipherSuites(c.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		ServerName:   serverName,
+		// The certificate is verified by VerifyConnection, which doesn't check the name of the server for verify-ca.
+		InsecureSkipVerify: true,
+	}
+
+	if c.SSLClientCert != nil {
+		var cert tls.Certificate
+		if c.SSLClientCert.SSLInline {
+			cert, err = tls.X509KeyPair([]byte(c.SSLClientCert.CertificatePath), []byte(c.SSLClientCert.KeyPath))
+		} else {
+			cert, err = tls.LoadX509KeyPair(c.SSLClientCert.CertificatePath, c.SSLClientCert.KeyPath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("postgresql: could not load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.SSLMode == "require" && c.SSLRootCertPath == "" {
+		return config, nil
+	}
+
+	var roots *x509.CertPool
+	if c.SSLRootCertPath != "" {
+		pem, err := os.ReadFile(c.SSLRootCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("postgresql: could not read sslrootcert: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("postgresql: could not parse the certificates of sslrootcert %s", c.SSLRootCertPath)
+		}
+	}
+
+	verifyName := c.SSLMode == "verify-full"
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("postgresql: the server did not send a certificate")
+		}
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+		if verifyName {
+			opts.DNSName = serverName
+		}
+		for _, cert := range state.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := state.PeerCertificates[0].Verify(opts)
+		return err
+	}
+	return config, nil
+}
+
+// tlsDialer negotiates TLS with the configuration of the provider on the connections it dials,
+// lib/pq then speaks the PostgreSQL protocol over them without SSL.
+type tlsDialer struct {
+	pq.Dialer
+	config *tls.Config
+}
+
+func (d tlsDialer) Dial(network, address string) (net.Conn, error) {
+	conn, err := d.Dialer.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return negotiateTLS(conn, d.config, time.Time{})
+}
+
+func (d tlsDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	conn, err := d.Dialer.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return negotiateTLS(conn, d.config, deadline)
+}
+
+// negotiateTLS sends an SSLRequest on conn and returns the TLS connection established over it,
+// the TLS handshake must be done before deadline (if not zero).
+func negotiateTLS(conn net.Conn, config *tls.Config, deadline time.Time) (net.Conn, error) {
+	tlsConn, err := func() (net.Conn, error) {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+
+		request := make([]byte, 8)
+		binary.BigEndian.PutUint32(request[0:4], 8)
+		binary.BigEndian.PutUint32(request[4:8], sslRequestCode)
+		if _, err := conn.Write(request); err != nil {
+			return nil, err
+		}
+
+		response := make([]byte, 1)
+		if _, err := conn.Read(response); err != nil {
+			return nil, err
+		}
+		if response[0] != 'S' {
+			return nil, errors.New("postgresql: SSL is not enabled on the server")
+		}
+
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			return nil, err
+		}
+		if err := conn.SetDeadline(time.Time{}); err != nil {
+			return nil, err
+		}
+		return tlsConn, nil
+	}()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// openWithTLSConfig opens a connection pool to host whose TLS connections are negotiated by the provider
+// with tls_min_version and tls_cipher_suites (see tlsDialer).
+func openWithTLSConfig(config Config, host, database string) (*sql.DB, error) {
+	serverName := host
+	var dialer pq.Dialer = proxyDriver{}
+	if config.SSLHostOverride != "" {
+		serverName = config.SSLHostOverride
+		dialer = sslHostOverrideDialer{address: net.JoinHostPort(host, strconv.Itoa(config.Port))}
+	}
+
+	tlsConfig, err := config.tlsConfig(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	// lib/pq must not negotiate SSL again over the TLS connection.
+	plainConfig := config
+	plainConfig.SSLMode = "disable"
+	plainConfig.SSLClientCert = nil
+	plainConfig.SSLRootCertPath = ""
+
+	connector, err := pq.NewConnector(plainConfig.connStrForHost(host, database))
+	if err != nil {
+		return nil, err
+	}
+	connector.Dialer(tlsDialer{Dialer: dialer, config: tlsConfig})
+	return sql.OpenDB(connector), nil
+}
@@ -0,0 +1,185 @@
+package postgresql
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTLSVersion(t *testing.T) {
+	version, err := parseTLSVersion("")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), version)
+
+	version, err = parseTLSVersion("1.3")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, err = parseTLSVersion("1.1")
+	assert.EqualError(t, err, `postgresql: unsupported tls_min_version "1.1", expected 1.2 or 1.3`)
+}
+
+func TestParseTLSCipherSuites(t *testing.T) {
+	suites, err := parseTLSCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, suites)
+
+	_, err = parseTLSCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.ErrorContains(t, err, `unknown or insecure TLS cipher suite "TLS_RSA_WITH_RC4_128_SHA"`)
+
+	_, err = parseTLSCipherSuites([]string{"AES256-SHA"})
+	assert.ErrorContains(t, err, `unknown or insecure TLS cipher suite "AES256-SHA"`)
+}
+
+func TestConfigCheckTLSSettings(t *testing.T) {
+	for _, test := range []struct {
+		config Config
+		err    string
+	}{
+		{config: Config{Scheme: "postgres", SSLMode: "disable"}},
+		{config: Config{Scheme: "postgres", SSLMode: "verify-full", TLSMinVersion: "1.3"}},
+		{config: Config{Scheme: "postgres", SSLMode: "require", TLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}}},
+		{
+			config: Config{Scheme: "awspostgres", SSLMode: "verify-full", TLSMinVersion: "1.2"},
+			err:    "postgresql: tls_min_version and tls_cipher_suites are only supported with the postgres scheme",
+		},
+		{
+			config: Config{Scheme: "postgres", SSLMode: "prefer", TLSMinVersion: "1.2"},
+			err:    `postgresql: tls_min_version and tls_cipher_suites require sslmode require, verify-ca or verify-full, got "prefer"`,
+		},
+		{
+			config: Config{Scheme: "postgres", SSLMode: "require", TLSMinVersion: "1.0"},
+			err:    `postgresql: unsupported tls_min_version "1.0", expected 1.2 or 1.3`,
+		},
+		{
+			config: Config{Scheme: "postgres", SSLMode: "require", TLSCipherSuites: []string{"unknown"}},
+			err:    `unknown or insecure TLS cipher suite "unknown"`,
+		},
+	} {
+		err := test.config.checkTLSSettings()
+		if test.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorContains(t, err, test.err)
+		}
+	}
+}
+
+// Test that the TLS connection is negotiated with an SSLRequest and the certificate of the server is verified.
+func TestNegotiateTLS(t *testing.T) {
+	cert, rootCertPath := testTLSCertificate(t, "db.example.com")
+
+	for _, test := range []struct {
+		name       string
+		sslMode    string
+		serverName string
+		minVersion string
+		acceptSSL  bool
+		err        string
+	}{
+		{name: "verify-full", sslMode: "verify-full", serverName: "db.example.com", minVersion: "1.3", acceptSSL: true},
+		{name: "verify-ca ignores the name", sslMode: "verify-ca", serverName: "lb.example.com", acceptSSL: true},
+		{name: "verify-full checks the name", sslMode: "verify-full", serverName: "lb.example.com", acceptSSL: true, err: "lb.example.com"},
+		{name: "SSL disabled", sslMode: "verify-full", serverName: "db.example.com", err: "SSL is not enabled on the server"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := Config{SSLMode: test.sslMode, SSLRootCertPath: rootCertPath, TLSMinVersion: test.minVersion}
+			tlsConfig, err := config.tlsConfig(test.serverName)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			client, server := net.Pipe()
+			defer server.Close()
+			go testTLSServer(server, cert, test.acceptSSL)
+
+			conn, err := negotiateTLS(client, tlsConfig, time.Now().Add(5*time.Second))
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+			assert.Equal(t, uint16(tls.VersionTLS13), conn.(*tls.Conn).ConnectionState().Version)
+		})
+	}
+}
+
+// testTLSServer answers the SSLRequest sent on conn, then does the TLS handshake with cert if acceptSSL.
+func testTLSServer(conn net.Conn, cert tls.Certificate, acceptSSL bool) {
+	request := make([]byte, 8)
+	if _, err := conn.Read(request); err != nil || binary.BigEndian.Uint32(request[4:8]) != sslRequestCode {
+		return
+	}
+	if !acceptSSL {
+		_, _ = conn.Write([]byte{'N'})
+		return
+	}
+	if _, err := conn.Write([]byte{'S'}); err != nil {
+		return
+	}
+	// Without session tickets, nothing is written on conn after the handshake.
+	tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}, SessionTicketsDisabled: true})
+	if err := tlsConn.Handshake(); err != nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, tlsConn)
+}
+
+// testTLSCertificate returns a self-signed certificate for name and the path of its PEM file.
+func testTLSCertificate(t *testing.T, name string) (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "root.crt")
+	if err := os.WriteFile(path, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, path
+}